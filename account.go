@@ -0,0 +1,177 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	ecomqtt "github.com/tknie/ecoflow/mqtt"
+)
+
+// CredentialType type of Ecoflow credentials
+type CredentialType int
+
+const (
+	// CredentialIoT IoT Open access and secret key for signed REST requests
+	CredentialIoT CredentialType = iota
+	// CredentialApp consumer app login used for MQTT app topics
+	CredentialApp
+)
+
+func (t CredentialType) String() string {
+	if t == CredentialApp {
+		return "app login"
+	}
+	return "IoT Open key"
+}
+
+// MissingCredentialError operation requires a credential not configured in the account
+type MissingCredentialError struct {
+	Type      CredentialType
+	Operation string
+}
+
+func (e *MissingCredentialError) Error() string {
+	return fmt.Sprintf("%s requires %s credentials, not configured", e.Operation, e.Type)
+}
+
+// Account holds both Ecoflow credential sets, app login and IoT Open keys
+type Account struct {
+	AccessKey string
+	SecretKey string
+	Email     string
+	Password  string
+	Options   []ClientOption
+
+	once   sync.Once
+	client *Client
+}
+
+// AccountFromEnv create account using the environment variables ECOFLOW_ACCESS_KEY,
+// ECOFLOW_SECRET_KEY, ECOFLOW_USER and ECOFLOW_PASSWORD
+func AccountFromEnv() *Account {
+	return &Account{
+		AccessKey: os.Getenv("ECOFLOW_ACCESS_KEY"),
+		SecretKey: os.Getenv("ECOFLOW_SECRET_KEY"),
+		Email:     os.Getenv("ECOFLOW_USER"),
+		Password:  os.Getenv("ECOFLOW_PASSWORD"),
+	}
+}
+
+// HasCredential check if the credential type is configured
+func (a *Account) HasCredential(t CredentialType) bool {
+	switch t {
+	case CredentialIoT:
+		return a.AccessKey != "" && a.SecretKey != ""
+	case CredentialApp:
+		return a.Email != "" && a.Password != ""
+	}
+	return false
+}
+
+// Require return error if credential type needed by the operation is missing
+func (a *Account) Require(t CredentialType, operation string) error {
+	if !a.HasCredential(t) {
+		return &MissingCredentialError{Type: t, Operation: operation}
+	}
+	return nil
+}
+
+// Client return IoT Open REST client.
+// Requires CredentialIoT.
+func (a *Account) Client() (*Client, error) {
+	if err := a.Require(CredentialIoT, "REST client"); err != nil {
+		return nil, err
+	}
+	a.once.Do(func() {
+		a.client = NewClient(a.AccessKey, a.SecretKey, a.Options...)
+	})
+	return a.client, nil
+}
+
+// GetDeviceList return devices linked to the account.
+// Requires CredentialIoT.
+func (a *Account) GetDeviceList(ctx context.Context) (*DeviceListResponse, error) {
+	client, err := a.Client()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetDeviceList(ctx)
+}
+
+// MqttClient create MQTT client for the app topics, the account credentials
+// overwrite the credentials of the configuration.
+// Requires CredentialApp.
+func (a *Account) MqttClient(ctx context.Context, config MqttClientConfiguration) (*MqttClient, error) {
+	if err := a.Require(CredentialApp, "MQTT app client"); err != nil {
+		return nil, err
+	}
+	config.Email = a.Email
+	config.Password = a.Password
+	return NewMqttClient(ctx, config)
+}
+
+// AppLogin login using the app credentials.
+// Requires CredentialApp.
+func (a *Account) AppLogin(ctx context.Context) (*MqttLoginResponse, error) {
+	if err := a.Require(CredentialApp, "app login"); err != nil {
+		return nil, err
+	}
+	return ecomqtt.Login(ctx, defaultHTTPClient, a.Email, a.Password)
+}
+
+// ErrUnencryptedCredentials credentials are only cached using storage encryption
+var ErrUnencryptedCredentials = errors.New("credential cache needs storage encryption")
+
+// cachedAccount credentials of the credential cache
+type cachedAccount struct {
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+// SaveCredentials write the credentials into the credential cache file, the
+// file is encrypted using the storage encryption
+func (a *Account) SaveCredentials(fileName string) error {
+	if err := StorageKeyError(); err != nil {
+		return err
+	}
+	if StorageEncryption() == nil {
+		return ErrUnencryptedCredentials
+	}
+	data, err := json.Marshal(cachedAccount{AccessKey: a.AccessKey, SecretKey: a.SecretKey,
+		Email: a.Email, Password: a.Password})
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(fileName, data)
+}
+
+// LoadAccount create account using the credential cache written by SaveCredentials
+func LoadAccount(fileName string) (*Account, error) {
+	data, err := readStoredFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedAccount
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("invalid credential cache %s: %w", fileName, err)
+	}
+	return &Account{AccessKey: cached.AccessKey, SecretKey: cached.SecretKey,
+		Email: cached.Email, Password: cached.Password}, nil
+}
@@ -0,0 +1,130 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountFromEnv(t *testing.T) {
+	t.Setenv("ECOFLOW_ACCESS_KEY", "access")
+	t.Setenv("ECOFLOW_SECRET_KEY", "secret")
+	t.Setenv("ECOFLOW_USER", "user@example.com")
+	t.Setenv("ECOFLOW_PASSWORD", "")
+	account := AccountFromEnv()
+	assert.Equal(t, "access", account.AccessKey)
+	assert.Equal(t, "secret", account.SecretKey)
+	assert.Equal(t, "user@example.com", account.Email)
+	assert.Empty(t, account.Password)
+	assert.True(t, account.HasCredential(CredentialIoT))
+	assert.False(t, account.HasCredential(CredentialApp))
+}
+
+func TestAccountRequire(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *Account
+		iot     bool
+		app     bool
+	}{
+		{"empty", &Account{}, false, false},
+		{"access key only", &Account{AccessKey: "access"}, false, false},
+		{"IoT keys", &Account{AccessKey: "access", SecretKey: "secret"}, true, false},
+		{"password only", &Account{Password: "pw"}, false, false},
+		{"app login", &Account{Email: "user@example.com", Password: "pw"}, false, true},
+		{"both", &Account{AccessKey: "access", SecretKey: "secret", Email: "user@example.com", Password: "pw"}, true, true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.iot, test.account.HasCredential(CredentialIoT), test.name)
+		assert.Equal(t, test.app, test.account.HasCredential(CredentialApp), test.name)
+		assert.False(t, test.account.HasCredential(CredentialType(99)), test.name)
+		for credential, ok := range map[CredentialType]bool{CredentialIoT: test.iot, CredentialApp: test.app} {
+			err := test.account.Require(credential, "test")
+			if ok {
+				assert.NoError(t, err, test.name)
+				continue
+			}
+			var missing *MissingCredentialError
+			if assert.ErrorAs(t, err, &missing, test.name) {
+				assert.Equal(t, credential, missing.Type)
+				assert.Equal(t, "test", missing.Operation)
+			}
+		}
+	}
+	err := (&Account{}).Require(CredentialApp, "app login")
+	assert.EqualError(t, err, "app login requires app login credentials, not configured")
+}
+
+func TestAccountClient(t *testing.T) {
+	ctx := context.Background()
+	var missing *MissingCredentialError
+	app := &Account{Email: "user@example.com", Password: "pw"}
+	_, err := app.Client()
+	assert.ErrorAs(t, err, &missing)
+	_, err = app.GetDeviceList(ctx)
+	assert.ErrorAs(t, err, &missing)
+
+	iot := &Account{AccessKey: "access", SecretKey: "secret"}
+	client, err := iot.Client()
+	assert.NoError(t, err)
+	again, err := iot.Client()
+	assert.NoError(t, err)
+	assert.Same(t, client, again)
+	_, err = iot.MqttClient(ctx, MqttClientConfiguration{})
+	assert.ErrorAs(t, err, &missing)
+	_, err = iot.AppLogin(ctx)
+	assert.ErrorAs(t, err, &missing)
+}
+
+func TestAccountCredentialCache(t *testing.T) {
+	defer SetStorageEncryption(StorageEncryption())
+	dir := t.TempDir()
+	key, err := ParseStorageKey(testStorageKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	encryption, err := NewAESGCM(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	SetStorageEncryption(encryption)
+
+	// only configured credentials are cached
+	name := filepath.Join(dir, "account")
+	assert.NoError(t, (&Account{AccessKey: "access", SecretKey: "secret"}).SaveCredentials(name))
+	loaded, err := LoadAccount(name)
+	if assert.NoError(t, err) {
+		assert.True(t, loaded.HasCredential(CredentialIoT))
+		assert.False(t, loaded.HasCredential(CredentialApp))
+	}
+
+	_, err = LoadAccount(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	invalid := filepath.Join(dir, "invalid")
+	assert.NoError(t, writeStoredFile(invalid, []byte("no json")))
+	_, err = LoadAccount(invalid)
+	assert.ErrorContains(t, err, "invalid credential cache")
+
+	// cache can not be read using another key
+	other, err := NewAESGCM(make([]byte, len(key)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	SetStorageEncryption(other)
+	_, err = LoadAccount(name)
+	assert.Error(t, err)
+}
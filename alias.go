@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var aliasLock sync.RWMutex
+var deviceAliases = make(map[string]string)
+
+// SetDeviceAlias set a human readable alias for a device serial number,
+// an empty alias removes it
+func SetDeviceAlias(serialNumber, alias string) {
+	aliasLock.Lock()
+	defer aliasLock.Unlock()
+	sn := strings.ToUpper(serialNumber)
+	if alias == "" {
+		delete(deviceAliases, sn)
+		return
+	}
+	deviceAliases[sn] = alias
+}
+
+// SetDeviceAliases replace all device aliases
+func SetDeviceAliases(aliases map[string]string) {
+	aliasLock.Lock()
+	defer aliasLock.Unlock()
+	deviceAliases = make(map[string]string, len(aliases))
+	for sn, alias := range aliases {
+		deviceAliases[strings.ToUpper(sn)] = alias
+	}
+}
+
+// DeviceAlias return alias of the device, the serial number if no alias is defined
+func DeviceAlias(serialNumber string) string {
+	aliasLock.RLock()
+	defer aliasLock.RUnlock()
+	if alias, ok := deviceAliases[strings.ToUpper(serialNumber)]; ok {
+		return alias
+	}
+	return serialNumber
+}
+
+// ResolveDevice return serial number for an alias or serial number
+func ResolveDevice(aliasOrSerialNumber string) string {
+	aliasLock.RLock()
+	defer aliasLock.RUnlock()
+	for sn, alias := range deviceAliases {
+		if alias == aliasOrSerialNumber {
+			return sn
+		}
+	}
+	return aliasOrSerialNumber
+}
+
+// DeviceAliases return all defined aliases in sorted order
+func DeviceAliases() []string {
+	aliasLock.RLock()
+	defer aliasLock.RUnlock()
+	aliases := make([]string, 0, len(deviceAliases))
+	for _, alias := range deviceAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
@@ -0,0 +1,72 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceAlias(t *testing.T) {
+	SetDeviceAliases(map[string]string{"hw51alias1": "balcony", "R331ALIAS": "station"})
+	defer SetDeviceAliases(nil)
+	SetDeviceAlias("HW52ALIAS", "lamp")
+
+	tests := []struct {
+		name     string
+		sn       string
+		alias    string
+		resolved string
+	}{
+		{"lower case serial number", "hw51alias1", "balcony", "HW51ALIAS1"},
+		{"upper case serial number", "HW51ALIAS1", "balcony", "HW51ALIAS1"},
+		{"single alias", "HW52ALIAS", "lamp", "HW52ALIAS"},
+		{"no alias", "HW51OTHER", "HW51OTHER", "HW51OTHER"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.alias, DeviceAlias(test.sn), test.name)
+		assert.Equal(t, test.resolved, ResolveDevice(test.alias), test.name)
+	}
+	assert.Equal(t, []string{"balcony", "lamp", "station"}, DeviceAliases())
+
+	SetDeviceAlias("hw52alias", "")
+	assert.Equal(t, "HW52ALIAS", DeviceAlias("HW52ALIAS"))
+	assert.Equal(t, "lamp", ResolveDevice("lamp"))
+}
+
+func TestDeviceGroupLookup(t *testing.T) {
+	SetDeviceAliases(map[string]string{"HW51GROUP1": "balcony"})
+	defer SetDeviceAliases(nil)
+	SetDeviceGroups(map[string][]string{"roof": {"balcony", "hw51group2"}, "empty": {}})
+	defer SetDeviceGroups(nil)
+	SetDeviceGroup("garage", "HW52GROUP1", "hw52group1")
+
+	tests := []struct {
+		group   string
+		members []string
+		ok      bool
+	}{
+		{"roof", []string{"HW51GROUP1", "HW51GROUP2"}, true},
+		{"garage", []string{"HW52GROUP1"}, true},
+		{"empty", nil, false},
+		{"unknown", nil, false},
+	}
+	for _, test := range tests {
+		members, ok := DeviceGroup(test.group)
+		assert.Equal(t, test.ok, ok, test.group)
+		assert.Equal(t, test.members, members, test.group)
+	}
+	assert.Equal(t, []string{"garage", "roof"}, DeviceGroups())
+	SetDeviceGroup("garage")
+	assert.Equal(t, []string{"roof"}, DeviceGroups())
+}
@@ -0,0 +1,202 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventAnomaly implausible telemetry value detected, Data contains Anomaly
+const EventAnomaly EventType = "telemetry.anomaly"
+
+// AnomalyAction handling of detected anomalies
+type AnomalyAction int
+
+const (
+	// AnomalyDrop drop the value and publish an event
+	AnomalyDrop AnomalyAction = iota
+	// AnomalyTag keep the value marked in Telemetry.Anomaly and publish an event
+	AnomalyTag
+	// AnomalyAlert keep the value unchanged and only publish an event
+	AnomalyAlert
+)
+
+// AnomalyKind kind of anomaly rule
+type AnomalyKind int
+
+const (
+	// AnomalyJump value changed more than Limit within Window
+	AnomalyJump AnomalyKind = iota
+	// AnomalyRange value below Min or above Max
+	AnomalyRange
+	// AnomalyFrozen value unchanged for longer than Window
+	AnomalyFrozen
+)
+
+// AnomalyRule rule applied to numeric telemetry values with keys matching the
+// case insensitive glob pattern
+type AnomalyRule struct {
+	Name    string
+	Pattern string
+	Kind    AnomalyKind
+	Limit   float64
+	Min     float64
+	Max     float64
+	Window  time.Duration
+}
+
+// Anomaly detected anomaly
+type Anomaly struct {
+	Rule         string    `json:"rule"`
+	SerialNumber string    `json:"serialNumber"`
+	Key          string    `json:"key"`
+	Value        float64   `json:"value"`
+	Previous     float64   `json:"previous,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// DefaultAnomalyRules rules for known firmware glitches: SoC jumping more than
+// 20% in one interval, negative PV power and temperatures frozen for hours
+var DefaultAnomalyRules = []AnomalyRule{
+	{Name: "soc-jump", Pattern: "*soc*", Kind: AnomalyJump, Limit: 20, Window: 5 * time.Minute},
+	{Name: "pv-negative", Pattern: "*pv*watts*", Kind: AnomalyRange, Min: 0, Max: math.Inf(1)},
+	{Name: "temp-frozen", Pattern: "*temp*", Kind: AnomalyFrozen, Window: 3 * time.Hour},
+}
+
+type anomalySample struct {
+	value   float64
+	time    time.Time
+	since   time.Time
+	alerted bool
+}
+
+// AnomalyDetector validation stage checking telemetry values against the rules
+type AnomalyDetector struct {
+	Rules  []AnomalyRule
+	Action AnomalyAction
+
+	mu      sync.Mutex
+	samples map[string]*anomalySample
+}
+
+// NewAnomalyDetector create anomaly detector, invalid patterns return an error
+func NewAnomalyDetector(action AnomalyAction, rules ...AnomalyRule) (*AnomalyDetector, error) {
+	checked := make([]AnomalyRule, 0, len(rules))
+	for _, r := range rules {
+		r.Pattern = strings.ToLower(r.Pattern)
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("anomaly rule %s: %w", r.Name, err)
+		}
+		checked = append(checked, r)
+	}
+	return &AnomalyDetector{Rules: checked, Action: action, samples: make(map[string]*anomalySample)}, nil
+}
+
+// Check validate the values, dropped values are removed from the result
+func (d *AnomalyDetector) Check(points []*Telemetry) []*Telemetry {
+	result := points[:0:0]
+	for _, p := range points {
+		anomaly := d.check(p)
+		if anomaly == nil {
+			result = append(result, p)
+			continue
+		}
+		log.Log.Infof("Anomaly %s of %s %s=%v", anomaly.Rule, p.SerialNumber, p.Key, p.Value)
+		Events.Publish(Event{Type: EventAnomaly, SerialNumber: p.SerialNumber, Time: anomaly.Time, Data: *anomaly})
+		switch d.Action {
+		case AnomalyDrop:
+		case AnomalyTag:
+			tagged := *p
+			tagged.Anomaly = anomaly.Rule
+			result = append(result, &tagged)
+		default:
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// check validate one value and update the samples, dropped values do not replace
+// the last valid sample
+func (d *AnomalyDetector) check(p *Telemetry) *Anomaly {
+	value, ok := p.Value.(float64)
+	if !ok {
+		return nil
+	}
+	key := strings.ToLower(p.Key)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := p.SerialNumber + "/" + p.Key
+	last := d.samples[id]
+	for _, r := range d.Rules {
+		if ok, _ := path.Match(r.Pattern, key); !ok {
+			continue
+		}
+		anomaly := &Anomaly{Rule: r.Name, SerialNumber: p.SerialNumber, Key: p.Key, Value: value, Time: p.Timestamp}
+		switch r.Kind {
+		case AnomalyRange:
+			if value < r.Min || value > r.Max {
+				return anomaly
+			}
+		case AnomalyJump:
+			if last != nil && p.Timestamp.Sub(last.time) <= r.Window && math.Abs(value-last.value) > r.Limit {
+				anomaly.Previous = last.value
+				return anomaly
+			}
+		case AnomalyFrozen:
+			if last != nil && last.value == value && p.Timestamp.Sub(last.since) > r.Window {
+				if last.alerted {
+					// reported once per frozen period
+					continue
+				}
+				last.alerted = true
+				anomaly.Previous = last.value
+				return anomaly
+			}
+		}
+	}
+	if last == nil || last.value != value {
+		d.samples[id] = &anomalySample{value: value, time: p.Timestamp, since: p.Timestamp}
+	} else {
+		last.time = p.Timestamp
+	}
+	return nil
+}
+
+var anomalyLock sync.RWMutex
+var anomalyDetector *AnomalyDetector
+
+// SetAnomalyDetector set validation stage applied to dispatched telemetry after
+// the device filters, nil disables the validation
+func SetAnomalyDetector(d *AnomalyDetector) {
+	anomalyLock.Lock()
+	defer anomalyLock.Unlock()
+	anomalyDetector = d
+}
+
+// detectAnomalies apply the anomaly detector if set
+func detectAnomalies(points []*Telemetry) []*Telemetry {
+	anomalyLock.RLock()
+	d := anomalyDetector
+	anomalyLock.RUnlock()
+	if d == nil {
+		return points
+	}
+	return d.Check(points)
+}
@@ -0,0 +1,63 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	var anomalies []Anomaly
+	unsubscribe := Events.Subscribe(func(e Event) { anomalies = append(anomalies, e.Data.(Anomaly)) }, EventAnomaly)
+	defer unsubscribe()
+
+	d, err := NewAnomalyDetector(AnomalyDrop, DefaultAnomalyRules...)
+	if !assert.NoError(t, err) {
+		return
+	}
+	point := func(key string, value float64, at time.Duration) *Telemetry {
+		return &Telemetry{SerialNumber: "R331TEST", Source: SourceMqtt, Key: key, Value: value, Timestamp: ts.Add(at)}
+	}
+	assert.Len(t, d.Check([]*Telemetry{point("pd.soc", 80, 0), point("inverterHeartbeat.pv1InputWatts", 120, 0)}), 2)
+	assert.Len(t, d.Check([]*Telemetry{point("pd.soc", 20, time.Minute)}), 0)
+	assert.Len(t, d.Check([]*Telemetry{point("pd.soc", 78, 2*time.Minute)}), 1)
+	assert.Len(t, d.Check([]*Telemetry{point("inverterHeartbeat.pv1InputWatts", -5, time.Minute)}), 0)
+	if assert.Len(t, anomalies, 2) {
+		assert.Equal(t, "soc-jump", anomalies[0].Rule)
+		assert.Equal(t, 80.0, anomalies[0].Previous)
+		assert.Equal(t, "pv-negative", anomalies[1].Rule)
+	}
+
+	tagger, err := NewAnomalyDetector(AnomalyTag, DefaultAnomalyRules...)
+	assert.NoError(t, err)
+	tagger.Check([]*Telemetry{point("bms_bmsStatus.temp", 25, 0)})
+	tagger.Check([]*Telemetry{point("bms_bmsStatus.temp", 25, 2*time.Hour)})
+	frozen := tagger.Check([]*Telemetry{point("bms_bmsStatus.temp", 25, 4*time.Hour)})
+	if assert.Len(t, frozen, 1) {
+		assert.Equal(t, "temp-frozen", frozen[0].Anomaly)
+	}
+	again := tagger.Check([]*Telemetry{point("bms_bmsStatus.temp", 25, 5*time.Hour)})
+	assert.Equal(t, "", again[0].Anomaly)
+	assert.Len(t, anomalies, 3)
+
+	SetAnomalyDetector(d)
+	defer SetAnomalyDetector(nil)
+	var received []*Telemetry
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) { received = append(received, points...) })
+	defer unregister()
+	DispatchTelemetry([]*Telemetry{point("inverterHeartbeat.pv2InputWatts", -1, 0), point("pd.model", 1, 0)})
+	assert.Len(t, received, 1)
+}
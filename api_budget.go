@@ -0,0 +1,184 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIQuota request limits of the IoT Open API per access key
+type APIQuota struct {
+	PerMinute int `json:"perMinute"`
+	PerDay    int `json:"perDay"`
+}
+
+// DefaultAPIQuota quota assumed for all access keys, adjust it with SetQuota
+// to the limits of the account
+var DefaultAPIQuota = APIQuota{PerMinute: 100, PerDay: 10000}
+
+// lowBudgetRatio remaining daily budget ratio reported as low
+const lowBudgetRatio = 0.1
+
+// APIUsage used and remaining requests of an access key
+type APIUsage struct {
+	AccessKey       string `json:"accessKey"`
+	Minute          int    `json:"minute"`
+	Day             int    `json:"day"`
+	RemainingMinute int    `json:"remainingMinute"`
+	RemainingDay    int    `json:"remainingDay"`
+	// Forecast requests expected until the end of the day at the current rate
+	Forecast int  `json:"forecast"`
+	Low      bool `json:"low"`
+}
+
+// APIBudgetTracker count the REST requests per access key
+type APIBudgetTracker struct {
+	mu    sync.Mutex
+	quota APIQuota
+	keys  map[string]*apiBudget
+}
+
+type apiBudget struct {
+	minute   []time.Time
+	dayStart time.Time
+	day      int
+}
+
+// APIBudgets global tracker counting all requests of the clients
+var APIBudgets = NewAPIBudgetTracker(DefaultAPIQuota)
+
+func init() {
+	expvar.Publish("ecoflowAPIBudget", expvar.Func(func() any { return APIBudgets.All() }))
+}
+
+// NewAPIBudgetTracker create tracker using the quota for all access keys
+func NewAPIBudgetTracker(quota APIQuota) *APIBudgetTracker {
+	return &APIBudgetTracker{quota: quota, keys: make(map[string]*apiBudget)}
+}
+
+// SetQuota set quota of all access keys
+func (t *APIBudgetTracker) SetQuota(quota APIQuota) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.quota = quota
+}
+
+// Quota return the quota of the access keys
+func (t *APIBudgetTracker) Quota() APIQuota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.quota
+}
+
+// budget return the counters of the access key, expired requests are removed
+func (t *APIBudgetTracker) budget(accessKey string, at time.Time) *apiBudget {
+	b, ok := t.keys[accessKey]
+	if !ok {
+		b = &apiBudget{}
+		t.keys[accessKey] = b
+	}
+	if day := startOfDay(at); !b.dayStart.Equal(day) {
+		b.dayStart = day
+		b.day = 0
+	}
+	i := 0
+	for i < len(b.minute) && at.Sub(b.minute[i]) >= time.Minute {
+		i++
+	}
+	b.minute = b.minute[i:]
+	return b
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Record count one request of the access key
+func (t *APIBudgetTracker) Record(accessKey string) {
+	at := now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.budget(accessKey, at)
+	b.minute = append(b.minute, at)
+	b.day++
+}
+
+// Usage return used and remaining requests of the access key
+func (t *APIBudgetTracker) Usage(accessKey string) APIUsage {
+	at := now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage(accessKey, t.budget(accessKey, at), at)
+}
+
+func (t *APIBudgetTracker) usage(accessKey string, b *apiBudget, at time.Time) APIUsage {
+	u := APIUsage{AccessKey: maskKey(accessKey), Minute: len(b.minute), Day: b.day,
+		RemainingMinute: max(t.quota.PerMinute-len(b.minute), 0),
+		RemainingDay:    max(t.quota.PerDay-b.day, 0), Forecast: b.day}
+	if elapsed := at.Sub(b.dayStart); elapsed > 0 {
+		remaining := b.dayStart.AddDate(0, 0, 1).Sub(at)
+		u.Forecast += int(float64(b.day) * float64(remaining) / float64(elapsed))
+	}
+	u.Low = t.quota.PerDay > 0 && float64(u.RemainingDay) < lowBudgetRatio*float64(t.quota.PerDay)
+	return u
+}
+
+// All return the usage of all access keys
+func (t *APIBudgetTracker) All() []APIUsage {
+	at := now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	list := make([]APIUsage, 0, len(t.keys))
+	for key := range t.keys {
+		list = append(list, t.usage(key, t.budget(key, at), at))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].AccessKey < list[j].AccessKey })
+	return list
+}
+
+// Stretch return the interval of a periodic request sequence stretched so the
+// daily budget of the access key lasts until the end of the day and the minute
+// limit is not exceeded. requests is the number of requests per interval.
+func (t *APIBudgetTracker) Stretch(accessKey string, interval time.Duration, requests int) time.Duration {
+	at := now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.budget(accessKey, at)
+	stretched := interval
+	if t.quota.PerDay > 0 && interval > 0 && requests > 0 {
+		remaining := t.quota.PerDay - b.day
+		left := b.dayStart.AddDate(0, 0, 1).Sub(at)
+		if remaining <= 0 {
+			stretched = max(stretched, left)
+		} else if needed := float64(left) / float64(interval) * float64(requests); needed > float64(remaining) {
+			stretched = time.Duration(float64(interval) * needed / float64(remaining))
+		}
+	}
+	if t.quota.PerMinute > 0 && len(b.minute)+requests > t.quota.PerMinute && len(b.minute) > 0 {
+		// wait until enough requests of the window are expired
+		i := min(len(b.minute)+requests-t.quota.PerMinute, len(b.minute)) - 1
+		stretched = max(stretched, b.minute[i].Add(time.Minute).Sub(at))
+	}
+	return stretched
+}
+
+// maskKey show only the start of the access key
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
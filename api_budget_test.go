@@ -0,0 +1,109 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIBudgetUsage(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	tracker := NewAPIBudgetTracker(APIQuota{PerMinute: 5, PerDay: 100})
+	for i := 0; i < 4; i++ {
+		tracker.Record("accesskey")
+	}
+	usage := tracker.Usage("accesskey")
+	assert.Equal(t, APIUsage{AccessKey: "acce****", Minute: 4, Day: 4, RemainingMinute: 1,
+		RemainingDay: 96, Forecast: 16}, usage)
+
+	clock.Advance(time.Minute)
+	for i := 0; i < 86; i++ {
+		tracker.Record("accesskey")
+	}
+	usage = tracker.Usage("accesskey")
+	assert.Equal(t, 86, usage.Minute)
+	assert.Equal(t, 0, usage.RemainingMinute)
+	assert.Equal(t, 10, usage.RemainingDay)
+	assert.False(t, usage.Low)
+	tracker.Record("accesskey")
+	assert.True(t, tracker.Usage("accesskey").Low)
+	assert.Len(t, tracker.All(), 1)
+
+	// new day resets the daily counter
+	clock.Advance(18 * time.Hour)
+	usage = tracker.Usage("accesskey")
+	assert.Equal(t, 0, usage.Day)
+	assert.Equal(t, 100, usage.RemainingDay)
+}
+
+func TestAPIBudgetStretch(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	tracker := NewAPIBudgetTracker(APIQuota{PerDay: 720})
+	// 12 hours left, 720 polls of one request fit into the budget
+	assert.Equal(t, time.Minute, tracker.Stretch("key", time.Minute, 1))
+	// two requests per poll need twice the interval
+	assert.Equal(t, 2*time.Minute, tracker.Stretch("key", time.Minute, 2).Round(time.Second))
+	for i := 0; i < 720; i++ {
+		tracker.Record("key")
+	}
+	assert.Equal(t, 12*time.Hour, tracker.Stretch("key", time.Minute, 1))
+
+	tracker = NewAPIBudgetTracker(APIQuota{PerMinute: 3})
+	tracker.Record("key")
+	clock.Advance(20 * time.Second)
+	tracker.Record("key")
+	tracker.Record("key")
+	assert.Equal(t, 40*time.Second, tracker.Stretch("key", time.Second, 1))
+	assert.Equal(t, time.Minute, tracker.Stretch("key", time.Second, 2))
+}
+
+func TestPollerBudget(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+	old := APIBudgets
+	APIBudgets = NewAPIBudgetTracker(APIQuota{PerDay: 1442})
+	defer func() { APIBudgets = old }()
+
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0})
+	client := newTestClient(t, transport)
+
+	var received []*Telemetry
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		received = append(received, points...)
+	})
+	defer unregister()
+
+	poller := NewPoller(client, time.Minute, "HW51POLL1", "HW51POLL2")
+	assert.Equal(t, time.Minute, poller.NextInterval())
+	assert.NoError(t, poller.Poll(context.Background()))
+	assert.Equal(t, 2, APIBudgets.Usage("access").Day)
+	assert.NotEmpty(t, received)
+	assert.Equal(t, time.Minute, poller.NextInterval())
+	// other requests leave 720 requests for the 720 polls of two devices
+	for i := 0; i < 720; i++ {
+		APIBudgets.Record("access")
+	}
+	assert.Equal(t, 2*time.Minute, poller.NextInterval())
+	poller.MaxInterval = 90 * time.Second
+	assert.Equal(t, 90*time.Second, poller.NextInterval())
+}
@@ -0,0 +1,186 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// DesiredState declarative spec of device settings, the devices are given by
+// serial number or alias
+type DesiredState struct {
+	Devices map[string]map[string]float64 `json:"devices"`
+}
+
+// ReadDesiredState read JSON spec of the desired device settings
+func ReadDesiredState(r io.Reader) (*DesiredState, error) {
+	state := &DesiredState{}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(state); err != nil {
+		return nil, fmt.Errorf("invalid desired state: %w", err)
+	}
+	return state, nil
+}
+
+// PlanAction action of one planned setting
+type PlanAction string
+
+const (
+	PlanNoop    PlanAction = "noop"
+	PlanUpdate  PlanAction = "update"
+	PlanInvalid PlanAction = "invalid"
+)
+
+// PlanChange planned change of one device setting
+type PlanChange struct {
+	SerialNumber string     `json:"serialNumber"`
+	Setting      string     `json:"setting"`
+	Action       PlanAction `json:"action"`
+	Current      *float64   `json:"current,omitempty"`
+	Desired      float64    `json:"desired"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Plan difference between desired and current device settings
+type Plan struct {
+	Changes []PlanChange `json:"changes"`
+}
+
+// Updates return number of settings to be changed
+func (p *Plan) Updates() int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == PlanUpdate {
+			n++
+		}
+	}
+	return n
+}
+
+// String format the plan, unchanged settings are omitted
+func (p *Plan) String() string {
+	var buffer bytes.Buffer
+	invalid := 0
+	for _, c := range p.Changes {
+		switch c.Action {
+		case PlanUpdate:
+			current := "(unknown)"
+			if c.Current != nil {
+				current = strconv.FormatFloat(*c.Current, 'f', -1, 64)
+			}
+			fmt.Fprintf(&buffer, "  ~ %s.%s: %s -> %s\n", DeviceAlias(c.SerialNumber), c.Setting,
+				current, strconv.FormatFloat(c.Desired, 'f', -1, 64))
+		case PlanInvalid:
+			invalid++
+			fmt.Fprintf(&buffer, "  ! %s.%s: %s\n", DeviceAlias(c.SerialNumber), c.Setting, c.Error)
+		}
+	}
+	fmt.Fprintf(&buffer, "Plan: %d to change, %d unchanged, %d invalid.\n", p.Updates(),
+		len(p.Changes)-p.Updates()-invalid, invalid)
+	return buffer.String()
+}
+
+// PlanState compare the desired state with the current device quota
+func (client *Client) PlanState(ctx context.Context, desired *DesiredState) (*Plan, error) {
+	devices := make([]string, 0, len(desired.Devices))
+	for d := range desired.Devices {
+		devices = append(devices, d)
+	}
+	sort.Strings(devices)
+	plan := &Plan{Changes: make([]PlanChange, 0)}
+	for _, d := range devices {
+		sn := ResolveDevice(d)
+		quota, err := client.GetDeviceAllParameters(ctx, sn)
+		if err != nil {
+			return nil, fmt.Errorf("read quota of %s: %w", d, err)
+		}
+		current := quotaSettings(DetectModel(sn), quota)
+		names := make([]string, 0, len(desired.Devices[d]))
+		for name := range desired.Devices[d] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c := PlanChange{SerialNumber: sn, Setting: name, Desired: desired.Devices[d][name], Action: PlanUpdate}
+			if v, ok := current[name]; ok {
+				c.Current = &v
+			}
+			setting, err := lookupDeviceSetting(sn, name)
+			switch {
+			case err != nil:
+				c.Action = PlanInvalid
+				c.Error = err.Error()
+			case c.Current != nil && settingEqual(*c.Current, c.Desired):
+				c.Action = PlanNoop
+			default:
+				if err := DeviceLimits.Validate(sn, setting.Name, c.Desired); err != nil {
+					c.Action = PlanInvalid
+					c.Error = err.Error()
+				}
+			}
+			plan.Changes = append(plan.Changes, c)
+		}
+	}
+	return plan, nil
+}
+
+// ApplyPlan issue the commands of the planned updates, the devices are verified
+// like ApplySettings
+func (client *Client) ApplyPlan(ctx context.Context, plan *Plan) ([]SettingResult, error) {
+	bySerial := make(map[string]map[string]float64)
+	order := make([]string, 0)
+	for _, c := range plan.Changes {
+		if c.Action != PlanUpdate {
+			continue
+		}
+		if _, ok := bySerial[c.SerialNumber]; !ok {
+			bySerial[c.SerialNumber] = make(map[string]float64)
+			order = append(order, c.SerialNumber)
+		}
+		bySerial[c.SerialNumber][c.Setting] = c.Desired
+	}
+	results := make([]SettingResult, 0)
+	var errs []error
+	for _, sn := range order {
+		r, err := client.ApplySettings(ctx, sn, &SettingsDocument{SerialNumber: sn, Settings: bySerial[sn]})
+		results = append(results, r...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", DeviceAlias(sn), err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// Apply plan the desired state and issue only the necessary commands. The plan
+// is returned together with the results; invalid settings are reported in the
+// plan and stop the apply before any command is sent.
+func (client *Client) Apply(ctx context.Context, desired *DesiredState) (*Plan, []SettingResult, error) {
+	plan, err := client.PlanState(ctx, desired)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range plan.Changes {
+		if c.Action == PlanInvalid {
+			return plan, nil, fmt.Errorf("invalid setting %s of %s: %s", c.Setting, DeviceAlias(c.SerialNumber), c.Error)
+		}
+	}
+	results, err := client.ApplyPlan(ctx, plan)
+	return plan, results, err
+}
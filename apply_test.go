@@ -0,0 +1,64 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDesiredState(t *testing.T) {
+	oldInterval := SettingsVerifyInterval
+	SettingsVerifyInterval = time.Millisecond
+	defer func() { SettingsVerifyInterval = oldInterval }()
+
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0, "20_1.lowerLimit": 10.0})
+	client := newTestClient(t, transport)
+
+	desired, err := ReadDesiredState(strings.NewReader(`{"devices":{"HW51APPLY":{"permanentWatts":200,"lowerLimit":10}}}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	plan, err := client.PlanState(context.Background(), desired)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, plan.Updates())
+	assert.Equal(t, "  ~ HW51APPLY.permanentWatts: 100 -> 200\nPlan: 1 to change, 1 unchanged, 0 invalid.\n", plan.String())
+
+	plan, results, err := client.Apply(context.Background(), desired)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, plan.Updates())
+	if assert.Len(t, results, 1) {
+		assert.True(t, results[0].Verified)
+	}
+	assert.Equal(t, 1, transport.puts())
+
+	plan, results, err = client.Apply(context.Background(), desired)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, plan.Updates())
+	assert.Len(t, results, 0)
+	assert.Equal(t, 1, transport.puts())
+
+	desired.Devices["HW51APPLY"]["permanentWatts"] = 5000
+	plan, _, err = client.Apply(context.Background(), desired)
+	assert.Error(t, err)
+	assert.Contains(t, plan.String(), "! HW51APPLY.permanentWatts")
+	assert.Equal(t, 1, transport.puts())
+
+	_, err = ReadDesiredState(strings.NewReader(`{"device":{}}`))
+	assert.Error(t, err)
+}
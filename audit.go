@@ -0,0 +1,242 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// defaultAuditSize number of audit entries kept in memory
+const defaultAuditSize = 1000
+
+type actorKey struct{}
+
+// AuditEntry audit record of an issued command
+type AuditEntry struct {
+	ID           string          `json:"id"`
+	Time         time.Time       `json:"time"`
+	Actor        string          `json:"actor"`
+	SerialNumber string          `json:"serialNumber"`
+	Request      CmdSetRequest   `json:"request"`
+	Response     *CmdSetResponse `json:"response,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Duration     time.Duration   `json:"duration"`
+	Verification string          `json:"verification,omitempty"`
+}
+
+// AuditSink persistent storage of audit entries
+type AuditSink interface {
+	// Record store a new audit entry
+	Record(entry AuditEntry) error
+	// Verify update the stored entry with the verification result
+	Verify(entry AuditEntry) error
+}
+
+// auditVerification verification record appended to audit files
+type auditVerification struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	Verification string    `json:"verification"`
+}
+
+// AuditFilter filter of audit queries, empty fields match all entries
+type AuditFilter struct {
+	SerialNumber string
+	Actor        string
+	Since        time.Time
+	Limit        int
+}
+
+// AuditLog in-memory ring buffer of issued commands with optional sinks
+type AuditLog struct {
+	mu      sync.Mutex
+	size    int
+	entries []AuditEntry
+	sinks   []AuditSink
+}
+
+// Audit audit log recording all commands send by clients
+var Audit = NewAuditLog(defaultAuditSize)
+
+// NewAuditLog create new audit log keeping size entries in memory
+func NewAuditLog(size int) *AuditLog {
+	return &AuditLog{size: size, entries: make([]AuditEntry, 0)}
+}
+
+// WithActor return context issuing commands on behalf of the given actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext return actor of the context, "unknown" if not set
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return "unknown"
+}
+
+// AddSink add persistent sink for new audit entries
+func (a *AuditLog) AddSink(sink AuditSink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sinks = append(a.sinks, sink)
+}
+
+// Record add entry to the audit log and all sinks
+func (a *AuditLog) Record(entry AuditEntry) {
+	a.mu.Lock()
+	a.entries = append(a.entries, entry)
+	if a.size > 0 && len(a.entries) > a.size {
+		a.entries = append(a.entries[:0], a.entries[len(a.entries)-a.size:]...)
+	}
+	sinks := append([]AuditSink(nil), a.sinks...)
+	a.mu.Unlock()
+	for _, s := range sinks {
+		if err := s.Record(entry); err != nil {
+			log.Log.Errorf("Error recording audit entry %s: %v", entry.ID, err)
+		}
+	}
+}
+
+// Verified add verification result to the audit entry with the given ID
+func (a *AuditLog) Verified(id, result string) {
+	a.mu.Lock()
+	var entry *AuditEntry
+	for i := len(a.entries) - 1; i >= 0; i-- {
+		if a.entries[i].ID == id {
+			a.entries[i].Verification = result
+			e := a.entries[i]
+			entry = &e
+			break
+		}
+	}
+	sinks := append([]AuditSink(nil), a.sinks...)
+	a.mu.Unlock()
+	if entry == nil {
+		return
+	}
+	for _, s := range sinks {
+		if err := s.Verify(*entry); err != nil {
+			log.Log.Errorf("Error recording audit verification %s: %v", entry.ID, err)
+		}
+	}
+}
+
+// Query return audit entries matching the filter, newest first
+func (a *AuditLog) Query(filter AuditFilter) []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	result := make([]AuditEntry, 0)
+	for i := len(a.entries) - 1; i >= 0; i-- {
+		e := a.entries[i]
+		if filter.SerialNumber != "" && !strings.EqualFold(filter.SerialNumber, e.SerialNumber) {
+			continue
+		}
+		if filter.Actor != "" && filter.Actor != e.Actor {
+			continue
+		}
+		if !filter.Since.IsZero() && e.Time.Before(filter.Since) {
+			continue
+		}
+		result = append(result, e)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// FileAuditSink append audit entries as JSON lines to a file
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink open audit file for appending
+func NewFileAuditSink(fileName string) (*FileAuditSink, error) {
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: f}, nil
+}
+
+// Record append entry to the audit file
+func (s *FileAuditSink) Record(entry AuditEntry) error {
+	return s.write(entry)
+}
+
+// Verify append the verification result of the entry to the audit file, the
+// entry itself is not written again
+func (s *FileAuditSink) Verify(entry AuditEntry) error {
+	return s.write(auditVerification{ID: entry.ID, Time: now(), Verification: entry.Verification})
+}
+
+func (s *FileAuditSink) write(record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close close audit file
+func (s *FileAuditSink) Close() error {
+	return s.file.Close()
+}
+
+// SQLAuditSink insert audit entries into a database table with the columns
+// id, time, actor, serial_number, entry
+type SQLAuditSink struct {
+	db     *sql.DB
+	query  string
+	update string
+}
+
+// NewSQLAuditSink create new database audit sink for the given table
+func NewSQLAuditSink(db *sql.DB, table string) *SQLAuditSink {
+	return &SQLAuditSink{db: db, query: "INSERT INTO " + table +
+		" (id, time, actor, serial_number, entry) VALUES ($1, $2, $3, $4, $5)",
+		update: "UPDATE " + table + " SET entry = $1 WHERE id = $2"}
+}
+
+// Record insert entry into the audit table
+func (s *SQLAuditSink) Record(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(s.query, entry.ID, entry.Time, entry.Actor, entry.SerialNumber, string(data))
+	return err
+}
+
+// Verify update the entry of the audit table row with the verification result
+func (s *SQLAuditSink) Verify(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(s.update, string(data), entry.ID)
+	return err
+}
@@ -0,0 +1,132 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingAuditSink struct {
+	records  []AuditEntry
+	verifies []AuditEntry
+}
+
+func (s *recordingAuditSink) Record(entry AuditEntry) error {
+	s.records = append(s.records, entry)
+	return nil
+}
+
+func (s *recordingAuditSink) Verify(entry AuditEntry) error {
+	s.verifies = append(s.verifies, entry)
+	return nil
+}
+
+func TestAuditLogRing(t *testing.T) {
+	a := NewAuditLog(3)
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		a.Record(AuditEntry{ID: id, Time: start.Add(time.Duration(i) * time.Minute)})
+	}
+	entries := a.Query(AuditFilter{})
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, "5", entries[0].ID)
+		assert.Equal(t, "3", entries[2].ID)
+	}
+}
+
+func TestAuditLogQuery(t *testing.T) {
+	a := NewAuditLog(0)
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	a.Record(AuditEntry{ID: "1", Time: start, Actor: "cli", SerialNumber: "HW51AUDIT"})
+	a.Record(AuditEntry{ID: "2", Time: start.Add(time.Minute), Actor: "scheduler", SerialNumber: "HW51AUDIT"})
+	a.Record(AuditEntry{ID: "3", Time: start.Add(2 * time.Minute), Actor: "cli", SerialNumber: "R331AUDIT"})
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		ids    []string
+	}{
+		{"all", AuditFilter{}, []string{"3", "2", "1"}},
+		{"serial number", AuditFilter{SerialNumber: "hw51audit"}, []string{"2", "1"}},
+		{"actor", AuditFilter{Actor: "cli"}, []string{"3", "1"}},
+		{"since", AuditFilter{Since: start.Add(time.Minute)}, []string{"3", "2"}},
+		{"limit", AuditFilter{Limit: 1}, []string{"3"}},
+		{"none", AuditFilter{Actor: "webhook"}, []string{}},
+	}
+	for _, test := range tests {
+		ids := make([]string, 0)
+		for _, e := range a.Query(test.filter) {
+			ids = append(ids, e.ID)
+		}
+		assert.Equal(t, test.ids, ids, test.name)
+	}
+}
+
+func TestAuditLogSinks(t *testing.T) {
+	a := NewAuditLog(10)
+	sink := &recordingAuditSink{}
+	a.AddSink(sink)
+	a.Record(AuditEntry{ID: "1", SerialNumber: "HW51AUDIT"})
+	a.Verified("1", "verified")
+	a.Verified("unknown", "verified")
+	assert.Len(t, sink.records, 1)
+	if assert.Len(t, sink.verifies, 1) {
+		assert.Equal(t, "1", sink.verifies[0].ID)
+		assert.Equal(t, "verified", sink.verifies[0].Verification)
+	}
+	assert.Equal(t, "verified", a.Query(AuditFilter{})[0].Verification)
+}
+
+func TestFileAuditSink(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileAuditSink(fileName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a := NewAuditLog(10)
+	a.AddSink(sink)
+	a.Record(AuditEntry{ID: "1", SerialNumber: "HW51AUDIT"})
+	a.Verified("1", "mismatch")
+	assert.NoError(t, sink.Close())
+
+	f, err := os.Open(fileName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	lines := make([]map[string]interface{}, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, "HW51AUDIT", lines[0]["serialNumber"])
+		assert.Equal(t, "1", lines[1]["id"])
+		assert.Equal(t, "mismatch", lines[1]["verification"])
+		assert.NotContains(t, lines[1], "serialNumber")
+	}
+}
+
+func TestSQLAuditSinkQueries(t *testing.T) {
+	sink := NewSQLAuditSink(nil, "audit")
+	assert.Equal(t, "INSERT INTO audit (id, time, actor, serial_number, entry) VALUES ($1, $2, $3, $4, $5)", sink.query)
+	assert.Equal(t, "UPDATE audit SET entry = $1 WHERE id = $2", sink.update)
+}
@@ -0,0 +1,208 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+const (
+	keyBatSoc        = "inverterHeartbeat.batSoc"
+	keyPv1InputWatts = "inverterHeartbeat.pv1InputWatts"
+	keyPv2InputWatts = "inverterHeartbeat.pv2InputWatts"
+)
+
+// InverterShare input and result of the output split of one inverter
+type InverterShare struct {
+	SerialNumber string  `json:"serialNumber"`
+	Soc          float64 `json:"soc"`
+	PvWatts      float64 `json:"pvWatts"`
+	MaxWatts     float64 `json:"maxWatts"`
+	Watts        float64 `json:"watts"`
+}
+
+// Balancer split a requested total output across several PowerStream inverters
+// proportionally to their battery level and PV production
+type Balancer struct {
+	client    *Client
+	inverters []string
+	// SocWatts weight of one percent battery level in watts PV production
+	SocWatts float64
+	// Tolerance output changes below this value are not sent
+	Tolerance float64
+	// MinInterval minimum time between rebalancing triggered by telemetry
+	MinInterval time.Duration
+	mu          sync.Mutex
+	total       float64
+	applied     map[string]float64
+	trigger     chan struct{}
+}
+
+// NewBalancer create balancer for the inverters
+func NewBalancer(client *Client, inverters ...string) *Balancer {
+	return &Balancer{client: client, inverters: inverters, SocWatts: 5, Tolerance: 10,
+		MinInterval: time.Minute, applied: make(map[string]float64), trigger: make(chan struct{}, 1)}
+}
+
+// SplitOutput split the total output in proportion to the weight PV watts plus
+// battery level times socWatts, limited by the maximum of each inverter. The
+// output exceeding the maximum of an inverter is distributed to the others.
+func SplitOutput(total float64, shares []InverterShare, socWatts float64) []InverterShare {
+	result := append([]InverterShare{}, shares...)
+	open := make([]int, 0, len(result))
+	for i := range result {
+		result[i].Watts = 0
+		if result[i].MaxWatts > 0 {
+			open = append(open, i)
+		}
+	}
+	remaining := total
+	for remaining > 0.5 && len(open) > 0 {
+		sum := 0.0
+		for _, i := range open {
+			sum += shareWeight(result[i], socWatts)
+		}
+		next := open[:0]
+		distributed := 0.0
+		for _, i := range open {
+			part := remaining / float64(len(open))
+			if sum > 0 {
+				part = remaining * shareWeight(result[i], socWatts) / sum
+			}
+			free := result[i].MaxWatts - result[i].Watts
+			if part >= free {
+				part = free
+			} else {
+				next = append(next, i)
+			}
+			result[i].Watts += part
+			distributed += part
+		}
+		remaining -= distributed
+		open = next
+		if distributed == 0 {
+			break
+		}
+	}
+	for i := range result {
+		result[i].Watts = math.Round(result[i].Watts)
+	}
+	return result
+}
+
+func shareWeight(s InverterShare, socWatts float64) float64 {
+	return math.Max(0, s.PvWatts) + math.Max(0, s.Soc)*socWatts
+}
+
+// shares collect the current state of the inverters
+func (b *Balancer) shares() []InverterShare {
+	shares := make([]InverterShare, 0, len(b.inverters))
+	for _, sn := range b.inverters {
+		s := InverterShare{SerialNumber: sn}
+		if v, ok := States.Get(sn, keyBatSoc); ok {
+			s.Soc, _ = v.Value.(float64)
+		}
+		for _, k := range []string{keyPv1InputWatts, keyPv2InputWatts} {
+			if v, ok := States.Get(sn, k); ok {
+				w, _ := v.Value.(float64)
+				// reported in 0.1 watt
+				s.PvWatts += w / 10
+			}
+		}
+		if r, ok := DeviceLimits.Get(sn, "permanentWatts"); ok {
+			s.MaxWatts = r.Max
+		}
+		shares = append(shares, s)
+	}
+	return shares
+}
+
+// SetTotal set the requested total output and distribute it
+func (b *Balancer) SetTotal(ctx context.Context, watts float64) ([]InverterShare, error) {
+	b.mu.Lock()
+	b.total = watts
+	b.mu.Unlock()
+	return b.Rebalance(ctx)
+}
+
+// Rebalance distribute the requested total output using the current telemetry
+func (b *Balancer) Rebalance(ctx context.Context) ([]InverterShare, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	shares := SplitOutput(b.total, b.shares(), b.SocWatts)
+	var errs []error
+	for _, s := range shares {
+		if last, ok := b.applied[s.SerialNumber]; ok && math.Abs(last-s.Watts) < b.Tolerance {
+			continue
+		}
+		if _, err := b.client.SetPermanentWatts(ctx, s.SerialNumber, s.Watts); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		b.applied[s.SerialNumber] = s.Watts
+	}
+	return shares, errors.Join(errs...)
+}
+
+// ObserveTelemetry telemetry handler triggering rebalancing on changes of the
+// battery level or PV production of the inverters
+func (b *Balancer) ObserveTelemetry(points []*Telemetry) {
+	for _, p := range points {
+		switch p.Key {
+		case keyBatSoc, keyPv1InputWatts, keyPv2InputWatts:
+		default:
+			continue
+		}
+		for _, sn := range b.inverters {
+			if strings.EqualFold(sn, p.SerialNumber) {
+				select {
+				case b.trigger <- struct{}{}:
+				default:
+				}
+				return
+			}
+		}
+	}
+}
+
+// Run rebalance on telemetry changes, at most once per MinInterval, until the
+// context is done
+func (b *Balancer) Run(ctx context.Context) error {
+	unregister := RegisterTelemetryHandler(b.ObserveTelemetry)
+	defer unregister()
+	last := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.trigger:
+		}
+		if wait := b.MinInterval - since(last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-CurrentClock().After(wait):
+			}
+		}
+		last = now()
+		if _, err := b.Rebalance(ctx); err != nil {
+			services.ServerMessage("Rebalancing inverters failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitOutput(t *testing.T) {
+	shares := []InverterShare{
+		{SerialNumber: "HW51A", Soc: 80, PvWatts: 100, MaxWatts: 800},
+		{SerialNumber: "HW51B", Soc: 20, PvWatts: 0, MaxWatts: 800},
+	}
+	result := SplitOutput(600, shares, 5)
+	// weights 500 and 100
+	assert.Equal(t, 500.0, result[0].Watts)
+	assert.Equal(t, 100.0, result[1].Watts)
+	assert.Equal(t, 0.0, shares[0].Watts)
+
+	// the part above the maximum is moved to the other inverter
+	shares[0].MaxWatts = 300
+	result = SplitOutput(600, shares, 5)
+	assert.Equal(t, 300.0, result[0].Watts)
+	assert.Equal(t, 300.0, result[1].Watts)
+
+	// without any weight the output is split equally
+	result = SplitOutput(400, []InverterShare{{MaxWatts: 800}, {MaxWatts: 800}}, 5)
+	assert.Equal(t, 200.0, result[0].Watts)
+	assert.Equal(t, 200.0, result[1].Watts)
+}
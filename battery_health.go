@@ -0,0 +1,282 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventBatteryHealth published once if the capacity of a pack drops below HealthWarnCapacity
+const EventBatteryHealth EventType = "battery.health"
+
+// HealthWarnCapacity remaining capacity in percent of the design capacity
+// a warning is published for
+var HealthWarnCapacity = 80.0
+
+// healthRetentionDays number of daily samples kept per pack
+const healthRetentionDays = 400
+
+// BatteryHealth health tracker fed by all dispatched telemetry
+var BatteryHealth = NewBatteryHealthTracker()
+
+func init() {
+	RegisterTelemetryHandler(BatteryHealth.Update)
+}
+
+// HealthSample daily health sample of a battery pack
+type HealthSample struct {
+	Day     string  `json:"day"`
+	Cycles  float64 `json:"cycles"`
+	FullCap float64 `json:"fullCap"`
+	SocMin  float64 `json:"socMin"`
+	SocMax  float64 `json:"socMax"`
+}
+
+// PackHistory collected history of a battery pack
+type PackHistory struct {
+	DesignCap float64         `json:"designCap,omitempty"`
+	Warned    bool            `json:"warned,omitempty"`
+	Samples   []*HealthSample `json:"samples"`
+}
+
+// PackHealth health estimation of a battery pack, capacities in mAh
+type PackHealth struct {
+	SerialNumber string  `json:"serialNumber"`
+	Pack         string  `json:"pack"`
+	Cycles       float64 `json:"cycles"`
+	FullCap      float64 `json:"fullCap"`
+	DesignCap    float64 `json:"designCap"`
+	// Capacity remaining capacity in percent of the design capacity, the first
+	// seen full capacity is used if the device does not report the design capacity
+	Capacity float64 `json:"capacity"`
+	// FadePerMonth capacity loss in percent per 30 days
+	FadePerMonth float64 `json:"fadePerMonth"`
+	// AvgSocSwing average daily SoC swing in percent
+	AvgSocSwing float64         `json:"avgSocSwing"`
+	Warning     bool            `json:"warning"`
+	Trend       []*HealthSample `json:"trend"`
+}
+
+// BatteryHealthTracker aggregate cycle count, full charge capacity and SoC swing
+// of all battery packs. Packs are identified by the key prefix of the pack
+// values, e.g. bms_bmsStatus or bms_slave_bmsSlaveStatus_1.
+type BatteryHealthTracker struct {
+	mu       sync.Mutex
+	location *time.Location
+	devices  map[string]map[string]*PackHistory
+}
+
+// NewBatteryHealthTracker create empty tracker with days evaluated in local time
+func NewBatteryHealthTracker() *BatteryHealthTracker {
+	return &BatteryHealthTracker{location: time.Local, devices: make(map[string]map[string]*PackHistory)}
+}
+
+// splitPackKey split telemetry key into pack prefix and pack value name
+func splitPackKey(key string) (string, string) {
+	i := strings.LastIndex(key, ".")
+	if i <= 0 {
+		return "", ""
+	}
+	switch name := key[i+1:]; name {
+	case "cycles", "fullCap", "designCap", "soc":
+		return key[:i], name
+	}
+	return "", ""
+}
+
+// Update collect pack values of the telemetry points
+func (b *BatteryHealthTracker) Update(points []*Telemetry) {
+	var warnings []PackHealth
+	b.mu.Lock()
+	for _, p := range points {
+		pack, name := splitPackKey(p.Key)
+		v, ok := p.Value.(float64)
+		if pack == "" || !ok {
+			continue
+		}
+		sn := strings.ToUpper(p.SerialNumber)
+		h := b.devices[sn][pack]
+		if h == nil {
+			if name == "soc" {
+				// only packs reporting capacity or cycles are tracked
+				continue
+			}
+			h = &PackHistory{}
+			if b.devices[sn] == nil {
+				b.devices[sn] = make(map[string]*PackHistory)
+			}
+			b.devices[sn][pack] = h
+		}
+		timestamp := p.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now()
+		}
+		h.observe(name, v, timestamp.In(b.location).Format(dayLayout))
+		if health := h.health(sn, pack); health.Warning && !h.Warned {
+			h.Warned = true
+			warnings = append(warnings, health)
+		}
+	}
+	b.mu.Unlock()
+	for _, w := range warnings {
+		Events.Publish(Event{Type: EventBatteryHealth, SerialNumber: w.SerialNumber, Data: w})
+	}
+}
+
+func (h *PackHistory) observe(name string, value float64, day string) {
+	if name == "designCap" {
+		h.DesignCap = value
+		return
+	}
+	var s *HealthSample
+	if n := len(h.Samples); n > 0 && h.Samples[n-1].Day == day {
+		s = h.Samples[n-1]
+	} else if n > 0 && h.Samples[n-1].Day > day {
+		// sample of a past day
+		return
+	} else {
+		s = &HealthSample{Day: day, SocMin: -1, SocMax: -1}
+		if n > 0 {
+			s.Cycles = h.Samples[n-1].Cycles
+			s.FullCap = h.Samples[n-1].FullCap
+		}
+		h.Samples = append(h.Samples, s)
+		if len(h.Samples) > healthRetentionDays {
+			h.Samples = h.Samples[len(h.Samples)-healthRetentionDays:]
+		}
+	}
+	switch name {
+	case "cycles":
+		s.Cycles = value
+	case "fullCap":
+		s.FullCap = value
+	case "soc":
+		if s.SocMin < 0 || value < s.SocMin {
+			s.SocMin = value
+		}
+		if value > s.SocMax {
+			s.SocMax = value
+		}
+	}
+}
+
+func (h *PackHistory) health(serialNumber, pack string) PackHealth {
+	health := PackHealth{SerialNumber: serialNumber, Pack: pack, DesignCap: h.DesignCap}
+	if len(h.Samples) == 0 {
+		return health
+	}
+	last := h.Samples[len(h.Samples)-1]
+	health.Cycles = last.Cycles
+	health.FullCap = last.FullCap
+	reference := h.DesignCap
+	swings := 0
+	var days, capacities []float64
+	start, _ := time.Parse(dayLayout, h.Samples[0].Day)
+	for _, s := range h.Samples {
+		if reference == 0 && s.FullCap > 0 {
+			reference = s.FullCap
+		}
+		if s.SocMin >= 0 && s.SocMax >= 0 {
+			health.AvgSocSwing += s.SocMax - s.SocMin
+			swings++
+		}
+		if s.FullCap > 0 && reference > 0 {
+			day, _ := time.Parse(dayLayout, s.Day)
+			days = append(days, day.Sub(start).Hours()/24)
+			capacities = append(capacities, s.FullCap*100/reference)
+		}
+		health.Trend = append(health.Trend, &HealthSample{Day: s.Day, Cycles: s.Cycles,
+			FullCap: s.FullCap, SocMin: s.SocMin, SocMax: s.SocMax})
+	}
+	if swings > 0 {
+		health.AvgSocSwing /= float64(swings)
+	}
+	if reference > 0 && last.FullCap > 0 {
+		health.Capacity = last.FullCap * 100 / reference
+		health.Warning = health.Capacity < HealthWarnCapacity
+	}
+	health.FadePerMonth = -slope(days, capacities) * 30
+	return health
+}
+
+// slope least squares slope of y over x
+func slope(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return 0
+	}
+	var sx, sy, sxx, sxy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+		sxx += x[i] * x[i]
+		sxy += x[i] * y[i]
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / d
+}
+
+// Health return health estimation of all packs of the device sorted by pack
+func (b *BatteryHealthTracker) Health(serialNumber string) []PackHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sn := strings.ToUpper(serialNumber)
+	result := make([]PackHealth, 0, len(b.devices[sn]))
+	for pack, h := range b.devices[sn] {
+		result = append(result, h.health(sn, pack))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Pack < result[j].Pack })
+	return result
+}
+
+// Save write the collected history to a JSON file
+func (b *BatteryHealthTracker) Save(fileName string) error {
+	b.mu.Lock()
+	data, err := json.MarshalIndent(b.devices, "", "  ")
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(fileName, data)
+}
+
+// Load read history written by Save, a missing file is ignored
+func (b *BatteryHealthTracker) Load(fileName string) error {
+	data, err := readStoredFile(fileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	devices := make(map[string]map[string]*PackHistory)
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.devices = devices
+	return nil
+}
+
+// Health return health estimation of all battery packs of the device
+func Health(serialNumber string) []PackHealth {
+	return BatteryHealth.Health(serialNumber)
+}
@@ -0,0 +1,67 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatteryHealth(t *testing.T) {
+	b := NewBatteryHealthTracker()
+	b.location = time.UTC
+	var warnings []Event
+	unsubscribe := Events.Subscribe(func(e Event) { warnings = append(warnings, e) }, EventBatteryHealth)
+	defer unsubscribe()
+
+	sn := "R351HEALTH"
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// capacity fades 1000 mAh per 30 days
+	for day := 0; day <= 90; day += 30 {
+		ts := start.AddDate(0, 0, day)
+		b.Update([]*Telemetry{
+			{SerialNumber: sn, Key: "bms_bmsStatus.designCap", Value: 40000.0, Timestamp: ts},
+			{SerialNumber: sn, Key: "bms_bmsStatus.fullCap", Value: 40000.0 - float64(day)*1000/30, Timestamp: ts},
+			{SerialNumber: sn, Key: "bms_bmsStatus.cycles", Value: float64(100 + day), Timestamp: ts},
+			{SerialNumber: sn, Key: "bms_bmsStatus.soc", Value: 90.0, Timestamp: ts},
+			{SerialNumber: sn, Key: "bms_bmsStatus.soc", Value: 30.0, Timestamp: ts.Add(time.Hour)},
+			{SerialNumber: sn, Key: "pd.soc", Value: 50.0, Timestamp: ts},
+		})
+	}
+	health := b.Health(sn)
+	if !assert.Len(t, health, 1) {
+		return
+	}
+	h := health[0]
+	assert.Equal(t, "bms_bmsStatus", h.Pack)
+	assert.Equal(t, 190.0, h.Cycles)
+	assert.InDelta(t, 92.5, h.Capacity, 0.001)
+	assert.InDelta(t, 2.5, h.FadePerMonth, 0.001)
+	assert.InDelta(t, 60.0, h.AvgSocSwing, 0.001)
+	assert.Len(t, h.Trend, 4)
+	assert.False(t, h.Warning)
+	assert.Empty(t, warnings)
+
+	b.Update([]*Telemetry{{SerialNumber: sn, Key: "bms_bmsStatus.fullCap", Value: 30000.0, Timestamp: start.AddDate(0, 4, 0)}})
+	b.Update([]*Telemetry{{SerialNumber: sn, Key: "bms_bmsStatus.fullCap", Value: 29000.0, Timestamp: start.AddDate(0, 4, 1)}})
+	assert.True(t, b.Health(sn)[0].Warning)
+	assert.Len(t, warnings, 1)
+
+	file := filepath.Join(t.TempDir(), "health.json")
+	assert.NoError(t, b.Save(file))
+	loaded := NewBatteryHealthTracker()
+	assert.NoError(t, loaded.Load(file))
+	assert.Equal(t, b.Health(sn)[0].Capacity, loaded.Health(sn)[0].Capacity)
+}
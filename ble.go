@@ -0,0 +1,185 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/tknie/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// Bluetooth LE access uses the same frames as the local network transport,
+// written to the device characteristic in chunks of the negotiated MTU.
+// The adapter and the characteristics of the device are provided by the
+// application using BleConnector.
+
+// DefaultBleMTU default payload size of a BLE write
+const DefaultBleMTU = 20
+
+// BleDevice connected BLE device
+type BleDevice interface {
+	// Write write data to the command characteristic
+	Write(data []byte) error
+	// Notifications return channel receiving notifications of the device, the
+	// channel is closed if the device disconnects
+	Notifications() <-chan []byte
+	// Close disconnect the device
+	Close() error
+}
+
+// BleConnector connect to a device in BLE range
+type BleConnector interface {
+	Connect(ctx context.Context, serialNumber string) (BleDevice, error)
+}
+
+// DefaultBleConnector connector used by ConnectBle, set by the application
+var DefaultBleConnector BleConnector
+
+// ErrBleNotAvailable no BLE connector available
+var ErrBleNotAvailable = errors.New("BLE not available, no DefaultBleConnector set")
+
+// BleTransport BLE connection to a device
+type BleTransport struct {
+	SerialNumber string
+	MTU          int
+	mu           sync.Mutex
+	device       BleDevice
+	seq          int32
+}
+
+// NewBleTransport create BLE transport for a connected device
+func NewBleTransport(serialNumber string, device BleDevice) *BleTransport {
+	return &BleTransport{SerialNumber: serialNumber, MTU: DefaultBleMTU, device: device}
+}
+
+// ConnectBle connect the device using the DefaultBleConnector
+func ConnectBle(ctx context.Context, serialNumber string) (*BleTransport, error) {
+	if DefaultBleConnector == nil {
+		return nil, ErrBleNotAvailable
+	}
+	device, err := DefaultBleConnector.Connect(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	return NewBleTransport(serialNumber, device), nil
+}
+
+// UseBle select BLE access for the device commands
+func UseBle(t *BleTransport) {
+	useLocal(t.SerialNumber, t)
+}
+
+// Send send header message to the device
+func (t *BleTransport) Send(ctx context.Context, header *Header) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	if header.Seq == nil {
+		seq := t.seq
+		header.Seq = &seq
+	}
+	if header.DeviceSn == nil {
+		header.DeviceSn = &t.SerialNumber
+	}
+	frame, err := EncodeLanFrame(header)
+	if err != nil {
+		return err
+	}
+	mtu := t.MTU
+	if mtu <= 0 {
+		mtu = DefaultBleMTU
+	}
+	for len(frame) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := min(mtu, len(frame))
+		if err := t.device.Write(frame[:n]); err != nil {
+			return err
+		}
+		frame = frame[n:]
+	}
+	return nil
+}
+
+// SendMessage send protobuf message with the given command function and id
+func (t *BleTransport) SendMessage(ctx context.Context, cmdFunc, cmdId int32, msg proto.Message) error {
+	pdata, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	dataLen := int32(len(pdata))
+	return t.Send(ctx, &Header{Pdata: pdata, CmdFunc: &cmdFunc, CmdId: &cmdId, DataLen: &dataLen})
+}
+
+// Run reassemble the notified frames until the context is done or the device
+// disconnects, heartbeats are dispatched like MQTT payloads
+func (t *BleTransport) Run(ctx context.Context) error {
+	var buffer bytes.Buffer
+	notifications := t.device.Notifications()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data, ok := <-notifications:
+			if !ok {
+				return io.EOF
+			}
+			buffer.Write(data)
+			t.dispatchFrames(&buffer)
+		}
+	}
+}
+
+// dispatchFrames dispatch all complete frames of the buffer
+func (t *BleTransport) dispatchFrames(buffer *bytes.Buffer) {
+	for buffer.Len() >= lanFrameHeaderSize {
+		data := buffer.Bytes()
+		if data[0] != lanFrameMagic {
+			// resynchronize on the next frame start
+			next := bytes.IndexByte(data[1:], lanFrameMagic)
+			if next < 0 {
+				buffer.Reset()
+				return
+			}
+			buffer.Next(next + 1)
+			continue
+		}
+		length := int(data[2]) | int(data[3])<<8
+		if len(data) < lanFrameHeaderSize+length+2 {
+			return
+		}
+		payload, err := ReadLanFrame(bytes.NewReader(data[:lanFrameHeaderSize+length+2]))
+		if err != nil {
+			log.Log.Errorf("BLE %s: %v", t.SerialNumber, err)
+			recordUndecoded("ble", t.SerialNumber, err.Error(), data[:lanFrameHeaderSize+length+2])
+			buffer.Next(1)
+			continue
+		}
+		buffer.Next(lanFrameHeaderSize + length + 2)
+		dispatchLocalPayload(t.SerialNumber, payload)
+	}
+}
+
+// Close disconnect the device
+func (t *BleTransport) Close() error {
+	if t.device == nil {
+		return fmt.Errorf("BLE device %s not connected", t.SerialNumber)
+	}
+	return t.device.Close()
+}
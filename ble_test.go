@@ -0,0 +1,61 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBleDevice struct {
+	written       [][]byte
+	notifications chan []byte
+}
+
+func (d *testBleDevice) Write(data []byte) error {
+	d.written = append(d.written, append([]byte{}, data...))
+	return nil
+}
+
+func (d *testBleDevice) Notifications() <-chan []byte { return d.notifications }
+
+func (d *testBleDevice) Close() error { return nil }
+
+func TestBleTransport(t *testing.T) {
+	device := &testBleDevice{notifications: make(chan []byte, 10)}
+	transport := NewBleTransport("HW51BLETEST", device)
+	msg := &SendHeaderMsg{Msg: &Header{Pdata: bytes.Repeat([]byte{1}, 40)}}
+	assert.NoError(t, transport.SendMessage(context.Background(), 20, 129, msg))
+	assert.Greater(t, len(device.written), 1)
+	for _, chunk := range device.written {
+		assert.LessOrEqual(t, len(chunk), DefaultBleMTU)
+	}
+	header, err := DecodeLanFrame(bytes.NewReader(bytes.Join(device.written, nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(20), header.GetCmdFunc())
+	assert.Equal(t, int32(129), header.GetCmdId())
+
+	// garbage followed by a frame split into notifications
+	frame, err := EncodeLanFrame(&Header{Pdata: []byte{}})
+	assert.NoError(t, err)
+	device.notifications <- []byte{0x01, 0x02}
+	device.notifications <- frame[:3]
+	device.notifications <- frame[3:]
+	close(device.notifications)
+	assert.Error(t, transport.Run(context.Background()))
+	stat, ok := HeartbeatStats("HW51BLETEST")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), stat.Messages)
+}
@@ -0,0 +1,58 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	cmdCodeBrightness = "WN511_SET_BRIGHTNESS_PACK"
+	// maxPowerStreamBrightness PowerStream brightness range is 0-1023
+	maxPowerStreamBrightness = 1023
+	// maxLcdBrightLevel Delta 2/River 2 LCD brightness levels 0-3
+	maxLcdBrightLevel = 3
+	// defaultLcdDelayOff seconds until display is switched off
+	defaultLcdDelayOff = 300
+)
+
+// SetIndicatorBrightness set LED/display brightness in percent, scaled to the
+// device specific brightness range
+func (client *Client) SetIndicatorBrightness(ctx context.Context, serialNumber string, percent int) (*CmdSetResponse, error) {
+	if percent < 0 || percent > 100 {
+		return nil, &ValidationError{SerialNumber: serialNumber, Parameter: "brightness",
+			Value: float64(percent), Range: Range{Min: 0, Max: 100}}
+	}
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelPowerStream:
+		cmdReq.CmdCode = cmdCodeBrightness
+		cmdReq.Params = map[string]interface{}{"brightness": scalePercent(percent, maxPowerStreamBrightness)}
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		cmdReq.ModuleType = ModuleTypePd
+		cmdReq.OperateType = "lcdCfg"
+		cmdReq.Params = map[string]interface{}{"brightLevel": scalePercent(percent, maxLcdBrightLevel),
+			"delayOff": defaultLcdDelayOff}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 39, "lcdBrightness": percent}
+	default:
+		return nil, fmt.Errorf("brightness not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
+
+// scalePercent scale percent value to the range 0-max
+func scalePercent(percent, max int) int {
+	return int(math.Round(float64(percent) * float64(max) / 100))
+}
@@ -0,0 +1,85 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalePercent(t *testing.T) {
+	tests := []struct {
+		percent int
+		max     int
+		scaled  int
+	}{
+		{0, maxPowerStreamBrightness, 0},
+		{1, maxPowerStreamBrightness, 10},
+		{50, maxPowerStreamBrightness, 512},
+		{100, maxPowerStreamBrightness, 1023},
+		{0, maxLcdBrightLevel, 0},
+		{16, maxLcdBrightLevel, 0},
+		{17, maxLcdBrightLevel, 1},
+		{50, maxLcdBrightLevel, 2},
+		{83, maxLcdBrightLevel, 2},
+		{84, maxLcdBrightLevel, 3},
+		{100, maxLcdBrightLevel, 3},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.scaled, scalePercent(test.percent, test.max), "%d%% of %d", test.percent, test.max)
+	}
+}
+
+func TestIndicatorBrightness(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	tests := []struct {
+		sn          string
+		percent     int
+		cmdCode     string
+		moduleType  ModuleType
+		operateType string
+		params      map[string]interface{}
+	}{
+		{"HW51BRIGHT", 40, cmdCodeBrightness, 0, "", map[string]interface{}{"brightness": float64(409)}},
+		{"R331BRIGHT", 100, "", ModuleTypePd, "lcdCfg",
+			map[string]interface{}{"brightLevel": float64(3), "delayOff": float64(defaultLcdDelayOff)}},
+		{"R621BRIGHT", 40, "", ModuleTypePd, "lcdCfg",
+			map[string]interface{}{"brightLevel": float64(1), "delayOff": float64(defaultLcdDelayOff)}},
+		{"DCABZBRIGHT", 40, "", 0, "", map[string]interface{}{"cmdSet": float64(32), "id": float64(39), "lcdBrightness": float64(40)}},
+	}
+	for i, test := range tests {
+		_, err := client.SetIndicatorBrightness(ctx, test.sn, test.percent)
+		assert.NoError(t, err, test.sn)
+		if assert.Len(t, transport.sent(), i+1, test.sn) {
+			req := transport.sent()[i]
+			assert.Equal(t, test.sn, req.Sn)
+			assert.Equal(t, test.cmdCode, req.CmdCode, test.sn)
+			assert.Equal(t, test.moduleType, req.ModuleType, test.sn)
+			assert.Equal(t, test.operateType, req.OperateType, test.sn)
+			assert.Equal(t, test.params, req.Params, test.sn)
+		}
+	}
+
+	var verr *ValidationError
+	_, err := client.SetIndicatorBrightness(ctx, "HW51BRIGHT", 101)
+	assert.ErrorAs(t, err, &verr)
+	_, err = client.SetIndicatorBrightness(ctx, "R331BRIGHT", -1)
+	assert.ErrorAs(t, err, &verr)
+	_, err = client.SetIndicatorBrightness(ctx, "HW52BRIGHT", 50)
+	assert.Error(t, err)
+	assert.Equal(t, len(tests), transport.puts())
+}
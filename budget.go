@@ -0,0 +1,255 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// LoadKind kind of a budget load
+type LoadKind int
+
+const (
+	// LoadPlug load switched on or off using a Smart Plug
+	LoadPlug LoadKind = iota
+	// LoadInverter PowerStream output adjusted between MinWatts and MaxWatts
+	LoadInverter
+)
+
+// BudgetLoad load sharing the power budget, loads with higher priority are
+// served first
+type BudgetLoad struct {
+	Name         string   `json:"name"`
+	SerialNumber string   `json:"serialNumber"`
+	Kind         LoadKind `json:"kind"`
+	Priority     int      `json:"priority"`
+	// Watts power of a plug load
+	Watts float64 `json:"watts,omitempty"`
+	// MinWatts and MaxWatts output range of an inverter load
+	MinWatts float64 `json:"minWatts,omitempty"`
+	MaxWatts float64 `json:"maxWatts,omitempty"`
+}
+
+// BudgetAllocation power assigned to a load
+type BudgetAllocation struct {
+	Name         string  `json:"name"`
+	SerialNumber string  `json:"serialNumber"`
+	On           bool    `json:"on"`
+	Watts        float64 `json:"watts"`
+}
+
+// Budget allocate the available PV and battery power among the registered
+// loads by priority, switching Smart Plugs and setting PowerStream outputs
+type Budget struct {
+	client *Client
+	// Sources PowerStream inverters providing PV and battery power
+	Sources []string
+	// BatteryWatts battery power added to the budget while the battery level is above MinSoc
+	BatteryWatts float64
+	MinSoc       float64
+	// Reserve power kept out of the budget
+	Reserve float64
+	// Tolerance inverter output changes below this value are not sent
+	Tolerance float64
+	// MinInterval minimum time between allocations triggered by telemetry
+	MinInterval time.Duration
+	mu          sync.Mutex
+	loads       []BudgetLoad
+	applied     map[string]BudgetAllocation
+	trigger     chan struct{}
+}
+
+// NewBudget create power budget fed by the PowerStream inverters
+func NewBudget(client *Client, sources ...string) *Budget {
+	return &Budget{client: client, Sources: sources, MinSoc: 20, Tolerance: 10, MinInterval: time.Minute,
+		applied: make(map[string]BudgetAllocation), trigger: make(chan struct{}, 1)}
+}
+
+// Register add or replace a load
+func (b *Budget) Register(load BudgetLoad) error {
+	switch {
+	case load.Name == "" || load.SerialNumber == "":
+		return errors.New("budget load need name and serial number")
+	case load.Kind == LoadPlug && load.Watts <= 0:
+		return fmt.Errorf("budget load %s need plug watts", load.Name)
+	case load.Kind == LoadInverter && load.MaxWatts < load.MinWatts:
+		return fmt.Errorf("budget load %s maximum below minimum", load.Name)
+	}
+	load.SerialNumber = ResolveDevice(load.SerialNumber)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.unregister(load.Name)
+	b.loads = append(b.loads, load)
+	return nil
+}
+
+// Unregister remove the load, the device is left in the last state
+func (b *Budget) Unregister(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.unregister(name)
+}
+
+func (b *Budget) unregister(name string) {
+	for i, l := range b.loads {
+		if l.Name == name {
+			b.loads = append(b.loads[:i], b.loads[i+1:]...)
+			delete(b.applied, name)
+			return
+		}
+	}
+}
+
+// Allocate assign the available power to the loads in order of priority. A plug
+// is switched on if its full power is left, an inverter gets the remaining
+// power within its range or nothing if the minimum is not left.
+func Allocate(available float64, loads []BudgetLoad) []BudgetAllocation {
+	ordered := append([]BudgetLoad{}, loads...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority > ordered[j].Priority })
+	remaining := math.Max(0, available)
+	allocations := make([]BudgetAllocation, 0, len(ordered))
+	for _, l := range ordered {
+		a := BudgetAllocation{Name: l.Name, SerialNumber: l.SerialNumber}
+		switch l.Kind {
+		case LoadPlug:
+			if remaining >= l.Watts {
+				a.On = true
+				a.Watts = l.Watts
+			}
+		case LoadInverter:
+			if remaining >= l.MinWatts {
+				a.Watts = math.Round(math.Min(remaining, l.MaxWatts))
+				a.On = a.Watts > 0
+			}
+		}
+		remaining -= a.Watts
+		allocations = append(allocations, a)
+	}
+	return allocations
+}
+
+// Available power of the budget, PV power of the sources plus the battery
+// power of sources above MinSoc, minus the reserve
+func (b *Budget) Available() float64 {
+	available := -b.Reserve
+	for _, sn := range b.Sources {
+		for _, k := range []string{keyPv1InputWatts, keyPv2InputWatts} {
+			if v, ok := States.Get(sn, k); ok {
+				w, _ := v.Value.(float64)
+				// reported in 0.1 watt
+				available += w / 10
+			}
+		}
+		if v, ok := States.Get(sn, keyBatSoc); ok {
+			if soc, _ := v.Value.(float64); soc > b.MinSoc {
+				available += b.BatteryWatts
+			}
+		}
+	}
+	return available
+}
+
+// Apply allocate the available power and send the changed allocations
+func (b *Budget) Apply(ctx context.Context) ([]BudgetAllocation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	allocations := Allocate(b.Available(), b.loads)
+	var errs []error
+	for _, a := range allocations {
+		last, ok := b.applied[a.Name]
+		var err error
+		switch b.kind(a.Name) {
+		case LoadPlug:
+			if ok && last.On == a.On {
+				continue
+			}
+			_, err = b.client.SetPlugSwitch(ctx, a.SerialNumber, a.On)
+		case LoadInverter:
+			if ok && math.Abs(last.Watts-a.Watts) < b.Tolerance {
+				continue
+			}
+			_, err = b.client.SetPermanentWatts(ctx, a.SerialNumber, a.Watts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("budget load %s: %w", a.Name, err))
+			continue
+		}
+		b.applied[a.Name] = a
+	}
+	return allocations, errors.Join(errs...)
+}
+
+func (b *Budget) kind(name string) LoadKind {
+	for _, l := range b.loads {
+		if l.Name == name {
+			return l.Kind
+		}
+	}
+	return LoadPlug
+}
+
+// ObserveTelemetry telemetry handler triggering an allocation on changes of
+// the battery level or PV production of the sources
+func (b *Budget) ObserveTelemetry(points []*Telemetry) {
+	for _, p := range points {
+		switch p.Key {
+		case keyBatSoc, keyPv1InputWatts, keyPv2InputWatts:
+		default:
+			continue
+		}
+		for _, sn := range b.Sources {
+			if strings.EqualFold(sn, p.SerialNumber) {
+				select {
+				case b.trigger <- struct{}{}:
+				default:
+				}
+				return
+			}
+		}
+	}
+}
+
+// Run allocate on telemetry changes, at most once per MinInterval, until the
+// context is done
+func (b *Budget) Run(ctx context.Context) error {
+	unregister := RegisterTelemetryHandler(b.ObserveTelemetry)
+	defer unregister()
+	clock := CurrentClock()
+	last := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.trigger:
+		}
+		if wait := b.MinInterval - clock.Now().Sub(last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(wait):
+			}
+		}
+		last = clock.Now()
+		if _, err := b.Apply(ctx); err != nil {
+			services.ServerMessage("Power budget allocation failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordTransport struct {
+	mu       sync.Mutex
+	requests []CmdSetRequest
+}
+
+func (t *recordTransport) Name() string                   { return "record" }
+func (t *recordTransport) Capabilities(string) Capability { return CapSetParam }
+func (t *recordTransport) ReadQuota(context.Context, string) (map[string]interface{}, error) {
+	return nil, ErrNotSupported
+}
+func (t *recordTransport) SetParam(_ context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests = append(t.requests, req)
+	return &CmdSetResponse{Code: "0"}, nil
+}
+func (t *recordTransport) Stream(context.Context, string, TelemetryHandler) (func(), error) {
+	return nil, ErrNotSupported
+}
+
+func TestAllocate(t *testing.T) {
+	loads := []BudgetLoad{
+		{Name: "heater", SerialNumber: "HW52A", Kind: LoadPlug, Priority: 1, Watts: 500},
+		{Name: "fridge", SerialNumber: "HW52B", Kind: LoadPlug, Priority: 10, Watts: 100},
+		{Name: "house", SerialNumber: "HW51A", Kind: LoadInverter, Priority: 5, MinWatts: 100, MaxWatts: 400},
+	}
+	allocations := Allocate(700, loads)
+	assert.Equal(t, []BudgetAllocation{
+		{Name: "fridge", SerialNumber: "HW52B", On: true, Watts: 100},
+		{Name: "house", SerialNumber: "HW51A", On: true, Watts: 400},
+		{Name: "heater", SerialNumber: "HW52A"},
+	}, allocations)
+
+	allocations = Allocate(150, loads)
+	assert.True(t, allocations[0].On)
+	assert.False(t, allocations[1].On)
+	assert.Equal(t, 0.0, allocations[1].Watts)
+}
+
+func TestBudgetApply(t *testing.T) {
+	record := &recordTransport{}
+	client := NewClient("access", "secret")
+	client.SetRouter(NewRouter(record))
+	States.Update([]*Telemetry{
+		{SerialNumber: "HW51BUDGET", Key: keyPv1InputWatts, Value: 4000.0},
+		{SerialNumber: "HW51BUDGET", Key: keyPv2InputWatts, Value: 2000.0},
+		{SerialNumber: "HW51BUDGET", Key: keyBatSoc, Value: 80.0},
+	})
+	budget := NewBudget(client, "HW51BUDGET")
+	budget.BatteryWatts = 200
+	budget.Reserve = 100
+	assert.Equal(t, 700.0, budget.Available())
+	assert.Error(t, budget.Register(BudgetLoad{Name: "plug", SerialNumber: "HW52BUDGET", Kind: LoadPlug}))
+	assert.NoError(t, budget.Register(BudgetLoad{Name: "plug", SerialNumber: "HW52BUDGET", Kind: LoadPlug, Priority: 2, Watts: 300}))
+	assert.NoError(t, budget.Register(BudgetLoad{Name: "inverter", SerialNumber: "HW51BUDGET", Kind: LoadInverter, Priority: 1, MaxWatts: 600}))
+
+	allocations, err := budget.Apply(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 2)
+	if assert.Len(t, record.requests, 2) {
+		assert.Equal(t, cmdCodePlugSwitch, record.requests[0].CmdCode)
+		assert.Equal(t, 1, record.requests[0].Params["plugSwitch"])
+		assert.Equal(t, 4000.0, record.requests[1].Params["permanentWatts"])
+	}
+
+	// unchanged allocations are not sent again
+	_, err = budget.Apply(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, record.requests, 2)
+}
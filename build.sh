@@ -4,4 +4,7 @@ if [ ! -d "bin" ]; then
   mkdir bin
 fi
 
-go build -o bin/client cmd/client/main.go
\ No newline at end of file
+go build -o bin/client cmd/client/main.go
+
+# REST-only binary without MQTT and protobuf dependencies
+go build -o bin/rest-only ./examples/rest-only
@@ -0,0 +1,82 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// car charging input current range in milliampere, Delta 2 and River 2 stations
+// accept the steps 4 A, 6 A and 8 A
+var carInputRange = Range{Min: 4000, Max: 8000}
+
+// carInputSteps current steps of the MPPT car input
+var carInputSteps = []int{4000, 6000, 8000}
+
+// maxCarStandbyMinutes maximum auto-off time of the car output, 0 disables auto-off
+const maxCarStandbyMinutes = 720
+
+// SetCarInputCurrent set the car charging input current limit in milliampere. The
+// Delta 2 and River 2 series only accept 4000, 6000 or 8000 mA.
+// The 12V car output itself is switched with SetDCOutput.
+func (client *Client) SetCarInputCurrent(ctx context.Context, serialNumber string, milliAmpere int) (*CmdSetResponse, error) {
+	if float64(milliAmpere) < carInputRange.Min || float64(milliAmpere) > carInputRange.Max {
+		return nil, &ValidationError{SerialNumber: serialNumber, Parameter: "carInputCurrent",
+			Value: float64(milliAmpere), Range: carInputRange}
+	}
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		if !validCarInputStep(milliAmpere) {
+			return nil, fmt.Errorf("car input current %d mA not supported for device %s, use one of %v",
+				milliAmpere, serialNumber, carInputSteps)
+		}
+		cmdReq.ModuleType = ModuleTypeMppt
+		cmdReq.OperateType = "dcChgCfg"
+		cmdReq.Params = map[string]interface{}{"dcChgCfg": milliAmpere}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 71, "currMa": milliAmpere}
+	default:
+		return nil, fmt.Errorf("car input not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
+
+// SetCarStandby set the time in minutes after the idle 12V car output is switched off,
+// 0 keeps the output switched on
+func (client *Client) SetCarStandby(ctx context.Context, serialNumber string, minutes int) (*CmdSetResponse, error) {
+	if minutes < 0 || minutes > maxCarStandbyMinutes {
+		return nil, &ValidationError{SerialNumber: serialNumber, Parameter: "carStandby",
+			Value: float64(minutes), Range: Range{Min: 0, Max: maxCarStandbyMinutes}}
+	}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+	default:
+		return nil, fmt.Errorf("car output standby not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		Sn:          serialNumber,
+		ModuleType:  ModuleTypeMppt,
+		OperateType: "carStandby",
+		Params:      map[string]interface{}{"standbyMins": minutes},
+	})
+}
+
+func validCarInputStep(milliAmpere int) bool {
+	for _, s := range carInputSteps {
+		if s == milliAmpere {
+			return true
+		}
+	}
+	return false
+}
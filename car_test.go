@@ -0,0 +1,51 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarCommands(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.SetCarInputCurrent(ctx, "R331CAR", 6000)
+	assert.NoError(t, err)
+	_, err = client.SetCarInputCurrent(ctx, "DCABZCAR", 5000)
+	assert.NoError(t, err)
+	_, err = client.SetCarStandby(ctx, "R621CAR", 120)
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 3) {
+		assert.Equal(t, "dcChgCfg", transport.requests[0].OperateType)
+		assert.Equal(t, float64(6000), transport.requests[0].Params["dcChgCfg"])
+		assert.Equal(t, float64(71), transport.requests[1].Params["id"])
+		assert.Equal(t, float64(5000), transport.requests[1].Params["currMa"])
+		assert.Equal(t, "carStandby", transport.requests[2].OperateType)
+		assert.Equal(t, float64(120), transport.requests[2].Params["standbyMins"])
+	}
+
+	_, err = client.SetCarInputCurrent(ctx, "R331CAR", 5000)
+	assert.Error(t, err)
+	var verr *ValidationError
+	_, err = client.SetCarInputCurrent(ctx, "R331CAR", 10000)
+	assert.ErrorAs(t, err, &verr)
+	_, err = client.SetCarStandby(ctx, "DAEBCAR", 60)
+	assert.Error(t, err)
+	_, err = client.SetCarStandby(ctx, "R331CAR", -1)
+	assert.ErrorAs(t, err, &verr)
+	assert.Len(t, transport.requests, 3)
+}
@@ -12,24 +12,14 @@
 package ecoflow
 
 import (
-	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log/slog"
-	"math/rand"
 	"net/http"
-	"sort"
-	"strconv"
-	"strings"
-	"time"
+	"sync"
 
-	"github.com/tknie/services"
+	"github.com/tknie/ecoflow/rest"
 )
 
 const (
@@ -50,21 +40,22 @@ const (
 	ModuleTypeMppt     ModuleType = 5
 )
 
-const (
-	accessKeyHeader = "accessKey"
-	nonceHeader     = "nonce"
-	timestampHeader = "timestamp"
-	signHeader      = "sign"
-)
-
 type Client struct {
 	httpClient  *http.Client //can be customized if required
 	accessToken string
 	secretToken string
+	signer      *Signer
+	commands    commandQueues
+	router      *Router
+	// deviceCache cached device list, created on first use
+	deviceCache     *DeviceListCache
+	deviceCacheOnce sync.Once
+	// canonicalBody marshal request bodies like the signed parameters
+	canonicalBody bool
 }
 
 type DeviceListResponse struct {
-	Code            string       `json:"code"`
+	Code            Code         `json:"code"`
 	Message         string       `json:"message"`
 	Devices         []DeviceInfo `json:"data"`
 	EagleEyeTraceID string       `json:"eagleEyeTraceId"`
@@ -76,161 +67,90 @@ type DeviceInfo struct {
 	Online int    `json:"online"`
 }
 
-type HttpRequest struct {
-	httpClient        *http.Client
-	method            string
-	uri               string
-	requestParameters map[string]interface{}
-	accessKey         string
-	secretKey         string
-	getSignParameters func() *signParameters //required for unit testing
-}
-
-type signParameters struct {
-	nonce       string
-	timestamp   string
-	accessKey   string
-	sign        string
-	queryParams string
-}
+// HttpRequest signed request of the Ecoflow IoT Open API
+type HttpRequest = rest.Request
 
 type CmdSetResponse struct {
-	Code    string `json:"code"`
+	Code    Code   `json:"code"`
 	Message string `json:"message"`
+	// Id of the command request, assigned by the client if not set
+	Id string `json:"-"`
+	// Unconfirmed command was sent without acknowledge of the device
+	Unconfirmed bool `json:"-"`
 }
 
-func (r *HttpRequest) generateSignParameters() *signParameters {
-	nonce := generateNonce()
-	timestamp := generateTimestamp()
-	queryParams := generateQueryParams(r.requestParameters)
-	return &signParameters{
-		queryParams: queryParams,
-		nonce:       nonce,
-		timestamp:   timestamp,
-		accessKey:   r.accessKey,
-		sign:        r.generateSign(queryParams, nonce, timestamp),
-	}
-}
-
-func generateQueryParams(data map[string]interface{}) string {
-	var result []string
-
-	// Process top-level map keys
-	for k, v := range data {
-		result = append(result, processValue(k, v)...)
-	}
-
-	// Sort results by ASCII value
-	sort.Strings(result)
-
-	// Concatenate results with & separator
-	return strings.Join(result, "&")
+// CanonicalQueryString return the canonical parameter string the request
+// signature is calculated of. Nested maps are flattened using dots, arrays
+// using the index in brackets and the result is sorted by ASCII value.
+func CanonicalQueryString(params map[string]interface{}) string {
+	return rest.CanonicalQueryString(params)
 }
 
-func processValue(prefix string, value interface{}) []string {
-	var result []string
-	switch v := value.(type) {
-	case map[string]interface{}:
-		for k, nestedValue := range v {
-			// Recursively process nested maps
-			nestedPrefix := prefix + "." + k
-			result = append(result, processValue(nestedPrefix, nestedValue)...)
-		}
-	case []interface{}:
-		for i, item := range v {
-			// Recursively process items in arrays
-			nestedPrefix := prefix + "[" + strconv.Itoa(i) + "]"
-			result = append(result, processValue(nestedPrefix, item)...)
-		}
-	case string:
-		result = append(result, prefix+"="+v)
-	case int:
-		result = append(result, prefix+"="+strconv.Itoa(v))
-	case float64:
-		result = append(result, prefix+"="+strconv.FormatFloat(v, 'f', -1, 64))
-	case bool:
-		result = append(result, prefix+"="+strconv.FormatBool(v))
-	default:
-		services.ServerMessage("Ecoflow: unknown type for process value: %T", value)
-	}
-	return result
-}
-
-func (r *HttpRequest) generateSign(queryString, nonce, timestamp string) string {
-	keyValueString := r.getKeyValueString(queryString, nonce, timestamp)
-	return encryptHmacSHA256(keyValueString, r.secretKey)
-}
-
-func encryptHmacSHA256(message string, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write([]byte(message))
-	sha := hex.EncodeToString(h.Sum(nil))
-	return sha
-}
-
-// timestamp is a UTC timestamp (in nano)
-func generateTimestamp() string {
-	return fmt.Sprint(time.Now().UnixNano())
-}
-
-// nonce is a random int with 6 digits
-func generateNonce() string {
-	return strconv.Itoa(rand.Intn(900000) + 100000)
-}
-
-func (r *HttpRequest) getKeyValueString(queryString string, nonce string, timestamp string) string {
-	keyValueString := accessKeyHeader + "=" + r.accessKey + "&" +
-		nonceHeader + "=" + nonce + "&" +
-		timestampHeader + "=" + timestamp
-
-	if queryString != "" {
-		keyValueString = queryString + "&" + keyValueString
+// NewClient with default http client, the options allow tuning of the HTTP transport
+func NewClient(accessToken, secretToken string, options ...ClientOption) *Client {
+	o := &clientOptions{}
+	for _, option := range options {
+		option(o)
 	}
-	return keyValueString
-}
-
-// NewClient with default http client
-func NewClient(accessToken, secretToken string) *Client {
 	c := &Client{
-		httpClient:  &http.Client{},
-		accessToken: accessToken,
-		secretToken: secretToken,
+		httpClient:    o.client(),
+		accessToken:   accessToken,
+		secretToken:   secretToken,
+		signer:        NewSigner(accessToken, secretToken),
+		canonicalBody: o.canonicalBody,
 	}
+	c.signer.Numbers = o.numbers
+	c.signer.Now = now
+	c.router = DefaultRouter(c)
 
 	return c
 }
 
 type CmdSetRequest struct {
-	Id          string                 `json:"id"`
-	OperateType string                 `json:"operateType,omitempty"`
-	ModuleType  ModuleType             `json:"moduleType,omitempty"`
-	CmdCode     string                 `json:"cmdCode,omitempty"`
-	Sn          string                 `json:"sn"`
-	Params      map[string]interface{} `json:"params"`
+	Id          string     `json:"id"`
+	OperateType string     `json:"operateType,omitempty"`
+	ModuleType  ModuleType `json:"moduleType,omitempty"`
+	CmdCode     string     `json:"cmdCode,omitempty"`
+	Sn          string     `json:"sn"`
+	// CmdId, CmdFunc and the routing fields are used by the Stream series
+	CmdId   int                    `json:"cmdId,omitempty"`
+	CmdFunc int                    `json:"cmdFunc,omitempty"`
+	Dest    int                    `json:"dest,omitempty"`
+	DirDest int                    `json:"dirDest,omitempty"`
+	DirSrc  int                    `json:"dirSrc,omitempty"`
+	NeedAck bool                   `json:"needAck,omitempty"`
+	Params  map[string]interface{} `json:"params"`
 }
 
 func NewHttpRequest(httpClient *http.Client, method string, uri string, params map[string]interface{}, accessKey, secretKey string) *HttpRequest {
-	r := &HttpRequest{
-		httpClient:        httpClient,
-		method:            method,
-		uri:               uri,
-		requestParameters: params,
-		accessKey:         accessKey,
-		secretKey:         secretKey,
-	}
+	return NewSignedHttpRequest(httpClient, method, uri, params, NewSigner(accessKey, secretKey))
+}
 
-	//required for unit testing
-	r.getSignParameters = func() *signParameters {
-		return r.generateSignParameters()
+// NewSignedHttpRequest create new request signed by the given signer
+func NewSignedHttpRequest(httpClient *http.Client, method string, uri string, params map[string]interface{}, signer *Signer) *HttpRequest {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
 	}
+	return rest.NewRequest(httpClient, method, uri, params, signer)
+}
+
+// newRequest create request signed by the client signer and count it in the API budget
+func (c *Client) newRequest(method string, uri string, params map[string]interface{}) *HttpRequest {
+	APIBudgets.Record(c.accessToken)
+	request := NewSignedHttpRequest(c.httpClient, method, uri, params, c.signer)
+	request.CanonicalBody = c.canonicalBody
+	return request
+}
 
-	return r
+// Signer return signer of the client, usable for endpoints not wrapped by the client
+func (c *Client) Signer() *Signer {
+	return c.signer
 }
 
 // GetDeviceList executes a request to get the list of devises linked to the user account. Shared devices are not included
 // If the response parameter "code" is not 0, then there is an error. Error code and error message are returned
 func (c *Client) GetDeviceList(ctx context.Context) (*DeviceListResponse, error) {
-	request := NewHttpRequest(c.httpClient, "GET", ecoflowAPI+deviceListPath, nil, c.accessToken, c.secretToken)
+	request := c.newRequest("GET", ecoflowAPI+deviceListPath, nil)
 	response, err := request.Execute(ctx)
 	if err != nil {
 		return nil, err
@@ -242,69 +162,13 @@ func (c *Client) GetDeviceList(ctx context.Context) (*DeviceListResponse, error)
 		return nil, err
 	}
 
-	if deviceResponse.Code != "0" {
+	observeCode(deviceResponse.Code)
+	if !deviceResponse.Code.OK() {
 		return &deviceResponse, fmt.Errorf("can't get device list, error code: %s, error message: %s", deviceResponse.Code, deviceResponse.Message)
 	}
 	return &deviceResponse, nil
 }
 
-func (r *HttpRequest) Execute(ctx context.Context) ([]byte, error) {
-	signParams := r.getSignParameters()
-	requestURI := r.uri + "?" + signParams.queryParams
-
-	var reqBody bytes.Buffer
-
-	if r.requestParameters != nil {
-		reqBytes, _ := json.Marshal(r.requestParameters)
-		reqBody.Write(reqBytes)
-	}
-
-	var httpReq *http.Request
-	var err error
-
-	switch r.method {
-	case http.MethodGet:
-		httpReq, err = http.NewRequestWithContext(ctx, http.MethodGet, requestURI, nil)
-		if err != nil {
-			return nil, err
-		}
-	case http.MethodPost:
-		httpReq, err = http.NewRequestWithContext(ctx, http.MethodPost, r.uri, &reqBody)
-		if err != nil {
-			return nil, err
-		}
-		httpReq.Header.Add("Content-Type", "application/json;charset=UTF-8")
-	case http.MethodPut:
-		httpReq, err = http.NewRequestWithContext(ctx, http.MethodPut, r.uri, &reqBody)
-		if err != nil {
-			return nil, err
-		}
-		httpReq.Header.Add("Content-Type", "application/json;charset=UTF-8")
-
-	default:
-		slog.Error("Only POST and GET methods are supported so far")
-		return nil, errors.New("unsupported http method")
-	}
-
-	httpReq.Header.Add(accessKeyHeader, r.accessKey)
-	httpReq.Header.Add(nonceHeader, signParams.nonce)
-	httpReq.Header.Add(timestampHeader, signParams.timestamp)
-	httpReq.Header.Add(signHeader, signParams.sign)
-
-	client := r.httpClient
-	if client == nil {
-		client = &http.Client{}
-	}
-
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response status is failed|url=%s, statusCode=%s", requestURI, resp.Status)
-	}
-	return io.ReadAll(resp.Body)
-}
-
 // GetDevice get all device parameters for a specific device
 // Use HTTP request to get the parameter information
 func (c *Client) GetDeviceAllParameters(ctx context.Context, deviceSn string) (map[string]interface{}, error) {
@@ -317,7 +181,7 @@ func (c *Client) GetDeviceInfo(ctx context.Context, deviceSn, specific string) (
 	requestParams := make(map[string]interface{})
 	requestParams["sn"] = deviceSn
 
-	request := NewHttpRequest(c.httpClient, "GET", ecoflowAPI+getAllQuotePath, requestParams, c.accessToken, c.secretToken)
+	request := c.newRequest("GET", ecoflowAPI+getAllQuotePath, requestParams)
 	response, err := request.Execute(ctx)
 	if err != nil {
 		fmt.Println("Error ... http request:", err)
@@ -330,7 +194,9 @@ func (c *Client) GetDeviceInfo(ctx context.Context, deviceSn, specific string) (
 		return nil, err
 	}
 
-	if code, ok := jsonData["code"].(string); !ok || code != "0" {
+	code := codeOf(jsonData["code"])
+	observeCode(code)
+	if !code.OK() {
 		return nil, fmt.Errorf("can't get parameters, error code %s", code)
 	}
 
@@ -338,6 +204,8 @@ func (c *Client) GetDeviceInfo(ctx context.Context, deviceSn, specific string) (
 	if jsonData, ok = jsonData["data"].(map[string]interface{}); !ok {
 		return nil, errors.New("response is not valid, can't process it")
 	}
+	markSeen(deviceSn, SourceHttp, now())
+	checkSchemaDrift(func() []*Telemetry { return NormalizeTelemetry(deviceSn, SourceHttp, jsonData, now()) })
 
 	if specific != "" {
 		if jsonData, ok = jsonData[specific].(map[string]interface{}); ok {
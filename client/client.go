@@ -0,0 +1,53 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package client stable v1 API of the signed Ecoflow IoT Open REST client
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/tknie/ecoflow"
+)
+
+// Client signed REST client
+type Client = ecoflow.Client
+
+// Option option of the HTTP client used by the REST client
+type Option = ecoflow.ClientOption
+
+// DeviceInfo device of the device list
+type DeviceInfo = ecoflow.DeviceInfo
+
+// Account credentials of the Ecoflow account
+type Account = ecoflow.Account
+
+// New create REST client using the IoT Open access and secret key
+func New(accessKey, secretKey string, options ...Option) *Client {
+	return ecoflow.NewClient(accessKey, secretKey, options...)
+}
+
+// FromEnv create REST client using ECOFLOW_ACCESS_KEY and ECOFLOW_SECRET_KEY
+func FromEnv(options ...Option) *Client {
+	account := ecoflow.AccountFromEnv()
+	return ecoflow.NewClient(account.AccessKey, account.SecretKey, options...)
+}
+
+// WithTimeout set the timeout of all HTTP requests
+func WithTimeout(timeout time.Duration) Option {
+	return ecoflow.WithTimeout(timeout)
+}
+
+// Quota read all quota of the device
+func Quota(ctx context.Context, c *Client, serialNumber string) (map[string]interface{}, error) {
+	return c.GetDeviceAllParameters(ctx, serialNumber)
+}
@@ -0,0 +1,193 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock time source used for timestamps, request ids, schedules, energy
+// integration and staleness detection. Tests replace it with a FakeClock to
+// fast-forward time instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	// After wait for the duration to elapse and then send the current time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock clock using the system time
+type SystemClock struct{}
+
+// Now current system time
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// After wait using a system timer
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type clockHolder struct {
+	clock Clock
+}
+
+// currentClock is initialized before the package variables using now()
+var currentClock = func() *atomic.Pointer[clockHolder] {
+	p := &atomic.Pointer[clockHolder]{}
+	p.Store(&clockHolder{clock: SystemClock{}})
+	return p
+}()
+
+// SetClock replace the clock of the package, nil restores the system clock
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock{}
+	}
+	currentClock.Store(&clockHolder{clock: c})
+}
+
+// CurrentClock return the clock of the package
+func CurrentClock() Clock {
+	return currentClock.Load().clock
+}
+
+// now current time of the package clock
+func now() time.Time {
+	return CurrentClock().Now()
+}
+
+// since time elapsed since t using the package clock
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// clockTimer timer of the package clock calling f in its own goroutine once
+// the duration elapsed, used instead of time.AfterFunc
+type clockTimer struct {
+	mu   sync.Mutex
+	f    func()
+	stop chan struct{}
+}
+
+// afterFunc call f after the duration elapsed on the package clock
+func afterFunc(d time.Duration, f func()) *clockTimer {
+	t := &clockTimer{f: f}
+	t.Reset(d)
+	return t
+}
+
+// Reset restart the timer with the duration, false if the timer had expired
+// or been stopped
+func (t *clockTimer) Reset(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	active := t.stopLocked()
+	stop := make(chan struct{})
+	t.stop = stop
+	fire := CurrentClock().After(d)
+	go func() {
+		select {
+		case <-fire:
+		case <-stop:
+			return
+		}
+		t.mu.Lock()
+		if t.stop != stop {
+			t.mu.Unlock()
+			return
+		}
+		t.stop = nil
+		t.mu.Unlock()
+		t.f()
+	}()
+	return active
+}
+
+// Stop prevent the call of f, false if the timer had expired or been stopped
+func (t *clockTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stopLocked()
+}
+
+func (t *clockTimer) stopLocked() bool {
+	if t.stop == nil {
+		return false
+	}
+	close(t.stop)
+	t.stop = nil
+	return true
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// FakeClock manually advanced clock for deterministic tests
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+// NewFakeClock create fake clock starting at the given time
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After return channel receiving the fake time once it is advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Waiters return number of pending After calls, used to synchronize with
+// goroutines waiting on the clock
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// Advance move the fake time forward and fire all waiters which are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set set the fake time and fire all waiters which are due
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].deadline.Before(c.waiters[j].deadline) })
+	i := 0
+	for i < len(c.waiters) && !c.waiters[i].deadline.After(t) {
+		c.waiters[i].ch <- t
+		i++
+	}
+	c.waiters = c.waiters[i:]
+}
@@ -0,0 +1,103 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockStaleness(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	markSeen("HW51CLOCK", SourceMqtt, now())
+	assert.True(t, Fresh("HW51CLOCK", time.Minute))
+	clock.Advance(2 * time.Minute)
+	assert.False(t, Fresh("HW51CLOCK", time.Minute))
+
+	header, err := NewClient("access", "secret").Signer().Sign("GET", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprint(clock.Now().UnixNano()), header.Get("timestamp"))
+
+	ch := clock.After(time.Hour)
+	clock.Advance(59 * time.Minute)
+	select {
+	case <-ch:
+		t.Fatal("fired too early")
+	default:
+	}
+	clock.Advance(time.Minute)
+	assert.Equal(t, clock.Now(), <-ch)
+	assert.Equal(t, 0, clock.Waiters())
+}
+
+func TestSchedulerFakeClock(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	SetClock(clock)
+	defer SetClock(nil)
+
+	sim := NewSimulator(SimulatedDevice{SerialNumber: "HW51SCHED"})
+	client := NewClient("access", "secret")
+	client.SetRouter(DemoRouter(sim))
+	scheduler := NewScheduler(client)
+	scheduler.OnError = func(e ScheduleEntry, err error) { t.Log(e, err) }
+	scheduler.Add(ScheduleEntry{At: start.Add(6 * time.Hour), SerialNumber: "HW51SCHED", Setting: "permanentWatts", Value: 400})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx)
+	assert.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		// waiters registered after the first advance fire as well
+		clock.Set(start.Add(6 * time.Hour))
+		quota, err := sim.ReadQuota(ctx, "HW51SCHED")
+		return err == nil && quota["20_1.permanentWatts"] == 4000.0
+	}, time.Second, time.Millisecond)
+}
+
+// TestNoSystemTime fail if the package reads or waits on the system time
+// without the package clock
+func TestNoSystemTime(t *testing.T) {
+	direct := regexp.MustCompile(`time\.(Now|Since|Until|After|AfterFunc|NewTimer|NewTicker|Tick|Sleep)\(`)
+	files, err := filepath.Glob("*.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, name := range files {
+		if name == "clock.go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for n, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "//") {
+				continue
+			}
+			if direct.MatchString(line) {
+				t.Errorf("%s:%d uses the system time instead of the package clock: %s", name, n+1, strings.TrimSpace(line))
+			}
+		}
+	}
+}
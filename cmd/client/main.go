@@ -5,14 +5,18 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/tknie/ecoflow"
+	"github.com/tknie/ecoflow/format"
 	"github.com/tknie/log"
 )
 
 var serialNumberConverter = ""
 var client *ecoflow.Client
+var locale = format.WithLocale(format.ParseLocale(os.Getenv("LANG")))
 
 func prepareEcoflow() {
 
@@ -41,27 +45,51 @@ func ListEcoflowDevices() {
 			fmt.Println("Get info of device error:", err)
 			return
 		}
-		dumpMap(0, m)
+		dumpMap(d.SN, 0, m)
 	}
 }
 
-func dumpMap(level int, m map[string]interface{}) {
+func dumpMap(sn string, level int, m map[string]interface{}) {
 	prefix := " " + strings.Repeat("  ", level*2)
 	for k, v := range m {
 		switch v.(type) {
 		case map[string]interface{}:
 			fmt.Printf(prefix+"%s:\n", k)
-			dumpMap(level+1, v.(map[string]interface{}))
+			dumpMap(sn, level+1, v.(map[string]interface{}))
 		default:
 			if s, ok := v.(string); ok {
 				fmt.Printf(prefix+"%s=%s\n", k, s)
 			} else {
-				fmt.Printf(prefix+"%s=%v\n", k, v)
+				fmt.Printf(prefix+"%s=%s\n", k, format.FormatValue(sn, k, v, locale))
 			}
 		}
 	}
 }
 
+// ExportHistory export the historical data of all devices between the days into the directory
+func ExportHistory(dir, from, to string) error {
+	prepareEcoflow()
+	begin, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+	if err != nil {
+		return err
+	}
+	end, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+	if err != nil {
+		return err
+	}
+	sink, err := ecoflow.NewCSVSink(dir)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	exporter := &ecoflow.Exporter{Client: client, From: begin, To: end.AddDate(0, 0, 1), Sink: sink,
+		Checkpoint: filepath.Join(dir, "checkpoint.json")}
+	summary, err := exporter.Run(context.Background())
+	fmt.Printf("Exported %d values of %d devices (%d requests, %d skipped)\n", summary.Points,
+		summary.Devices, summary.Requests, summary.Skipped)
+	return err
+}
+
 func SetCarACOn(sn string, turnOn bool) {
 	prepareEcoflow()
 
@@ -72,10 +100,53 @@ func SetCarACOn(sn string, turnOn bool) {
 
 func main() {
 	list := false
+	interactive := false
+	daemonConfig := ""
+	exportDir := ""
+	dumpDir := ""
+	from := time.Now().AddDate(0, 0, -7).Format(time.DateOnly)
+	to := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
 	flag.BoolVar(&list, "l", false, "List all devices")
+	flag.BoolVar(&interactive, "i", false, "Start interactive device shell")
+	flag.StringVar(&daemonConfig, "d", "", "Run as daemon using the given configuration file")
+	flag.StringVar(&exportDir, "export", "", "Export the historical data of all devices into the directory")
+	flag.StringVar(&from, "from", from, "First day of the history export")
+	flag.StringVar(&to, "to", to, "Last day of the history export")
+	flag.StringVar(&dumpDir, "dump", "", "Write undecoded payloads into rotating dump files of the directory")
 	flag.Parse()
 
+	if dumpDir != "" {
+		if err := ecoflow.EnablePayloadDumps(ecoflow.PayloadDumpConfig{Dir: dumpDir}); err != nil {
+			fmt.Println("Dump error:", err)
+			os.Exit(1)
+		}
+		defer ecoflow.DisablePayloadDumps()
+	}
+
+	if daemonConfig != "" {
+		err := ecoflow.RunDaemon(context.Background(), ecoflow.DaemonOptions{ConfigFile: daemonConfig})
+		if err != nil {
+			fmt.Println("Daemon error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if exportDir != "" {
+		if err := ExportHistory(exportDir, from, to); err != nil {
+			fmt.Println("Export error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if list {
 		ListEcoflowDevices()
 	}
+	if interactive {
+		if err := RunShell(); err != nil {
+			fmt.Println("Shell error:", err)
+			os.Exit(1)
+		}
+	}
 }
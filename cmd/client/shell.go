@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/tknie/ecoflow"
+	"github.com/tknie/ecoflow/format"
+)
+
+// shell interactive device shell
+type shell struct {
+	mu     sync.Mutex
+	quotas map[string][]string
+}
+
+const shellHelp = `Commands:
+  devices                        list devices with alias and online state
+  get <device> [key prefix]      show quota values of the device
+  watch <device> [key prefix]    show changed values every 5 seconds until Ctrl-C
+  settings <device>              list writable settings of the device
+  set <device> <setting> <value> change a setting
+  help                           show this help
+  exit                           leave the shell`
+
+// devices return serial numbers and aliases used for completion
+func (s *shell) devices(string) []string {
+	names := ecoflow.DeviceAliases()
+	if list := client.GetDevices(); list != nil {
+		for _, d := range list.Devices {
+			names = append(names, d.SN)
+		}
+	}
+	return names
+}
+
+// keys return cached quota keys of the device in the current line
+func (s *shell) keys(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quotas[ecoflow.ResolveDevice(fields[1])]
+}
+
+// settingNames return writable settings of the device in the current line
+func (s *shell) settingNames(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil
+	}
+	names := make([]string, 0)
+	for _, setting := range ecoflow.SettingsFor(ecoflow.DetectModel(ecoflow.ResolveDevice(fields[1]))) {
+		names = append(names, setting.Name)
+	}
+	return names
+}
+
+func (s *shell) completer() *readline.PrefixCompleter {
+	device := readline.PcItemDynamic(s.devices, readline.PcItemDynamic(s.keys))
+	return readline.NewPrefixCompleter(
+		readline.PcItem("devices"),
+		readline.PcItem("get", device),
+		readline.PcItem("watch", device),
+		readline.PcItem("settings", readline.PcItemDynamic(s.devices)),
+		readline.PcItem("set", readline.PcItemDynamic(s.devices, readline.PcItemDynamic(s.settingNames))),
+		readline.PcItem("help"),
+		readline.PcItem("exit"),
+	)
+}
+
+// quota read and flatten the quota of the device, the keys are cached for completion
+func (s *shell) quota(ctx context.Context, sn string) ([]*ecoflow.Telemetry, error) {
+	m, err := client.GetDeviceAllParameters(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+	points := ecoflow.NormalizeTelemetry(sn, ecoflow.SourceHttp, m, time.Now())
+	keys := make([]string, 0, len(points))
+	for _, p := range points {
+		keys = append(keys, p.Key)
+	}
+	s.mu.Lock()
+	s.quotas[sn] = keys
+	s.mu.Unlock()
+	return points, nil
+}
+
+func printPoints(w io.Writer, points []*ecoflow.Telemetry, prefix string) {
+	for _, p := range points {
+		if strings.HasPrefix(p.Key, prefix) {
+			fmt.Fprintf(w, "  %s=%s\n", p.Key, format.FormatValue(p.SerialNumber, p.Key, p.Value, locale))
+		}
+	}
+}
+
+// watch poll the quota and print changed values until Ctrl-C
+func (s *shell) watch(w io.Writer, sn, prefix string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	last := make(map[string]interface{})
+	for {
+		points, err := s.quota(ctx, sn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, p := range points {
+			if strings.HasPrefix(p.Key, prefix) && last[p.Key] != p.Value {
+				fmt.Fprintf(w, "%s %s=%s\n", time.Now().Format(time.TimeOnly), p.Key,
+					format.FormatValue(p.SerialNumber, p.Key, p.Value, locale))
+				last[p.Key] = p.Value
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// execute run one shell command
+func (s *shell) execute(w io.Writer, fields []string) error {
+	ctx := context.Background()
+	arg := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	switch fields[0] {
+	case "help":
+		fmt.Fprintln(w, shellHelp)
+	case "devices":
+		list, err := client.GetDeviceList(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range list.Devices {
+			fmt.Fprintf(w, "  %-20s %-16s %-12s online=%d\n", d.SN, ecoflow.DeviceAlias(d.SN), ecoflow.DetectModel(d.SN), d.Online)
+		}
+	case "get", "watch", "settings":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: %s <device>", fields[0])
+		}
+		sn := ecoflow.ResolveDevice(fields[1])
+		switch fields[0] {
+		case "watch":
+			return s.watch(w, sn, arg(2))
+		case "settings":
+			names := s.settingNames(strings.Join(fields, " "))
+			sort.Strings(names)
+			for _, name := range names {
+				value, err := client.ReadSetting(ctx, sn, name)
+				if err != nil {
+					fmt.Fprintf(w, "  %s: %v\n", name, err)
+					continue
+				}
+				fmt.Fprintf(w, "  %s=%v\n", name, value)
+			}
+			return nil
+		}
+		points, err := s.quota(ctx, sn)
+		if err != nil {
+			return err
+		}
+		printPoints(w, points, arg(2))
+	case "set":
+		if len(fields) != 4 {
+			return errors.New("usage: set <device> <setting> <value>")
+		}
+		value, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %s: %w", fields[3], err)
+		}
+		resp, err := client.ApplySetting(ctx, ecoflow.ResolveDevice(fields[1]), fields[2], value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  code=%s %s\n", resp.Code, resp.Message)
+	default:
+		return fmt.Errorf("unknown command %s, type help for a list of commands", fields[0])
+	}
+	return nil
+}
+
+// RunShell start the interactive device shell
+func RunShell() error {
+	prepareEcoflow()
+	s := &shell{quotas: make(map[string][]string)}
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          "ecoflow> ",
+		AutoComplete:    s.completer(),
+		HistoryFile:     os.ExpandEnv("${HOME}/.ecoflow_history"),
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+	fmt.Fprintln(rl.Stdout(), "EcoFlow device shell, type help for a list of commands")
+	for {
+		line, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			continue
+		}
+		if err != nil {
+			return nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "exit" || fields[0] == "quit" {
+			return nil
+		}
+		if err := s.execute(rl.Stdout(), fields); err != nil {
+			fmt.Fprintln(rl.Stderr(), "Error:", err)
+		}
+	}
+}
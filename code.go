@@ -0,0 +1,44 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+
+	"github.com/tknie/ecoflow/rest"
+)
+
+// CodeSuccess response code of successful requests
+const CodeSuccess = rest.CodeSuccess
+
+// Code response code of the EcoFlow API
+type Code = rest.Code
+
+// codeOf return response code of a generic decoded JSON value
+func codeOf(v interface{}) Code {
+	return rest.CodeOf(v)
+}
+
+// APIError API answered the command with an error code, it wraps ErrCommandRejected
+type APIError struct {
+	Code    Code
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v: error code %s: %s", ErrCommandRejected, e.Code, e.Message)
+}
+
+// Unwrap return ErrCommandRejected
+func (e *APIError) Unwrap() error {
+	return ErrCommandRejected
+}
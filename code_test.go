@@ -0,0 +1,50 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeUnmarshal(t *testing.T) {
+	for _, data := range []string{`{"code":"0","message":"Success"}`, `{"code":0,"message":"Success"}`} {
+		var response CmdSetResponse
+		assert.NoError(t, json.Unmarshal([]byte(data), &response), data)
+		assert.True(t, response.Code.OK(), data)
+		assert.Equal(t, "Success", response.Message)
+	}
+
+	var list DeviceListResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"code":8521,"message":"signature is wrong","data":null}`), &list))
+	assert.False(t, list.Code.OK())
+	assert.Equal(t, "8521", list.Code.String())
+
+	var login MqttLoginResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"code":null,"message":""}`), &login))
+	assert.Equal(t, Code(""), login.Code)
+
+	var credentials MqttCredentialsResponse
+	assert.NoError(t, json.Unmarshal([]byte(`{"code":"1006","message":"error"}`), &credentials))
+	assert.Equal(t, Code("1006"), credentials.Code)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"code":true}`), &list))
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.True(t, codeOf("0").OK())
+	assert.True(t, codeOf(float64(0)).OK())
+	assert.Equal(t, Code("1006"), codeOf(float64(1006)))
+	assert.Equal(t, Code(""), codeOf(nil))
+}
@@ -0,0 +1,142 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encode and decode telemetry values for storage and publishing
+type Codec interface {
+	Name() string
+	ContentType() string
+	Encode(points []*Telemetry) ([]byte, error)
+	Decode(data []byte) ([]*Telemetry, error)
+}
+
+// JSONCodec JSON array of telemetry values
+type JSONCodec struct{}
+
+// ProtobufCodec protobuf TelemetryBatch message
+type ProtobufCodec struct{}
+
+// MsgpackCodec msgpack array of telemetry values using the JSON field names
+type MsgpackCodec struct{}
+
+var codecs = map[Encoding]Codec{
+	EncodingJSON:     JSONCodec{},
+	EncodingProtobuf: ProtobufCodec{},
+	EncodingMsgpack:  MsgpackCodec{},
+}
+
+// CodecFor return codec of the encoding
+func CodecFor(encoding Encoding) (Codec, error) {
+	if c, ok := codecs[encoding]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown telemetry encoding %d", encoding)
+}
+
+// CodecByName return codec using the name or content type, used by readers
+// to decode stored telemetry
+func CodecByName(name string) (Codec, error) {
+	for _, c := range codecs {
+		if strings.EqualFold(c.Name(), name) || strings.EqualFold(c.ContentType(), name) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown telemetry codec %s", name)
+}
+
+// Name return codec name
+func (JSONCodec) Name() string { return "json" }
+
+// ContentType return MIME type of the encoded data
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// Encode encode telemetry values
+func (JSONCodec) Encode(points []*Telemetry) ([]byte, error) {
+	return json.Marshal(points)
+}
+
+// Decode decode telemetry values
+func (JSONCodec) Decode(data []byte) ([]*Telemetry, error) {
+	var points []*Telemetry
+	err := json.Unmarshal(data, &points)
+	return points, err
+}
+
+// Name return codec name
+func (ProtobufCodec) Name() string { return "protobuf" }
+
+// ContentType return MIME type of the encoded data
+func (ProtobufCodec) ContentType() string { return "application/x-protobuf" }
+
+// Encode encode telemetry values
+func (ProtobufCodec) Encode(points []*Telemetry) ([]byte, error) {
+	batch := &TelemetryBatch{Points: make([]*TelemetryPoint, 0, len(points))}
+	for _, p := range points {
+		batch.Points = append(batch.Points, p.Proto())
+	}
+	return proto.Marshal(batch)
+}
+
+// Decode decode telemetry values
+func (ProtobufCodec) Decode(data []byte) ([]*Telemetry, error) {
+	batch := &TelemetryBatch{}
+	if err := proto.Unmarshal(data, batch); err != nil {
+		return nil, err
+	}
+	points := make([]*Telemetry, 0, len(batch.Points))
+	for _, p := range batch.Points {
+		points = append(points, TelemetryFromProto(p))
+	}
+	return points, nil
+}
+
+// Name return codec name
+func (MsgpackCodec) Name() string { return "msgpack" }
+
+// ContentType return MIME type of the encoded data
+func (MsgpackCodec) ContentType() string { return "application/msgpack" }
+
+// Encode encode telemetry values
+func (MsgpackCodec) Encode(points []*Telemetry) ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := msgpack.NewEncoder(&buffer)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(points); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode decode telemetry values
+func (MsgpackCodec) Decode(data []byte) ([]*Telemetry, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	var points []*Telemetry
+	if err := dec.Decode(&points); err != nil {
+		return nil, err
+	}
+	for _, p := range points {
+		p.Value = normalizeValue(p.Value)
+		p.Timestamp = p.Timestamp.UTC()
+	}
+	return points, nil
+}
@@ -0,0 +1,48 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	points := []*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 80.5, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceHttp, Key: "inv.acOut", Value: true, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.model", Value: "D2", Timestamp: ts},
+	}
+	sizes := make(map[string]int)
+	for _, name := range []string{"json", "protobuf", "application/msgpack"} {
+		codec, err := CodecByName(name)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		data, err := codec.Encode(points)
+		assert.NoError(t, err)
+		sizes[codec.Name()] = len(data)
+		decoded, err := codec.Decode(data)
+		assert.NoError(t, err, codec.Name())
+		assert.Equal(t, points, decoded, codec.Name())
+	}
+	assert.Less(t, sizes["msgpack"], sizes["json"])
+	assert.Less(t, sizes["protobuf"], sizes["json"])
+
+	_, err := CodecByName("xml")
+	assert.Error(t, err)
+	_, err = CodecFor(Encoding(99))
+	assert.Error(t, err)
+}
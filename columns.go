@@ -0,0 +1,111 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// ColumnCase case style of generated column names
+type ColumnCase int
+
+const (
+	// CaseKeep keep the case of the telemetry key
+	CaseKeep ColumnCase = iota
+	// CaseLower convert column names to lower case
+	CaseLower
+	// CaseSnake convert camel case keys to snake case, acOutVol gets ac_out_vol
+	CaseSnake
+)
+
+// columnHashLength number of hex digits of the hash appended to truncated names
+const columnHashLength = 8
+
+// ColumnNamer map telemetry keys to database column names
+type ColumnNamer struct {
+	// Prefix prepended to each column name
+	Prefix string
+	// Separator replacing dots and characters not allowed in column names
+	Separator string
+	Case      ColumnCase
+	// MaxLength maximum column name length, longer names are truncated and made
+	// unique using a hash of the full name, no limit if 0
+	MaxLength int
+}
+
+// DefaultColumnNamer column naming with "eco_" prefix and dots replaced by underscore
+var DefaultColumnNamer = ColumnNamer{Prefix: "eco_", Separator: "_", Case: CaseKeep}
+
+// PostgresColumnNamer column naming for Postgres and TimescaleDB, identifiers are
+// limited to 63 bytes and unquoted names are folded to lower case
+var PostgresColumnNamer = ColumnNamer{Prefix: "eco_", Separator: "_", Case: CaseSnake, MaxLength: 63}
+
+// Column return column name of the telemetry key
+func (n ColumnNamer) Column(key string) string {
+	separator := n.Separator
+	if separator == "" {
+		separator = "_"
+	}
+	var buffer strings.Builder
+	buffer.WriteString(n.Prefix)
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if n.Case == CaseSnake && unicode.IsUpper(r) && i > 0 && snakeBoundary(runes, i) {
+				buffer.WriteString(separator)
+			}
+			if n.Case != CaseKeep {
+				r = unicode.ToLower(r)
+			}
+			buffer.WriteRune(r)
+		default:
+			buffer.WriteString(separator)
+		}
+	}
+	return n.truncate(buffer.String())
+}
+
+// snakeBoundary check if an upper case letter starts a new word, abbreviations
+// like "SOC" in "batSOC" are kept together
+func snakeBoundary(runes []rune, i int) bool {
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+func (n ColumnNamer) truncate(name string) string {
+	if n.MaxLength <= 0 || len(name) <= n.MaxLength {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:columnHashLength]
+	keep := n.MaxLength - columnHashLength - 1
+	if keep < 0 {
+		return hash[:n.MaxLength]
+	}
+	return name[:keep] + "_" + hash
+}
+
+// Columns return column names of all keys, the result maps column to key
+func (n ColumnNamer) Columns(keys []string) map[string]string {
+	columns := make(map[string]string, len(keys))
+	for _, k := range keys {
+		columns[n.Column(k)] = k
+	}
+	return columns
+}
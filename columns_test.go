@@ -0,0 +1,38 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnNamer(t *testing.T) {
+	assert.Equal(t, "eco_inv_acOutVol", DefaultColumnNamer.Column("inv.acOutVol"))
+	assert.Equal(t, "eco_bms_bmsStatus_f32ShowSoc", DefaultColumnNamer.Column("bms_bmsStatus.f32ShowSoc"))
+	assert.Equal(t, "eco_inv_ac_out_vol", PostgresColumnNamer.Column("inv.acOutVol"))
+	assert.Equal(t, "eco_pd_bat_soc_value", PostgresColumnNamer.Column("pd.batSOCValue"))
+
+	lower := ColumnNamer{Case: CaseLower, Separator: "__"}
+	assert.Equal(t, "inv__acoutvol", lower.Column("inv.acOutVol"))
+
+	short := ColumnNamer{Prefix: "eco_", MaxLength: 20}
+	long1 := short.Column("inverterHeartbeat.pv1InputWatts")
+	long2 := short.Column("inverterHeartbeat.pv2InputWatts")
+	assert.Len(t, long1, 20)
+	assert.NotEqual(t, long1, long2)
+	assert.True(t, strings.HasPrefix(long1, "eco_inverte_"))
+	assert.Equal(t, long1, short.Column("inverterHeartbeat.pv1InputWatts"))
+	assert.Len(t, short.Columns([]string{"inverterHeartbeat.pv1InputWatts", "inverterHeartbeat.pv2InputWatts"}), 2)
+}
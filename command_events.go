@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+// Command lifecycle events, Data contains CommandEvent
+const (
+	EventCommandIssued   EventType = "command.issued"
+	EventCommandVerified EventType = "command.verified"
+	EventCommandFailed   EventType = "command.failed"
+)
+
+// CommandEvents all command lifecycle event types
+var CommandEvents = []EventType{EventCommandIssued, EventCommandVerified, EventCommandFailed}
+
+// CommandEvent lifecycle event of a command
+type CommandEvent struct {
+	ID           string          `json:"id"`
+	SerialNumber string          `json:"serialNumber"`
+	Actor        string          `json:"actor,omitempty"`
+	Request      *CmdSetRequest  `json:"request,omitempty"`
+	Response     *CmdSetResponse `json:"response,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Verification string          `json:"verification,omitempty"`
+}
+
+// publishCommandEntry publish issued or failed event of the audit entry
+func publishCommandEntry(entry AuditEntry) {
+	eventType := EventCommandIssued
+	if entry.Error != "" {
+		eventType = EventCommandFailed
+	}
+	request := entry.Request
+	Events.Publish(Event{Type: eventType, SerialNumber: entry.SerialNumber, Time: now(),
+		Data: CommandEvent{ID: entry.ID, SerialNumber: entry.SerialNumber, Actor: entry.Actor,
+			Request: &request, Response: entry.Response, Error: entry.Error}})
+}
+
+// publishCommandVerification publish verified or failed event of the verification
+func publishCommandVerification(id, serialNumber, verification string, err error) {
+	event := CommandEvent{ID: id, SerialNumber: serialNumber, Verification: verification}
+	eventType := EventCommandVerified
+	if err != nil {
+		eventType = EventCommandFailed
+		event.Error = err.Error()
+	}
+	Events.Publish(Event{Type: eventType, SerialNumber: serialNumber, Time: now(), Data: event})
+}
@@ -0,0 +1,282 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tknie/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrAckTimeout device did not acknowledge the command frame in time
+var ErrAckTimeout = errors.New("command not acknowledged")
+
+// ErrCommandRejected device acknowledged the command frame with an error code
+var ErrCommandRejected = errors.New("command rejected by device")
+
+// AckTimeout maximum wait time for the acknowledge of a command frame
+var AckTimeout = 5 * time.Second
+
+const (
+	// frameSrcApp source address of frames sent by the app
+	frameSrcApp = 32
+	// frameDestPowerStream destination address of the PowerStream inverter
+	frameDestPowerStream = 53
+	frameVersion         = 19
+	framePayloadVersion  = 1
+)
+
+// frameSeq sequence number of outbound frames, started with the time to avoid
+// matching replies of a previous run
+var frameSeq atomic.Int32
+
+func init() {
+	frameSeq.Store(int32(now().Unix() & 0xffffff))
+}
+
+// CommandFrame outbound protobuf set command
+type CommandFrame struct {
+	SerialNumber string
+	CmdFunc      int32
+	CmdId        int32
+	Dest         int32
+	Message      proto.Message
+	NeedAck      bool
+}
+
+// Header build the frame header with a new sequence number
+func (f CommandFrame) Header() (*Header, error) {
+	pdata, err := proto.Marshal(f.Message)
+	if err != nil {
+		return nil, err
+	}
+	dest := f.Dest
+	if dest == 0 {
+		dest = frameDestPowerStream
+	}
+	header := &Header{
+		Pdata:      pdata,
+		Src:        proto.Int32(frameSrcApp),
+		Dest:       proto.Int32(dest),
+		DSrc:       proto.Int32(1),
+		DDest:      proto.Int32(1),
+		CmdFunc:    proto.Int32(f.CmdFunc),
+		CmdId:      proto.Int32(f.CmdId),
+		DataLen:    proto.Int32(int32(len(pdata))),
+		Seq:        proto.Int32(frameSeq.Add(1)),
+		Version:    proto.Int32(frameVersion),
+		PayloadVer: proto.Int32(framePayloadVersion),
+		From:       proto.String("Android"),
+		DeviceSn:   proto.String(f.SerialNumber),
+	}
+	if f.NeedAck {
+		header.NeedAck = proto.Int32(1)
+	}
+	return header, nil
+}
+
+// EncodeCommandFrame marshal the header into the MQTT payload
+func EncodeCommandFrame(header *Header) ([]byte, error) {
+	return proto.Marshal(&SendHeaderMsg{Msg: header})
+}
+
+// AckReply acknowledge of a command frame
+type AckReply struct {
+	SerialNumber string
+	Seq          int32
+	CmdFunc      int32
+	CmdId        int32
+	Code         string
+}
+
+var ackLock sync.Mutex
+var pendingAcks = make(map[int32]*pendingAck)
+
+type pendingAck struct {
+	serialNumber string
+	reply        chan AckReply
+}
+
+// expectAck register pending command waiting for the acknowledge of the sequence number
+func expectAck(serialNumber string, seq int32) (<-chan AckReply, func()) {
+	p := &pendingAck{serialNumber: strings.ToUpper(serialNumber), reply: make(chan AckReply, 1)}
+	ackLock.Lock()
+	pendingAcks[seq] = p
+	ackLock.Unlock()
+	return p.reply, func() {
+		ackLock.Lock()
+		defer ackLock.Unlock()
+		if pendingAcks[seq] == p {
+			delete(pendingAcks, seq)
+		}
+	}
+}
+
+// resolveAck match reply header to a pending command, return false if no
+// command is waiting for it
+func resolveAck(serialNumber string, header *Header) bool {
+	seq := header.GetSeq()
+	ackLock.Lock()
+	p, ok := pendingAcks[seq]
+	if ok && p.serialNumber == strings.ToUpper(serialNumber) {
+		delete(pendingAcks, seq)
+	} else {
+		ok = false
+	}
+	ackLock.Unlock()
+	if !ok {
+		return false
+	}
+	p.reply <- AckReply{SerialNumber: serialNumber, Seq: seq, CmdFunc: header.GetCmdFunc(),
+		CmdId: header.GetCmdId(), Code: header.GetCode()}
+	return true
+}
+
+// waitAck wait for the acknowledge or the timeout
+func waitAck(ctx context.Context, reply <-chan AckReply, timeout time.Duration) (AckReply, error) {
+	select {
+	case ack := <-reply:
+		return ack, nil
+	case <-CurrentClock().After(timeout):
+		return AckReply{}, ErrAckTimeout
+	case <-ctx.Done():
+		return AckReply{}, ctx.Err()
+	}
+}
+
+// AckReplyHandler handle replies on the set_reply topic of the devices
+func AckReplyHandler(_ mqtt.Client, msg mqtt.Message) {
+	// topic /app/<user id>/<serial number>/thing/property/set_reply
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) < 4 {
+		return
+	}
+	serialNumber := parts[len(parts)-4]
+	defer recoverHandler("mqtt set reply", serialNumber)
+	for _, frame := range splitFrames(msg.Payload()) {
+		reply := &SendHeaderMsg{}
+		if err := proto.Unmarshal(frame, reply); err != nil {
+			log.Log.Debugf("Ignore set reply of %s: %v", serialNumber, err)
+			continue
+		}
+		if !resolveAck(serialNumber, reply.Msg) {
+			log.Log.Debugf("Set reply of %s seq %d without pending command", serialNumber, reply.Msg.GetSeq())
+		}
+	}
+}
+
+var replySubscriptions sync.Map
+
+// subscribeReplies subscribe for the set reply topic of the device once per client
+func (m *MqttClient) subscribeReplies(serialNumber string) error {
+	topic := fmt.Sprintf("/app/%s/%s/thing/property/set_reply", m.connectionConfig.UserId, serialNumber)
+	key := fmt.Sprintf("%p/%s", m.Client, topic)
+	if _, ok := replySubscriptions.Load(key); ok {
+		return nil
+	}
+	token := m.Client.Subscribe(topic, 1, AckReplyHandler)
+	token.Wait()
+	if err := token.Error(); err != nil {
+		return err
+	}
+	replySubscriptions.Store(key, true)
+	return nil
+}
+
+// SendCommandFrame publish the command frame on the set topic of the device, with
+// NeedAck the acknowledge of the device is awaited
+func (m *MqttClient) SendCommandFrame(ctx context.Context, frame CommandFrame) (*AckReply, error) {
+	if m.connectionConfig == nil {
+		return nil, ErrNotSupported
+	}
+	if err := DeviceCompliance.CheckFrame(&frame); err != nil {
+		return nil, err
+	}
+	header, err := frame.Header()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := EncodeCommandFrame(header)
+	if err != nil {
+		return nil, err
+	}
+	var reply <-chan AckReply
+	if frame.NeedAck {
+		if err := m.subscribeReplies(frame.SerialNumber); err != nil {
+			return nil, err
+		}
+		var cancel func()
+		reply, cancel = expectAck(frame.SerialNumber, header.GetSeq())
+		defer cancel()
+	}
+	topic := fmt.Sprintf("/app/%s/%s/thing/property/set", m.connectionConfig.UserId, frame.SerialNumber)
+	token := m.Client.Publish(topic, 1, false, payload)
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+	if !frame.NeedAck {
+		return nil, nil
+	}
+	ack, err := waitAck(ctx, reply, AckTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s seq %d: %w", frame.SerialNumber, header.GetSeq(), err)
+	}
+	return &ack, nil
+}
+
+// Accepted check if the device acknowledged the command without error code
+func (a *AckReply) Accepted() bool {
+	return a.Code == "" || a.Code == "0"
+}
+
+// ackResponse command response of the acknowledge, a rejected command returns
+// ErrCommandRejected with the code of the device
+func ackResponse(ack *AckReply) (*CmdSetResponse, error) {
+	if !ack.Accepted() {
+		return &CmdSetResponse{Code: Code(ack.Code), Message: fmt.Sprintf("rejected seq %d", ack.Seq)},
+			fmt.Errorf("%w: %s seq %d code %s", ErrCommandRejected, ack.SerialNumber, ack.Seq, ack.Code)
+	}
+	return &CmdSetResponse{Code: CodeSuccess, Message: fmt.Sprintf("acknowledged seq %d", ack.Seq)}, nil
+}
+
+// commandFrameOf protobuf frame of PowerStream commands, false if the command
+// has no protobuf equivalent
+func commandFrameOf(req CmdSetRequest) (CommandFrame, bool, error) {
+	cmd, supported := lanCommands[req.CmdCode]
+	if !supported {
+		return CommandFrame{}, false, nil
+	}
+	var value float64
+	switch v := req.Params[cmd.param].(type) {
+	case float64:
+		value = v
+	case int:
+		value = float64(v)
+	default:
+		return CommandFrame{}, true, fmt.Errorf("invalid %s parameter %v", cmd.param, v)
+	}
+	return CommandFrame{SerialNumber: req.Sn, CmdFunc: powerStreamCmdFunc, CmdId: cmd.cmdId,
+		Message: cmd.build(value), NeedAck: true}, true, nil
+}
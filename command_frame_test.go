@@ -0,0 +1,80 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCommandFrameAck(t *testing.T) {
+	frame, supported, err := commandFrameOf(CmdSetRequest{Sn: "HW51FRAME", CmdCode: cmdCodePermanentWatts,
+		Params: map[string]interface{}{"permanentWatts": 2000.0}})
+	assert.True(t, supported)
+	if !assert.NoError(t, err) {
+		return
+	}
+	header, err := frame.Header()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int32(1), header.GetNeedAck())
+	assert.Equal(t, int32(129), header.GetCmdId())
+	assert.Equal(t, int32(len(header.Pdata)), header.GetDataLen())
+	next, _ := frame.Header()
+	assert.Equal(t, header.GetSeq()+1, next.GetSeq())
+
+	payload, err := EncodeCommandFrame(header)
+	assert.NoError(t, err)
+	decoded := &SendHeaderMsg{}
+	assert.NoError(t, proto.Unmarshal(payload, decoded))
+	pack := &PermanentWattsPack{}
+	assert.NoError(t, proto.Unmarshal(decoded.Msg.Pdata, pack))
+	assert.Equal(t, uint32(2000), pack.GetPermanentWatts())
+
+	reply, cancel := expectAck("HW51FRAME", header.GetSeq())
+	defer cancel()
+	// replies of other devices and sequence numbers are ignored
+	AckReplyHandler(nil, &benchMessage{topic: "/app/1234/HW51OTHER/thing/property/set_reply", payload: payload})
+	ackPayload, _ := EncodeCommandFrame(&Header{Seq: next.Seq, CmdId: header.CmdId})
+	AckReplyHandler(nil, &benchMessage{topic: "/app/1234/HW51FRAME/thing/property/set_reply", payload: ackPayload})
+	_, err = waitAck(context.Background(), reply, 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrAckTimeout)
+
+	AckReplyHandler(nil, &benchMessage{topic: "/app/1234/HW51FRAME/thing/property/set_reply", payload: payload})
+	ack, err := waitAck(context.Background(), reply, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, header.GetSeq(), ack.Seq)
+	assert.Equal(t, int32(129), ack.CmdId)
+	response, err := ackResponse(&ack)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeSuccess, response.Code)
+
+	// a device rejecting the command is not reported as success
+	reply, cancelNack := expectAck("HW51FRAME", next.GetSeq())
+	defer cancelNack()
+	nackPayload, _ := EncodeCommandFrame(&Header{Seq: next.Seq, CmdId: header.CmdId, Code: proto.String("5")})
+	AckReplyHandler(nil, &benchMessage{topic: "/app/1234/HW51FRAME/thing/property/set_reply", payload: nackPayload})
+	nack, err := waitAck(context.Background(), reply, time.Second)
+	if assert.NoError(t, err) {
+		response, err = ackResponse(&nack)
+		assert.ErrorIs(t, err, ErrCommandRejected)
+		assert.Equal(t, Code("5"), response.Code)
+	}
+
+	_, supported, _ = commandFrameOf(CmdSetRequest{Sn: "HW51FRAME", CmdCode: "WN511_SET_UNKNOWN"})
+	assert.False(t, supported)
+}
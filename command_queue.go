@@ -0,0 +1,144 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// CommandPriority priority of commands send to a device
+type CommandPriority int
+
+const (
+	// PriorityLow background automation, e.g. zero export loops
+	PriorityLow CommandPriority = iota
+	// PriorityNormal default priority of commands
+	PriorityNormal
+	// PriorityHigh user or emergency commands
+	PriorityHigh
+)
+
+// ErrCommandSuperseded returned for a pending command cancelled by a higher priority command
+var ErrCommandSuperseded = errors.New("command superseded by higher priority command")
+
+type commandWaiter struct {
+	priority CommandPriority
+	ready    chan struct{}
+	err      error
+}
+
+// deviceQueue serializes all commands send to one device
+type deviceQueue struct {
+	mu      sync.Mutex
+	busy    bool
+	waiters []*commandWaiter
+}
+
+type commandQueues struct {
+	mu     sync.Mutex
+	queues map[string]*deviceQueue
+}
+
+func (cq *commandQueues) queue(serialNumber string) *deviceQueue {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if cq.queues == nil {
+		cq.queues = make(map[string]*deviceQueue)
+	}
+	sn := strings.ToUpper(serialNumber)
+	q, ok := cq.queues[sn]
+	if !ok {
+		q = &deviceQueue{}
+		cq.queues[sn] = q
+	}
+	return q
+}
+
+// acquire wait until the device is free for the command. All pending commands with
+// lower priority are cancelled with ErrCommandSuperseded.
+func (q *deviceQueue) acquire(ctx context.Context, priority CommandPriority) (func(), error) {
+	q.mu.Lock()
+	if !q.busy && len(q.waiters) == 0 {
+		q.busy = true
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	pending := q.waiters[:0]
+	for _, w := range q.waiters {
+		if w.priority < priority {
+			w.err = ErrCommandSuperseded
+			close(w.ready)
+			continue
+		}
+		pending = append(pending, w)
+	}
+	w := &commandWaiter{priority: priority, ready: make(chan struct{})}
+	q.waiters = append(pending, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		if w.err != nil {
+			return nil, w.err
+		}
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		for i, p := range q.waiters {
+			if p == w {
+				q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+				q.mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		q.mu.Unlock()
+		// granted or superseded concurrently
+		if w.err != nil {
+			return nil, w.err
+		}
+		q.release()
+		return nil, ctx.Err()
+	}
+}
+
+// release pass the device to the pending command with the highest priority
+func (q *deviceQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters) == 0 {
+		q.busy = false
+		return
+	}
+	next := 0
+	for i, w := range q.waiters {
+		if w.priority > q.waiters[next].priority {
+			next = i
+		}
+	}
+	w := q.waiters[next]
+	q.waiters = append(q.waiters[:next], q.waiters[next+1:]...)
+	close(w.ready)
+}
+
+// SetCommandWithPriority send a typed command request serialized with all other
+// commands of the same device. Pending commands of lower priority are cancelled.
+func (c *Client) SetCommandWithPriority(ctx context.Context, priority CommandPriority, req CmdSetRequest) (*CmdSetResponse, error) {
+	release, err := c.commands.queue(req.Sn).acquire(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.sendCommand(ctx, req)
+}
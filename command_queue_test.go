@@ -0,0 +1,61 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandQueuePriority(t *testing.T) {
+	cq := &commandQueues{}
+	q := cq.queue("hw51test")
+	assert.Equal(t, q, cq.queue("HW51TEST"))
+
+	release, err := q.acquire(context.Background(), PriorityNormal)
+	assert.NoError(t, err)
+
+	lowErr := make(chan error, 1)
+	go func() {
+		_, err := q.acquire(context.Background(), PriorityLow)
+		lowErr <- err
+	}()
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.waiters) == 1
+	}, time.Second, time.Millisecond)
+
+	highDone := make(chan func(), 1)
+	go func() {
+		r, err := q.acquire(context.Background(), PriorityHigh)
+		assert.NoError(t, err)
+		highDone <- r
+	}()
+	assert.ErrorIs(t, <-lowErr, ErrCommandSuperseded)
+
+	release()
+	r := <-highDone
+	r()
+	assert.False(t, q.busy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release, _ = q.acquire(context.Background(), PriorityNormal)
+	cancel()
+	_, err = q.acquire(ctx, PriorityNormal)
+	assert.ErrorIs(t, err, context.Canceled)
+	release()
+	assert.False(t, q.busy)
+}
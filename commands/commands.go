@@ -0,0 +1,53 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package commands stable v1 API writing device settings
+package commands
+
+import (
+	"context"
+
+	"github.com/tknie/ecoflow"
+)
+
+// Request command set request
+type Request = ecoflow.CmdSetRequest
+
+// Response response of a command
+type Response = ecoflow.CmdSetResponse
+
+// Setting writable device setting
+type Setting = ecoflow.Setting
+
+// Send send command using the router of the client
+func Send(ctx context.Context, client *ecoflow.Client, req Request) (*Response, error) {
+	return client.SetCommand(ctx, req)
+}
+
+// Lookup return writable setting with the given name
+func Lookup(name string) (*Setting, bool) {
+	return ecoflow.LookupSetting(name)
+}
+
+// For return all writable settings supported by the device
+func For(serialNumber string) []*Setting {
+	return ecoflow.SettingsFor(ecoflow.DetectModel(serialNumber))
+}
+
+// Read read the current value of a setting
+func Read(ctx context.Context, client *ecoflow.Client, serialNumber, name string) (float64, error) {
+	return client.ReadSetting(ctx, serialNumber, name)
+}
+
+// Apply write the value of a setting
+func Apply(ctx context.Context, client *ecoflow.Client, serialNumber, name string, value float64) (*Response, error) {
+	return client.ApplySetting(ctx, serialNumber, name, value)
+}
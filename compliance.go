@@ -0,0 +1,240 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// EventComplianceViolation command exceeded the legal output limit, Data
+// contains the ComplianceViolation
+const EventComplianceViolation EventType = "compliance.violation"
+
+// ComplianceMode handling of commands exceeding the legal limit
+type ComplianceMode int
+
+const (
+	// ComplianceClamp reduce the requested output to the limit
+	ComplianceClamp ComplianceMode = iota
+	// ComplianceRefuse refuse the command
+	ComplianceRefuse
+)
+
+// ErrComplianceLimit command refused because it exceeds the legal output limit
+var ErrComplianceLimit = errors.New("legal output limit exceeded")
+
+// maxComplianceViolations number of violations kept
+const maxComplianceViolations = 100
+
+// CountryOutputLimits legal output limits in watts of plug-in inverters per country code
+var CountryOutputLimits = map[string]float64{
+	"DE": 800,
+	"AT": 800,
+	"CH": 600,
+	"NL": 600,
+}
+
+// ComplianceViolation command exceeding the legal limit
+type ComplianceViolation struct {
+	Time         time.Time `json:"time"`
+	SerialNumber string    `json:"serialNumber"`
+	Parameter    string    `json:"parameter"`
+	Requested    float64   `json:"requested"`
+	Limit        float64   `json:"limit"`
+	Clamped      bool      `json:"clamped"`
+}
+
+// Compliance enforce the legal output limit on all commands sent by the client
+type Compliance struct {
+	mu           sync.Mutex
+	country      string
+	limit        float64
+	mode         ComplianceMode
+	deviceLimits map[string]float64
+	violations   []ComplianceViolation
+}
+
+// DeviceCompliance global compliance configuration, no limit is enforced until
+// a country or limit is configured
+var DeviceCompliance = &Compliance{deviceLimits: make(map[string]float64)}
+
+// compliance parameters in watts with the factor used in the command
+var complianceParameters = map[string]float64{
+	"permanentWatts":       10,
+	"feedGridModePowLimit": 1,
+}
+
+// SetCountry set the legal output limit of the country
+func (c *Compliance) SetCountry(country string, mode ComplianceMode) error {
+	country = strings.ToUpper(country)
+	limit, ok := CountryOutputLimits[country]
+	if !ok {
+		return fmt.Errorf("no output limit known for country %s", country)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.country = country
+	c.limit = limit
+	c.mode = mode
+	return nil
+}
+
+// SetLimit set a custom output limit in watts, zero disables the check
+func (c *Compliance) SetLimit(watts float64, mode ComplianceMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.country = ""
+	c.limit = watts
+	c.mode = mode
+}
+
+// SetDeviceLimit set a device specific output limit in watts, e.g. for inverters
+// sharing one feed-in point
+func (c *Compliance) SetDeviceLimit(serialNumber string, watts float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deviceLimits[strings.ToUpper(serialNumber)] = watts
+}
+
+// Limit return the output limit in watts enforced for the device, zero if none
+func (c *Compliance) Limit(serialNumber string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deviceLimit(serialNumber)
+}
+
+func (c *Compliance) deviceLimit(serialNumber string) float64 {
+	limit := c.limit
+	if l, ok := c.deviceLimits[strings.ToUpper(serialNumber)]; ok && l > 0 && (limit == 0 || l < limit) {
+		limit = l
+	}
+	return limit
+}
+
+// Check check the command against the output limit. Depending on the mode the
+// output parameter is clamped or the command is refused.
+func (c *Compliance) Check(req *CmdSetRequest) error {
+	c.mu.Lock()
+	limit := c.deviceLimit(req.Sn)
+	mode := c.mode
+	c.mu.Unlock()
+	if limit <= 0 {
+		return nil
+	}
+	for parameter, factor := range complianceParameters {
+		value, ok := req.Params[parameter]
+		if !ok {
+			continue
+		}
+		requested, ok := complianceValue(value)
+		if !ok {
+			return fmt.Errorf("%w: %s value %v cannot be checked", ErrComplianceLimit, parameter, value)
+		}
+		if requested/factor <= limit {
+			continue
+		}
+		violation := ComplianceViolation{Time: now(), SerialNumber: req.Sn, Parameter: parameter,
+			Requested: requested / factor, Limit: limit, Clamped: mode == ComplianceClamp}
+		c.record(violation)
+		if mode == ComplianceRefuse {
+			return fmt.Errorf("%w: %s %.0f W above %.0f W", ErrComplianceLimit, parameter, violation.Requested, limit)
+		}
+		params := make(map[string]interface{}, len(req.Params))
+		for k, v := range req.Params {
+			params[k] = v
+		}
+		params[parameter] = limit * factor
+		req.Params = params
+	}
+	return nil
+}
+
+// CheckFrame check the protobuf command frame against the output limit. A
+// clamped value replaces the message of the frame.
+func (c *Compliance) CheckFrame(frame *CommandFrame) error {
+	if frame.CmdFunc != powerStreamCmdFunc || frame.Message == nil {
+		return nil
+	}
+	for code, cmd := range lanCommands {
+		if cmd.cmdId != frame.CmdId {
+			continue
+		}
+		if _, ok := complianceParameters[cmd.param]; !ok {
+			return nil
+		}
+		m := frame.Message.ProtoReflect()
+		fd := m.Descriptor().Fields().ByJSONName(cmd.param)
+		if fd == nil || !m.Has(fd) {
+			return nil
+		}
+		value, _ := complianceValue(m.Get(fd).Interface())
+		req := CmdSetRequest{Sn: frame.SerialNumber, CmdCode: code, Params: map[string]interface{}{cmd.param: value}}
+		if err := c.Check(&req); err != nil {
+			return err
+		}
+		if clamped := req.Params[cmd.param].(float64); clamped != value {
+			frame.Message = cmd.build(clamped)
+		}
+		return nil
+	}
+	return nil
+}
+
+// complianceValue numeric value of a command parameter, false for other kinds
+func complianceValue(value interface{}) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func (c *Compliance) record(v ComplianceViolation) {
+	action := "refused"
+	if v.Clamped {
+		action = "clamped"
+	}
+	services.ServerMessage("Compliance: %s %s=%.0f W exceeds legal limit %.0f W, %s", v.SerialNumber,
+		v.Parameter, v.Requested, v.Limit, action)
+	c.mu.Lock()
+	c.violations = append(c.violations, v)
+	if len(c.violations) > maxComplianceViolations {
+		c.violations = c.violations[len(c.violations)-maxComplianceViolations:]
+	}
+	c.mu.Unlock()
+	Events.Publish(Event{Type: EventComplianceViolation, SerialNumber: v.SerialNumber, Time: v.Time, Data: v})
+}
+
+// Violations return the recent violations
+func (c *Compliance) Violations() []ComplianceViolation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ComplianceViolation{}, c.violations...)
+}
@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompliance(t *testing.T) {
+	c := &Compliance{deviceLimits: make(map[string]float64)}
+	params := map[string]interface{}{"permanentWatts": 9000.0}
+	req := CmdSetRequest{Sn: "HW51TEST", CmdCode: cmdCodePermanentWatts, Params: params}
+	assert.NoError(t, c.Check(&req))
+	assert.Equal(t, 9000.0, req.Params["permanentWatts"])
+
+	assert.Error(t, c.SetCountry("XX", ComplianceClamp))
+	assert.NoError(t, c.SetCountry("ch", ComplianceClamp))
+	assert.NoError(t, c.Check(&req))
+	assert.Equal(t, 6000.0, req.Params["permanentWatts"])
+	assert.Equal(t, 9000.0, params["permanentWatts"])
+
+	c.SetLimit(800, ComplianceRefuse)
+	c.SetDeviceLimit("hw51test", 400)
+	assert.Equal(t, 400.0, c.Limit("HW51TEST"))
+	req = CmdSetRequest{Sn: "HW51TEST", Params: map[string]interface{}{"permanentWatts": 4500.0}}
+	assert.ErrorIs(t, c.Check(&req), ErrComplianceLimit)
+	req = CmdSetRequest{Sn: "BK11TEST", Params: map[string]interface{}{"feedGridModePowLimit": 800}}
+	assert.NoError(t, c.Check(&req))
+
+	violations := c.Violations()
+	assert.Len(t, violations, 2)
+	assert.True(t, violations[0].Clamped)
+	assert.Equal(t, 450.0, violations[1].Requested)
+}
+
+func TestComplianceValueKinds(t *testing.T) {
+	c := &Compliance{deviceLimits: make(map[string]float64)}
+	c.SetLimit(600, ComplianceClamp)
+	for _, value := range []interface{}{uint32(9000), int64(9000), float32(9000), json.Number("9000")} {
+		req := CmdSetRequest{Sn: "HW51TEST", Params: map[string]interface{}{"permanentWatts": value}}
+		assert.NoError(t, c.Check(&req))
+		assert.Equal(t, 6000.0, req.Params["permanentWatts"], "%T", value)
+	}
+	req := CmdSetRequest{Sn: "HW51TEST", Params: map[string]interface{}{"permanentWatts": "9000"}}
+	assert.ErrorIs(t, c.Check(&req), ErrComplianceLimit)
+}
+
+func TestComplianceFrameAndParameters(t *testing.T) {
+	c := &Compliance{deviceLimits: make(map[string]float64)}
+	c.SetLimit(600, ComplianceClamp)
+	frame, _, err := commandFrameOf(CmdSetRequest{Sn: "HW51TEST", CmdCode: cmdCodePermanentWatts,
+		Params: map[string]interface{}{"permanentWatts": 9000}})
+	assert.NoError(t, err)
+	assert.NoError(t, c.CheckFrame(&frame))
+	assert.Equal(t, uint32(6000), frame.Message.(*PermanentWattsPack).GetPermanentWatts())
+
+	saved := DeviceCompliance
+	defer func() { DeviceCompliance = saved }()
+	DeviceCompliance = &Compliance{deviceLimits: make(map[string]float64)}
+	DeviceCompliance.SetLimit(600, ComplianceRefuse)
+	client := NewClient("access", "secret")
+	_, err = client.SetDeviceParameter(context.Background(), map[string]interface{}{"sn": "HW51TEST",
+		"params": map[string]interface{}{"permanentWatts": 9000.0}})
+	assert.ErrorIs(t, err, ErrComplianceLimit)
+}
@@ -0,0 +1,228 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecoderRule user defined decoding of telemetry keys matching the case insensitive
+// glob pattern. The rule is only applied to keys without an entry in the key
+// catalog, native support of a key takes precedence.
+type DecoderRule struct {
+	Key string `yaml:"key" json:"key"`
+	// Models model names like "Delta 2" the rule is restricted to, all models if empty
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+	// Rename new key of the value, a "*" is replaced by the original key
+	Rename string `yaml:"rename,omitempty" json:"rename,omitempty"`
+	// Scale factor applied to numeric values, 1 if 0
+	Scale float64 `yaml:"scale,omitempty" json:"scale,omitempty"`
+	// Enum labels of numeric or string values, unmapped values are kept
+	Enum map[string]string `yaml:"enum,omitempty" json:"enum,omitempty"`
+
+	models []DeviceModel
+}
+
+// DecoderConfig document of the decoder rules
+type DecoderConfig struct {
+	Decoders []DecoderRule `yaml:"decoders" json:"decoders"`
+}
+
+var decoderLock sync.RWMutex
+var decoderRules []DecoderRule
+
+// ReadDecoders read YAML (or JSON) decoder rules
+func ReadDecoders(r io.Reader) ([]DecoderRule, error) {
+	var config DecoderConfig
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid decoders: %w", err)
+	}
+	return config.Decoders, nil
+}
+
+// LoadDecoders read the decoder rules of the file and activate them
+func LoadDecoders(fileName string) error {
+	rules, err := readDecoderFile(fileName)
+	if err != nil {
+		return err
+	}
+	return SetDecoders(rules)
+}
+
+// readDecoderFile read the decoder rules of the YAML file
+func readDecoderFile(fileName string) ([]DecoderRule, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ReadDecoders(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return rules, nil
+}
+
+// SetDecoders validate and activate the decoder rules, the first matching rule
+// of a key is used. No rules disable the custom decoding.
+func SetDecoders(rules []DecoderRule) error {
+	active, err := parseDecoders(rules)
+	if err != nil {
+		return err
+	}
+	setDecoders(active)
+	return nil
+}
+
+// parseDecoders validate the decoder rules and resolve their models
+func parseDecoders(rules []DecoderRule) ([]DecoderRule, error) {
+	active := make([]DecoderRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Key == "" {
+			return nil, errors.New("decoder rule needs a key")
+		}
+		r.Key = strings.ToLower(r.Key)
+		if _, err := path.Match(r.Key, ""); err != nil {
+			return nil, fmt.Errorf("decoder rule %s: %w", r.Key, err)
+		}
+		r.models = nil
+		for _, name := range r.Models {
+			model, ok := modelByName(name)
+			if !ok {
+				return nil, fmt.Errorf("decoder rule %s: unknown model %s", r.Key, name)
+			}
+			r.models = append(r.models, model)
+		}
+		if r.Scale == 0 {
+			r.Scale = 1
+		}
+		active = append(active, r)
+	}
+	return active, nil
+}
+
+func setDecoders(active []DecoderRule) {
+	decoderLock.Lock()
+	decoderRules = active
+	decoderLock.Unlock()
+}
+
+// modelByName return the model of the case insensitive model name
+func modelByName(name string) (DeviceModel, bool) {
+	for model, n := range modelNames {
+		if strings.EqualFold(n, name) {
+			return model, true
+		}
+	}
+	return ModelUnknown, false
+}
+
+// decoderRule return the rule matching the key of the device, the lock need to be held
+func decoderRule(serialNumber, key string) (*DecoderRule, bool) {
+	model := DetectModel(serialNumber)
+	lower := strings.ToLower(key)
+	for i := range decoderRules {
+		r := &decoderRules[i]
+		if len(r.models) > 0 && !modelIn(model, r.models) {
+			continue
+		}
+		if ok, _ := path.Match(r.Key, lower); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// decode return the decoded copy of the telemetry value
+func (r *DecoderRule) decode(p *Telemetry) *Telemetry {
+	decoded := *p
+	if r.Rename != "" {
+		decoded.Key = strings.ReplaceAll(r.Rename, "*", p.Key)
+	}
+	raw := ""
+	switch v := p.Value.(type) {
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+		decoded.Value = v * r.Scale
+	case string:
+		raw = v
+	case bool:
+		raw = strconv.FormatBool(v)
+	}
+	if label, ok := r.Enum[raw]; ok {
+		decoded.Value = label
+	}
+	return &decoded
+}
+
+// applyDecoders decode the values of keys matching a rule and not known by the key catalog
+func applyDecoders(points []*Telemetry) []*Telemetry {
+	decoderLock.RLock()
+	defer decoderLock.RUnlock()
+	if len(decoderRules) == 0 {
+		return points
+	}
+	var result []*Telemetry
+	for i, p := range points {
+		r, ok := decoderRule(p.SerialNumber, p.Key)
+		if ok {
+			if _, native := LookupKey(p.SerialNumber, p.Key); native {
+				ok = false
+			}
+		}
+		if !ok {
+			if result != nil {
+				result = append(result, p)
+			}
+			continue
+		}
+		if result == nil {
+			result = append(make([]*Telemetry, 0, len(points)), points[:i]...)
+		}
+		result = append(result, r.decode(p))
+	}
+	if result == nil {
+		return points
+	}
+	return result
+}
+
+// decodedKey check if the key is the result of a decoder rule of the device
+func decodedKey(serialNumber, key string) bool {
+	decoderLock.RLock()
+	defer decoderLock.RUnlock()
+	model := DetectModel(serialNumber)
+	for _, r := range decoderRules {
+		if len(r.models) > 0 && !modelIn(model, r.models) {
+			continue
+		}
+		pattern := r.Key
+		if r.Rename != "" {
+			pattern = strings.ToLower(strings.ReplaceAll(r.Rename, "*", r.Key))
+		}
+		if ok, _ := path.Match(pattern, strings.ToLower(key)); ok {
+			return true
+		}
+	}
+	return false
+}
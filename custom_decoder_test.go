@@ -0,0 +1,80 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDecoders = `
+decoders:
+  - key: pd.newHeaterLoad
+    models: [Delta 2]
+    rename: pd.heaterPower
+    scale: 0.1
+  - key: pd.chgState
+    enum:
+      "0": idle
+      "1": charging
+  - key: pd.soc
+    scale: 10
+`
+
+func TestCustomDecoders(t *testing.T) {
+	rules, err := ReadDecoders(strings.NewReader(testDecoders))
+	if !assert.NoError(t, err) || !assert.NoError(t, SetDecoders(rules)) {
+		return
+	}
+	defer SetDecoders(nil)
+
+	var points []*Telemetry
+	unregister := RegisterTelemetryHandler(func(p []*Telemetry) { points = append(points, p...) })
+	defer unregister()
+	input := NormalizeTelemetry("R331DECODE", SourceHttp, map[string]interface{}{
+		"pd.newHeaterLoad": 1234.0, "pd.chgState": 1.0, "pd.soc": 80.0}, time.Now())
+	DispatchTelemetry(input)
+	values := make(map[string]interface{})
+	for _, p := range points {
+		values[p.Key] = p.Value
+	}
+	assert.Equal(t, 123.4, values["pd.heaterPower"])
+	assert.Equal(t, "charging", values["pd.chgState"])
+	// native catalog keys are not changed
+	assert.Equal(t, 80.0, values["pd.soc"])
+	assert.Equal(t, 1234.0, input[1].Value)
+	assert.True(t, decodedKey("R331DECODE", "pd.heaterPower"))
+	assert.False(t, decodedKey("R621DECODE", "pd.heaterPower"))
+
+	points = nil
+	DispatchTelemetry(NormalizeTelemetry("R621DECODE", SourceHttp, map[string]interface{}{"pd.newHeaterLoad": 10.0}, time.Now()))
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "pd.newHeaterLoad", points[0].Key)
+	}
+}
+
+func TestLoadDecoders(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "decoders.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("decoders:\n  - key: x\n    models: [Toaster]\n"), 0600))
+	assert.ErrorContains(t, LoadDecoders(file), "unknown model")
+	assert.NoError(t, os.WriteFile(file, []byte("decoders:\n  - key: x\n    factor: 2\n"), 0600))
+	assert.Error(t, LoadDecoders(file))
+	assert.NoError(t, os.WriteFile(file, []byte("decoders:\n  - key: \"[\"\n"), 0600))
+	assert.Error(t, LoadDecoders(file))
+	assert.NoError(t, os.WriteFile(file, []byte(""), 0600))
+	assert.NoError(t, LoadDecoders(file))
+}
@@ -0,0 +1,278 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// daemonSchedulePlan plan name of the schedule entries of the daemon configuration
+const daemonSchedulePlan = "daemon"
+
+// DaemonConfig configuration file of the daemon, re-read on SIGHUP
+type DaemonConfig struct {
+	StatusAddress string              `json:"statusAddress,omitempty"`
+	Aliases       map[string]string   `json:"aliases,omitempty"`
+	Groups        map[string][]string `json:"groups,omitempty"`
+	Filters       map[string][]string `json:"filters,omitempty"`
+	Schedule      []ScheduleEntry     `json:"schedule,omitempty"`
+	// Tokens scopes of the gateway bearer tokens
+	Tokens map[string][]string `json:"tokens,omitempty"`
+	// Tenants tenant of serial numbers, aliases or glob patterns
+	Tenants map[string]Tenant `json:"tenants,omitempty"`
+	// Decoders YAML file of custom decoder rules for unknown keys
+	Decoders string `json:"decoders,omitempty"`
+	// TimeZone IANA time zone of the daily schedule entries and the night mode, local time zone if empty
+	TimeZone string `json:"timeZone,omitempty"`
+	// PayloadDumps write undecoded payloads into rotating dump files
+	PayloadDumps *PayloadDumpConfig `json:"payloadDumps,omitempty"`
+	// NightMode quiet settings of the devices at night, not changed by a reload
+	NightMode *NightModeConfig `json:"nightMode,omitempty"`
+}
+
+// LoadDaemonConfig read the JSON daemon configuration
+func LoadDaemonConfig(fileName string) (*DaemonConfig, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	config := &DaemonConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid daemon configuration %s: %w", fileName, err)
+	}
+	return config, nil
+}
+
+// Apply activate tokens, aliases, tenants, filters, decoders and schedules of the
+// configuration, the scheduler may be nil if no REST client is available. The
+// whole configuration is validated first, an error keeps the active one.
+func (c *DaemonConfig) Apply(scheduler *Scheduler) error {
+	if scheduler == nil && len(c.Schedule) > 0 {
+		return errors.New("schedule needs IoT Open keys for the REST client")
+	}
+	prepared, err := c.prepare()
+	if err != nil {
+		return err
+	}
+	prepared.activate()
+	prepared.activateSchedule(scheduler)
+	return nil
+}
+
+// preparedConfig validated daemon configuration, activated without errors
+type preparedConfig struct {
+	config   *DaemonConfig
+	tokens   map[[sha256.Size]byte][]Scope
+	filters  map[string]*TelemetryFilter
+	tenants  *TenantRouter
+	decoders []DecoderRule
+	location *time.Location
+}
+
+// prepare validate the configuration and build all parts of it
+func (c *DaemonConfig) prepare() (*preparedConfig, error) {
+	p := &preparedConfig{config: c}
+	var err error
+	if p.tokens, err = parseTokens(c.Tokens); err != nil {
+		return nil, err
+	}
+	if p.filters, err = parseTelemetryFilters(c.Filters); err != nil {
+		return nil, err
+	}
+	if p.tenants, err = newTenantAssignments(c.Tenants); err != nil {
+		return nil, err
+	}
+	var rules []DecoderRule
+	if c.Decoders != "" {
+		if rules, err = readDecoderFile(c.Decoders); err != nil {
+			return nil, err
+		}
+	}
+	if p.decoders, err = parseDecoders(rules); err != nil {
+		return nil, err
+	}
+	if p.location, err = c.location(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// activate swap in tokens, filters, aliases, groups, tenants and decoders
+func (p *preparedConfig) activate() {
+	GatewayTokens.setTokens(p.tokens)
+	setTelemetryFilters(p.filters)
+	SetDeviceAliases(p.config.Aliases)
+	SetDeviceGroups(p.config.Groups)
+	Tenants.replace(p.tenants)
+	setDecoders(p.decoders)
+}
+
+// activateSchedule replace the schedule entries of the configuration, aliases
+// are resolved using the activated aliases
+func (p *preparedConfig) activateSchedule(scheduler *Scheduler) {
+	if scheduler == nil {
+		return
+	}
+	scheduler.SetLocation(p.location)
+	entries := make([]ScheduleEntry, 0, len(p.config.Schedule))
+	for _, e := range p.config.Schedule {
+		e.SerialNumber = ResolveDevice(e.SerialNumber)
+		entries = append(entries, e)
+	}
+	scheduler.Replace(daemonSchedulePlan, entries...)
+}
+
+// location return the time zone of the configuration
+func (c *DaemonConfig) location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %w", c.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// DaemonOptions options of RunDaemon
+type DaemonOptions struct {
+	ConfigFile string
+	Bootstrap  BootstrapConfig
+}
+
+// notify send state to systemd, ignored if not started by systemd
+func notify(state string) {
+	if _, err := daemon.SdNotify(false, state); err != nil {
+		log.Log.Errorf("Error notifying systemd %q: %v", state, err)
+	}
+}
+
+// RunDaemon start the system and run until SIGTERM/SIGINT or the context is
+// cancelled. Readiness and watchdog are reported using sd_notify, SIGHUP
+// re-reads the configuration file.
+func RunDaemon(ctx context.Context, options DaemonOptions) error {
+	config := &DaemonConfig{}
+	if options.ConfigFile != "" {
+		var err error
+		if config, err = LoadDaemonConfig(options.ConfigFile); err != nil {
+			return err
+		}
+	}
+	bootstrap := options.Bootstrap
+	if bootstrap.StatusAddress == "" {
+		bootstrap.StatusAddress = config.StatusAddress
+	}
+	prepared, err := config.prepare()
+	if err != nil {
+		return err
+	}
+	prepared.activate()
+
+	if config.PayloadDumps != nil {
+		if err := EnablePayloadDumps(*config.PayloadDumps); err != nil {
+			return err
+		}
+		defer DisablePayloadDumps()
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	system, err := Bootstrap(ctx, bootstrap)
+	if err != nil {
+		return err
+	}
+	var scheduler *Scheduler
+	if system.Client != nil {
+		scheduler = NewScheduler(system.Client)
+		system.Go(Component{Name: "scheduler", Run: scheduler.Run, Restart: RestartOnFailure})
+		if config.NightMode != nil {
+			nightConfig := *config.NightMode
+			nightConfig.Location = prepared.location
+			nightMode, err := NewNightMode(system.Client, nightConfig)
+			if err != nil {
+				system.Stop(context.Background())
+				return err
+			}
+			system.Go(Component{Name: "nightmode", Run: nightMode.Run, Restart: RestartOnFailure, Optional: true})
+		}
+	}
+	if scheduler == nil && len(config.Schedule) > 0 {
+		system.Stop(context.Background())
+		return errors.New("schedule needs IoT Open keys for the REST client")
+	}
+	prepared.activateSchedule(scheduler)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	var watchdog <-chan time.Time
+	watchdogInterval, err := daemon.SdWatchdogEnabled(false)
+	if err == nil && watchdogInterval > 0 {
+		watchdog = CurrentClock().After(watchdogInterval / 2)
+	}
+	notify(daemon.SdNotifyReady)
+	services.ServerMessage("Ecoflow daemon started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			notify(daemon.SdNotifyStopping)
+			services.ServerMessage("Ecoflow daemon stopping")
+			shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return system.Stop(shutdown)
+		case <-system.Runtime.Done():
+			notify(daemon.SdNotifyStopping)
+			services.ServerMessage("Ecoflow daemon component failed, stopping")
+			shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return system.Stop(shutdown)
+		case <-hup:
+			notify(daemon.SdNotifyReloading)
+			reload(options.ConfigFile, scheduler)
+			notify(daemon.SdNotifyReady)
+		case <-watchdog:
+			// the watchdog supervises the daemon process, a lost MQTT connection
+			// is handled by the reconnect and must not restart the daemon
+			notify(daemon.SdNotifyWatchdog)
+			watchdog = CurrentClock().After(watchdogInterval / 2)
+		}
+	}
+}
+
+// reload re-read and apply the configuration, errors keep the active configuration
+func reload(configFile string, scheduler *Scheduler) {
+	if configFile == "" {
+		return
+	}
+	config, err := LoadDaemonConfig(configFile)
+	if err == nil {
+		err = config.Apply(scheduler)
+	}
+	if err != nil {
+		services.ServerMessage("Ecoflow daemon reload of %s failed: %v", configFile, err)
+		return
+	}
+	services.ServerMessage("Ecoflow daemon configuration %s reloaded", configFile)
+}
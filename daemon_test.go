@@ -0,0 +1,68 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonConfigReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "daemon.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"aliases":{"hw51daemon":"garage"},
+	"filters":{"HW51DAEMON":["pd.*"]},"groups":{"yard":["garage"]},
+	"schedule":[{"at":"2030-01-01T08:00:00Z","serialNumber":"garage","setting":"permanentWatts","value":200}]}`), 0600))
+	defer SetDeviceAliases(nil)
+	defer SetTelemetryFilters(nil)
+	defer SetDeviceGroups(nil)
+
+	config, err := LoadDaemonConfig(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	scheduler := NewScheduler(NewClient("access", "secret"))
+	assert.NoError(t, config.Apply(scheduler))
+	assert.Equal(t, "garage", DeviceAlias("HW51DAEMON"))
+	assert.NotNil(t, telemetryFilter("HW51DAEMON"))
+	members, _ := DeviceGroup("yard")
+	assert.Equal(t, []string{"HW51DAEMON"}, members)
+	pending := scheduler.Pending()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "HW51DAEMON", pending[0].SerialNumber)
+	}
+
+	// reload replaces previous aliases, filters and schedule entries
+	assert.NoError(t, os.WriteFile(file, []byte(`{"aliases":{"hw51daemon":"shed"}}`), 0600))
+	reload(file, scheduler)
+	assert.Equal(t, "shed", DeviceAlias("HW51DAEMON"))
+	assert.Nil(t, telemetryFilter("HW51DAEMON"))
+	assert.Empty(t, DeviceGroups())
+	assert.Empty(t, scheduler.Pending())
+
+	// invalid configuration keeps the active one
+	assert.NoError(t, os.WriteFile(file, []byte(`{"filters":{"*":["["]}}`), 0600))
+	reload(file, scheduler)
+	assert.Equal(t, "shed", DeviceAlias("HW51DAEMON"))
+
+	// an error of a later part does not activate the earlier parts
+	assert.NoError(t, os.WriteFile(file, []byte(`{"aliases":{"hw51daemon":"barn"},
+	"filters":{"HW51DAEMON":["pd.*"]},"tokens":{"secret":["telemetry:read"]},"timeZone":"Nowhere/Invalid"}`), 0600))
+	reload(file, scheduler)
+	assert.Equal(t, "shed", DeviceAlias("HW51DAEMON"))
+	assert.Nil(t, telemetryFilter("HW51DAEMON"))
+	assert.True(t, GatewayTokens.Allowed("", ScopeTelemetryRead))
+
+	assert.Error(t, (&DaemonConfig{Schedule: pending}).Apply(nil))
+}
@@ -0,0 +1,157 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const dayLayout = "2006-01-02"
+
+// defaultEnergyRetention number of days kept in daily statistics
+const defaultEnergyRetention = 31
+
+// DailyEnergy daily statistics of cumulative energy counters. Devices reset
+// their counters at their own midnight, a decrease of the counter is detected
+// as reset and the new counter value is attributed to the local day of the sample.
+type DailyEnergy struct {
+	mu        sync.Mutex
+	location  *time.Location
+	keys      map[string]bool
+	Retention int
+	counters  map[string]*energyCounter
+}
+
+type energyCounter struct {
+	last     float64
+	lastTime time.Time
+	resets   uint64
+	days     map[string]float64
+}
+
+// NewDailyEnergy create daily statistics evaluated in the given time zone for
+// the given cumulative counter keys
+func NewDailyEnergy(location *time.Location, keys ...string) *DailyEnergy {
+	if location == nil {
+		location = time.Local
+	}
+	d := &DailyEnergy{location: location, keys: make(map[string]bool),
+		Retention: defaultEnergyRetention, counters: make(map[string]*energyCounter)}
+	for _, k := range keys {
+		d.keys[k] = true
+	}
+	return d
+}
+
+// Location time zone daily totals are calculated in
+func (d *DailyEnergy) Location() *time.Location {
+	return d.location
+}
+
+// ObserveData observe all configured counter keys contained in device data
+func (d *DailyEnergy) ObserveData(serialNumber string, data map[string]interface{}, timestamp time.Time) {
+	for k := range d.keys {
+		if v, ok := data[k].(float64); ok {
+			d.Observe(serialNumber, k, v, timestamp)
+		}
+	}
+}
+
+// Observe add a new cumulative counter value and return the energy attributed to
+// the local day of the timestamp
+func (d *DailyEnergy) Observe(serialNumber, key string, value float64, timestamp time.Time) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := serialNumber + "/" + key
+	c, ok := d.counters[id]
+	if !ok {
+		c = &energyCounter{days: make(map[string]float64)}
+		d.counters[id] = c
+		c.last = value
+		c.lastTime = timestamp
+		return 0
+	}
+	if timestamp.Before(c.lastTime) {
+		// out of order sample, ignore it
+		return 0
+	}
+	delta := value - c.last
+	if delta < 0 {
+		// counter reset, energy counted since the reset
+		c.resets++
+		delta = value
+	}
+	c.last = value
+	c.lastTime = timestamp
+	day := timestamp.In(d.location).Format(dayLayout)
+	c.days[day] += delta
+	d.prune(c, timestamp)
+	return delta
+}
+
+func (d *DailyEnergy) prune(c *energyCounter, now time.Time) {
+	if d.Retention <= 0 || len(c.days) <= d.Retention {
+		return
+	}
+	limit := now.In(d.location).AddDate(0, 0, -d.Retention).Format(dayLayout)
+	for day := range c.days {
+		if day < limit {
+			delete(c.days, day)
+		}
+	}
+}
+
+// DailyTotal return the energy of the local day containing the given time
+func (d *DailyEnergy) DailyTotal(serialNumber, key string, day time.Time) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c, ok := d.counters[serialNumber+"/"+key]; ok {
+		return c.days[day.In(d.location).Format(dayLayout)]
+	}
+	return 0
+}
+
+// DailyTotals return all daily totals of a counter indexed by day (YYYY-MM-DD)
+func (d *DailyEnergy) DailyTotals(serialNumber, key string) map[string]float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	totals := make(map[string]float64)
+	if c, ok := d.counters[serialNumber+"/"+key]; ok {
+		for day, v := range c.days {
+			totals[day] = v
+		}
+	}
+	return totals
+}
+
+// Days return all days with statistics of a counter in ascending order
+func (d *DailyEnergy) Days(serialNumber, key string) []string {
+	totals := d.DailyTotals(serialNumber, key)
+	days := make([]string, 0, len(totals))
+	for day := range totals {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+// Resets return number of detected counter resets
+func (d *DailyEnergy) Resets(serialNumber, key string) uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c, ok := d.counters[serialNumber+"/"+key]; ok {
+		return c.resets
+	}
+	return 0
+}
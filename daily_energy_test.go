@@ -0,0 +1,39 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyEnergyReset(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	d := NewDailyEnergy(berlin, "pv1Energy")
+	sn := "HW51TEST"
+	// 22:30 UTC is 00:30 local time in summer
+	base := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	d.ObserveData(sn, map[string]interface{}{"pv1Energy": 1000.0}, base)
+	d.Observe(sn, "pv1Energy", 1200, base.Add(time.Hour))
+	d.Observe(sn, "pv1Energy", 30, base.Add(150*time.Minute))
+	d.Observe(sn, "pv1Energy", 80, base.Add(180*time.Minute))
+
+	assert.Equal(t, uint64(1), d.Resets(sn, "pv1Energy"))
+	assert.Equal(t, 200.0, d.DailyTotal(sn, "pv1Energy", base))
+	assert.Equal(t, 80.0, d.DailyTotal(sn, "pv1Energy", base.Add(3*time.Hour)))
+	assert.Equal(t, []string{"2025-06-01", "2025-06-02"}, d.Days(sn, "pv1Energy"))
+}
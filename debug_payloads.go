@@ -0,0 +1,82 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxRecentPayloads number of undecoded payloads kept for debugging
+var MaxRecentPayloads = 50
+
+// UndecodedPayload payload which could not be decoded
+type UndecodedPayload struct {
+	SerialNumber string    `json:"serialNumber"`
+	Topic        string    `json:"topic,omitempty"`
+	Received     time.Time `json:"received"`
+	Reason       string    `json:"reason"`
+	Payload      string    `json:"payload"`
+}
+
+var recentLock sync.Mutex
+var recentPayloads = make([]UndecodedPayload, 0)
+
+// recordUndecoded keep payload in the ring of recent undecoded payloads and
+// write it into the dump files if enabled
+func recordUndecoded(topic, serialNumber, reason string, payload []byte) {
+	p := UndecodedPayload{SerialNumber: serialNumber, Topic: topic,
+		Received: now(), Reason: reason, Payload: base64.StdEncoding.EncodeToString(payload)}
+	dumpPayload(p)
+	recentLock.Lock()
+	defer recentLock.Unlock()
+	if MaxRecentPayloads <= 0 {
+		return
+	}
+	recentPayloads = append(recentPayloads, p)
+	if len(recentPayloads) > MaxRecentPayloads {
+		recentPayloads = append(recentPayloads[:0], recentPayloads[len(recentPayloads)-MaxRecentPayloads:]...)
+	}
+}
+
+// RecentUndecodedPayloads return recent undecoded payloads, oldest first
+func RecentUndecodedPayloads() []UndecodedPayload {
+	recentLock.Lock()
+	defer recentLock.Unlock()
+	return append([]UndecodedPayload(nil), recentPayloads...)
+}
+
+// SaveUndecodedPayloads write the recent undecoded payloads as capture file,
+// encrypted if the storage encryption is active
+func SaveUndecodedPayloads(fileName string) error {
+	data, err := json.MarshalIndent(RecentUndecodedPayloads(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(fileName, data)
+}
+
+// LoadUndecodedPayloads read capture file written by SaveUndecodedPayloads
+func LoadUndecodedPayloads(fileName string) ([]UndecodedPayload, error) {
+	data, err := readStoredFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var payloads []UndecodedPayload
+	if err := json.Unmarshal(data, &payloads); err != nil {
+		return nil, fmt.Errorf("invalid capture file %s: %w", fileName, err)
+	}
+	return payloads, nil
+}
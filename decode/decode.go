@@ -0,0 +1,79 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package decode protobuf messages of the Ecoflow devices. The package only
+// depends on the protobuf runtime.
+package decode
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// CmdIdInverterHeartbeat command id of the PowerStream heartbeat
+	CmdIdInverterHeartbeat = 1
+	// CmdIdPowerPack command id of the PowerStream power streams
+	CmdIdPowerPack = 32
+	// CmdFuncStream command function of the Stream series messages
+	CmdFuncStream = 254
+	// CmdIdStreamDisplay command id of the Stream display properties
+	CmdIdStreamDisplay = 21
+	// CmdIdStreamRuntime command id of the Stream runtime properties
+	CmdIdStreamRuntime = 22
+)
+
+// ErrUnknownMessage the command of the header has no known message type
+var ErrUnknownMessage = errors.New("unknown message")
+
+// Envelope decode the envelope of a MQTT or local protobuf payload
+func Envelope(payload []byte) (*SendHeaderMsg, error) {
+	msg := &SendHeaderMsg{}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// NewMessage return empty message of the command of the header
+func NewMessage(header *Header) (proto.Message, error) {
+	if header.GetCmdFunc() == CmdFuncStream {
+		switch header.GetCmdId() {
+		case CmdIdStreamDisplay:
+			return &StreamDisplayPropertyUpload{}, nil
+		case CmdIdStreamRuntime:
+			return &StreamRuntimePropertyUpload{}, nil
+		}
+		return nil, fmt.Errorf("%w: stream cmd id %d", ErrUnknownMessage, header.GetCmdId())
+	}
+	switch header.GetCmdId() {
+	case CmdIdInverterHeartbeat:
+		return &InverterHeartbeat{}, nil
+	case CmdIdPowerPack:
+		return &PowerPack{}, nil
+	}
+	return nil, fmt.Errorf("%w: cmd id %d", ErrUnknownMessage, header.GetCmdId())
+}
+
+// Pdata decode the payload data of the header, ErrUnknownMessage is returned
+// for commands without known message type
+func Pdata(header *Header) (proto.Message, error) {
+	msg, err := NewMessage(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(header.GetPdata(), msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,49 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package decode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodePdata(t *testing.T) {
+	watts := uint32(1200)
+	pdata, err := proto.Marshal(&InverterHeartbeat{PermanentWatts: &watts})
+	assert.NoError(t, err)
+	cmdId := int32(CmdIdInverterHeartbeat)
+	payload, err := proto.Marshal(&SendHeaderMsg{Msg: &Header{Pdata: pdata, CmdId: &cmdId}})
+	assert.NoError(t, err)
+
+	envelope, err := Envelope(payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg, err := Pdata(envelope.Msg)
+	assert.NoError(t, err)
+	if ih, ok := msg.(*InverterHeartbeat); assert.True(t, ok) {
+		assert.Equal(t, watts, ih.GetPermanentWatts())
+	}
+
+	cmdFunc := int32(CmdFuncStream)
+	msg, err = NewMessage(&Header{CmdFunc: &cmdFunc, CmdId: proto.Int32(CmdIdStreamRuntime)})
+	assert.NoError(t, err)
+	assert.IsType(t, &StreamRuntimePropertyUpload{}, msg)
+
+	_, err = Pdata(&Header{CmdId: proto.Int32(99)})
+	assert.True(t, errors.Is(err, ErrUnknownMessage))
+	_, err = Envelope([]byte{0xff})
+	assert.Error(t, err)
+}
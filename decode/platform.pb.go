@@ -4,7 +4,7 @@
 // 	protoc        v5.29.3
 // source: platform.proto
 
-package ecoflow
+package decode
 
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -0,0 +1,441 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: stream.proto
+
+package decode
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Stream series status report (cmd_func 254, cmd_id 21)
+type StreamDisplayPropertyUpload struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	ErrCode                   *uint32                `protobuf:"varint,1,opt,name=err_code,json=errCode,proto3,oneof" json:"err_code,omitempty"`
+	CmsBattSoc                *float32               `protobuf:"fixed32,2,opt,name=cms_batt_soc,json=cmsBattSoc,proto3,oneof" json:"cms_batt_soc,omitempty"`
+	CmsMaxChgSoc              *uint32                `protobuf:"varint,3,opt,name=cms_max_chg_soc,json=cmsMaxChgSoc,proto3,oneof" json:"cms_max_chg_soc,omitempty"`
+	CmsMinDsgSoc              *uint32                `protobuf:"varint,4,opt,name=cms_min_dsg_soc,json=cmsMinDsgSoc,proto3,oneof" json:"cms_min_dsg_soc,omitempty"`
+	PowGetPvSum               *float32               `protobuf:"fixed32,5,opt,name=pow_get_pv_sum,json=powGetPvSum,proto3,oneof" json:"pow_get_pv_sum,omitempty"`
+	PowGetSysGrid             *float32               `protobuf:"fixed32,6,opt,name=pow_get_sys_grid,json=powGetSysGrid,proto3,oneof" json:"pow_get_sys_grid,omitempty"`
+	PowGetSysLoad             *float32               `protobuf:"fixed32,7,opt,name=pow_get_sys_load,json=powGetSysLoad,proto3,oneof" json:"pow_get_sys_load,omitempty"`
+	PowGetBpCms               *float32               `protobuf:"fixed32,8,opt,name=pow_get_bp_cms,json=powGetBpCms,proto3,oneof" json:"pow_get_bp_cms,omitempty"`
+	FeedGridMode              *uint32                `protobuf:"varint,9,opt,name=feed_grid_mode,json=feedGridMode,proto3,oneof" json:"feed_grid_mode,omitempty"`
+	FeedGridModePowLimit      *uint32                `protobuf:"varint,10,opt,name=feed_grid_mode_pow_limit,json=feedGridModePowLimit,proto3,oneof" json:"feed_grid_mode_pow_limit,omitempty"`
+	FeedGridModePowMax        *uint32                `protobuf:"varint,11,opt,name=feed_grid_mode_pow_max,json=feedGridModePowMax,proto3,oneof" json:"feed_grid_mode_pow_max,omitempty"`
+	CfgBypassMode             *bool                  `protobuf:"varint,12,opt,name=cfg_bypass_mode,json=cfgBypassMode,proto3,oneof" json:"cfg_bypass_mode,omitempty"`
+	Relay2Onoff               *bool                  `protobuf:"varint,13,opt,name=relay2_onoff,json=relay2Onoff,proto3,oneof" json:"relay2_onoff,omitempty"`
+	Relay3Onoff               *bool                  `protobuf:"varint,14,opt,name=relay3_onoff,json=relay3Onoff,proto3,oneof" json:"relay3_onoff,omitempty"`
+	EnergyStrategyOperateMode *uint32                `protobuf:"varint,15,opt,name=energy_strategy_operate_mode,json=energyStrategyOperateMode,proto3,oneof" json:"energy_strategy_operate_mode,omitempty"`
+	PowGetPv1                 *float32               `protobuf:"fixed32,16,opt,name=pow_get_pv1,json=powGetPv1,proto3,oneof" json:"pow_get_pv1,omitempty"`
+	PowGetPv2                 *float32               `protobuf:"fixed32,17,opt,name=pow_get_pv2,json=powGetPv2,proto3,oneof" json:"pow_get_pv2,omitempty"`
+	PowGetPv3                 *float32               `protobuf:"fixed32,18,opt,name=pow_get_pv3,json=powGetPv3,proto3,oneof" json:"pow_get_pv3,omitempty"`
+	PowGetPv4                 *float32               `protobuf:"fixed32,19,opt,name=pow_get_pv4,json=powGetPv4,proto3,oneof" json:"pow_get_pv4,omitempty"`
+	UtcTimestamp              *uint32                `protobuf:"varint,20,opt,name=utc_timestamp,json=utcTimestamp,proto3,oneof" json:"utc_timestamp,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *StreamDisplayPropertyUpload) Reset() {
+	*x = StreamDisplayPropertyUpload{}
+	mi := &file_stream_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamDisplayPropertyUpload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDisplayPropertyUpload) ProtoMessage() {}
+
+func (x *StreamDisplayPropertyUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_stream_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDisplayPropertyUpload.ProtoReflect.Descriptor instead.
+func (*StreamDisplayPropertyUpload) Descriptor() ([]byte, []int) {
+	return file_stream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamDisplayPropertyUpload) GetErrCode() uint32 {
+	if x != nil && x.ErrCode != nil {
+		return *x.ErrCode
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetCmsBattSoc() float32 {
+	if x != nil && x.CmsBattSoc != nil {
+		return *x.CmsBattSoc
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetCmsMaxChgSoc() uint32 {
+	if x != nil && x.CmsMaxChgSoc != nil {
+		return *x.CmsMaxChgSoc
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetCmsMinDsgSoc() uint32 {
+	if x != nil && x.CmsMinDsgSoc != nil {
+		return *x.CmsMinDsgSoc
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetPvSum() float32 {
+	if x != nil && x.PowGetPvSum != nil {
+		return *x.PowGetPvSum
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetSysGrid() float32 {
+	if x != nil && x.PowGetSysGrid != nil {
+		return *x.PowGetSysGrid
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetSysLoad() float32 {
+	if x != nil && x.PowGetSysLoad != nil {
+		return *x.PowGetSysLoad
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetBpCms() float32 {
+	if x != nil && x.PowGetBpCms != nil {
+		return *x.PowGetBpCms
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetFeedGridMode() uint32 {
+	if x != nil && x.FeedGridMode != nil {
+		return *x.FeedGridMode
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetFeedGridModePowLimit() uint32 {
+	if x != nil && x.FeedGridModePowLimit != nil {
+		return *x.FeedGridModePowLimit
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetFeedGridModePowMax() uint32 {
+	if x != nil && x.FeedGridModePowMax != nil {
+		return *x.FeedGridModePowMax
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetCfgBypassMode() bool {
+	if x != nil && x.CfgBypassMode != nil {
+		return *x.CfgBypassMode
+	}
+	return false
+}
+
+func (x *StreamDisplayPropertyUpload) GetRelay2Onoff() bool {
+	if x != nil && x.Relay2Onoff != nil {
+		return *x.Relay2Onoff
+	}
+	return false
+}
+
+func (x *StreamDisplayPropertyUpload) GetRelay3Onoff() bool {
+	if x != nil && x.Relay3Onoff != nil {
+		return *x.Relay3Onoff
+	}
+	return false
+}
+
+func (x *StreamDisplayPropertyUpload) GetEnergyStrategyOperateMode() uint32 {
+	if x != nil && x.EnergyStrategyOperateMode != nil {
+		return *x.EnergyStrategyOperateMode
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetPv1() float32 {
+	if x != nil && x.PowGetPv1 != nil {
+		return *x.PowGetPv1
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetPv2() float32 {
+	if x != nil && x.PowGetPv2 != nil {
+		return *x.PowGetPv2
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetPv3() float32 {
+	if x != nil && x.PowGetPv3 != nil {
+		return *x.PowGetPv3
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetPowGetPv4() float32 {
+	if x != nil && x.PowGetPv4 != nil {
+		return *x.PowGetPv4
+	}
+	return 0
+}
+
+func (x *StreamDisplayPropertyUpload) GetUtcTimestamp() uint32 {
+	if x != nil && x.UtcTimestamp != nil {
+		return *x.UtcTimestamp
+	}
+	return 0
+}
+
+// Stream series runtime report (cmd_func 254, cmd_id 22)
+type StreamRuntimePropertyUpload struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	BmsBattVol         *float32               `protobuf:"fixed32,1,opt,name=bms_batt_vol,json=bmsBattVol,proto3,oneof" json:"bms_batt_vol,omitempty"`
+	BmsBattAmp         *float32               `protobuf:"fixed32,2,opt,name=bms_batt_amp,json=bmsBattAmp,proto3,oneof" json:"bms_batt_amp,omitempty"`
+	BmsMaxCellTemp     *float32               `protobuf:"fixed32,3,opt,name=bms_max_cell_temp,json=bmsMaxCellTemp,proto3,oneof" json:"bms_max_cell_temp,omitempty"`
+	BmsMinCellTemp     *float32               `protobuf:"fixed32,4,opt,name=bms_min_cell_temp,json=bmsMinCellTemp,proto3,oneof" json:"bms_min_cell_temp,omitempty"`
+	GridConnectionVol  *float32               `protobuf:"fixed32,5,opt,name=grid_connection_vol,json=gridConnectionVol,proto3,oneof" json:"grid_connection_vol,omitempty"`
+	GridConnectionFreq *float32               `protobuf:"fixed32,6,opt,name=grid_connection_freq,json=gridConnectionFreq,proto3,oneof" json:"grid_connection_freq,omitempty"`
+	InvOutputWatts     *float32               `protobuf:"fixed32,7,opt,name=inv_output_watts,json=invOutputWatts,proto3,oneof" json:"inv_output_watts,omitempty"`
+	UtcTimestamp       *uint32                `protobuf:"varint,8,opt,name=utc_timestamp,json=utcTimestamp,proto3,oneof" json:"utc_timestamp,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StreamRuntimePropertyUpload) Reset() {
+	*x = StreamRuntimePropertyUpload{}
+	mi := &file_stream_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRuntimePropertyUpload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRuntimePropertyUpload) ProtoMessage() {}
+
+func (x *StreamRuntimePropertyUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_stream_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRuntimePropertyUpload.ProtoReflect.Descriptor instead.
+func (*StreamRuntimePropertyUpload) Descriptor() ([]byte, []int) {
+	return file_stream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamRuntimePropertyUpload) GetBmsBattVol() float32 {
+	if x != nil && x.BmsBattVol != nil {
+		return *x.BmsBattVol
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetBmsBattAmp() float32 {
+	if x != nil && x.BmsBattAmp != nil {
+		return *x.BmsBattAmp
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetBmsMaxCellTemp() float32 {
+	if x != nil && x.BmsMaxCellTemp != nil {
+		return *x.BmsMaxCellTemp
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetBmsMinCellTemp() float32 {
+	if x != nil && x.BmsMinCellTemp != nil {
+		return *x.BmsMinCellTemp
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetGridConnectionVol() float32 {
+	if x != nil && x.GridConnectionVol != nil {
+		return *x.GridConnectionVol
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetGridConnectionFreq() float32 {
+	if x != nil && x.GridConnectionFreq != nil {
+		return *x.GridConnectionFreq
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetInvOutputWatts() float32 {
+	if x != nil && x.InvOutputWatts != nil {
+		return *x.InvOutputWatts
+	}
+	return 0
+}
+
+func (x *StreamRuntimePropertyUpload) GetUtcTimestamp() uint32 {
+	if x != nil && x.UtcTimestamp != nil {
+		return *x.UtcTimestamp
+	}
+	return 0
+}
+
+var File_stream_proto protoreflect.FileDescriptor
+
+const file_stream_proto_rawDesc = "" +
+	"\n" +
+	"\fstream.proto\"\x98\n" +
+	"\n" +
+	"\x1bStreamDisplayPropertyUpload\x12\x1e\n" +
+	"\berr_code\x18\x01 \x01(\rH\x00R\aerrCode\x88\x01\x01\x12%\n" +
+	"\fcms_batt_soc\x18\x02 \x01(\x02H\x01R\n" +
+	"cmsBattSoc\x88\x01\x01\x12*\n" +
+	"\x0fcms_max_chg_soc\x18\x03 \x01(\rH\x02R\fcmsMaxChgSoc\x88\x01\x01\x12*\n" +
+	"\x0fcms_min_dsg_soc\x18\x04 \x01(\rH\x03R\fcmsMinDsgSoc\x88\x01\x01\x12(\n" +
+	"\x0epow_get_pv_sum\x18\x05 \x01(\x02H\x04R\vpowGetPvSum\x88\x01\x01\x12,\n" +
+	"\x10pow_get_sys_grid\x18\x06 \x01(\x02H\x05R\rpowGetSysGrid\x88\x01\x01\x12,\n" +
+	"\x10pow_get_sys_load\x18\a \x01(\x02H\x06R\rpowGetSysLoad\x88\x01\x01\x12(\n" +
+	"\x0epow_get_bp_cms\x18\b \x01(\x02H\aR\vpowGetBpCms\x88\x01\x01\x12)\n" +
+	"\x0efeed_grid_mode\x18\t \x01(\rH\bR\ffeedGridMode\x88\x01\x01\x12;\n" +
+	"\x18feed_grid_mode_pow_limit\x18\n" +
+	" \x01(\rH\tR\x14feedGridModePowLimit\x88\x01\x01\x127\n" +
+	"\x16feed_grid_mode_pow_max\x18\v \x01(\rH\n" +
+	"R\x12feedGridModePowMax\x88\x01\x01\x12+\n" +
+	"\x0fcfg_bypass_mode\x18\f \x01(\bH\vR\rcfgBypassMode\x88\x01\x01\x12&\n" +
+	"\frelay2_onoff\x18\r \x01(\bH\fR\vrelay2Onoff\x88\x01\x01\x12&\n" +
+	"\frelay3_onoff\x18\x0e \x01(\bH\rR\vrelay3Onoff\x88\x01\x01\x12D\n" +
+	"\x1cenergy_strategy_operate_mode\x18\x0f \x01(\rH\x0eR\x19energyStrategyOperateMode\x88\x01\x01\x12#\n" +
+	"\vpow_get_pv1\x18\x10 \x01(\x02H\x0fR\tpowGetPv1\x88\x01\x01\x12#\n" +
+	"\vpow_get_pv2\x18\x11 \x01(\x02H\x10R\tpowGetPv2\x88\x01\x01\x12#\n" +
+	"\vpow_get_pv3\x18\x12 \x01(\x02H\x11R\tpowGetPv3\x88\x01\x01\x12#\n" +
+	"\vpow_get_pv4\x18\x13 \x01(\x02H\x12R\tpowGetPv4\x88\x01\x01\x12(\n" +
+	"\rutc_timestamp\x18\x14 \x01(\rH\x13R\futcTimestamp\x88\x01\x01B\v\n" +
+	"\t_err_codeB\x0f\n" +
+	"\r_cms_batt_socB\x12\n" +
+	"\x10_cms_max_chg_socB\x12\n" +
+	"\x10_cms_min_dsg_socB\x11\n" +
+	"\x0f_pow_get_pv_sumB\x13\n" +
+	"\x11_pow_get_sys_gridB\x13\n" +
+	"\x11_pow_get_sys_loadB\x11\n" +
+	"\x0f_pow_get_bp_cmsB\x11\n" +
+	"\x0f_feed_grid_modeB\x1b\n" +
+	"\x19_feed_grid_mode_pow_limitB\x19\n" +
+	"\x17_feed_grid_mode_pow_maxB\x12\n" +
+	"\x10_cfg_bypass_modeB\x0f\n" +
+	"\r_relay2_onoffB\x0f\n" +
+	"\r_relay3_onoffB\x1f\n" +
+	"\x1d_energy_strategy_operate_modeB\x0e\n" +
+	"\f_pow_get_pv1B\x0e\n" +
+	"\f_pow_get_pv2B\x0e\n" +
+	"\f_pow_get_pv3B\x0e\n" +
+	"\f_pow_get_pv4B\x10\n" +
+	"\x0e_utc_timestamp\"\xb6\x04\n" +
+	"\x1bStreamRuntimePropertyUpload\x12%\n" +
+	"\fbms_batt_vol\x18\x01 \x01(\x02H\x00R\n" +
+	"bmsBattVol\x88\x01\x01\x12%\n" +
+	"\fbms_batt_amp\x18\x02 \x01(\x02H\x01R\n" +
+	"bmsBattAmp\x88\x01\x01\x12.\n" +
+	"\x11bms_max_cell_temp\x18\x03 \x01(\x02H\x02R\x0ebmsMaxCellTemp\x88\x01\x01\x12.\n" +
+	"\x11bms_min_cell_temp\x18\x04 \x01(\x02H\x03R\x0ebmsMinCellTemp\x88\x01\x01\x123\n" +
+	"\x13grid_connection_vol\x18\x05 \x01(\x02H\x04R\x11gridConnectionVol\x88\x01\x01\x125\n" +
+	"\x14grid_connection_freq\x18\x06 \x01(\x02H\x05R\x12gridConnectionFreq\x88\x01\x01\x12-\n" +
+	"\x10inv_output_watts\x18\a \x01(\x02H\x06R\x0einvOutputWatts\x88\x01\x01\x12(\n" +
+	"\rutc_timestamp\x18\b \x01(\rH\aR\futcTimestamp\x88\x01\x01B\x0f\n" +
+	"\r_bms_batt_volB\x0f\n" +
+	"\r_bms_batt_ampB\x14\n" +
+	"\x12_bms_max_cell_tempB\x14\n" +
+	"\x12_bms_min_cell_tempB\x16\n" +
+	"\x14_grid_connection_volB\x17\n" +
+	"\x15_grid_connection_freqB\x13\n" +
+	"\x11_inv_output_wattsB\x10\n" +
+	"\x0e_utc_timestampb\x06proto3"
+
+var (
+	file_stream_proto_rawDescOnce sync.Once
+	file_stream_proto_rawDescData []byte
+)
+
+func file_stream_proto_rawDescGZIP() []byte {
+	file_stream_proto_rawDescOnce.Do(func() {
+		file_stream_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stream_proto_rawDesc), len(file_stream_proto_rawDesc)))
+	})
+	return file_stream_proto_rawDescData
+}
+
+var file_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_stream_proto_goTypes = []any{
+	(*StreamDisplayPropertyUpload)(nil), // 0: StreamDisplayPropertyUpload
+	(*StreamRuntimePropertyUpload)(nil), // 1: StreamRuntimePropertyUpload
+}
+var file_stream_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_stream_proto_init() }
+func file_stream_proto_init() {
+	if File_stream_proto != nil {
+		return
+	}
+	file_stream_proto_msgTypes[0].OneofWrappers = []any{}
+	file_stream_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stream_proto_rawDesc), len(file_stream_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_stream_proto_goTypes,
+		DependencyIndexes: file_stream_proto_depIdxs,
+		MessageInfos:      file_stream_proto_msgTypes,
+	}.Build()
+	File_stream_proto = out.File
+	file_stream_proto_goTypes = nil
+	file_stream_proto_depIdxs = nil
+}
@@ -0,0 +1,124 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupWindow number of recent sequence numbers kept per device and command
+const dedupWindow = 64
+
+// dedupExpiry idle time after which the sequence history of a command is
+// dropped, a device restarting its sequence numbers is not seen as duplicate
+const dedupExpiry = 5 * time.Minute
+
+// seqHistory recent sequence numbers of one command of a device
+type seqHistory struct {
+	last   int32
+	recent [dedupWindow]int32
+	next   int
+	filled int
+	seen   time.Time
+}
+
+// restarted check if the sequence number indicates a device restart, it is far
+// below the window of recent numbers or the history expired
+func (h *seqHistory) restarted(seq int32) bool {
+	if h.filled == 0 {
+		return false
+	}
+	return seq < h.last-dedupWindow || since(h.seen) > dedupExpiry
+}
+
+func (h *seqHistory) contains(seq int32) bool {
+	for i := 0; i < h.filled; i++ {
+		if h.recent[i] == seq {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *seqHistory) add(seq int32) {
+	h.recent[h.next] = seq
+	h.next = (h.next + 1) % dedupWindow
+	if h.filled < dedupWindow {
+		h.filled++
+	}
+	if seq > h.last {
+		h.last = seq
+	}
+	h.seen = now()
+}
+
+// seqTracker sequence numbers and dedup counters of a device
+type seqTracker struct {
+	mu         sync.Mutex
+	commands   map[int32]*seqHistory
+	duplicates uint64
+	outOfOrder uint64
+}
+
+var seqTrackers sync.Map
+
+func seqTrackerOf(serialNumber string) *seqTracker {
+	if t, ok := seqTrackers.Load(serialNumber); ok {
+		return t.(*seqTracker)
+	}
+	t, _ := seqTrackers.LoadOrStore(serialNumber, &seqTracker{commands: make(map[int32]*seqHistory)})
+	return t.(*seqTracker)
+}
+
+// duplicateFrame check the sequence number of a received header. Frames with a
+// sequence number received before are duplicates, e.g. redelivered after a
+// reconnect, and must not be processed again. Older sequence numbers not seen
+// before are counted as out of order but processed.
+func duplicateFrame(serialNumber string, header *Header) bool {
+	if header == nil || header.GetSeq() == 0 {
+		return false
+	}
+	seq := header.GetSeq()
+	command := header.GetCmdFunc()<<16 | header.GetCmdId()&0xffff
+	t := seqTrackerOf(serialNumber)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.commands[command]
+	if !ok || h.restarted(seq) {
+		h = &seqHistory{}
+		t.commands[command] = h
+	}
+	if h.contains(seq) {
+		t.duplicates++
+		metricMqttDuplicates.Add(1)
+		return true
+	}
+	if h.filled > 0 && seq < h.last {
+		t.outOfOrder++
+		metricMqttOutOfOrder.Add(1)
+	}
+	h.add(seq)
+	return false
+}
+
+// dedupCounters return duplicate and out of order counters of the device
+func dedupCounters(serialNumber string) (duplicates, outOfOrder uint64) {
+	v, ok := seqTrackers.Load(serialNumber)
+	if !ok {
+		return 0, 0
+	}
+	t := v.(*seqTracker)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.duplicates, t.outOfOrder
+}
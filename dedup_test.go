@@ -0,0 +1,76 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDuplicateFrames(t *testing.T) {
+	pdata, err := proto.Marshal(&InverterHeartbeat{PermanentWatts: proto.Uint32(2000)})
+	if !assert.NoError(t, err) {
+		return
+	}
+	frame := func(seq int32) []byte {
+		payload, err := proto.Marshal(&SendHeaderMsg{Msg: &Header{Pdata: pdata, CmdId: proto.Int32(1), Seq: &seq}})
+		assert.NoError(t, err)
+		return payload
+	}
+	received := 0
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		if points[0].SerialNumber == "HW51DEDUP" {
+			received++
+		}
+	})
+	defer unregister()
+
+	topic := "/app/device/property/HW51DEDUP"
+	for _, seq := range []int32{10, 11, 11, 12, 9, 10} {
+		MessageHandler(nil, &benchMessage{topic: topic, payload: frame(seq)})
+	}
+	assert.Equal(t, 4, received)
+	stats, ok := HeartbeatStats("HW51DEDUP")
+	if assert.True(t, ok) {
+		assert.Equal(t, uint64(2), stats.Duplicates)
+		assert.Equal(t, uint64(1), stats.OutOfOrder)
+		assert.Equal(t, uint64(6), stats.Messages)
+	}
+}
+
+func TestDuplicateFramesRestart(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+	header := func(seq int32) *Header {
+		return &Header{CmdFunc: proto.Int32(20), CmdId: proto.Int32(1), Seq: &seq}
+	}
+	for seq := int32(1000); seq < 1010; seq++ {
+		assert.False(t, duplicateFrame("HW51REBOOT", header(seq)))
+	}
+	assert.True(t, duplicateFrame("HW51REBOOT", header(1005)))
+
+	// device rebooted and starts again with low sequence numbers
+	for seq := int32(1); seq < 5; seq++ {
+		assert.False(t, duplicateFrame("HW51REBOOT", header(seq)))
+	}
+	assert.True(t, duplicateFrame("HW51REBOOT", header(3)))
+
+	// short restart reusing recent sequence numbers after the history expired
+	clock.Advance(dedupExpiry + time.Second)
+	assert.False(t, duplicateFrame("HW51REBOOT", header(2)))
+	_, outOfOrder := dedupCounters("HW51REBOOT")
+	assert.Equal(t, uint64(0), outOfOrder)
+}
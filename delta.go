@@ -0,0 +1,115 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// quotaSlowChgWatts AC charging power reported by the inverter of Delta Max/Pro and Delta 2 Max
+	quotaSlowChgWatts = "inv.cfgSlowChgWatts"
+	// quotaMpptChgWatts AC charging power reported by Delta 2 and River 2 stations
+	quotaMpptChgWatts = "mppt.cfgChgWatts"
+	// quotaBeepMode beeper mode of Delta 2 and River 2 stations, 1 is quiet
+	quotaBeepMode = "pd.beepMode"
+	// keepChgWatts inverter value leaving the fast charging power unchanged
+	keepChgWatts = 255
+)
+
+// SetChargeLimit set maximum charge level in percent of Delta/River stations
+func (client *Client) SetChargeLimit(ctx context.Context, serialNumber string, percent int) (*CmdSetResponse, error) {
+	if err := DeviceLimits.Validate(serialNumber, "maxChgSoc", float64(percent)); err != nil {
+		return nil, err
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		Sn:          serialNumber,
+		ModuleType:  ModuleTypeBms,
+		OperateType: "upsConfig",
+		Params:      map[string]interface{}{"maxChgSoc": percent},
+	})
+}
+
+// SetDischargeLimit set minimum discharge level in percent of Delta/River stations
+func (client *Client) SetDischargeLimit(ctx context.Context, serialNumber string, percent int) (*CmdSetResponse, error) {
+	if err := DeviceLimits.Validate(serialNumber, "minDsgSoc", float64(percent)); err != nil {
+		return nil, err
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		Sn:          serialNumber,
+		ModuleType:  ModuleTypeBms,
+		OperateType: "dsgCfg",
+		Params:      map[string]interface{}{"minDsgSoc": percent},
+	})
+}
+
+// SetACChargeWatts set AC charging power (input current limit) of Delta and River
+// stations. Delta Max/Pro use the command set interface, the Delta 2 Max the
+// inverter module (module type 3) and Delta 2/River 2 the MPPT module.
+func (client *Client) SetACChargeWatts(ctx context.Context, serialNumber string, watts int) (*CmdSetResponse, error) {
+	model := DetectModel(serialNumber)
+	if _, ok := modelRanges["acChargeWatts"][model]; !ok {
+		return nil, fmt.Errorf("AC charging power not supported for device %s", serialNumber)
+	}
+	if err := DeviceLimits.Validate(serialNumber, "acChargeWatts", float64(watts)); err != nil {
+		return nil, err
+	}
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch model {
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 69, "slowChgPower": watts}
+	case ModelDelta2Max:
+		cmdReq.ModuleType = ModuleTypeInv
+		cmdReq.OperateType = "acChgCfg"
+		cmdReq.Params = map[string]interface{}{"fastChgWatts": keepChgWatts, "slowChgWatts": watts,
+			"chgPauseFlag": 0}
+	default:
+		cmdReq.ModuleType = ModuleTypeMppt
+		cmdReq.OperateType = "acChgCfg"
+		cmdReq.Params = map[string]interface{}{"chgWatts": watts, "chgPauseFlag": 0}
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
+
+// SetSlowChargePower set AC charging power using SetACChargeWatts and verify it
+// using the value reported by the device
+func (client *Client) SetSlowChargePower(ctx context.Context, serialNumber string, watts int) (*CmdSetResponse, error) {
+	quotaKey := quotaSlowChgWatts
+	switch DetectModel(serialNumber) {
+	case ModelDeltaMax, ModelDeltaPro, ModelDelta2Max:
+	default:
+		quotaKey = quotaMpptChgWatts
+	}
+	sent := now()
+	resp, err := client.SetACChargeWatts(ctx, serialNumber, watts)
+	if err != nil {
+		return resp, err
+	}
+	return resp, client.verifyValue(ctx, resp.Id, serialNumber, sent, quotaKey, []string{quotaKey}, float64(watts))
+}
+
+// SetQuietMode switch the beeper of Delta and River stations off (quiet) or on
+func (client *Client) SetQuietMode(ctx context.Context, serialNumber string, quiet bool) (*CmdSetResponse, error) {
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		cmdReq.ModuleType = ModuleTypePd
+		cmdReq.OperateType = "quietCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": boolToInt(quiet)}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 38, "enabled": boolToInt(!quiet)}
+	default:
+		return nil, fmt.Errorf("quiet mode not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
@@ -0,0 +1,58 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSlowChargePower(t *testing.T) {
+	oldTimeout := VerifyTimeout
+	VerifyTimeout = time.Millisecond
+	defer func() { VerifyTimeout = oldTimeout }()
+
+	transport := newFakeAPI(map[string]interface{}{quotaSlowChgWatts: 800.0, quotaMpptChgWatts: 600.0})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.SetSlowChargePower(ctx, "DCABZSLOW", 800)
+	assert.NoError(t, err)
+	_, err = client.SetSlowChargePower(ctx, "R351SLOW", 800)
+	assert.NoError(t, err)
+	_, err = client.SetSlowChargePower(ctx, "R331SLOW", 600)
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 3) {
+		assert.Equal(t, float64(69), transport.requests[0].Params["id"])
+		assert.Equal(t, float64(800), transport.requests[0].Params["slowChgPower"])
+		assert.Equal(t, ModuleTypeInv, transport.requests[1].ModuleType)
+		assert.Equal(t, float64(800), transport.requests[1].Params["slowChgWatts"])
+		assert.Equal(t, float64(keepChgWatts), transport.requests[1].Params["fastChgWatts"])
+		assert.Equal(t, ModuleTypeMppt, transport.requests[2].ModuleType)
+		assert.Equal(t, float64(600), transport.requests[2].Params["chgWatts"])
+	}
+
+	_, err = client.SetSlowChargePower(ctx, "DAEBSLOW", 1200)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	_, err = client.SetSlowChargePower(ctx, "DAEBSLOW", 2900)
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr)
+	_, err = client.SetSlowChargePower(ctx, "HW51SLOW", 600)
+	assert.Error(t, err)
+	_, err = client.SetACChargeWatts(ctx, "R621SLOW", 600)
+	assert.ErrorAs(t, err, &verr)
+	assert.Equal(t, Range{Min: 100, Max: 360}, verr.Range)
+	assert.Len(t, transport.requests, 4)
+}
@@ -0,0 +1,165 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// EventDeratingAdvice published if the advised charge power of a device changes
+const EventDeratingAdvice EventType = "battery.derating"
+
+// DeratingPoint charge power in percent of the maximum at a battery temperature in °C
+type DeratingPoint struct {
+	Temp    float64 `json:"temp"`
+	Percent float64 `json:"percent"`
+}
+
+// DeratingCurve derating points, the charge power between the points is interpolated
+type DeratingCurve []DeratingPoint
+
+// DefaultDeratingCurve no charging below 0°C and above 50°C, full charge power
+// between 15°C and 40°C
+var DefaultDeratingCurve = DeratingCurve{{0, 0}, {5, 25}, {15, 100}, {40, 100}, {45, 50}, {50, 0}}
+
+// Percent return the charge power in percent at the given temperature
+func (c DeratingCurve) Percent(temp float64) float64 {
+	if len(c) == 0 {
+		return 100
+	}
+	points := append(DeratingCurve{}, c...)
+	sort.Slice(points, func(i, j int) bool { return points[i].Temp < points[j].Temp })
+	if temp <= points[0].Temp {
+		return points[0].Percent
+	}
+	for i := 1; i < len(points); i++ {
+		if temp <= points[i].Temp {
+			p, n := points[i-1], points[i]
+			return p.Percent + (temp-p.Temp)*(n.Percent-p.Percent)/(n.Temp-p.Temp)
+		}
+	}
+	return points[len(points)-1].Percent
+}
+
+// DeratingAdvice advised charge power of a device
+type DeratingAdvice struct {
+	SerialNumber string    `json:"serialNumber"`
+	Temperature  float64   `json:"temperature"`
+	Percent      float64   `json:"percent"`
+	Watts        int       `json:"watts"`
+	Time         time.Time `json:"time"`
+	Applied      bool      `json:"applied"`
+}
+
+// DeratingAdvisor watch the battery pack temperatures and advise a reduced charge
+// power in cold or hot conditions. The pack with the worst temperature is used.
+// If Apply is set, the advice is written using the charge power setting.
+type DeratingAdvisor struct {
+	mu     sync.Mutex
+	client *Client
+	Curve  DeratingCurve
+	// MaxWatts charge power at 100 percent
+	MaxWatts int
+	// Step minimum change of the charge power in watts before a new advice is given
+	Step int
+	// Setting name of the charge power setting
+	Setting string
+	Apply   bool
+	temps   map[string]map[string]float64
+	advice  map[string]DeratingAdvice
+}
+
+// NewDeratingAdvisor create advisor using the default curve, the client may be
+// nil if the advice should not be applied
+func NewDeratingAdvisor(client *Client, maxWatts int) *DeratingAdvisor {
+	return &DeratingAdvisor{client: client, Curve: DefaultDeratingCurve, MaxWatts: maxWatts,
+		Step: 50, Setting: "acChargeWatts", temps: make(map[string]map[string]float64),
+		advice: make(map[string]DeratingAdvice)}
+}
+
+// Handle telemetry handler evaluating battery temperatures, register it with
+// RegisterTelemetryContextHandler
+func (a *DeratingAdvisor) Handle(ctx context.Context, points []*Telemetry) {
+	changed := a.update(points)
+	for _, advice := range changed {
+		if a.Apply && a.client != nil {
+			if _, err := a.client.ApplySetting(ctx, advice.SerialNumber, a.Setting, float64(advice.Watts)); err != nil {
+				services.ServerMessage("Ecoflow: derating charge power of %s failed: %v", advice.SerialNumber, err)
+			} else {
+				advice.Applied = true
+				a.mu.Lock()
+				a.advice[advice.SerialNumber] = advice
+				a.mu.Unlock()
+			}
+		}
+		Events.Publish(Event{Type: EventDeratingAdvice, SerialNumber: advice.SerialNumber, Data: advice})
+	}
+}
+
+func (a *DeratingAdvisor) update(points []*Telemetry) []DeratingAdvice {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	devices := make(map[string]bool)
+	for _, p := range points {
+		temp, ok := p.Value.(float64)
+		if !ok || !strings.HasSuffix(p.Key, ".temp") || !strings.HasPrefix(p.Key, "bms") {
+			continue
+		}
+		sn := strings.ToUpper(p.SerialNumber)
+		if a.temps[sn] == nil {
+			a.temps[sn] = make(map[string]float64)
+		}
+		a.temps[sn][strings.TrimSuffix(p.Key, ".temp")] = temp
+		devices[sn] = true
+	}
+	var changed []DeratingAdvice
+	for sn := range devices {
+		advice := DeratingAdvice{SerialNumber: sn, Percent: 101, Time: now()}
+		for _, temp := range a.temps[sn] {
+			if percent := a.Curve.Percent(temp); percent < advice.Percent {
+				advice.Percent = percent
+				advice.Temperature = temp
+			}
+		}
+		advice.Watts = int(math.Round(float64(a.MaxWatts) * advice.Percent / 100))
+		if old, ok := a.advice[sn]; ok && abs(old.Watts-advice.Watts) < a.Step &&
+			(advice.Watts != 0 || old.Watts == 0) && (advice.Watts != a.MaxWatts || old.Watts == a.MaxWatts) {
+			continue
+		}
+		a.advice[sn] = advice
+		changed = append(changed, advice)
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].SerialNumber < changed[j].SerialNumber })
+	return changed
+}
+
+// Advice return the current advice of the device
+func (a *DeratingAdvisor) Advice(serialNumber string) (DeratingAdvice, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	advice, ok := a.advice[strings.ToUpper(serialNumber)]
+	return advice, ok
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeratingCurve(t *testing.T) {
+	assert.Equal(t, 0.0, DefaultDeratingCurve.Percent(-10))
+	assert.Equal(t, 62.5, DefaultDeratingCurve.Percent(10))
+	assert.Equal(t, 100.0, DefaultDeratingCurve.Percent(25))
+	assert.Equal(t, 75.0, DefaultDeratingCurve.Percent(42.5))
+	assert.Equal(t, 0.0, DefaultDeratingCurve.Percent(60))
+	assert.Equal(t, 100.0, DeratingCurve{}.Percent(60))
+}
+
+func TestDeratingAdvisor(t *testing.T) {
+	transport := newPowerStreamAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+
+	a := NewDeratingAdvisor(client, 1200)
+	sn := "R331DERATE"
+	temps := func(master, slave float64) []*Telemetry {
+		return []*Telemetry{{SerialNumber: sn, Key: "bms_bmsStatus.temp", Value: master},
+			{SerialNumber: sn, Key: "bms_slave_bmsSlaveStatus_1.temp", Value: slave},
+			{SerialNumber: sn, Key: "inv.outTemp", Value: -20.0}}
+	}
+	a.Handle(context.Background(), temps(25, 10))
+	advice, ok := a.Advice(sn)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, advice.Temperature)
+	assert.Equal(t, 750, advice.Watts)
+	assert.False(t, advice.Applied)
+	assert.Equal(t, 0, transport.puts())
+
+	// small changes are ignored
+	a.Apply = true
+	a.Handle(context.Background(), temps(25, 10.2))
+	assert.Equal(t, 0, transport.puts())
+
+	a.Handle(context.Background(), temps(25, 20))
+	advice, _ = a.Advice(sn)
+	assert.Equal(t, 1200, advice.Watts)
+	assert.True(t, advice.Applied)
+	assert.Equal(t, 1, transport.puts())
+	assert.Equal(t, 1200.0, transport.value("20_1.chgWatts"))
+}
@@ -0,0 +1,79 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultDeviceListTTL default time a fetched device list is reused
+var DefaultDeviceListTTL = 5 * time.Minute
+
+// DeviceListCache device list cached for a configurable time, concurrent
+// requests during a refresh wait for the single running request
+type DeviceListCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	fetch   func(ctx context.Context) (*DeviceListResponse, error)
+	list    *DeviceListResponse
+	fetched time.Time
+	now     func() time.Time
+}
+
+func newDeviceListCache(fetch func(ctx context.Context) (*DeviceListResponse, error)) *DeviceListCache {
+	return &DeviceListCache{ttl: DefaultDeviceListTTL, fetch: fetch, now: now}
+}
+
+// SetTTL set time a fetched device list is reused, zero disables caching
+func (d *DeviceListCache) SetTTL(ttl time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ttl = ttl
+}
+
+// Get return cached device list or fetch it if expired
+func (d *DeviceListCache) Get(ctx context.Context) (*DeviceListResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.list != nil && d.ttl > 0 && d.now().Sub(d.fetched) < d.ttl {
+		return d.list, nil
+	}
+	list, err := d.fetch(ctx)
+	if err != nil {
+		return list, err
+	}
+	d.list = list
+	d.fetched = d.now()
+	return list, nil
+}
+
+// Invalidate drop the cached device list, the next Get fetches it again
+func (d *DeviceListCache) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.list = nil
+}
+
+// DeviceCache return the device list cache of the client
+func (c *Client) DeviceCache() *DeviceListCache {
+	c.deviceCacheOnce.Do(func() {
+		c.deviceCache = newDeviceListCache(c.GetDeviceList)
+	})
+	return c.deviceCache
+}
+
+// CachedDeviceList return the device list using the cache of the client
+func (c *Client) CachedDeviceList(ctx context.Context) (*DeviceListResponse, error) {
+	return c.DeviceCache().Get(ctx)
+}
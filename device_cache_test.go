@@ -0,0 +1,43 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceListCache(t *testing.T) {
+	calls := 0
+	cache := newDeviceListCache(func(context.Context) (*DeviceListResponse, error) {
+		calls++
+		return &DeviceListResponse{Code: "0"}, nil
+	})
+	now := time.Date(2025, 4, 7, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	cache.SetTTL(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Get(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Get(context.Background())
+	assert.Equal(t, 2, calls)
+	cache.Invalidate()
+	_, _ = cache.Get(context.Background())
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,98 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"strings"
+	"sync"
+)
+
+// DeviceModel Ecoflow device model family
+type DeviceModel int
+
+const (
+	ModelUnknown DeviceModel = iota
+	ModelPowerStream
+	ModelSmartPlug
+	ModelDelta2
+	ModelDelta2Max
+	ModelDeltaMax
+	ModelDeltaPro
+	ModelRiver2
+	ModelRiver2Max
+	ModelRiver2Pro
+	ModelSmartGenerator
+	ModelStream
+)
+
+var modelNames = map[DeviceModel]string{
+	ModelUnknown:        "unknown",
+	ModelPowerStream:    "PowerStream",
+	ModelSmartPlug:      "Smart Plug",
+	ModelDelta2:         "Delta 2",
+	ModelDelta2Max:      "Delta 2 Max",
+	ModelDeltaMax:       "Delta Max",
+	ModelDeltaPro:       "Delta Pro",
+	ModelRiver2:         "River 2",
+	ModelRiver2Max:      "River 2 Max",
+	ModelRiver2Pro:      "River 2 Pro",
+	ModelSmartGenerator: "Smart Generator",
+	ModelStream:         "Stream",
+}
+
+func (m DeviceModel) String() string {
+	if name, ok := modelNames[m]; ok {
+		return name
+	}
+	return modelNames[ModelUnknown]
+}
+
+var modelLock sync.RWMutex
+
+// modelPrefixes serial number prefixes of the device models
+var modelPrefixes = map[string]DeviceModel{
+	"HW51":  ModelPowerStream,
+	"HW52":  ModelSmartPlug,
+	"R331":  ModelDelta2,
+	"R351":  ModelDelta2Max,
+	"DAEB":  ModelDeltaMax,
+	"DCABZ": ModelDeltaPro,
+	"R621":  ModelRiver2,
+	"R611":  ModelRiver2Max,
+	"R631":  ModelRiver2Pro,
+	"DGEB":  ModelSmartGenerator,
+	"BK":    ModelStream,
+}
+
+// RegisterModelPrefix register serial number prefix of a device model
+func RegisterModelPrefix(prefix string, model DeviceModel) {
+	modelLock.Lock()
+	defer modelLock.Unlock()
+	modelPrefixes[strings.ToUpper(prefix)] = model
+}
+
+// DetectModel detect device model using the serial number prefix,
+// the longest matching prefix is used
+func DetectModel(serialNumber string) DeviceModel {
+	modelLock.RLock()
+	defer modelLock.RUnlock()
+	sn := strings.ToUpper(serialNumber)
+	model := ModelUnknown
+	length := 0
+	for prefix, m := range modelPrefixes {
+		if len(prefix) > length && strings.HasPrefix(sn, prefix) {
+			model = m
+			length = len(prefix)
+		}
+	}
+	return model
+}
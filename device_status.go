@@ -0,0 +1,158 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tknie/log"
+)
+
+// EventDeviceStatus online state or Wi-Fi signal of a device changed, Data contains DeviceStatusEvent
+const EventDeviceStatus EventType = "device.status"
+
+// statusTopicPrefix prefix of the device status topics
+const statusTopicPrefix = "/app/device/status/"
+
+// DeviceStatusEvent status of a device received on the status topic
+type DeviceStatusEvent struct {
+	SerialNumber string `json:"serialNumber"`
+	Online       bool   `json:"online"`
+	// HasRSSI the status message contained the Wi-Fi signal strength
+	HasRSSI bool `json:"hasRssi"`
+	// RSSI Wi-Fi signal strength in dBm
+	RSSI int       `json:"rssi,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// device status keys of the status messages
+var (
+	statusOnlineKeys = []string{"status", "online", "onlineStatus", "state"}
+	statusRSSIKeys   = []string{"wifiRssi", "rssi", "wifi_rssi", "signal"}
+)
+
+var deviceStatusLock sync.Mutex
+var deviceStatus = make(map[string]DeviceStatusEvent)
+
+func statusTopic(deviceSn string) string {
+	return statusTopicPrefix + deviceSn
+}
+
+// isStatusTopic check if the topic is a device status topic
+func isStatusTopic(topic string) bool {
+	return strings.HasPrefix(topic, statusTopicPrefix)
+}
+
+// SubscribeForStatus subscribe the status topic of the device
+func (m *MqttClient) SubscribeForStatus(deviceSn string, callback mqtt.MessageHandler) error {
+	return m.SubscribeToTopics([]string{statusTopic(deviceSn)}, callback)
+}
+
+// parseDeviceStatus parse the status message, the keys are searched in the
+// message and the params
+func parseDeviceStatus(serialNumber string, payload []byte) (DeviceStatusEvent, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return DeviceStatusEvent{}, err
+	}
+	if params, ok := data["params"].(map[string]interface{}); ok {
+		data = params
+	}
+	status := DeviceStatusEvent{SerialNumber: serialNumber, Time: now()}
+	found := false
+	for _, k := range statusOnlineKeys {
+		if v, ok := data[k]; ok {
+			online, ok := statusOnline(v)
+			if !ok {
+				return DeviceStatusEvent{}, fmt.Errorf("invalid status %v", v)
+			}
+			status.Online = online
+			found = true
+			break
+		}
+	}
+	for _, k := range statusRSSIKeys {
+		if v, ok := data[k].(float64); ok {
+			status.RSSI = int(v)
+			status.HasRSSI = true
+			break
+		}
+	}
+	if !found && !status.HasRSSI {
+		return DeviceStatusEvent{}, fmt.Errorf("no status in message")
+	}
+	if !found {
+		// a signal strength is only reported by online devices
+		status.Online = true
+	}
+	return status, nil
+}
+
+func statusOnline(v interface{}) (bool, bool) {
+	switch s := v.(type) {
+	case float64:
+		return s != 0, true
+	case bool:
+		return s, true
+	case string:
+		switch strings.ToLower(s) {
+		case "1", "online", "connected", "true":
+			return true, true
+		case "0", "offline", "disconnected", "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// StatusMessageHandler message handler of the device status topics, publish an
+// EventDeviceStatus if the online state or the signal strength changed
+func StatusMessageHandler(_ mqtt.Client, msg mqtt.Message) {
+	serialNumber := getSnFromTopic(msg.Topic())
+	defer recoverHandler("mqtt status", serialNumber)
+	countTopic(msg.Topic())
+	status, err := parseDeviceStatus(serialNumber, msg.Payload())
+	if err != nil {
+		log.Log.Errorf("Unable to parse status of %s: %v", serialNumber, err)
+		return
+	}
+	observeDeviceStatus(status)
+}
+
+// observeDeviceStatus store the status and publish an event on changes
+func observeDeviceStatus(status DeviceStatusEvent) {
+	key := strings.ToUpper(status.SerialNumber)
+	deviceStatusLock.Lock()
+	last, known := deviceStatus[key]
+	deviceStatus[key] = status
+	deviceStatusLock.Unlock()
+	if status.Online {
+		markSeen(status.SerialNumber, SourceMqtt, status.Time)
+	}
+	if known && last.Online == status.Online && last.HasRSSI == status.HasRSSI && last.RSSI == status.RSSI {
+		return
+	}
+	Events.Publish(Event{Type: EventDeviceStatus, SerialNumber: status.SerialNumber, Time: status.Time, Data: status})
+}
+
+// DeviceStatus return the last status received on the status topic of the device
+func DeviceStatus(serialNumber string) (DeviceStatusEvent, bool) {
+	deviceStatusLock.Lock()
+	defer deviceStatusLock.Unlock()
+	s, ok := deviceStatus[strings.ToUpper(serialNumber)]
+	return s, ok
+}
@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeviceStatus(t *testing.T) {
+	s, err := parseDeviceStatus("R331STATUS", []byte(`{"id":1,"params":{"status":1,"wifiRssi":-61}}`))
+	if assert.NoError(t, err) {
+		assert.True(t, s.Online)
+		assert.True(t, s.HasRSSI)
+		assert.Equal(t, -61, s.RSSI)
+	}
+	s, err = parseDeviceStatus("R331STATUS", []byte(`{"status":"offline"}`))
+	if assert.NoError(t, err) {
+		assert.False(t, s.Online)
+		assert.False(t, s.HasRSSI)
+	}
+	_, err = parseDeviceStatus("R331STATUS", []byte(`{"params":{"status":"unknown"}}`))
+	assert.Error(t, err)
+	_, err = parseDeviceStatus("R331STATUS", []byte(`{"params":{}}`))
+	assert.Error(t, err)
+}
+
+func TestStatusMessageHandler(t *testing.T) {
+	var events []DeviceStatusEvent
+	unsubscribe := Events.Subscribe(func(e Event) { events = append(events, e.Data.(DeviceStatusEvent)) }, EventDeviceStatus)
+	defer unsubscribe()
+	topic := statusTopic("HW51STATUS")
+	MessageHandler(nil, &benchMessage{topic: topic, payload: []byte(`{"params":{"status":1,"rssi":-70}}`)})
+	MessageHandler(nil, &benchMessage{topic: topic, payload: []byte(`{"params":{"status":1,"rssi":-70}}`)})
+	MessageHandler(nil, &benchMessage{topic: topic, payload: []byte(`{"params":{"status":0}}`)})
+	if assert.Len(t, events, 2) {
+		assert.True(t, events[0].Online)
+		assert.Equal(t, -70, events[0].RSSI)
+		assert.False(t, events[1].Online)
+	}
+	s, ok := DeviceStatus("hw51status")
+	assert.True(t, ok)
+	assert.False(t, s.Online)
+	_, ok = HeartbeatStats("HW51STATUS")
+	assert.False(t, ok)
+}
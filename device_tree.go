@@ -0,0 +1,279 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Channel indexed channel of a module, e.g. a circuit of the Smart Home Panel
+type Channel struct {
+	Index  int                    `json:"index"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// Module sub module of a device identified by the module serial number or the
+// quota group name
+type Module struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type,omitempty"`
+	Addr     int                    `json:"addr,omitempty"`
+	Values   map[string]interface{} `json:"values"`
+	Channels map[int]*Channel       `json:"channels,omitempty"`
+}
+
+// DeviceTree parent device with its modules and channels
+type DeviceTree struct {
+	mu           sync.RWMutex
+	SerialNumber string             `json:"serialNumber"`
+	Modules      map[string]*Module `json:"modules"`
+}
+
+var (
+	// moduleSnPattern module serial numbers used as quota key segment
+	moduleSnPattern = regexp.MustCompile(`^[A-Z0-9]{12,}$`)
+	channelPattern  = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+)
+
+// NewDeviceTree create empty device tree
+func NewDeviceTree(serialNumber string) *DeviceTree {
+	return &DeviceTree{SerialNumber: serialNumber, Modules: make(map[string]*Module)}
+}
+
+func (t *DeviceTree) module(id, moduleType string) *Module {
+	m, ok := t.Modules[id]
+	if !ok {
+		m = &Module{ID: id, Type: moduleType, Values: make(map[string]interface{})}
+		t.Modules[id] = m
+	}
+	if m.Type == "" {
+		m.Type = moduleType
+	}
+	return m
+}
+
+func (m *Module) channel(index int) *Channel {
+	if m.Channels == nil {
+		m.Channels = make(map[int]*Channel)
+	}
+	c, ok := m.Channels[index]
+	if !ok {
+		c = &Channel{Index: index, Values: make(map[string]interface{})}
+		m.Channels[index] = c
+	}
+	return c
+}
+
+// isModuleSn check if key segment is a module serial number, serial numbers
+// contain letters and digits
+func isModuleSn(segment string) bool {
+	return moduleSnPattern.MatchString(segment) && strings.ContainsAny(segment, "0123456789") &&
+		strings.IndexFunc(segment, func(r rune) bool { return r >= 'A' && r <= 'Z' }) >= 0
+}
+
+// AddQuota add flat dotted quota keys. A key segment containing a serial number
+// selects the module, an index like info[3] selects the channel, otherwise the
+// first segment is used as module.
+func (t *DeviceTree) AddQuota(quota map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, v := range quota {
+		t.addKey(k, v)
+	}
+}
+
+func (t *DeviceTree) addKey(key string, value interface{}) {
+	segments := strings.Split(key, ".")
+	moduleID := segments[0]
+	moduleType := ""
+	rest := segments[1:]
+	for i, s := range segments {
+		if isModuleSn(s) {
+			moduleID = s
+			moduleType = strings.Join(segments[:i], ".")
+			rest = segments[i+1:]
+			break
+		}
+	}
+	if len(rest) == 0 {
+		// value of the root device
+		rest = []string{moduleID}
+		moduleID = ""
+	}
+	if match := channelPattern.FindStringSubmatch(moduleID); match != nil {
+		moduleID = match[1]
+		rest = append([]string{"[" + match[2] + "]"}, rest...)
+	}
+	m := t.module(moduleID, moduleType)
+	for i, s := range rest {
+		if match := channelPattern.FindStringSubmatch(s); match != nil {
+			index, _ := strconv.Atoi(match[2])
+			channelKey := strings.Join(rest[i+1:], ".")
+			if channelKey == "" {
+				channelKey = match[1]
+			}
+			m.channel(index).Values[channelKey] = value
+			return
+		}
+	}
+	m.Values[strings.Join(rest, ".")] = value
+}
+
+// AddMessage add MQTT JSON message, messages with moduleSn and addr fields are
+// assigned to the module, the params are flattened into the module values
+func (t *DeviceTree) AddMessage(message map[string]interface{}) {
+	moduleSn, _ := message["moduleSn"].(string)
+	params, ok := message["params"].(map[string]interface{})
+	if !ok {
+		params = message
+	}
+	if moduleSn == "" {
+		quota := make(map[string]interface{})
+		flattenMap("", params, quota)
+		t.AddQuota(quota)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := t.module(moduleSn, "")
+	if addr, ok := message["addr"].(float64); ok {
+		m.Addr = int(addr)
+	}
+	if moduleType, ok := message["moduleType"]; ok && m.Type == "" {
+		m.Type = fmt.Sprint(moduleType)
+	}
+	quota := make(map[string]interface{})
+	flattenMap("", params, quota)
+	for k, v := range quota {
+		t.addKey(moduleSn+"."+k, v)
+	}
+}
+
+// flattenMap flatten nested maps and slices into dotted keys with indexes
+func flattenMap(prefix string, value interface{}, result map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flattenMap(key, e, result)
+		}
+	case []interface{}:
+		for i, e := range v {
+			flattenMap(fmt.Sprintf("%s[%d]", prefix, i), e, result)
+		}
+	default:
+		result[prefix] = v
+	}
+}
+
+// ModuleIDs return sorted module ids, the root device values use the empty id
+func (t *DeviceTree) ModuleIDs() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]string, 0, len(t.Modules))
+	for id := range t.Modules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Module return module with the id
+func (t *DeviceTree) Module(id string) (*Module, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	m, ok := t.Modules[id]
+	return m, ok
+}
+
+// ModuleByAddr return module with the MQTT address
+func (t *DeviceTree) ModuleByAddr(addr int) (*Module, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, m := range t.Modules {
+		if m.Addr == addr && addr != 0 {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// ChannelIndexes return sorted channel indexes of the module
+func (m *Module) ChannelIndexes() []int {
+	indexes := make([]int, 0, len(m.Channels))
+	for i := range m.Channels {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Walk call fn for all values in module, channel and key order. The channel is
+// -1 for module values.
+func (t *DeviceTree) Walk(fn func(module *Module, channel int, key string, value interface{})) {
+	type entry struct {
+		module  *Module
+		channel int
+		key     string
+		value   interface{}
+	}
+	entries := make([]entry, 0)
+	for _, id := range t.ModuleIDs() {
+		m, _ := t.Module(id)
+		t.mu.RLock()
+		for _, k := range sortedKeys(m.Values) {
+			entries = append(entries, entry{m, -1, k, m.Values[k]})
+		}
+		for _, i := range m.ChannelIndexes() {
+			c := m.Channels[i]
+			for _, k := range sortedKeys(c.Values) {
+				entries = append(entries, entry{m, i, k, c.Values[k]})
+			}
+		}
+		t.mu.RUnlock()
+	}
+	for _, e := range entries {
+		fn(e.module, e.channel, e.key, e.value)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var treeLock sync.Mutex
+var deviceTrees = make(map[string]*DeviceTree)
+
+// Tree return device tree built from the received MQTT messages of the device
+func Tree(serialNumber string) *DeviceTree {
+	treeLock.Lock()
+	defer treeLock.Unlock()
+	t, ok := deviceTrees[serialNumber]
+	if !ok {
+		t = NewDeviceTree(serialNumber)
+		deviceTrees[serialNumber] = t
+	}
+	return t
+}
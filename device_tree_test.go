@@ -0,0 +1,50 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceTree(t *testing.T) {
+	tree := NewDeviceTree("SP10TEST")
+	tree.AddQuota(map[string]interface{}{
+		"pd.soc":                     80.0,
+		"loadChInfo.info[3].chName":  "Kitchen",
+		"loadChInfo.info[3].setAmp":  16.0,
+		"bp5000.M106ZAB4Z000001.soc": 55.0,
+		"online":                     1.0,
+	})
+	tree.AddMessage(map[string]interface{}{"addr": 3.0, "moduleSn": "M106ZAB4Z000001",
+		"params": map[string]interface{}{"temp": 21.0, "cells": []interface{}{3.31, 3.32}}})
+
+	assert.Equal(t, []string{"", "M106ZAB4Z000001", "loadChInfo", "pd"}, tree.ModuleIDs())
+	root, _ := tree.Module("")
+	assert.Equal(t, 1.0, root.Values["online"])
+
+	bp, ok := tree.ModuleByAddr(3)
+	assert.True(t, ok)
+	assert.Equal(t, "bp5000", bp.Type)
+	assert.Equal(t, 55.0, bp.Values["soc"])
+	assert.Equal(t, 21.0, bp.Values["temp"])
+	assert.Equal(t, []int{0, 1}, bp.ChannelIndexes())
+	assert.Equal(t, 3.32, bp.Channels[1].Values["cells"])
+
+	load, _ := tree.Module("loadChInfo")
+	assert.Equal(t, "Kitchen", load.Channels[3].Values["chName"])
+
+	count := 0
+	tree.Walk(func(m *Module, channel int, key string, value interface{}) { count++ })
+	assert.Equal(t, 8, count)
+}
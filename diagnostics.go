@@ -0,0 +1,101 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/tknie/ecoflow/rest"
+)
+
+// CodeSignatureInvalid response code of requests with wrong signature
+const CodeSignatureInvalid = rest.CodeSignatureInvalid
+
+// Internal counters published with expvar under the name "ecoflow"
+var (
+	metricRequests       = rest.Requests
+	metricRequestErrors  = rest.RequestErrors
+	metricRetries        = new(expvar.Int)
+	metricSignFailures   = rest.SignFailures
+	metricMqttReconnects = new(expvar.Int)
+	metricMqttMessages   = new(expvar.Int)
+	metricHandlerPanics  = new(expvar.Int)
+	// MQTT session resumption counters
+	metricResumptions      = new(expvar.Int)
+	metricMissedHeartbeats = new(expvar.Int)
+	metricBackfills        = new(expvar.Int)
+	// sequence number checks of received frames
+	metricMqttDuplicates = new(expvar.Int)
+	metricMqttOutOfOrder = new(expvar.Int)
+)
+
+var startTime = now()
+
+func init() {
+	m := expvar.NewMap("ecoflow")
+	m.Set("httpRequests", metricRequests)
+	m.Set("httpRequestErrors", metricRequestErrors)
+	m.Set("retries", metricRetries)
+	m.Set("signFailures", metricSignFailures)
+	m.Set("mqttReconnects", metricMqttReconnects)
+	m.Set("mqttMessages", metricMqttMessages)
+	m.Set("handlerPanics", metricHandlerPanics)
+	m.Set("mqttResumptions", metricResumptions)
+	m.Set("mqttMissedHeartbeats", metricMissedHeartbeats)
+	m.Set("backfills", metricBackfills)
+	m.Set("mqttDuplicates", metricMqttDuplicates)
+	m.Set("mqttOutOfOrder", metricMqttOutOfOrder)
+}
+
+// DiagnosticsInfo snapshot of the internal counters
+type DiagnosticsInfo struct {
+	Uptime            time.Duration `json:"uptime"`
+	HTTPRequests      int64         `json:"httpRequests"`
+	HTTPRequestErrors int64         `json:"httpRequestErrors"`
+	Retries           int64         `json:"retries"`
+	SignFailures      int64         `json:"signFailures"`
+	MqttReconnects    int64         `json:"mqttReconnects"`
+	MqttMessages      int64         `json:"mqttMessages"`
+	HandlerPanics     int64         `json:"handlerPanics"`
+	Resumptions       int64         `json:"mqttResumptions"`
+	MissedHeartbeats  int64         `json:"mqttMissedHeartbeats"`
+	Backfills         int64         `json:"backfills"`
+	MqttDuplicates    int64         `json:"mqttDuplicates"`
+	MqttOutOfOrder    int64         `json:"mqttOutOfOrder"`
+	MqttConnected     bool          `json:"mqttConnected"`
+}
+
+// Diagnostics return the internal counters
+func Diagnostics() DiagnosticsInfo {
+	return DiagnosticsInfo{
+		Uptime:            since(startTime),
+		HTTPRequests:      metricRequests.Value(),
+		HTTPRequestErrors: metricRequestErrors.Value(),
+		Retries:           metricRetries.Value(),
+		SignFailures:      metricSignFailures.Value(),
+		MqttReconnects:    metricMqttReconnects.Value(),
+		MqttMessages:      metricMqttMessages.Value(),
+		HandlerPanics:     metricHandlerPanics.Value(),
+		Resumptions:       metricResumptions.Value(),
+		MissedHeartbeats:  metricMissedHeartbeats.Value(),
+		Backfills:         metricBackfills.Value(),
+		MqttDuplicates:    metricMqttDuplicates.Value(),
+		MqttOutOfOrder:    metricMqttOutOfOrder.Value(),
+		MqttConnected:     ecoclient != nil && ecoclient.Client.IsConnected(),
+	}
+}
+
+// observeCode count API responses with signature errors
+func observeCode(code Code) {
+	rest.ObserveCode(code)
+}
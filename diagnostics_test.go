@@ -0,0 +1,52 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostics(t *testing.T) {
+	before := Diagnostics()
+	policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	_ = policy.Retry(context.Background(), "diagnostics", func(context.Context) error { return errors.New("offline") })
+	observeCode(CodeSignatureInvalid)
+	observeCode(CodeSuccess)
+	OnReconnect(nil, nil)
+	after := Diagnostics()
+	assert.Equal(t, before.Retries+1, after.Retries)
+	assert.Equal(t, before.SignFailures+1, after.SignFailures)
+	assert.Equal(t, before.MqttReconnects+1, after.MqttReconnects)
+
+	s := NewStatusServer(":0")
+	recorder := httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	var vars map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &vars))
+	var counters map[string]int64
+	assert.NoError(t, json.Unmarshal(vars["ecoflow"], &counters))
+	assert.Equal(t, after.Retries, counters["retries"])
+
+	recorder = httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/diagnostics", nil))
+	var info DiagnosticsInfo
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &info))
+	assert.Equal(t, after.SignFailures, info.SignFailures)
+}
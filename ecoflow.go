@@ -14,10 +14,10 @@ package ecoflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
-	"time"
 
 	"github.com/tknie/services"
 )
@@ -32,7 +32,8 @@ type deviceInfo struct {
 // RefreshDeviceList refresh device list using HTTP device list request
 func (client *Client) RefreshDeviceList() {
 	//get all linked ecoflow devices. Returns SN and online status
-	list, err := client.GetDeviceList(context.Background())
+	client.DeviceCache().Invalidate()
+	list, err := client.CachedDeviceList(context.Background())
 	if err != nil {
 		services.ServerMessage("Ecoflow: Error getting device list: %v", err)
 	} else {
@@ -47,33 +48,7 @@ func (client *Client) GetDevices() *DeviceListResponse {
 
 // SetEnvironmentPowerConsumption set new environment consumption value
 func (client *Client) SetEnvironmentPowerConsumption(converter string, value float64) {
-
-	params := make(map[string]interface{})
-	// Ecoflow need to set a value times by 10
-	// e.g. 200 watt needs value 2000
-	params["permanentWatts"] = value * 10
-	cmdReq := CmdSetRequest{
-		Id:      fmt.Sprint(time.Now().UnixMilli()),
-		CmdCode: "WN511_SET_PERMANENT_WATTS_PACK",
-		Sn:      converter,
-		Params:  params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		services.ServerMessage("Ecoflow: Error marshal data: %v", err)
-		return
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		services.ServerMessage("Ecoflow: Error unmarshal data: %v", err)
-		return
-	}
-	cmd, err := client.SetDeviceParameter(context.Background(), req)
-
+	cmd, err := client.SetPermanentWatts(context.Background(), converter, value)
 	if err != nil {
 		services.ServerMessage("Ecoflow: Error set device parameter: %v", err)
 	} else {
@@ -81,56 +56,136 @@ func (client *Client) SetEnvironmentPowerConsumption(converter string, value flo
 	}
 }
 
-func (client *Client) sendEnable(d *deviceInfo) (*CmdSetResponse, error) {
+func (client *Client) sendEnable(ctx context.Context, d *deviceInfo) (*CmdSetResponse, error) {
 	params := make(map[string]interface{}, 1)
 	if d.turnOn {
 		params["enabled"] = 1
 	} else {
 		params["enabled"] = 0
 	}
-	cmdReq := &CmdSetRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
+	cmdReq := CmdSetRequest{
 		Sn:          strings.ToUpper(d.serialNumber),
 		ModuleType:  d.moduleType,
 		OperateType: d.operateType,
 		Params:      params,
 	}
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		services.ServerMessage("Ecoflow: Error marshal data: %v", err)
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		services.ServerMessage("Ecoflow: Error unmarshal data: %v", err)
-		return nil, err
-	}
-
-	return client.SetDeviceParameter(context.Background(), req)
+	return client.SetCommand(ctx, cmdReq)
 }
 
 func (client *Client) SetCarACOn(serialNumber string, turnOn bool) (*CmdSetResponse, error) {
-	return client.sendEnable(&deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
+	return client.sendEnable(context.Background(), &deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
 		moduleType: 5, operateType: "mpptCar"})
 }
 
 func (client *Client) SetACOn(serialNumber string, turnOn bool) (*CmdSetResponse, error) {
-	return client.sendEnable(&deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
+	return client.sendEnable(context.Background(), &deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
 		moduleType: 1, operateType: "newAcAutoOnCfg"})
 }
 
 func (client *Client) SetUSBOn(serialNumber string, turnOn bool) (*CmdSetResponse, error) {
-	return client.sendEnable(&deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
-		moduleType: 1, operateType: "dcOutCfg"})
+	return client.setUSBOn(context.Background(), serialNumber, turnOn)
+}
+
+// setUSBOn switch the USB outputs using the PD module (module type 1)
+func (client *Client) setUSBOn(ctx context.Context, serialNumber string, turnOn bool) (*CmdSetResponse, error) {
+	return client.sendEnable(ctx, &deviceInfo{serialNumber: serialNumber, turnOn: turnOn,
+		moduleType: ModuleTypePd, operateType: "dcOutCfg"})
+}
+
+// SetCommand send a typed command request to the device. The request is normalized
+// into the generic parameter map, so nested parameters are signed the same way
+// they are sent in the request body. Commands to the same device are serialized.
+func (c *Client) SetCommand(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	return c.SetCommandWithPriority(ctx, PriorityNormal, req)
+}
+
+func (c *Client) sendCommand(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	if req.Id == "" {
+		req.Id = fmt.Sprint(now().UnixMilli())
+	}
+	if PauseDuringFirmwareUpdate && FirmwareUpdating(req.Sn) {
+		err := fmt.Errorf("%w: %s", ErrFirmwareUpdating, req.Sn)
+		recordCommand(AuditEntry{ID: req.Id, Time: now(), Actor: ActorFromContext(ctx),
+			SerialNumber: req.Sn, Request: req, Error: err.Error()})
+		return nil, err
+	}
+	if err := DeviceCompliance.Check(&req); err != nil {
+		recordCommand(AuditEntry{ID: req.Id, Time: now(), Actor: ActorFromContext(ctx),
+			SerialNumber: req.Sn, Request: req, Error: err.Error()})
+		return nil, err
+	}
+	entry := AuditEntry{ID: req.Id, Time: now(), Actor: ActorFromContext(ctx),
+		SerialNumber: req.Sn, Request: req}
+	response, err := c.sendRequest(ctx, req)
+	if response != nil {
+		response.Id = req.Id
+	}
+	entry.Duration = since(entry.Time)
+	entry.Response = response
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	recordCommand(entry)
+	return response, err
 }
 
+// recordCommand add the command to the audit log and publish its lifecycle event
+func recordCommand(entry AuditEntry) {
+	Audit.Record(entry)
+	publishCommandEntry(entry)
+}
+
+func (c *Client) sendRequest(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	return c.Router().SetParam(ctx, req)
+}
+
+// sendHTTP send request using the HTTP API
+func (c *Client) sendHTTP(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	request, err := req.parameters()
+	if err != nil {
+		services.ServerMessage("Ecoflow: Error marshal data: %v", err)
+		return nil, err
+	}
+	return c.setDeviceParameter(ctx, request)
+}
+
+// parameters convert the request into the generic map used for signing and body
+func (req CmdSetRequest) parameters() (map[string]interface{}, error) {
+	if req.Id == "" {
+		req.Id = fmt.Sprint(now().UnixMilli())
+	}
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var request map[string]interface{}
+	err = json.Unmarshal(jsonData, &request)
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// SetDeviceParameter send a generic parameter map to the device
+//
+// Deprecated: use SetCommand with a typed CmdSetRequest instead.
 func (c *Client) SetDeviceParameter(ctx context.Context, request map[string]interface{}) (*CmdSetResponse, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var req CmdSetRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameter request: %w", err)
+	}
+	return c.SetCommand(ctx, req)
+}
+
+func (c *Client) setDeviceParameter(ctx context.Context, request map[string]interface{}) (*CmdSetResponse, error) {
 	slog.Debug("SetDeviceParameter", "request", request)
 
-	r := NewHttpRequest(c.httpClient, "PUT", ecoflowAPI+setDeviceFunctionPath, request, c.accessToken, c.secretToken)
+	r := c.newRequest("PUT", ecoflowAPI+setDeviceFunctionPath, request)
 
 	response, err := r.Execute(ctx)
 	if err != nil {
@@ -144,6 +199,12 @@ func (c *Client) SetDeviceParameter(ctx context.Context, request map[string]inte
 	if err != nil {
 		return nil, err
 	}
-
+	if cmdResponse == nil {
+		return nil, errors.New("response is not valid, can't process it")
+	}
+	observeCode(cmdResponse.Code)
+	if !cmdResponse.Code.OK() {
+		return cmdResponse, &APIError{Code: cmdResponse.Code, Message: cmdResponse.Message}
+	}
 	return cmdResponse, nil
 }
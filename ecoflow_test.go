@@ -12,10 +12,17 @@
 package ecoflow
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/log"
@@ -41,7 +48,7 @@ func TestClientAllDevices(t *testing.T) {
 	m, err := client.GetDeviceList(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, m)
-	assert.Equal(t, "0", m.Code)
+	assert.Equal(t, "0", m.Code.String())
 	assert.Equal(t, "Success", m.Message)
 	assert.Len(t, m.Devices, 3)
 	assert.Equal(t, sn, m.Devices[0].SN)
@@ -79,3 +86,122 @@ func TestClientMicroConverter(t *testing.T) {
 	assert.True(t, ok)
 	assert.NotNil(t, x)
 }
+
+func TestCmdSetRequestParameters(t *testing.T) {
+	cmdReq := CmdSetRequest{
+		Id:         "1",
+		Sn:         "R331TEST",
+		ModuleType: ModuleTypeMppt,
+		Params: map[string]interface{}{"enabled": 1,
+			"cfg": map[string]int{"watts": 200}},
+	}
+	params, err := cmdReq.parameters()
+	assert.NoError(t, err)
+	assert.Equal(t, "id=1&moduleType=5&params.cfg.watts=200&params.enabled=1&sn=R331TEST",
+		CanonicalQueryString(params))
+}
+
+// signedTransport record PUT bodies and verify the signature against the body text
+type signedTransport struct {
+	bodies   []string
+	verified bool
+}
+
+func (s *signedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	data, _ := io.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(data))
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var params map[string]interface{}
+	if err := decoder.Decode(&params); err == nil {
+		ts, _ := strconv.ParseInt(r.Header.Get("timestamp"), 10, 64)
+		signer := NewSigner("access", "secret")
+		signer.Now = func() time.Time { return time.Unix(0, ts) }
+		signer.Nonce = func() string { return r.Header.Get("nonce") }
+		s.verified = signer.Signature(params).Sign == r.Header.Get("sign")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header),
+		Body: io.NopCloser(strings.NewReader(`{"code":"0","message":"Success"}`))}, nil
+}
+
+func TestPermanentWattsNumberFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format *NumberFormat
+		watts  float64
+		want   string
+	}{
+		{nil, 200, `"permanentWatts":2000}`},
+		{&NumberFormat{Rules: map[string]NumberRule{"permanentWatts": NumberDecimal}}, 200, `"permanentWatts":2000.0}`},
+		{&NumberFormat{Default: NumberInteger}, 123.45, `"permanentWatts":1235}`},
+	} {
+		transport := &signedTransport{}
+		client := newTestClient(t, transport, WithNumberFormat(tc.format))
+		_, err := client.SetPermanentWatts(context.Background(), "HW51TEST", tc.watts)
+		assert.NoError(t, err)
+		if assert.Len(t, transport.bodies, 1) {
+			assert.Contains(t, transport.bodies[0], tc.want)
+			assert.Contains(t, transport.bodies[0], `"cmdCode":"WN511_SET_PERMANENT_WATTS_PACK"`)
+		}
+		assert.True(t, transport.verified, "signature of %s", tc.want)
+	}
+}
+
+func TestSetCommandErrorCode(t *testing.T) {
+	transport := newFakeAPI(nil)
+	transport.code = "1006"
+	client := newTestClient(t, transport)
+	var failed []Event
+	unsubscribe := Events.Subscribe(func(e Event) {
+		if e.SerialNumber == "R331CODE" {
+			failed = append(failed, e)
+		}
+	}, EventCommandFailed)
+	defer unsubscribe()
+
+	response, err := client.SetCommand(context.Background(), CmdSetRequest{Sn: "R331CODE", ModuleType: ModuleTypePd,
+		OperateType: "quietCfg", Params: map[string]interface{}{"enabled": 1}})
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, Code("1006"), apiErr.Code)
+		assert.Equal(t, "command failed", apiErr.Message)
+	}
+	assert.ErrorIs(t, err, ErrCommandRejected)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, Code("1006"), response.Code)
+	}
+	assert.Equal(t, 1, transport.puts())
+	entries := Audit.Query(AuditFilter{SerialNumber: "R331CODE"})
+	if assert.Len(t, entries, 1) {
+		assert.Contains(t, entries[0].Error, "error code 1006")
+	}
+	assert.Len(t, failed, 1)
+}
+
+func TestSetDeviceParameterCommandPath(t *testing.T) {
+	saved := DeviceCompliance
+	defer func() { DeviceCompliance = saved }()
+	DeviceCompliance = &Compliance{deviceLimits: make(map[string]float64)}
+	DeviceCompliance.SetLimit(600, ComplianceClamp)
+	transport := newFakeAPI(nil)
+	client := newTestClient(t, transport)
+
+	_, err := client.SetDeviceParameter(context.Background(), map[string]interface{}{"sn": "HW51PARAM",
+		"cmdCode": "WN511_SET_PERMANENT_WATTS_PACK", "params": map[string]interface{}{"permanentWatts": 9000.0}})
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 1) {
+		assert.Equal(t, 6000.0, transport.requests[0].Params["permanentWatts"])
+	}
+	assert.Len(t, Audit.Query(AuditFilter{SerialNumber: "HW51PARAM"}), 1)
+
+	observeFirmware("HW51PARAM", map[string]interface{}{"params": map[string]interface{}{
+		"pd": map[string]interface{}{"otaStatus": 1.0, "otaProgress": 10.0}}})
+	defer observeFirmware("HW51PARAM", map[string]interface{}{"params": map[string]interface{}{
+		"pd": map[string]interface{}{"otaStatus": 3.0, "otaProgress": 100.0}}})
+	_, err = client.SetDeviceParameter(context.Background(), map[string]interface{}{"sn": "HW51PARAM",
+		"params": map[string]interface{}{"permanentWatts": 100.0}})
+	assert.ErrorIs(t, err, ErrFirmwareUpdating)
+	assert.Len(t, transport.requests, 1)
+
+	_, err = client.SetDeviceParameter(context.Background(), map[string]interface{}{"sn": 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// ConsumerGrid energy fed into the grid by the inverter
+	ConsumerGrid = "grid"
+	// ConsumerPlugs energy delivered to smart plugs not attributed to a single plug
+	ConsumerPlugs = "plugs"
+)
+
+// defaultLedgerMaxGap maximum time between two power samples integrated into energy
+const defaultLedgerMaxGap = 15 * time.Minute
+
+// PowerPackLedger ledger fed by the PowerPack messages of all PowerStream inverters
+var PowerPackLedger = NewEnergyLedger(time.Local)
+
+// ConsumerEnergy energy delivered to one consumer of an inverter in Wh
+type ConsumerEnergy struct {
+	SerialNumber string             `json:"serialNumber"`
+	Consumer     string             `json:"consumer"`
+	Energy       float64            `json:"energy"`
+	Days         map[string]float64 `json:"days"`
+}
+
+// EnergyLedger attribute the inverter output power reported in the PowerPack
+// power streams to the downstream consumers. Plug power is split between the
+// smart plugs proportionally to their last reported power.
+type EnergyLedger struct {
+	mu       sync.Mutex
+	location *time.Location
+	// MaxGap samples further apart are not integrated
+	MaxGap  time.Duration
+	streams map[string]*ledgerStream
+}
+
+type ledgerStream struct {
+	lastTime  time.Time
+	plugs     map[string]float64
+	consumers map[string]map[string]float64
+}
+
+// NewEnergyLedger create ledger with daily totals evaluated in the given time zone
+func NewEnergyLedger(location *time.Location) *EnergyLedger {
+	if location == nil {
+		location = time.Local
+	}
+	return &EnergyLedger{location: location, MaxGap: defaultLedgerMaxGap,
+		streams: make(map[string]*ledgerStream)}
+}
+
+func (l *EnergyLedger) stream(serialNumber string) *ledgerStream {
+	s, ok := l.streams[serialNumber]
+	if !ok {
+		s = &ledgerStream{plugs: make(map[string]float64), consumers: make(map[string]map[string]float64)}
+		l.streams[serialNumber] = s
+	}
+	return s
+}
+
+// ObservePlug set the current power of a smart plug connected to the inverter,
+// a plug with zero watts stays registered but receives no energy
+func (l *EnergyLedger) ObservePlug(serialNumber, plugSerialNumber string, watts float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if watts < 0 {
+		watts = 0
+	}
+	l.stream(serialNumber).plugs[plugSerialNumber] = watts
+}
+
+// ObservePowerPack integrate the power stream entries of a PowerPack message
+func (l *EnergyLedger) ObservePowerPack(serialNumber string, pp *PowerPack) {
+	items := append([]*PowerItem{}, pp.GetSysPowerStream()...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].GetTimestamp() < items[j].GetTimestamp() })
+	for _, item := range items {
+		timestamp := now()
+		if item.GetTimestamp() > 0 {
+			timestamp = time.Unix(int64(item.GetTimestamp()), 0)
+		}
+		// power values are reported in 0.1 W
+		l.Observe(serialNumber, timestamp, float64(item.GetInvToGridPower())/10,
+			float64(item.GetInvToPlugPower())/10)
+	}
+}
+
+// Observe add a power sample of the inverter, the power of the previous interval
+// is assumed to be the power of this sample
+func (l *EnergyLedger) Observe(serialNumber string, timestamp time.Time, gridWatts, plugWatts float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stream(serialNumber)
+	last := s.lastTime
+	if !timestamp.After(last) {
+		// first or out of order sample
+		if last.IsZero() {
+			s.lastTime = timestamp
+		}
+		return
+	}
+	s.lastTime = timestamp
+	elapsed := timestamp.Sub(last)
+	if elapsed > l.MaxGap {
+		return
+	}
+	hours := elapsed.Hours()
+	day := timestamp.In(l.location).Format(dayLayout)
+	s.add(ConsumerGrid, day, gridWatts*hours)
+	total := 0.0
+	for _, w := range s.plugs {
+		total += w
+	}
+	if total <= 0 {
+		s.add(ConsumerPlugs, day, plugWatts*hours)
+		return
+	}
+	for plug, w := range s.plugs {
+		s.add(plug, day, plugWatts*hours*w/total)
+	}
+}
+
+func (s *ledgerStream) add(consumer, day string, energy float64) {
+	if energy <= 0 {
+		return
+	}
+	days, ok := s.consumers[consumer]
+	if !ok {
+		days = make(map[string]float64)
+		s.consumers[consumer] = days
+	}
+	days[day] += energy
+}
+
+// Report return the consumption of all consumers of the inverter sorted by consumer
+func (l *EnergyLedger) Report(serialNumber string) []ConsumerEnergy {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.streams[serialNumber]
+	if !ok {
+		return nil
+	}
+	report := make([]ConsumerEnergy, 0, len(s.consumers))
+	for consumer, days := range s.consumers {
+		c := ConsumerEnergy{SerialNumber: serialNumber, Consumer: consumer, Days: make(map[string]float64)}
+		for day, e := range days {
+			c.Days[day] = e
+			c.Energy += e
+		}
+		report = append(report, c)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Consumer < report[j].Consumer })
+	return report
+}
+
+// DailyReport return the energy per consumer of the local day containing the given time
+func (l *EnergyLedger) DailyReport(serialNumber string, day time.Time) map[string]float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make(map[string]float64)
+	if s, ok := l.streams[serialNumber]; ok {
+		key := day.In(l.location).Format(dayLayout)
+		for consumer, days := range s.consumers {
+			if e, ok := days[key]; ok {
+				result[consumer] = e
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEnergyLedger(t *testing.T) {
+	l := NewEnergyLedger(time.UTC)
+	sn := "HW51LEDGER"
+	base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	item := func(offset time.Duration, grid, plug uint32) *PowerItem {
+		return &PowerItem{Timestamp: proto.Uint32(uint32(base.Add(offset).Unix())),
+			InvToGridPower: proto.Uint32(grid), InvToPlugPower: proto.Uint32(plug)}
+	}
+	// out of order entries are sorted, the first entry starts the integration
+	l.ObservePowerPack(sn, &PowerPack{SysPowerStream: []*PowerItem{
+		item(10*time.Minute, 1200, 600), item(0, 0, 0), item(5*time.Minute, 1200, 600)}})
+	day := l.DailyReport(sn, base)
+	assert.InDelta(t, 20.0, day[ConsumerGrid], 0.001)
+	assert.InDelta(t, 10.0, day[ConsumerPlugs], 0.001)
+
+	l.ObservePlug(sn, "HW52FRIDGE", 90)
+	l.ObservePlug(sn, "HW52WASHER", 30)
+	l.Observe(sn, base.Add(25*time.Minute), 0, 120)
+	report := l.Report(sn)
+	if assert.Len(t, report, 4) {
+		assert.Equal(t, "HW52FRIDGE", report[0].Consumer)
+		assert.InDelta(t, 22.5, report[0].Energy, 0.001)
+		assert.Equal(t, "HW52WASHER", report[1].Consumer)
+		assert.InDelta(t, 7.5, report[1].Energy, 0.001)
+		assert.Equal(t, ConsumerGrid, report[2].Consumer)
+	}
+
+	// gaps longer than MaxGap are not integrated
+	l.Observe(sn, base.Add(2*time.Hour), 1000, 0)
+	assert.InDelta(t, 20.0, l.DailyReport(sn, base)[ConsumerGrid], 0.001)
+	assert.Nil(t, l.Report("HW51UNKNOWN"))
+}
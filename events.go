@@ -0,0 +1,103 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventType type of an event
+type EventType string
+
+// Event event published on the event bus
+type Event struct {
+	Type         EventType   `json:"type"`
+	SerialNumber string      `json:"serialNumber,omitempty"`
+	Time         time.Time   `json:"time"`
+	Data         interface{} `json:"data,omitempty"`
+}
+
+// EventHandler handler called for published events
+type EventHandler func(event Event)
+
+type eventSubscription struct {
+	handler EventHandler
+	types   map[EventType]bool
+}
+
+// EventBus synchronous publish/subscribe of events
+type EventBus struct {
+	mu            sync.RWMutex
+	nextID        uint64
+	subscriptions map[uint64]*eventSubscription
+}
+
+// Events global event bus
+var Events = NewEventBus()
+
+// NewEventBus create new event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subscriptions: make(map[uint64]*eventSubscription)}
+}
+
+// Subscribe register handler for the given event types, all events are
+// delivered if no type is given. The returned function unsubscribes.
+func (b *EventBus) Subscribe(handler EventHandler, types ...EventType) func() {
+	s := &eventSubscription{handler: handler}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool)
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subscriptions[id] = s
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscriptions, id)
+	}
+}
+
+// Publish deliver event to all subscribed handlers
+func (b *EventBus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = now()
+	}
+	b.mu.RLock()
+	handlers := make([]EventHandler, 0, len(b.subscriptions))
+	for _, s := range b.subscriptions {
+		if s.types == nil || s.types[event.Type] {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		callEventHandler(h, event)
+	}
+}
+
+func callEventHandler(h EventHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			metricHandlerPanics.Add(1)
+			log.Log.Errorf("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+	h(event)
+}
@@ -0,0 +1,39 @@
+// mqtt-stream connects to the Ecoflow MQTT broker using the app login and
+// prints the normalized telemetry of all devices until interrupted.
+//
+// Environment: ECOFLOW_USER and ECOFLOW_PASSWORD, optional ECOFLOW_ACCESS_KEY
+// and ECOFLOW_SECRET_KEY to subscribe all devices of the account.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tknie/ecoflow"
+	"github.com/tknie/ecoflow/telemetry"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	system, err := ecoflow.Bootstrap(ctx, ecoflow.BootstrapConfig{
+		Handlers: []telemetry.Handler{func(points []*telemetry.Point) {
+			for _, p := range points {
+				fmt.Printf("%s %s %s = %v\n", p.Timestamp.Format("15:04:05"),
+					ecoflow.DeviceAlias(p.SerialNumber), p.Key, p.Value)
+			}
+		}},
+	})
+	if err != nil {
+		fmt.Println("Bootstrap error:", err)
+		os.Exit(1)
+	}
+	<-ctx.Done()
+	if err := system.Stop(context.Background()); err != nil {
+		fmt.Println("Stop error:", err)
+	}
+}
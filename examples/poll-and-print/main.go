@@ -0,0 +1,69 @@
+// poll-and-print polls the quota of all devices of the account using the
+// signed REST API and prints them.
+//
+// Environment: ECOFLOW_ACCESS_KEY, ECOFLOW_SECRET_KEY and optional
+// ECOFLOW_POLL_INTERVAL (e.g. 30s, one poll if not set).
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/tknie/ecoflow/client"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	interval := time.Duration(0)
+	if v := os.Getenv("ECOFLOW_POLL_INTERVAL"); v != "" {
+		var err error
+		if interval, err = time.ParseDuration(v); err != nil {
+			fmt.Println("Invalid ECOFLOW_POLL_INTERVAL:", err)
+			os.Exit(1)
+		}
+	}
+	c := client.FromEnv(client.WithTimeout(30 * time.Second))
+	for {
+		if err := poll(ctx, c); err != nil {
+			fmt.Println("Poll error:", err)
+		}
+		if interval == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func poll(ctx context.Context, c *client.Client) error {
+	list, err := c.GetDeviceList(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range list.Devices {
+		fmt.Printf("%s online=%d\n", d.SN, d.Online)
+		quota, err := client.Quota(ctx, c, d.SN)
+		if err != nil {
+			fmt.Println("  quota error:", err)
+			continue
+		}
+		keys := make([]string, 0, len(quota))
+		for k := range quota {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s = %v\n", k, quota[k])
+		}
+	}
+	return nil
+}
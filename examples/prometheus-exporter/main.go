@@ -0,0 +1,78 @@
+// prometheus-exporter serves the latest numeric telemetry values of all devices
+// in the Prometheus text format on /metrics.
+//
+// Environment: ECOFLOW_USER and ECOFLOW_PASSWORD and/or ECOFLOW_ACCESS_KEY and
+// ECOFLOW_SECRET_KEY, optional ECOFLOW_EXPORTER_ADDRESS (default :9120).
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/tknie/ecoflow"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	address := os.Getenv("ECOFLOW_EXPORTER_ADDRESS")
+	if address == "" {
+		address = ":9120"
+	}
+	system, err := ecoflow.Bootstrap(ctx, ecoflow.BootstrapConfig{})
+	if err != nil {
+		fmt.Println("Bootstrap error:", err)
+		os.Exit(1)
+	}
+	defer system.Stop(context.Background())
+
+	server := &http.Server{Addr: address, Handler: http.HandlerFunc(metrics)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Println("Exporter error:", err)
+	}
+}
+
+func metrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP ecoflow_value Latest telemetry value of the device")
+	fmt.Fprintln(w, "# TYPE ecoflow_value gauge")
+	for _, sn := range ecoflow.States.Devices() {
+		snapshot := ecoflow.States.Snapshot(sn)
+		keys := make([]string, 0, len(snapshot))
+		for k := range snapshot {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var value float64
+			switch v := snapshot[k].Value.(type) {
+			case float64:
+				value = v
+			case bool:
+				if v {
+					value = 1
+				}
+			default:
+				continue
+			}
+			fmt.Fprintf(w, "ecoflow_value{sn=%q,alias=%q,key=%q} %g\n", sn, ecoflow.DeviceAlias(sn), k, value)
+		}
+	}
+	fmt.Fprintln(w, "# HELP ecoflow_last_seen_seconds Unix time of the last data of the device")
+	fmt.Fprintln(w, "# TYPE ecoflow_last_seen_seconds gauge")
+	for _, sn := range ecoflow.States.Devices() {
+		if t, source := ecoflow.LastSeen(sn); !t.IsZero() {
+			fmt.Fprintf(w, "ecoflow_last_seen_seconds{sn=%q,source=%q} %d\n", sn, source, t.Unix())
+		}
+	}
+}
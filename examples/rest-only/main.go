@@ -0,0 +1,55 @@
+// rest-only polls the quota of all devices using only the rest package. The
+// binary does not contain MQTT, protobuf or uuid and fits into small
+// containers and constrained gateways.
+//
+// Environment: ECOFLOW_ACCESS_KEY, ECOFLOW_SECRET_KEY and optional
+// ECOFLOW_QUOTA_KEYS, a comma separated list of quota keys to read.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tknie/ecoflow/rest"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	c := rest.ClientFromEnv()
+	devices, err := c.Devices(ctx)
+	if err != nil {
+		fmt.Println("Device list error:", err)
+		os.Exit(1)
+	}
+	var keys []string
+	if v := os.Getenv("ECOFLOW_QUOTA_KEYS"); v != "" {
+		keys = strings.Split(v, ",")
+	}
+	for _, d := range devices {
+		fmt.Printf("%s online=%d\n", d.SN, d.Online)
+		var quota map[string]interface{}
+		if len(keys) > 0 {
+			quota, err = c.SelectedQuota(ctx, d.SN, keys...)
+		} else {
+			quota, err = c.Quota(ctx, d.SN)
+		}
+		if err != nil {
+			fmt.Println("  quota error:", err)
+			continue
+		}
+		names := make([]string, 0, len(quota))
+		for k := range quota {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			fmt.Printf("  %s = %v\n", k, quota[k])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// zero-export-controller adjusts the output of PowerStream inverters so that no
+// energy is fed into the grid. The grid power is read from a meter device
+// reporting the import as positive and the export as negative value.
+//
+// Environment: ECOFLOW_USER, ECOFLOW_PASSWORD, ECOFLOW_ACCESS_KEY,
+// ECOFLOW_SECRET_KEY, ECOFLOW_INVERTERS (comma separated serial numbers),
+// ECOFLOW_METER_SN and ECOFLOW_METER_KEY (telemetry key of the grid power).
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tknie/ecoflow"
+)
+
+// margin import kept to avoid feeding in on load changes
+const margin = 30.0
+
+// maxAge meter values older than this are not used
+const maxAge = 2 * time.Minute
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	inverters := strings.Split(os.Getenv("ECOFLOW_INVERTERS"), ",")
+	meter := os.Getenv("ECOFLOW_METER_SN")
+	meterKey := os.Getenv("ECOFLOW_METER_KEY")
+	if inverters[0] == "" || meter == "" || meterKey == "" {
+		fmt.Println("ECOFLOW_INVERTERS, ECOFLOW_METER_SN and ECOFLOW_METER_KEY are required")
+		os.Exit(1)
+	}
+
+	system, err := ecoflow.Bootstrap(ctx, ecoflow.BootstrapConfig{})
+	if err != nil {
+		fmt.Println("Bootstrap error:", err)
+		os.Exit(1)
+	}
+	defer system.Stop(context.Background())
+	if system.Client == nil {
+		fmt.Println("ECOFLOW_ACCESS_KEY and ECOFLOW_SECRET_KEY are required")
+		return
+	}
+	balancer := ecoflow.NewBalancer(system.Client, inverters...)
+	go balancer.Run(ctx)
+
+	total := 0.0
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		value, ok := ecoflow.States.Get(meter, meterKey)
+		grid, isNumber := value.Value.(float64)
+		if !ok || !isNumber || !ecoflow.Fresh(meter, maxAge) {
+			fmt.Println("No current meter value, output unchanged")
+			continue
+		}
+		total = math.Max(0, total+grid-margin)
+		shares, err := balancer.SetTotal(ctx, total)
+		if err != nil {
+			fmt.Println("Set output error:", err)
+		}
+		for _, s := range shares {
+			fmt.Printf("grid=%.0fW %s -> %.0fW\n", grid, s.SerialNumber, s.Watts)
+		}
+	}
+}
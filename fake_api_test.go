@@ -0,0 +1,141 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// newTestClient create client sending its HTTP requests to the round tripper,
+// commands are routed to the HTTP API
+func newTestClient(t *testing.T, rt http.RoundTripper, options ...ClientOption) *Client {
+	t.Helper()
+	client := NewClient("access", "secret", options...)
+	client.httpClient = &http.Client{Transport: rt}
+	client.SetRouter(NewRouter(&HTTPTransport{Client: client}))
+	return client
+}
+
+// fakeAPI configurable fake of the IoT Open API. GET requests return the quota,
+// PUT requests are recorded and passed to apply, other requests are answered
+// by handle.
+type fakeAPI struct {
+	mu       sync.Mutex
+	quota    map[string]interface{}
+	requests []CmdSetRequest
+	// apply update the quota with the received command, nil keeps the quota
+	apply func(quota map[string]interface{}, req CmdSetRequest)
+	// handle return the response body of other requests, e.g. the history API
+	handle func(r *http.Request) (interface{}, error)
+	// code response code of commands, empty for success
+	code Code
+}
+
+// newFakeAPI create fake API reporting the quota and recording the commands
+func newFakeAPI(quota map[string]interface{}) *fakeAPI {
+	if quota == nil {
+		quota = make(map[string]interface{})
+	}
+	return &fakeAPI{quota: quota}
+}
+
+// newPowerStreamAPI create fake API updating the PowerStream quota on commands
+func newPowerStreamAPI(quota map[string]interface{}) *fakeAPI {
+	f := newFakeAPI(quota)
+	f.apply = func(quota map[string]interface{}, req CmdSetRequest) {
+		for k, v := range req.Params {
+			quota["20_1."+k] = v
+		}
+	}
+	return f
+}
+
+// newStationAPI create fake API updating the Delta 2 quota on PD and MPPT commands
+func newStationAPI(quota map[string]interface{}) *fakeAPI {
+	f := newFakeAPI(quota)
+	f.apply = func(quota map[string]interface{}, req CmdSetRequest) {
+		switch req.OperateType {
+		case "acChgCfg":
+			quota["mppt.cfgChgWatts"] = req.Params["chgWatts"]
+		case "lcdCfg":
+			quota["pd.brightLevel"] = req.Params["brightLevel"]
+		case "quietCfg":
+			quota["pd.beepMode"] = req.Params["enabled"]
+		}
+	}
+	return f
+}
+
+func (f *fakeAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body interface{} = map[string]interface{}{"code": "0", "message": "Success"}
+	switch r.Method {
+	case http.MethodGet:
+		body = map[string]interface{}{"code": "0", "data": f.quota}
+	case http.MethodPut:
+		var req CmdSetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		f.requests = append(f.requests, req)
+		if f.code != "" && !f.code.OK() {
+			body = map[string]interface{}{"code": f.code, "message": "command failed"}
+			break
+		}
+		if f.apply != nil {
+			f.apply(f.quota, req)
+		}
+	default:
+		if f.handle != nil {
+			var err error
+			if body, err = f.handle(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	data, _ := json.Marshal(body)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data)), Header: make(http.Header)}, nil
+}
+
+// puts number of received commands
+func (f *fakeAPI) puts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+// sent return copy of the received commands
+func (f *fakeAPI) sent() []CmdSetRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]CmdSetRequest(nil), f.requests...)
+}
+
+// value return the quota value of the key
+func (f *fakeAPI) value(key string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.quota[key]
+}
+
+// set change the quota value of the key
+func (f *fakeAPI) set(key string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.quota[key] = value
+}
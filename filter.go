@@ -0,0 +1,145 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// TelemetryFilter allowlist/denylist of telemetry keys using glob patterns like
+// "bms_*". Patterns starting with "!" drop the matching keys. The last matching
+// pattern decides, keys not matching any pattern are kept if the filter only
+// contains drop patterns and dropped otherwise.
+type TelemetryFilter struct {
+	patterns []filterPattern
+	allow    bool
+}
+
+type filterPattern struct {
+	pattern string
+	keep    bool
+}
+
+// NewTelemetryFilter create filter, invalid patterns return an error
+func NewTelemetryFilter(patterns ...string) (*TelemetryFilter, error) {
+	f := &TelemetryFilter{}
+	for _, p := range patterns {
+		keep := !strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+		f.patterns = append(f.patterns, filterPattern{pattern: p, keep: keep})
+		if keep {
+			f.allow = true
+		}
+	}
+	return f, nil
+}
+
+// Keep check if the key passes the filter
+func (f *TelemetryFilter) Keep(key string) bool {
+	keep := !f.allow
+	for _, p := range f.patterns {
+		if ok, _ := path.Match(p.pattern, key); ok {
+			keep = p.keep
+		}
+	}
+	return keep
+}
+
+var filterLock sync.RWMutex
+var telemetryFilters = make(map[string]*TelemetryFilter)
+
+// SetTelemetryFilter set filter patterns of the device applied before telemetry
+// is dispatched to the handlers and sinks. The serial number "*" sets the filter
+// of all devices without own filter. No patterns remove the filter.
+func SetTelemetryFilter(serialNumber string, patterns ...string) error {
+	sn := strings.ToUpper(serialNumber)
+	filterLock.Lock()
+	defer filterLock.Unlock()
+	if len(patterns) == 0 {
+		delete(telemetryFilters, sn)
+		return nil
+	}
+	f, err := NewTelemetryFilter(patterns...)
+	if err != nil {
+		return err
+	}
+	telemetryFilters[sn] = f
+	return nil
+}
+
+// SetTelemetryFilters replace the filters of all devices, the map key is the
+// serial number or "*"
+func SetTelemetryFilters(filters map[string][]string) error {
+	parsed, err := parseTelemetryFilters(filters)
+	if err != nil {
+		return err
+	}
+	setTelemetryFilters(parsed)
+	return nil
+}
+
+// parseTelemetryFilters compile the filter patterns of the devices
+func parseTelemetryFilters(filters map[string][]string) (map[string]*TelemetryFilter, error) {
+	parsed := make(map[string]*TelemetryFilter, len(filters))
+	for sn, patterns := range filters {
+		if len(patterns) == 0 {
+			continue
+		}
+		f, err := NewTelemetryFilter(patterns...)
+		if err != nil {
+			return nil, fmt.Errorf("filter of %s: %w", sn, err)
+		}
+		parsed[strings.ToUpper(sn)] = f
+	}
+	return parsed, nil
+}
+
+func setTelemetryFilters(parsed map[string]*TelemetryFilter) {
+	filterLock.Lock()
+	defer filterLock.Unlock()
+	telemetryFilters = parsed
+}
+
+func telemetryFilter(serialNumber string) *TelemetryFilter {
+	filterLock.RLock()
+	defer filterLock.RUnlock()
+	if len(telemetryFilters) == 0 {
+		return nil
+	}
+	if f, ok := telemetryFilters[strings.ToUpper(serialNumber)]; ok {
+		return f
+	}
+	return telemetryFilters["*"]
+}
+
+// FilterTelemetry remove telemetry values dropped by the device filters
+func FilterTelemetry(points []*Telemetry) []*Telemetry {
+	filterLock.RLock()
+	empty := len(telemetryFilters) == 0
+	filterLock.RUnlock()
+	if empty {
+		return points
+	}
+	result := make([]*Telemetry, 0, len(points))
+	for _, p := range points {
+		if f := telemetryFilter(p.SerialNumber); f == nil || f.Keep(p.Key) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelemetryFilter(t *testing.T) {
+	f, err := NewTelemetryFilter("bms_*", "!*.reserved")
+	assert.NoError(t, err)
+	assert.True(t, f.Keep("bms_bmsStatus.soc"))
+	assert.False(t, f.Keep("bms_bmsStatus.reserved"))
+	assert.False(t, f.Keep("pd.soc"))
+
+	f, _ = NewTelemetryFilter("!*.reserved")
+	assert.True(t, f.Keep("pd.soc"))
+	assert.False(t, f.Keep("pd.reserved"))
+
+	_, err = NewTelemetryFilter("[")
+	assert.Error(t, err)
+
+	assert.NoError(t, SetTelemetryFilter("r331filter", "pd.*"))
+	defer SetTelemetryFilter("R331FILTER")
+	var received []*Telemetry
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) { received = append(received, points...) })
+	defer unregister()
+	DispatchTelemetry([]*Telemetry{{SerialNumber: "R331FILTER", Key: "pd.soc"},
+		{SerialNumber: "R331FILTER", Key: "inv.acOut"}, {SerialNumber: "HW51OTHER", Key: "inv.acOut"}})
+	assert.Len(t, received, 2)
+	assert.Equal(t, "pd.soc", received[0].Key)
+}
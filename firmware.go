@@ -0,0 +1,212 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventFirmwareUpdate firmware upgrade status changed, Data contains FirmwareStatus
+const EventFirmwareUpdate EventType = "firmware.update"
+
+// FirmwareState state of a firmware upgrade
+type FirmwareState int
+
+const (
+	FirmwareIdle FirmwareState = iota
+	FirmwareDownloading
+	FirmwareInstalling
+	FirmwareSucceeded
+	FirmwareFailed
+	// FirmwareUnknown raw upgrade state not known
+	FirmwareUnknown
+)
+
+// firmwareRawStates upgrade states of the raw status values sent by the device
+var firmwareRawStates = map[float64]FirmwareState{
+	0: FirmwareIdle,
+	1: FirmwareDownloading,
+	2: FirmwareInstalling,
+	3: FirmwareSucceeded,
+	4: FirmwareFailed,
+}
+
+func (s FirmwareState) String() string {
+	switch s {
+	case FirmwareDownloading:
+		return "downloading"
+	case FirmwareInstalling:
+		return "installing"
+	case FirmwareSucceeded:
+		return "succeeded"
+	case FirmwareFailed:
+		return "failed"
+	case FirmwareUnknown:
+		return "unknown"
+	default:
+		return "idle"
+	}
+}
+
+// InProgress check if the upgrade is running
+func (s FirmwareState) InProgress() bool {
+	return s == FirmwareDownloading || s == FirmwareInstalling
+}
+
+// FirmwareStatus firmware upgrade status of a device module
+type FirmwareStatus struct {
+	SerialNumber string        `json:"serialNumber"`
+	Module       string        `json:"module,omitempty"`
+	State        FirmwareState `json:"state"`
+	RawState     float64       `json:"rawState"`
+	Percent      float64       `json:"percent"`
+	Version      string        `json:"version,omitempty"`
+	Updated      time.Time     `json:"updated"`
+}
+
+// ErrFirmwareUpdating command refused because a firmware upgrade is running
+var ErrFirmwareUpdating = errors.New("firmware upgrade in progress")
+
+// PauseDuringFirmwareUpdate refuse commands to devices while a firmware upgrade is running
+var PauseDuringFirmwareUpdate = true
+
+// firmwareStaleAfter upgrades without status messages for this time are treated as finished
+const firmwareStaleAfter = 30 * time.Minute
+
+// firmware status keys of the upgrade messages
+var (
+	firmwareStateKeys   = []string{"otaStatus", "upgradeStatus", "otaState"}
+	firmwarePercentKeys = []string{"otaProgress", "upgradeProgress", "otaProcess", "progress"}
+	firmwareVersionKeys = []string{"otaVersion", "upgradeVersion", "targetVersion"}
+)
+
+var firmwareLock sync.Mutex
+var firmwareStatus = make(map[string]*FirmwareStatus)
+
+type firmwareCandidate struct {
+	module string
+	values map[string]interface{}
+}
+
+// parseFirmwareStatus parse upgrade status of a MQTT JSON message, the keys are
+// searched in the message, the params and the first level module groups. A
+// status is returned for every module, ordered by module name.
+func parseFirmwareStatus(serialNumber string, data map[string]interface{}) []*FirmwareStatus {
+	candidates := []firmwareCandidate{{"", data}}
+	if params, ok := data["params"].(map[string]interface{}); ok {
+		candidates = append(candidates, firmwareCandidate{"", params})
+		data = params
+	}
+	modules := make([]string, 0)
+	for k, v := range data {
+		if _, ok := v.(map[string]interface{}); ok {
+			modules = append(modules, k)
+		}
+	}
+	sort.Strings(modules)
+	for _, k := range modules {
+		candidates = append(candidates, firmwareCandidate{k, data[k].(map[string]interface{})})
+	}
+	statuses := make([]*FirmwareStatus, 0)
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		raw, ok := firmwareValue(c.values, firmwareStateKeys)
+		if !ok {
+			continue
+		}
+		state, known := firmwareRawStates[raw]
+		if !known {
+			state = FirmwareUnknown
+		}
+		status := &FirmwareStatus{SerialNumber: serialNumber, Module: c.module,
+			State: state, RawState: raw, Updated: now()}
+		if percent, ok := firmwareValue(c.values, firmwarePercentKeys); ok {
+			status.Percent = percent
+		}
+		for _, k := range firmwareVersionKeys {
+			if v, ok := c.values[k]; ok {
+				status.Version = fmt.Sprint(v)
+				break
+			}
+		}
+		if moduleSn, ok := data["moduleSn"].(string); ok && status.Module == "" {
+			status.Module = moduleSn
+		}
+		if seen[status.Module] {
+			continue
+		}
+		seen[status.Module] = true
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func firmwareValue(values map[string]interface{}, keys []string) (float64, bool) {
+	for _, k := range keys {
+		if v, ok := values[k].(float64); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// observeFirmware update the firmware status using the MQTT message and publish
+// an event on changes
+func observeFirmware(serialNumber string, data map[string]interface{}) {
+	for _, status := range parseFirmwareStatus(serialNumber, data) {
+		key := strings.ToUpper(serialNumber) + "/" + status.Module
+		firmwareLock.Lock()
+		last, known := firmwareStatus[key]
+		firmwareStatus[key] = status
+		firmwareLock.Unlock()
+		if known && last.RawState == status.RawState && last.Percent == status.Percent {
+			continue
+		}
+		if status.State == FirmwareUnknown {
+			log.Log.Infof("Firmware status %v of %s %s unknown", status.RawState, serialNumber, status.Module)
+		}
+		Events.Publish(Event{Type: EventFirmwareUpdate, SerialNumber: serialNumber, Time: status.Updated, Data: *status})
+	}
+}
+
+// FirmwareStatuses return the latest firmware status of all modules of the device
+// ordered by module
+func FirmwareStatuses(serialNumber string) []FirmwareStatus {
+	prefix := strings.ToUpper(serialNumber) + "/"
+	firmwareLock.Lock()
+	defer firmwareLock.Unlock()
+	result := make([]FirmwareStatus, 0)
+	for k, s := range firmwareStatus {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Module < result[j].Module })
+	return result
+}
+
+// FirmwareUpdating check if a firmware upgrade of the device is running
+func FirmwareUpdating(serialNumber string) bool {
+	for _, s := range FirmwareStatuses(serialNumber) {
+		if s.State.InProgress() && since(s.Updated) < firmwareStaleAfter {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirmwareStatus(t *testing.T) {
+	sn := "FWTEST0001"
+	events := make([]FirmwareStatus, 0)
+	unsubscribe := Events.Subscribe(func(e Event) {
+		events = append(events, e.Data.(FirmwareStatus))
+	}, EventFirmwareUpdate)
+	defer unsubscribe()
+
+	observeFirmware(sn, map[string]interface{}{"params": map[string]interface{}{
+		"pd": map[string]interface{}{"otaStatus": 1.0, "otaProgress": 35.0, "otaVersion": "1.0.1.2"}}})
+	observeFirmware(sn, map[string]interface{}{"params": map[string]interface{}{
+		"pd": map[string]interface{}{"otaStatus": 1.0, "otaProgress": 35.0}}})
+	assert.True(t, FirmwareUpdating(sn))
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "pd", events[0].Module)
+		assert.Equal(t, FirmwareDownloading, events[0].State)
+		assert.Equal(t, 35.0, events[0].Percent)
+		assert.Equal(t, "1.0.1.2", events[0].Version)
+	}
+
+	client := &Client{}
+	_, err := client.sendCommand(context.Background(), CmdSetRequest{Sn: sn})
+	assert.True(t, errors.Is(err, ErrFirmwareUpdating))
+
+	observeFirmware(sn, map[string]interface{}{"params": map[string]interface{}{
+		"pd": map[string]interface{}{"otaStatus": 3.0, "otaProgress": 100.0}}})
+	assert.False(t, FirmwareUpdating(sn))
+	assert.Len(t, events, 2)
+	assert.Len(t, FirmwareStatuses(sn), 1)
+
+	observeFirmware(sn, map[string]interface{}{"params": map[string]interface{}{"batSoc": 50.0}})
+	assert.Len(t, events, 2)
+}
+
+func TestFirmwareStatusModules(t *testing.T) {
+	sn := "FWTEST0002"
+	observeFirmware(sn, map[string]interface{}{"params": map[string]interface{}{
+		"pd":   map[string]interface{}{"otaStatus": 3.0},
+		"bms":  map[string]interface{}{"otaStatus": 2.0, "otaProgress": 60.0},
+		"mppt": map[string]interface{}{"otaStatus": 9.0}}})
+	statuses := FirmwareStatuses(sn)
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, "bms", statuses[0].Module)
+		assert.Equal(t, FirmwareInstalling, statuses[0].State)
+		assert.Equal(t, "mppt", statuses[1].Module)
+		assert.Equal(t, FirmwareUnknown, statuses[1].State)
+		assert.Equal(t, 9.0, statuses[1].RawState)
+		assert.False(t, statuses[1].State.InProgress())
+		assert.Equal(t, "pd", statuses[2].Module)
+		assert.Equal(t, FirmwareSucceeded, statuses[2].State)
+	}
+	assert.True(t, FirmwareUpdating(sn))
+}
@@ -0,0 +1,137 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ForecastSlot forecasted average PV production of a time slot
+type ForecastSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Watts float64   `json:"watts"`
+}
+
+// ForecastProvider provider of PV production forecasts
+type ForecastProvider interface {
+	Forecast(ctx context.Context, from, to time.Time) ([]ForecastSlot, error)
+}
+
+// ForecastSolarURL base URL of the forecast.solar API
+var ForecastSolarURL = "https://api.forecast.solar"
+
+// ForecastSolar forecast.solar PV forecast of one plane
+type ForecastSolar struct {
+	Latitude    float64
+	Longitude   float64
+	Declination float64
+	// Azimuth -180 north, -90 east, 0 south, 90 west
+	Azimuth float64
+	// KWp installed peak power of the plane
+	KWp        float64
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+type forecastSolarResponse struct {
+	Result  map[string]float64 `json:"result"`
+	Message struct {
+		Code int    `json:"code"`
+		Type string `json:"type"`
+		Text string `json:"text"`
+		Info struct {
+			Timezone string `json:"timezone"`
+		} `json:"info"`
+	} `json:"message"`
+}
+
+func (f *ForecastSolar) url() string {
+	base := ForecastSolarURL
+	if f.APIKey != "" {
+		base += "/" + f.APIKey
+	}
+	return fmt.Sprintf("%s/estimate/watts/%g/%g/%g/%g/%g", base, f.Latitude, f.Longitude,
+		f.Declination, f.Azimuth, f.KWp)
+}
+
+// Forecast request the forecast, the API returns watts at the given points in
+// time which are used as slots up to the next point
+func (f *ForecastSolar) Forecast(ctx context.Context, from, to time.Time) ([]ForecastSlot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := f.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forecast.solar request failed: %s %s", resp.Status, string(body))
+	}
+	return parseForecastSolar(body, from, to)
+}
+
+func parseForecastSolar(body []byte, from, to time.Time) ([]ForecastSlot, error) {
+	response := &forecastSolarResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, err
+	}
+	if response.Message.Type == "error" {
+		return nil, fmt.Errorf("forecast.solar error %d: %s", response.Message.Code, response.Message.Text)
+	}
+	location := time.UTC
+	if response.Message.Info.Timezone != "" {
+		l, err := time.LoadLocation(response.Message.Info.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		location = l
+	}
+	points := make([]ForecastSlot, 0, len(response.Result))
+	for k, w := range response.Result {
+		t, err := time.ParseInLocation(time.DateTime, k, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid forecast time %s: %w", k, err)
+		}
+		points = append(points, ForecastSlot{Start: t, Watts: w})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Start.Before(points[j].Start) })
+	slots := make([]ForecastSlot, 0, len(points))
+	for i := range points {
+		if i+1 < len(points) && points[i+1].Start.Sub(points[i].Start) <= 2*time.Hour {
+			points[i].End = points[i+1].Start
+		} else {
+			points[i].End = points[i].Start.Add(time.Hour)
+		}
+		if points[i].End.After(from) && points[i].Start.Before(to) {
+			slots = append(slots, points[i])
+		}
+	}
+	return slots, nil
+}
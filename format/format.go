@@ -0,0 +1,226 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package format formats power, energy and duration values of the Ecoflow
+// devices for the display in user interfaces. Units and scale factors of the
+// raw quota values are taken out of the key catalog.
+package format
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tknie/ecoflow"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// DefaultPrecision fraction digits used for scaled units like kW or kWh
+const DefaultPrecision = 2
+
+type options struct {
+	tag       language.Tag
+	precision int
+}
+
+// Option formatting option
+type Option func(*options)
+
+// WithLocale format numbers using the decimal and grouping separators of the locale
+func WithLocale(tag language.Tag) Option {
+	return func(o *options) {
+		o.tag = tag
+	}
+}
+
+// WithPrecision format numbers with the given number of fraction digits
+func WithPrecision(digits int) Option {
+	return func(o *options) {
+		if digits >= 0 {
+			o.precision = digits
+		}
+	}
+}
+
+// ParseLocale parse locale names like de_DE.UTF-8 used in the LANG environment,
+// English is returned if the name is unknown
+func ParseLocale(name string) language.Tag {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ReplaceAll(name, "_", "-")
+	if name == "" || name == "C" || name == "POSIX" {
+		return language.English
+	}
+	tag, err := language.Parse(name)
+	if err != nil {
+		return language.English
+	}
+	return tag
+}
+
+func evaluate(opts []Option) *options {
+	o := &options{tag: language.English, precision: -1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// digits fraction digits of the value, base units without prefix are shown
+// without fraction unless a precision is requested
+func (o *options) digits(scaled bool) int {
+	switch {
+	case o.precision >= 0:
+		return o.precision
+	case scaled:
+		return DefaultPrecision
+	default:
+		return 0
+	}
+}
+
+func (o *options) number(v float64, digits int) string {
+	p := message.NewPrinter(o.tag)
+	return p.Sprint(number.Decimal(v, number.MinFractionDigits(digits),
+		number.MaxFractionDigits(digits)))
+}
+
+// prefixed format the value with the metric prefix k or M of the unit
+func prefixed(v float64, unit string, o *options) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e6:
+		return o.number(v/1e6, o.digits(true)) + " M" + unit
+	case abs >= 1e3:
+		return o.number(v/1e3, o.digits(true)) + " k" + unit
+	default:
+		return o.number(v, o.digits(false)) + " " + unit
+	}
+}
+
+// FormatNumber format the number with the separators of the locale
+func FormatNumber(v float64, opts ...Option) string {
+	o := evaluate(opts)
+	return o.number(v, o.digits(false))
+}
+
+// FormatPower format the power in watts using W, kW or MW
+func FormatPower(watts float64, opts ...Option) string {
+	return prefixed(watts, "W", evaluate(opts))
+}
+
+// FormatEnergy format the energy in watt hours using Wh, kWh or MWh
+func FormatEnergy(wattHours float64, opts ...Option) string {
+	return prefixed(wattHours, "Wh", evaluate(opts))
+}
+
+// FormatPercent format the percentage like the state of charge
+func FormatPercent(percent float64, opts ...Option) string {
+	o := evaluate(opts)
+	return o.number(percent, o.digits(false)) + " %"
+}
+
+// FormatDuration format the duration with the two most significant units,
+// like 2 d 3 h, 1 h 5 min or 45 s
+func FormatDuration(d time.Duration, opts ...Option) string {
+	o := evaluate(opts)
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Second)
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "min"},
+		{time.Second, "s"},
+	}
+	parts := make([]string, 0, 2)
+	for _, u := range units {
+		n := d / u.size
+		d -= n * u.size
+		switch {
+		case n > 0:
+			parts = append(parts, o.number(float64(n), 0)+" "+u.name)
+		case len(parts) > 0:
+			// stop at a zero unit, 1 h 0 min 5 s is shown as 1 h
+			parts = append(parts, "")
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "0 s"
+	}
+	return sign + strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// FormatValue format the raw quota value of the device using the unit and
+// scale factor of the key catalog. Unknown keys and non numeric values are
+// returned unchanged.
+func FormatValue(serialNumber, key string, value interface{}, opts ...Option) string {
+	raw, ok := toFloat(value)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	info, found := ecoflow.LookupKey(serialNumber, key)
+	if !found {
+		return fmt.Sprint(value)
+	}
+	v := info.Value(raw)
+	switch info.Unit {
+	case ecoflow.UnitWatt:
+		return FormatPower(v, opts...)
+	case ecoflow.UnitMinute:
+		return FormatDuration(time.Duration(v*float64(time.Minute)), opts...)
+	case ecoflow.UnitPercent:
+		return FormatPercent(v, opts...)
+	case ecoflow.UnitNone:
+		return FormatNumber(v, opts...)
+	default:
+		o := evaluate(opts)
+		digits := o.digits(false)
+		if o.precision < 0 && v != math.Trunc(v) {
+			digits = 1
+		}
+		return o.number(v, digits) + " " + string(info.Unit)
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
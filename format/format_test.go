@@ -0,0 +1,71 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestFormatPower(t *testing.T) {
+	assert.Equal(t, "350 W", FormatPower(350))
+	assert.Equal(t, "-350 W", FormatPower(-350))
+	assert.Equal(t, "1.24 kW", FormatPower(1240))
+	assert.Equal(t, "2.5 MW", FormatPower(2_500_000, WithPrecision(1)))
+	assert.Equal(t, "1,24 kW", FormatPower(1240, WithLocale(language.German)))
+}
+
+func TestFormatEnergy(t *testing.T) {
+	assert.Equal(t, "1.24 kWh", FormatEnergy(1240))
+	assert.Equal(t, "812 Wh", FormatEnergy(812.3))
+	assert.Equal(t, "812.3 Wh", FormatEnergy(812.3, WithPrecision(1)))
+	assert.Equal(t, "1.240 MWh", FormatEnergy(1_240_000, WithPrecision(3)))
+	assert.Equal(t, "1,2 kWh", FormatEnergy(1240, WithLocale(language.French), WithPrecision(1)))
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0 s"},
+		{45 * time.Second, "45 s"},
+		{65 * time.Minute, "1 h 5 min"},
+		{time.Hour + 5*time.Second, "1 h"},
+		{51 * time.Hour, "2 d 3 h"},
+		{24 * time.Hour, "1 d"},
+		{-90 * time.Second, "-1 min 30 s"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatDuration(tt.d), tt.d.String())
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "85 %", FormatValue("R331ZEB4ZE000000", "bms_bmsStatus.soc", 85.0))
+	assert.Equal(t, "1.1 kW", FormatValue("R331ZEB4ZE000000", "inv.inputWatts", 1100.0, WithPrecision(1)))
+	assert.Equal(t, "2 h 30 min", FormatValue("R331ZEB4ZE000000", "pd.remainTime", 150))
+	assert.Equal(t, "230.1 V", FormatValue("R331ZEB4ZE000000", "inv.acInVol", 230100.0))
+	assert.Equal(t, "12345", FormatValue("R331ZEB4ZE000000", "pd.unknownCounter", 12345.0))
+	assert.Equal(t, "on", FormatValue("R331ZEB4ZE000000", "pd.mode", "on"))
+	assert.Equal(t, "230,1 V", FormatValue("R331ZEB4ZE000000", "inv.acInVol", 230100.0,
+		WithLocale(ParseLocale("de_DE.UTF-8"))))
+}
+
+func TestParseLocale(t *testing.T) {
+	assert.Equal(t, language.English, ParseLocale(""))
+	assert.Equal(t, language.English, ParseLocale("C"))
+	assert.Equal(t, "de-DE", ParseLocale("de_DE.UTF-8").String())
+}
@@ -0,0 +1,92 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// fuzzSeeds payloads seen on the broker: JSON, protobuf, concatenated and
+// truncated frames
+func fuzzSeeds(f *testing.F) {
+	frame := benchProtoPayload(f)
+	cmdId := int32(32)
+	pdata, _ := proto.Marshal(&PowerPack{SysPowerStream: []*PowerItem{{}}})
+	powerPack, _ := proto.Marshal(&SendHeaderMsg{Msg: &Header{Pdata: pdata, CmdId: &cmdId, DeviceSn: proto.String("HW51FUZZ")}})
+	f.Add("HW51FUZZ", []byte(`{"params":{"pd.soc":80}}`))
+	f.Add("HW51FUZZ", []byte(`{"otaStatus":1,"params":null}`))
+	f.Add("HW51FUZZ", frame)
+	f.Add("HW51FUZZ", append(append([]byte{}, frame...), powerPack...))
+	f.Add("HW51FUZZ", frame[:len(frame)/2])
+	f.Add("", powerPack)
+	f.Add("HW51FUZZ", []byte("\n{"))
+	// empty payload and frame without header
+	f.Add("HW51FUZZ", []byte(""))
+	f.Add("HW51FUZZ", []byte{0x10, 0x01})
+}
+
+// failOnHandlerPanic fail the test if a panic is recovered by the handlers
+func failOnHandlerPanic(t *testing.T) {
+	unsubscribe := Events.Subscribe(func(e Event) {
+		p := e.Data.(HandlerPanic)
+		t.Errorf("%s panic: %v\n%s", p.Handler, p.Value, p.Stack)
+	}, EventHandlerPanic)
+	t.Cleanup(unsubscribe)
+}
+
+func FuzzMessageHandler(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, serialNumber string, payload []byte) {
+		failOnHandlerPanic(t)
+		MessageHandler(nil, &benchMessage{topic: "/app/device/property/" + serialNumber, payload: payload})
+	})
+}
+
+func FuzzDisplayPayload(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, serialNumber string, payload []byte) {
+		failOnHandlerPanic(t)
+		DisplayPayload(serialNumber, payload)
+	})
+}
+
+func TestSplitFrames(t *testing.T) {
+	frame := benchProtoPayload(t)
+	payload := append(append([]byte{}, frame...), frame...)
+	frames := splitFrames(payload)
+	assert.Equal(t, [][]byte{frame, frame}, frames)
+
+	// truncated second frame is returned as undecodable rest
+	frames = splitFrames(payload[:len(payload)-2])
+	if assert.Len(t, frames, 2) {
+		assert.Equal(t, frame, frames[0])
+		assert.Len(t, frames[1], len(frame)-2)
+	}
+	assert.Equal(t, [][]byte{{0xff}}, splitFrames([]byte{0xff}))
+
+	var watts []interface{}
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		for _, p := range points {
+			if p.Key == "inverterHeartbeat.permanentWatts" {
+				watts = append(watts, p.Value)
+			}
+		}
+	})
+	defer unregister()
+	MessageHandler(nil, &benchMessage{topic: "/app/device/property/HW51FRAMES", payload: payload})
+	assert.Len(t, watts, 2)
+	MessageHandler(nil, &benchMessage{topic: "/app/device/property/", payload: payload})
+	assert.Len(t, watts, 2)
+}
@@ -0,0 +1,176 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Scope permission granted to a gateway token
+type Scope string
+
+const (
+	// ScopeTelemetryRead read state, statistics and telemetry streams
+	ScopeTelemetryRead Scope = "telemetry:read"
+	// ScopeDeviceControl send commands to devices
+	ScopeDeviceControl Scope = "device:control"
+)
+
+var knownScopes = map[Scope]bool{ScopeTelemetryRead: true, ScopeDeviceControl: true}
+
+// GatewayTokens tokens of the status server and the gRPC telemetry export
+var GatewayTokens = NewTokenAuth()
+
+// TokenAuth bearer token authorization with scopes. As long as no token is
+// defined the gateway is open for reading and device control is denied.
+type TokenAuth struct {
+	lock   sync.RWMutex
+	tokens map[[sha256.Size]byte][]Scope
+}
+
+// NewTokenAuth create token authorization without tokens
+func NewTokenAuth() *TokenAuth {
+	return &TokenAuth{tokens: make(map[[sha256.Size]byte][]Scope)}
+}
+
+// SetTokens replace all tokens, the map contains the scopes of each token
+func (a *TokenAuth) SetTokens(tokens map[string][]string) error {
+	parsed, err := parseTokens(tokens)
+	if err != nil {
+		return err
+	}
+	a.setTokens(parsed)
+	return nil
+}
+
+// parseTokens validate the scopes of the tokens and key them by the token hash
+func parseTokens(tokens map[string][]string) (map[[sha256.Size]byte][]Scope, error) {
+	parsed := make(map[[sha256.Size]byte][]Scope, len(tokens))
+	for token, scopes := range tokens {
+		if token == "" {
+			return nil, errors.New("empty gateway token")
+		}
+		if len(scopes) == 0 {
+			// a token without scope grants nothing, it is a configuration error
+			return nil, errors.New("gateway token without scope")
+		}
+		key := sha256.Sum256([]byte(token))
+		for _, s := range scopes {
+			if !knownScopes[Scope(s)] {
+				return nil, fmt.Errorf("unknown gateway scope %q", s)
+			}
+			parsed[key] = append(parsed[key], Scope(s))
+		}
+	}
+	return parsed, nil
+}
+
+func (a *TokenAuth) setTokens(parsed map[[sha256.Size]byte][]Scope) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.tokens = parsed
+}
+
+// Allowed check if the token grants the scope
+func (a *TokenAuth) Allowed(token string, scope Scope) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if len(a.tokens) == 0 {
+		return scope == ScopeTelemetryRead
+	}
+	for _, s := range a.tokens[sha256.Sum256([]byte(token))] {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// known check if the token is defined, used to distinguish 401 and 403
+func (a *TokenAuth) known(token string) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	_, ok := a.tokens[sha256.Sum256([]byte(token))]
+	return ok
+}
+
+// bearerToken extract token of the Authorization header value
+func bearerToken(value string) string {
+	if len(value) > 7 && strings.EqualFold(value[:7], "Bearer ") {
+		return strings.TrimSpace(value[7:])
+	}
+	return ""
+}
+
+// Require wrap the handler to require a bearer token granting the scope
+func (a *TokenAuth) Require(scope Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r.Header.Get("Authorization"))
+		if !a.Allowed(token, scope) {
+			if token == "" || !a.known(token) {
+				w.Header().Set("WWW-Authenticate", `Bearer scope="`+string(scope)+`"`)
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+				return
+			}
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "token lacks scope " + string(scope)})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorizeContext check the authorization metadata of a gRPC call
+func (a *TokenAuth) authorizeContext(ctx context.Context, scope Scope) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = bearerToken(values[0])
+		}
+	}
+	if a.Allowed(token, scope) {
+		return nil
+	}
+	if token == "" || !a.known(token) {
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	return status.Error(codes.PermissionDenied, "token lacks scope "+string(scope))
+}
+
+// ServerOptions gRPC interceptors requiring the scope for all calls, the
+// telemetry export only provides read access
+func (a *TokenAuth) ServerOptions(scope Scope) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := a.authorizeContext(ctx, scope); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := a.authorizeContext(ss.Context(), scope); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,76 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGatewayScopes(t *testing.T) {
+	transport := newPowerStreamAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	s := NewStatusServer("")
+	s.Auth = NewTokenAuth()
+	s.EnableControl(client)
+
+	call := func(method, path, token, body string) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		s.Handler().ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+	setWatts := func(token string) int {
+		return call(http.MethodPost, "/devices/HW51GATE/settings/permanentWatts", token, `{"value":200}`)
+	}
+
+	// without tokens reading is open and control denied
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/stats", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, setWatts(""))
+
+	assert.Error(t, s.Auth.SetTokens(map[string][]string{"grafana": {"telemetry:write"}}))
+	assert.Error(t, s.Auth.SetTokens(map[string][]string{"grafana": {}}))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/stats", "", ""))
+	assert.NoError(t, s.Auth.SetTokens(map[string][]string{"grafana": {"telemetry:read"},
+		"automation": {"telemetry:read", "device:control"}}))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/healthz", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/stats", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/stats", "unknown", ""))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/stats", "grafana", ""))
+	assert.Equal(t, http.StatusForbidden, setWatts("grafana"))
+	assert.Equal(t, 0, transport.puts())
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/devices/HW51GATE/settings/permanentWatts", "automation", `{}`))
+	assert.Equal(t, http.StatusOK, setWatts("automation"))
+	assert.Equal(t, 1, transport.puts())
+}
+
+func TestGatewayGRPCAuthorization(t *testing.T) {
+	auth := NewTokenAuth()
+	assert.NoError(t, auth.SetTokens(map[string][]string{"grafana": {"telemetry:read"}, "automation": {"device:control"}}))
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	assert.NoError(t, auth.authorizeContext(withToken("grafana"), ScopeTelemetryRead))
+	assert.Equal(t, codes.PermissionDenied, status.Code(auth.authorizeContext(withToken("automation"), ScopeTelemetryRead)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(auth.authorizeContext(context.Background(), ScopeTelemetryRead)))
+}
@@ -4,8 +4,14 @@ GOPATH=$(go env GOPATH)
 PATH=$PATH:$GOPATH/bin
 export PATH
 
-protoc --proto_path=proto --go_out=. \
-  --go_opt=Mplatform.proto=github.com/tknie/ecoflow \
-  --go_opt=Mpowerstream.proto=github.com/tknie/ecoflow \
-  --go_opt=Mecopacket.proto=github.com/tknie/ecoflow \
-  --go_opt=paths=source_relative  proto/platform.proto proto/powerstream.proto proto/ecopacket.proto
+protoc --proto_path=proto --go_out=decode \
+  --go_opt=Mplatform.proto=github.com/tknie/ecoflow/decode \
+  --go_opt=Mpowerstream.proto=github.com/tknie/ecoflow/decode \
+  --go_opt=Mecopacket.proto=github.com/tknie/ecoflow/decode \
+  --go_opt=Mstream.proto=github.com/tknie/ecoflow/decode \
+  --go_opt=paths=source_relative  proto/platform.proto proto/powerstream.proto proto/ecopacket.proto proto/stream.proto
+
+protoc --proto_path=proto --go_out=. --go-grpc_out=. \
+  --go_opt=Mtelemetry.proto=github.com/tknie/ecoflow \
+  --go-grpc_opt=Mtelemetry.proto=github.com/tknie/ecoflow \
+  --go_opt=paths=source_relative --go-grpc_opt=paths=source_relative proto/telemetry.proto
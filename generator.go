@@ -0,0 +1,110 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// FuelType fuel used by the Smart Generator
+type FuelType int
+
+const (
+	// FuelGasoline generator running on gasoline
+	FuelGasoline FuelType = 1
+	// FuelLPG generator running on liquefied petroleum gas
+	FuelLPG FuelType = 2
+)
+
+// GeneratorQuota quota of the Smart Generator
+type GeneratorQuota struct {
+	FuelLevel   float64  `quota:"pd.fuelLevel"`
+	FuelType    FuelType `quota:"pd.fuelType"`
+	Running     bool     `quota:"pd.engineOpen"`
+	EcoMode     bool     `quota:"pd.generatorPerfMode"`
+	OutputWatts float64  `quota:"pd.outputWatts"`
+	RunTime     float64  `quota:"pd.runTime"`
+	ErrorCode   float64  `quota:"pd.errorCode"`
+}
+
+// GetGeneratorState read fuel level and run state of the Smart Generator
+func (client *Client) GetGeneratorState(ctx context.Context, serialNumber string) (*GeneratorQuota, error) {
+	if DetectModel(serialNumber) != ModelSmartGenerator {
+		return nil, fmt.Errorf("device %s is no Smart Generator", serialNumber)
+	}
+	q := &GeneratorQuota{}
+	if _, err := client.GetQuota(ctx, serialNumber, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// StartGenerator start the engine of the Smart Generator
+func (client *Client) StartGenerator(ctx context.Context, serialNumber string) (*CmdSetResponse, error) {
+	return client.setGeneratorEngine(ctx, serialNumber, true)
+}
+
+// StopGenerator stop the engine of the Smart Generator
+func (client *Client) StopGenerator(ctx context.Context, serialNumber string) (*CmdSetResponse, error) {
+	return client.setGeneratorEngine(ctx, serialNumber, false)
+}
+
+func (client *Client) setGeneratorEngine(ctx context.Context, serialNumber string, open bool) (*CmdSetResponse, error) {
+	if DetectModel(serialNumber) != ModelSmartGenerator {
+		return nil, fmt.Errorf("device %s is no Smart Generator", serialNumber)
+	}
+	return client.SetCommandWithPriority(ctx, PriorityHigh, CmdSetRequest{
+		Sn:          serialNumber,
+		ModuleType:  ModuleTypePd,
+		OperateType: "engineOpen",
+		Params:      map[string]interface{}{"open": boolToInt(open)},
+	})
+}
+
+// SetGeneratorAutoStart configure the power station connected to the Smart Generator
+// to start the generator if the battery level falls below startSoc and stop it if
+// the battery level reaches stopSoc
+func (client *Client) SetGeneratorAutoStart(ctx context.Context, stationSn string, startSoc, stopSoc int) error {
+	if err := DeviceLimits.Validate(stationSn, "openOilSoc", float64(startSoc)); err != nil {
+		return err
+	}
+	if err := DeviceLimits.Validate(stationSn, "closeOilSoc", float64(stopSoc)); err != nil {
+		return err
+	}
+	if startSoc >= stopSoc {
+		return fmt.Errorf("generator start level %d%% need to be below stop level %d%%", startSoc, stopSoc)
+	}
+	var requests []CmdSetRequest
+	switch DetectModel(stationSn) {
+	case ModelDelta2, ModelDelta2Max:
+		requests = []CmdSetRequest{
+			{Sn: stationSn, ModuleType: ModuleTypeBms, OperateType: "openOilSoc",
+				Params: map[string]interface{}{"openOilSoc": startSoc}},
+			{Sn: stationSn, ModuleType: ModuleTypeBms, OperateType: "closeOilSoc",
+				Params: map[string]interface{}{"closeOilSoc": stopSoc}},
+		}
+	case ModelDeltaMax, ModelDeltaPro:
+		requests = []CmdSetRequest{
+			{Sn: stationSn, Params: map[string]interface{}{"cmdSet": 32, "id": 52, "openOilSoc": startSoc}},
+			{Sn: stationSn, Params: map[string]interface{}{"cmdSet": 32, "id": 53, "closeOilSoc": stopSoc}},
+		}
+	default:
+		return fmt.Errorf("generator auto start not supported for device %s", stationSn)
+	}
+	for _, req := range requests {
+		if _, err := client.SetCommand(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorEngine(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{"pd.fuelLevel": 45, "pd.fuelType": 2, "pd.engineOpen": 1})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	state, err := client.GetGeneratorState(ctx, "DGEBGEN")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 45.0, state.FuelLevel)
+		assert.Equal(t, FuelLPG, state.FuelType)
+		assert.True(t, state.Running)
+	}
+	_, err = client.StartGenerator(ctx, "DGEBGEN")
+	assert.NoError(t, err)
+	_, err = client.StopGenerator(ctx, "DGEBGEN")
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 2) {
+		for i, open := range []float64{1, 0} {
+			assert.Equal(t, "DGEBGEN", transport.requests[i].Sn)
+			assert.Equal(t, ModuleTypePd, transport.requests[i].ModuleType)
+			assert.Equal(t, "engineOpen", transport.requests[i].OperateType)
+			assert.Equal(t, open, transport.requests[i].Params["open"])
+		}
+	}
+
+	_, err = client.GetGeneratorState(ctx, "R331GEN")
+	assert.Error(t, err)
+	_, err = client.StartGenerator(ctx, "R331GEN")
+	assert.Error(t, err)
+	_, err = client.StopGenerator(ctx, "HW51GEN")
+	assert.Error(t, err)
+	assert.Len(t, transport.requests, 2)
+}
+
+func TestGeneratorAutoStart(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	assert.NoError(t, client.SetGeneratorAutoStart(ctx, "R331GEN", 20, 80))
+	assert.NoError(t, client.SetGeneratorAutoStart(ctx, "DCABZGEN", 15, 90))
+	if assert.Len(t, transport.requests, 4) {
+		assert.Equal(t, ModuleTypeBms, transport.requests[0].ModuleType)
+		assert.Equal(t, "openOilSoc", transport.requests[0].OperateType)
+		assert.Equal(t, float64(20), transport.requests[0].Params["openOilSoc"])
+		assert.Equal(t, "closeOilSoc", transport.requests[1].OperateType)
+		assert.Equal(t, float64(80), transport.requests[1].Params["closeOilSoc"])
+		assert.Equal(t, float64(52), transport.requests[2].Params["id"])
+		assert.Equal(t, float64(15), transport.requests[2].Params["openOilSoc"])
+		assert.Equal(t, float64(53), transport.requests[3].Params["id"])
+		assert.Equal(t, float64(90), transport.requests[3].Params["closeOilSoc"])
+	}
+
+	var verr *ValidationError
+	assert.ErrorAs(t, client.SetGeneratorAutoStart(ctx, "R331GEN", 5, 80), &verr)
+	assert.ErrorAs(t, client.SetGeneratorAutoStart(ctx, "R331GEN", 20, 40), &verr)
+	assert.Error(t, client.SetGeneratorAutoStart(ctx, "HW51GEN", 20, 80))
+	assert.Len(t, transport.requests, 4)
+}
@@ -0,0 +1,180 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownGroup no device group with the given name defined
+var ErrUnknownGroup = errors.New("unknown device group")
+
+// BulkParallelism maximal number of devices a bulk command is sent to in parallel
+var BulkParallelism = 4
+
+var groupLock sync.RWMutex
+var deviceGroups = make(map[string][]string)
+
+// SetDeviceGroup define a named group of devices like "balcony" or "garage",
+// members are serial numbers or aliases. No members removes the group.
+func SetDeviceGroup(name string, members ...string) {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	if len(members) == 0 {
+		delete(deviceGroups, name)
+		return
+	}
+	deviceGroups[name] = append([]string(nil), members...)
+}
+
+// SetDeviceGroups replace all device groups
+func SetDeviceGroups(groups map[string][]string) {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	deviceGroups = make(map[string][]string, len(groups))
+	for name, members := range groups {
+		if len(members) > 0 {
+			deviceGroups[name] = append([]string(nil), members...)
+		}
+	}
+}
+
+// DeviceGroup return the serial numbers of the group, aliases are resolved
+func DeviceGroup(name string) ([]string, bool) {
+	groupLock.RLock()
+	members, ok := deviceGroups[name]
+	groupLock.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	serialNumbers := make([]string, 0, len(members))
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		sn := strings.ToUpper(ResolveDevice(m))
+		if !seen[sn] {
+			seen[sn] = true
+			serialNumbers = append(serialNumbers, sn)
+		}
+	}
+	return serialNumbers, true
+}
+
+// DeviceGroups return all defined group names in sorted order
+func DeviceGroups() []string {
+	groupLock.RLock()
+	defer groupLock.RUnlock()
+	names := make([]string, 0, len(deviceGroups))
+	for name := range deviceGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BulkCommand command executed for one device of a bulk operation
+type BulkCommand func(ctx context.Context, serialNumber string) (*CmdSetResponse, error)
+
+// BulkResult result of a bulk operation for one device
+type BulkResult struct {
+	SerialNumber string
+	Response     *CmdSetResponse
+	Err          error
+}
+
+// BulkError partial failure of a bulk operation
+type BulkError struct {
+	Group  string
+	Total  int
+	Failed []BulkResult
+}
+
+func (e *BulkError) Error() string {
+	failed := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		failed = append(failed, DeviceAlias(r.SerialNumber)+": "+r.Err.Error())
+	}
+	return fmt.Sprintf("group %s: %d of %d devices failed: %s", e.Group, len(e.Failed), e.Total,
+		strings.Join(failed, "; "))
+}
+
+// Unwrap return the device errors
+func (e *BulkError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		errs = append(errs, r.Err)
+	}
+	return errs
+}
+
+// SetAll send the command to all devices of the group, the serial number of the
+// request is replaced for each device. See RunAll for results and errors.
+func (c *Client) SetAll(ctx context.Context, group string, req CmdSetRequest) ([]BulkResult, error) {
+	return RunAll(ctx, group, func(ctx context.Context, serialNumber string) (*CmdSetResponse, error) {
+		r := req
+		r.Sn = serialNumber
+		r.Params = make(map[string]interface{}, len(req.Params))
+		for k, v := range req.Params {
+			r.Params[k] = v
+		}
+		return c.SetCommand(ctx, r)
+	})
+}
+
+// RunAll execute the command for all devices of the group, at most
+// BulkParallelism devices in parallel. Results are returned in member order,
+// a response with an error code counts as failure. If some devices fail a
+// BulkError is returned together with all results.
+func RunAll(ctx context.Context, group string, command BulkCommand) ([]BulkResult, error) {
+	serialNumbers, ok := DeviceGroup(group)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGroup, group)
+	}
+	parallel := BulkParallelism
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]BulkResult, len(serialNumbers))
+	limit := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, sn := range serialNumbers {
+		results[i].SerialNumber = sn
+		select {
+		case limit <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(r *BulkResult) {
+			defer wg.Done()
+			defer func() { <-limit }()
+			r.Response, r.Err = command(ctx, r.SerialNumber)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	bulkErr := &BulkError{Group: group, Total: len(results)}
+	for _, r := range results {
+		if r.Err != nil {
+			bulkErr.Failed = append(bulkErr.Failed, r)
+		}
+	}
+	if len(bulkErr.Failed) > 0 {
+		return results, bulkErr
+	}
+	return results, nil
+}
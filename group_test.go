@@ -0,0 +1,81 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAllPartialFailure(t *testing.T) {
+	SetDeviceAliases(map[string]string{"HW52PLUG1": "lamp"})
+	SetDeviceGroups(map[string][]string{"balcony": {"lamp", "hw52plug2", "HW52PLUG3", "HW52PLUG1"}})
+	defer SetDeviceAliases(nil)
+	defer SetDeviceGroups(nil)
+	assert.Equal(t, []string{"balcony"}, DeviceGroups())
+	members, ok := DeviceGroup("balcony")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"HW52PLUG1", "HW52PLUG2", "HW52PLUG3"}, members)
+
+	var running, maxRunning int32
+	results, err := RunAll(context.Background(), "balcony", func(ctx context.Context, sn string) (*CmdSetResponse, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		switch sn {
+		case "HW52PLUG2":
+			return nil, errors.New("offline")
+		case "HW52PLUG3":
+			return &CmdSetResponse{Code: "1006", Message: "device not online"},
+				&APIError{Code: "1006", Message: "device not online"}
+		}
+		return &CmdSetResponse{Code: "0"}, nil
+	})
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, "offline")
+	assert.ErrorIs(t, results[2].Err, ErrCommandRejected)
+	var bulkErr *BulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Equal(t, 3, bulkErr.Total)
+		assert.Len(t, bulkErr.Failed, 2)
+		assert.Contains(t, err.Error(), "2 of 3 devices failed")
+	}
+	assert.LessOrEqual(t, maxRunning, int32(BulkParallelism))
+
+	_, err = RunAll(context.Background(), "garage", nil)
+	assert.ErrorIs(t, err, ErrUnknownGroup)
+}
+
+func TestSetAllCommand(t *testing.T) {
+	SetDeviceGroup("garage", "HW52PLUG1", "HW52PLUG2")
+	defer SetDeviceGroup("garage")
+	transport := newPowerStreamAPI(make(map[string]interface{}))
+	client := newTestClient(t, transport)
+
+	results, err := client.SetAll(context.Background(), "garage", CmdSetRequest{CmdCode: "WN511_SOCKET_SET_PLUG_SWITCH_MESSAGE",
+		Params: map[string]interface{}{"plugSwitch": 1}})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, transport.puts())
+}
@@ -0,0 +1,151 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// revertTimeout timeout of reverting the snapshot
+const revertTimeout = time.Minute
+
+// Guard snapshot of device settings taken before automated changes. The snapshot
+// is reverted if the change is not committed, e.g. verification failed, the
+// controller panics or the lease expired without renewal.
+type Guard struct {
+	mu           sync.Mutex
+	client       *Client
+	serialNumber string
+	snapshot     map[string]float64
+	lease        time.Duration
+	timer        *clockTimer
+	finished     bool
+}
+
+// NewGuard snapshot the given settings of the device. If lease is not zero, the
+// snapshot is reverted if the guard is not renewed or committed within the lease.
+func (client *Client) NewGuard(ctx context.Context, serialNumber string, names []string, lease time.Duration) (*Guard, error) {
+	g := &Guard{client: client, serialNumber: serialNumber, lease: lease,
+		snapshot: make(map[string]float64)}
+	for _, name := range names {
+		value, err := client.ReadSetting(ctx, serialNumber, name)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot of %s failed: %w", name, err)
+		}
+		g.snapshot[name] = value
+	}
+	if lease > 0 {
+		g.timer = afterFunc(lease, func() {
+			services.ServerMessage("Ecoflow: guard lease of %s expired, reverting settings", serialNumber)
+			g.revertOnce()
+		})
+	}
+	return g, nil
+}
+
+// Snapshot return copy of the snapshot values
+func (g *Guard) Snapshot() map[string]float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	snapshot := make(map[string]float64, len(g.snapshot))
+	for k, v := range g.snapshot {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// Renew extend the lease of the guard
+func (g *Guard) Renew() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.timer != nil && !g.finished {
+		g.timer.Reset(g.lease)
+	}
+}
+
+// Commit keep the changed settings
+func (g *Guard) Commit() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.finish()
+}
+
+func (g *Guard) finish() bool {
+	if g.finished {
+		return false
+	}
+	g.finished = true
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	return true
+}
+
+// Verify revert the snapshot if the verification error is not nil
+func (g *Guard) Verify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if revertErr := g.revertOnce(); revertErr != nil {
+		return fmt.Errorf("%w (revert failed: %v)", err, revertErr)
+	}
+	return err
+}
+
+// Close revert the snapshot if not committed. Use it deferred, a panic of the
+// controller is reverted and passed through.
+func (g *Guard) Close() {
+	r := recover()
+	if err := g.revertOnce(); err != nil {
+		log.Log.Errorf("Error reverting settings of %s: %v", g.serialNumber, err)
+	}
+	if r != nil {
+		panic(r)
+	}
+}
+
+func (g *Guard) revertOnce() error {
+	g.mu.Lock()
+	if !g.finish() {
+		g.mu.Unlock()
+		return nil
+	}
+	g.mu.Unlock()
+	return g.Revert()
+}
+
+// Revert write all snapshot values back to the device
+func (g *Guard) Revert() error {
+	ctx, cancel := context.WithTimeout(WithActor(context.Background(), "guard"), revertTimeout)
+	defer cancel()
+	names := make([]string, 0, len(g.snapshot))
+	for name := range g.snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lastErr error
+	for _, name := range names {
+		_, err := g.client.ApplySetting(ctx, g.serialNumber, name, g.snapshot[name])
+		if err != nil {
+			log.Log.Errorf("Error reverting %s of %s: %v", name, g.serialNumber, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,82 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newGuardTest(t *testing.T, lease time.Duration) (*Client, *fakeAPI, *Guard) {
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0, "20_1.lowerLimit": 10.0})
+	client := newTestClient(t, transport)
+	g, err := client.NewGuard(context.Background(), "HW51GUARD", []string{"permanentWatts", "lowerLimit"}, lease)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, map[string]float64{"permanentWatts": 100, "lowerLimit": 10}, g.Snapshot())
+	_, err = client.ApplySetting(context.Background(), "HW51GUARD", "permanentWatts", 300)
+	assert.NoError(t, err)
+	return client, transport, g
+}
+
+func TestGuardClose(t *testing.T) {
+	_, transport, g := newGuardTest(t, 0)
+	assert.Equal(t, 3000.0, transport.value("20_1.permanentWatts"))
+	g.Close()
+	assert.Equal(t, 1000.0, transport.value("20_1.permanentWatts"))
+	assert.Equal(t, 3, transport.puts())
+
+	// closing twice does not revert again
+	g.Close()
+	assert.Equal(t, 3, transport.puts())
+}
+
+func TestGuardCommit(t *testing.T) {
+	_, transport, g := newGuardTest(t, 0)
+	g.Commit()
+	g.Close()
+	assert.Equal(t, 3000.0, transport.value("20_1.permanentWatts"))
+	assert.Equal(t, 1, transport.puts())
+}
+
+func TestGuardPanic(t *testing.T) {
+	_, transport, g := newGuardTest(t, 0)
+	assert.PanicsWithValue(t, "controller failed", func() {
+		defer g.Close()
+		panic("controller failed")
+	})
+	assert.Equal(t, 1000.0, transport.value("20_1.permanentWatts"))
+}
+
+func TestGuardVerify(t *testing.T) {
+	_, transport, g := newGuardTest(t, 0)
+	assert.NoError(t, g.Verify(nil))
+	assert.Equal(t, 3000.0, transport.value("20_1.permanentWatts"))
+	errVerify := errors.New("value not applied")
+	assert.ErrorIs(t, g.Verify(errVerify), errVerify)
+	assert.Equal(t, 1000.0, transport.value("20_1.permanentWatts"))
+}
+
+func TestGuardLease(t *testing.T) {
+	_, transport, g := newGuardTest(t, 20*time.Millisecond)
+	g.Renew()
+	assert.Eventually(t, func() bool {
+		return transport.value("20_1.permanentWatts") == 1000.0
+	}, time.Second, 5*time.Millisecond)
+	g.Close()
+	assert.Equal(t, 1000.0, transport.value("20_1.permanentWatts"))
+}
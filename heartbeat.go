@@ -0,0 +1,139 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultHeartbeatInterval expected interval between two MQTT messages of a device
+var DefaultHeartbeatInterval = 10 * time.Second
+
+// heartbeatTolerance factor of the expected interval a gap need to exceed
+// to be counted as missed interval
+const heartbeatTolerance = 1.5
+
+var expectedIntervals = make(map[string]time.Duration)
+
+// HeartbeatStatistics data-quality statistics of received device messages
+type HeartbeatStatistics struct {
+	SerialNumber     string        `json:"serialNumber"`
+	Messages         uint64        `json:"messages"`
+	ExpectedInterval time.Duration `json:"expectedInterval"`
+	FirstReceived    time.Time     `json:"firstReceived"`
+	LastReceived     time.Time     `json:"lastReceived"`
+	Gaps             uint64        `json:"gaps"`
+	MissedIntervals  uint64        `json:"missedIntervals"`
+	LongestGap       time.Duration `json:"longestGap"`
+	Jitter           time.Duration `json:"jitter"`
+	// Duplicates frames suppressed because the sequence number was received before
+	Duplicates uint64 `json:"duplicates"`
+	// OutOfOrder frames received with a sequence number older than the last one
+	OutOfOrder uint64 `json:"outOfOrder"`
+}
+
+// SetExpectedInterval set the expected heartbeat interval of a specific device
+func SetExpectedInterval(serialNumber string, interval time.Duration) {
+	statLock.Lock()
+	defer statLock.Unlock()
+	expectedIntervals[serialNumber] = interval
+}
+
+func expectedInterval(serialNumber string) time.Duration {
+	statLock.Lock()
+	defer statLock.Unlock()
+	if d, ok := expectedIntervals[serialNumber]; ok && d > 0 {
+		return d
+	}
+	return DefaultHeartbeatInterval
+}
+
+// recordHeartbeat update gap statistics with a message received at given time,
+// the caller need to hold the statistic lock
+func (stat *statMqtt) recordHeartbeat(expected time.Duration, received time.Time) {
+	stat.expected = expected
+	if stat.firstReceived.IsZero() {
+		stat.firstReceived = received
+		stat.lastReceived = received
+		return
+	}
+	gap := received.Sub(stat.lastReceived)
+	if gap < 0 {
+		return
+	}
+	stat.lastReceived = received
+	if gap > stat.longestGap {
+		stat.longestGap = gap
+	}
+	if expected <= 0 {
+		return
+	}
+	if float64(gap) > float64(expected)*heartbeatTolerance {
+		stat.gaps++
+		stat.missedIntervals += uint64((gap+expected/2)/expected) - 1
+		return
+	}
+	// interarrival jitter smoothed as described in RFC 3550
+	deviation := gap - expected
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	stat.jitter += (deviation - stat.jitter) / 16
+}
+
+func (stat *statMqtt) heartbeatStatistics(serialNumber string) HeartbeatStatistics {
+	return HeartbeatStatistics{
+		SerialNumber:     serialNumber,
+		Messages:         stat.mqttCounter,
+		ExpectedInterval: stat.expected,
+		FirstReceived:    stat.firstReceived,
+		LastReceived:     stat.lastReceived,
+		Gaps:             stat.gaps,
+		MissedIntervals:  stat.missedIntervals,
+		LongestGap:       stat.longestGap,
+		Jitter:           stat.jitter,
+	}
+}
+
+// HeartbeatStats return heartbeat gap statistics of a device
+func HeartbeatStats(serialNumber string) (HeartbeatStatistics, bool) {
+	statLock.Lock()
+	stat, ok := mapStatMqtt[serialNumber]
+	statLock.Unlock()
+	if !ok {
+		return HeartbeatStatistics{SerialNumber: serialNumber}, false
+	}
+	stat.mu.Lock()
+	s := stat.heartbeatStatistics(serialNumber)
+	stat.mu.Unlock()
+	s.Duplicates, s.OutOfOrder = dedupCounters(serialNumber)
+	return s, true
+}
+
+// Stats return heartbeat gap statistics of all devices sorted by serial number
+func Stats() []HeartbeatStatistics {
+	statLock.Lock()
+	serialNumbers := make([]string, 0, len(mapStatMqtt))
+	for sn := range mapStatMqtt {
+		serialNumbers = append(serialNumbers, sn)
+	}
+	statLock.Unlock()
+	sort.Strings(serialNumbers)
+	stats := make([]HeartbeatStatistics, 0, len(serialNumbers))
+	for _, sn := range serialNumbers {
+		if s, ok := HeartbeatStats(sn); ok {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
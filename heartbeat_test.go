@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatGaps(t *testing.T) {
+	stat := &statMqtt{}
+	start := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	expected := 10 * time.Second
+	for _, offset := range []time.Duration{0, 10, 20, 31, 40, 80, 90} {
+		stat.mqttCounter++
+		stat.recordHeartbeat(expected, start.Add(offset*time.Second))
+	}
+	s := stat.heartbeatStatistics("HW51TEST")
+	assert.Equal(t, uint64(7), s.Messages)
+	assert.Equal(t, uint64(1), s.Gaps)
+	assert.Equal(t, uint64(3), s.MissedIntervals)
+	assert.Equal(t, 40*time.Second, s.LongestGap)
+	assert.True(t, s.Jitter > 0)
+	assert.Equal(t, start.Add(90*time.Second), s.LastReceived)
+}
+
+func TestHeartbeatStatsInHandler(t *testing.T) {
+	sn := "HW51HEARTBEATLOCK"
+	stats := make(chan HeartbeatStatistics, 1)
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		if len(points) > 0 && points[0].SerialNumber == sn {
+			s, _ := HeartbeatStats(sn)
+			stats <- s
+		}
+	})
+	defer unregister()
+	go MessageHandler(nil, &benchMessage{topic: "/app/device/property/" + sn,
+		payload: []byte(`{"params":{"pd.soc":50}}`)})
+	select {
+	case s := <-stats:
+		assert.Equal(t, uint64(1), s.Messages)
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat statistics blocked in telemetry handler")
+	}
+}
@@ -0,0 +1,253 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// SourceHistory source of telemetry read using the historical data API
+const SourceHistory = "history"
+
+// historyDataPath historical data endpoint of the IoT Open API
+const historyDataPath = "/iot-open/sign/device/quota/data"
+
+// historyTimeLayout time format of the historical data API
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// DefaultExportChunk time range of one historical data request
+const DefaultExportChunk = 24 * time.Hour
+
+// GetHistory read the historical data of the device between begin and end,
+// code selects the data set of the device
+func (c *Client) GetHistory(ctx context.Context, serialNumber string, begin, end time.Time, code string) ([]*Telemetry, error) {
+	params := map[string]interface{}{"beginTime": begin.Format(historyTimeLayout),
+		"endTime": end.Format(historyTimeLayout)}
+	if code != "" {
+		params["code"] = code
+	}
+	request := c.newRequest("POST", ecoflowAPI+historyDataPath,
+		map[string]interface{}{"sn": serialNumber, "params": params})
+	response, err := request.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(response, &jsonData); err != nil {
+		return nil, err
+	}
+	status := codeOf(jsonData["code"])
+	observeCode(status)
+	if !status.OK() {
+		return nil, fmt.Errorf("can't get history of %s, error code %s: %v", serialNumber, status, jsonData["message"])
+	}
+	return parseHistory(serialNumber, jsonData["data"], begin.Location()), nil
+}
+
+// parseHistory normalize the records of the historical data response. Records
+// are either index records {"indexName", "indexValue", "time"} or flat records
+// containing a time field and the values.
+func parseHistory(serialNumber string, data interface{}, location *time.Location) []*Telemetry {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		if list, ok := d["list"]; ok {
+			return parseHistory(serialNumber, list, location)
+		}
+		return parseHistory(serialNumber, []interface{}{d}, location)
+	case []interface{}:
+		points := make([]*Telemetry, 0, len(d))
+		for _, e := range d {
+			record, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ts, ok := historyTime(record, location)
+			if !ok {
+				continue
+			}
+			if name, ok := record["indexName"].(string); ok {
+				points = append(points, &Telemetry{SerialNumber: serialNumber, Source: SourceHistory,
+					Key: name, Value: record["indexValue"], Timestamp: ts})
+				continue
+			}
+			values := make(map[string]interface{}, len(record))
+			for k, v := range record {
+				switch k {
+				case "time", "timestamp", "beginTime":
+				default:
+					values[k] = v
+				}
+			}
+			points = append(points, NormalizeTelemetry(serialNumber, SourceHistory, values, ts)...)
+		}
+		return points
+	default:
+		return nil
+	}
+}
+
+// historyTime timestamp of the record, either formatted or in milliseconds
+func historyTime(record map[string]interface{}, location *time.Location) (time.Time, bool) {
+	for _, name := range []string{"time", "timestamp", "beginTime"} {
+		switch v := record[name].(type) {
+		case string:
+			if t, err := time.ParseInLocation(historyTimeLayout, v, location); err == nil {
+				return t, true
+			}
+		case float64:
+			return time.UnixMilli(int64(v)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ExportCheckpoint exported time range of the devices, used to resume an export
+type ExportCheckpoint struct {
+	Devices map[string]time.Time `json:"devices"`
+}
+
+// ExportSummary result of an export run
+type ExportSummary struct {
+	Devices  int `json:"devices"`
+	Requests int `json:"requests"`
+	Points   int `json:"points"`
+	// Skipped chunks already exported by a previous run
+	Skipped int `json:"skipped"`
+}
+
+// Exporter walk the historical data API across a date range for all devices
+// and write the normalized telemetry into a sink, e.g. the CSVSink or a sink
+// writing columnar files. The
+// progress is saved after every chunk so an interrupted export resumes.
+type Exporter struct {
+	Client *Client
+	// Devices serial numbers, aliases or groups, all devices of the account if empty
+	Devices []string
+	From    time.Time
+	To      time.Time
+	// Chunk time range per request, DefaultExportChunk if 0
+	Chunk time.Duration
+	// Code data set code of the historical data API
+	Code string
+	// Checkpoint file of the exported ranges, the export is not resumable if empty
+	Checkpoint string
+	Sink       Sink
+}
+
+// LoadExportCheckpoint read checkpoint file, a missing file is an empty checkpoint
+func LoadExportCheckpoint(fileName string) (*ExportCheckpoint, error) {
+	checkpoint := &ExportCheckpoint{Devices: make(map[string]time.Time)}
+	if fileName == "" {
+		return checkpoint, nil
+	}
+	data, err := readStoredFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return checkpoint, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid export checkpoint %s: %w", fileName, err)
+	}
+	if checkpoint.Devices == nil {
+		checkpoint.Devices = make(map[string]time.Time)
+	}
+	return checkpoint, nil
+}
+
+// Save write the checkpoint file
+func (c *ExportCheckpoint) Save(fileName string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(fileName, data)
+}
+
+// devices serial numbers of the export
+func (e *Exporter) devices(ctx context.Context) ([]string, error) {
+	if len(e.Devices) > 0 {
+		return expandDevices(e.Devices), nil
+	}
+	list, err := e.Client.CachedDeviceList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]string, 0, len(list.Devices))
+	for _, d := range list.Devices {
+		devices = append(devices, d.SN)
+	}
+	return devices, nil
+}
+
+// Run export the date range of all devices
+func (e *Exporter) Run(ctx context.Context) (ExportSummary, error) {
+	summary := ExportSummary{}
+	if e.Sink == nil {
+		return summary, errors.New("export needs a sink")
+	}
+	if !e.From.Before(e.To) {
+		return summary, fmt.Errorf("invalid export range %s - %s", e.From, e.To)
+	}
+	chunk := e.Chunk
+	if chunk <= 0 {
+		chunk = DefaultExportChunk
+	}
+	checkpoint, err := LoadExportCheckpoint(e.Checkpoint)
+	if err != nil {
+		return summary, err
+	}
+	devices, err := e.devices(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.Devices = len(devices)
+	for _, sn := range devices {
+		for begin := e.From; begin.Before(e.To); begin = begin.Add(chunk) {
+			end := begin.Add(chunk)
+			if end.After(e.To) {
+				end = e.To
+			}
+			if done, ok := checkpoint.Devices[sn]; ok && !end.After(done) {
+				summary.Skipped++
+				continue
+			}
+			points, err := e.Client.GetHistory(ctx, sn, begin, end, e.Code)
+			summary.Requests++
+			if err != nil {
+				return summary, err
+			}
+			if len(points) > 0 {
+				if err := e.Sink.Write(points); err != nil {
+					return summary, err
+				}
+			}
+			summary.Points += len(points)
+			checkpoint.Devices[sn] = end
+			if e.Checkpoint != "" {
+				if err := checkpoint.Save(e.Checkpoint); err != nil {
+					return summary, err
+				}
+			}
+		}
+		services.ServerMessage("Ecoflow: history of %s exported until %s", sn, e.To.Format(historyTimeLayout))
+	}
+	return summary, nil
+}
@@ -0,0 +1,89 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// historyAPI fake historical data API returning one record per request, the
+// request failAt fails
+func historyAPI(requests *[]string, failAt int) *fakeAPI {
+	f := newFakeAPI(nil)
+	f.handle = func(r *http.Request) (interface{}, error) {
+		var req struct {
+			SN     string            `json:"sn"`
+			Params map[string]string `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		*requests = append(*requests, req.SN+" "+req.Params["beginTime"])
+		if len(*requests) == failAt {
+			return nil, errors.New("connection reset")
+		}
+		return map[string]interface{}{"code": "0", "data": map[string]interface{}{"list": []interface{}{
+			map[string]interface{}{"indexName": "solarEnergy", "indexValue": 1240.0, "time": req.Params["beginTime"]},
+			map[string]interface{}{"time": req.Params["beginTime"], "pd": map[string]interface{}{"soc": 80.0}},
+		}}}, nil
+	}
+	return f
+}
+
+func TestExporterResume(t *testing.T) {
+	dir := t.TempDir()
+	var requests []string
+	client := newTestClient(t, historyAPI(&requests, 2))
+	sink, err := NewCSVSink(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	exporter := &Exporter{Client: client, Devices: []string{"R331HIST"}, Sink: sink,
+		From:       time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		Checkpoint: filepath.Join(dir, "checkpoint.json")}
+
+	summary, err := exporter.Run(context.Background())
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, 2, summary.Points)
+
+	summary, err = exporter.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ExportSummary{Devices: 1, Requests: 2, Points: 4, Skipped: 1}, summary)
+	assert.Equal(t, []string{"R331HIST 2025-03-01 00:00:00", "R331HIST 2025-03-02 00:00:00",
+		"R331HIST 2025-03-02 00:00:00", "R331HIST 2025-03-03 00:00:00"}, requests)
+	assert.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, csvRawDir, "2025-03-02.csv"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "timestamp,serial_number,source,key,value\n"+
+			"2025-03-02T00:00:00Z,R331HIST,history,solarEnergy,1240\n"+
+			"2025-03-02T00:00:00Z,R331HIST,history,pd.soc,80\n", string(data))
+	}
+	checkpoint, err := LoadExportCheckpoint(exporter.Checkpoint)
+	if assert.NoError(t, err) {
+		assert.Equal(t, exporter.To, checkpoint.Devices["R331HIST"].UTC())
+	}
+	summary, err = exporter.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Skipped)
+	assert.Len(t, requests, 4)
+}
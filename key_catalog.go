@@ -0,0 +1,222 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"math"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Unit unit of a telemetry value after scaling
+type Unit string
+
+// Units of the key catalog
+const (
+	UnitNone    Unit = ""
+	UnitWatt    Unit = "W"
+	UnitVolt    Unit = "V"
+	UnitPercent Unit = "%"
+	UnitCelsius Unit = "°C"
+	UnitMinute  Unit = "min"
+)
+
+// PowerFlow power flow a key contributes to
+type PowerFlow int
+
+const (
+	// FlowNone key is no power flow
+	FlowNone PowerFlow = iota
+	// FlowBattery key contributes to netBatteryPower, positive while discharging
+	FlowBattery
+	// FlowGrid key contributes to netGridPower, positive while importing
+	FlowGrid
+)
+
+// Derived net power keys with consistent polarity: the battery power is
+// positive while discharging, the grid power is positive while importing
+const (
+	KeyNetBatteryPower = "netBatteryPower"
+	KeyNetGridPower    = "netGridPower"
+)
+
+// KeyInfo unit and sign convention of telemetry keys matching the case
+// insensitive glob pattern. Without models the entry applies to all devices.
+type KeyInfo struct {
+	Pattern string
+	Models  []DeviceModel
+	Unit    Unit
+	// Scale factor converting the raw value into the unit, 1 if 0
+	Scale float64
+	// Signed value is a signed 32 bit integer reported unsigned
+	Signed bool
+	Flow   PowerFlow
+	// Polarity sign converting the scaled value into the net power flow
+	Polarity float64
+	// DirectionKey flag of the same message inverting the sign if its value
+	// equals DirectionNegative
+	DirectionKey      string
+	DirectionNegative float64
+}
+
+var (
+	catalogLock sync.RWMutex
+	catalogKeys = []KeyInfo{
+		{Pattern: "*.batinputwatts", Models: []DeviceModel{ModelPowerStream}, Unit: UnitWatt, Scale: 0.1, Signed: true, Flow: FlowBattery, Polarity: 1},
+		{Pattern: "*watts", Models: []DeviceModel{ModelPowerStream, ModelSmartPlug}, Unit: UnitWatt, Scale: 0.1},
+		{Pattern: "*volt", Models: []DeviceModel{ModelPowerStream, ModelSmartPlug}, Unit: UnitVolt, Scale: 0.1},
+		{Pattern: "*.powgetsysgrid", Models: []DeviceModel{ModelStream}, Unit: UnitWatt, Flow: FlowGrid, Polarity: 1},
+		{Pattern: "*.powgetbpcms", Models: []DeviceModel{ModelStream}, Unit: UnitWatt, Flow: FlowBattery, Polarity: -1},
+		{Pattern: "*.powget*", Models: []DeviceModel{ModelStream}, Unit: UnitWatt},
+		{Pattern: "bms_bmsstatus.inputwatts", Unit: UnitWatt, Flow: FlowBattery, Polarity: -1},
+		{Pattern: "bms_bmsstatus.outputwatts", Unit: UnitWatt, Flow: FlowBattery, Polarity: 1},
+		{Pattern: "inv.inputwatts", Unit: UnitWatt, Flow: FlowGrid, Polarity: 1},
+		{Pattern: "*.acinvol", Unit: UnitVolt, Scale: 0.001},
+		{Pattern: "*.vol", Unit: UnitVolt, Scale: 0.001},
+		{Pattern: "*.remaintime", Unit: UnitMinute},
+		{Pattern: "*soc", Unit: UnitPercent},
+		{Pattern: "*watts*", Unit: UnitWatt},
+		{Pattern: "*temp", Unit: UnitCelsius},
+	}
+	catalogCache sync.Map
+)
+
+// RegisterKeyInfo add catalog entry, it takes precedence over the existing entries
+func RegisterKeyInfo(info KeyInfo) {
+	info.Pattern = strings.ToLower(info.Pattern)
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+	catalogKeys = append([]KeyInfo{info}, catalogKeys...)
+	catalogCache.Clear()
+}
+
+// LookupKey return the catalog entry of the telemetry key of the device, the
+// first matching entry is used
+func LookupKey(serialNumber, key string) (KeyInfo, bool) {
+	model := DetectModel(serialNumber)
+	cacheKey := model.String() + "|" + key
+	if v, ok := catalogCache.Load(cacheKey); ok {
+		info, found := v.(*KeyInfo)
+		if !found || info == nil {
+			return KeyInfo{}, false
+		}
+		return *info, true
+	}
+	lower := strings.ToLower(key)
+	var found *KeyInfo
+	catalogLock.RLock()
+	for i := range catalogKeys {
+		info := catalogKeys[i]
+		if len(info.Models) > 0 && !modelIn(model, info.Models) {
+			continue
+		}
+		if ok, _ := path.Match(info.Pattern, lower); ok {
+			found = &info
+			break
+		}
+	}
+	catalogLock.RUnlock()
+	catalogCache.Store(cacheKey, found)
+	if found == nil {
+		return KeyInfo{}, false
+	}
+	return *found, true
+}
+
+func modelIn(model DeviceModel, models []DeviceModel) bool {
+	for _, m := range models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Value convert the raw value into the unit
+func (k KeyInfo) Value(raw float64) float64 {
+	if k.Scale == 0 {
+		return raw
+	}
+	return raw * k.Scale
+}
+
+// signedValue reinterpret unsigned 32 bit values of signed keys
+func signedValue(serialNumber, key string, value interface{}) interface{} {
+	f, ok := value.(float64)
+	if !ok || f <= math.MaxInt32 || f > math.MaxUint32 {
+		return value
+	}
+	if info, ok := LookupKey(serialNumber, key); ok && info.Signed {
+		return float64(int32(uint32(f)))
+	}
+	return value
+}
+
+// DerivePowerFlow derive netBatteryPower and netGridPower of each device from
+// the power flow keys of the message
+func DerivePowerFlow(points []*Telemetry) []*Telemetry {
+	type net struct {
+		template *Telemetry
+		values   map[PowerFlow]float64
+	}
+	var devices map[string]*net
+	var order []string
+	for _, p := range points {
+		value, ok := p.Value.(float64)
+		if !ok {
+			continue
+		}
+		info, ok := LookupKey(p.SerialNumber, p.Key)
+		if !ok || info.Flow == FlowNone {
+			continue
+		}
+		value = info.Value(value) * info.Polarity
+		if info.DirectionKey != "" && directionNegative(points, p.SerialNumber, info) {
+			value = -value
+		}
+		if devices == nil {
+			devices = make(map[string]*net)
+		}
+		d, ok := devices[p.SerialNumber]
+		if !ok {
+			d = &net{template: p, values: make(map[PowerFlow]float64)}
+			devices[p.SerialNumber] = d
+			order = append(order, p.SerialNumber)
+		}
+		d.values[info.Flow] += value
+	}
+	derived := make([]*Telemetry, 0, 2*len(order))
+	for _, sn := range order {
+		d := devices[sn]
+		for _, f := range []struct {
+			flow PowerFlow
+			key  string
+		}{{FlowBattery, KeyNetBatteryPower}, {FlowGrid, KeyNetGridPower}} {
+			if v, ok := d.values[f.flow]; ok {
+				derived = append(derived, &Telemetry{SerialNumber: sn, Source: d.template.Source, Key: f.key,
+					Value: math.Round(v*10) / 10, Timestamp: d.template.Timestamp})
+			}
+		}
+	}
+	return derived
+}
+
+func directionNegative(points []*Telemetry, serialNumber string, info KeyInfo) bool {
+	for _, p := range points {
+		if p.SerialNumber == serialNumber && strings.EqualFold(p.Key, info.DirectionKey) {
+			v, ok := p.Value.(float64)
+			return ok && v == info.DirectionNegative
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCatalogSign(t *testing.T) {
+	info, ok := LookupKey("HW51SIGN", "inverterHeartbeat.batInputWatts")
+	assert.True(t, ok)
+	assert.Equal(t, UnitWatt, info.Unit)
+	assert.Equal(t, 12.5, info.Value(125))
+	info, ok = LookupKey("R331SIGN", "pd.soc")
+	assert.True(t, ok)
+	assert.Equal(t, UnitPercent, info.Unit)
+	_, ok = LookupKey("R331SIGN", "pd.beepMode")
+	assert.False(t, ok)
+
+	// unsigned 32 bit encoding of -150 W (0.1 W) keeps the sign
+	points := NormalizeTelemetry("HW51SIGN", SourceHttp, map[string]interface{}{
+		"20_1.batInputWatts": float64(uint32(0xFFFFFFFF - 1499)), "20_1.ratedPower": float64(uint32(0xFFFFFFFF - 1499))}, time.Now())
+	assert.Equal(t, -1500.0, points[0].Value)
+	assert.Equal(t, 4294965796.0, points[1].Value)
+
+	derived := DerivePowerFlow(points)
+	if assert.Len(t, derived, 1) {
+		assert.Equal(t, KeyNetBatteryPower, derived[0].Key)
+		assert.Equal(t, -150.0, derived[0].Value)
+	}
+}
+
+func TestDerivePowerFlow(t *testing.T) {
+	derived := DerivePowerFlow([]*Telemetry{
+		{SerialNumber: "R331NET", Key: "bms_bmsStatus.inputWatts", Value: 0.0},
+		{SerialNumber: "R331NET", Key: "bms_bmsStatus.outputWatts", Value: 320.0},
+		{SerialNumber: "R331NET", Key: "inv.inputWatts", Value: 0.0},
+		{SerialNumber: "BK11NET", Key: "streamDisplayPropertyUpload.powGetBpCms", Value: 450.0},
+		{SerialNumber: "BK11NET", Key: "streamDisplayPropertyUpload.powGetSysGrid", Value: -120.0},
+	})
+	assert.Equal(t, []*Telemetry{
+		{SerialNumber: "R331NET", Key: KeyNetBatteryPower, Value: 320.0},
+		{SerialNumber: "R331NET", Key: KeyNetGridPower, Value: 0.0},
+		{SerialNumber: "BK11NET", Key: KeyNetBatteryPower, Value: -450.0},
+		{SerialNumber: "BK11NET", Key: KeyNetGridPower, Value: -120.0},
+	}, derived)
+
+	RegisterKeyInfo(KeyInfo{Pattern: "test.batWatts", Models: []DeviceModel{ModelRiver2}, Unit: UnitWatt,
+		Flow: FlowBattery, Polarity: 1, DirectionKey: "test.charging", DirectionNegative: 1})
+	derived = DerivePowerFlow([]*Telemetry{
+		{SerialNumber: "R621NET", Key: "test.batWatts", Value: 80.0},
+		{SerialNumber: "R621NET", Key: "test.charging", Value: 1.0},
+	})
+	if assert.Len(t, derived, 1) {
+		assert.Equal(t, -80.0, derived[0].Value)
+	}
+
+	var received []*Telemetry
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) { received = points })
+	defer unregister()
+	DispatchTelemetry([]*Telemetry{{SerialNumber: "R331NET", Key: "inv.inputWatts", Value: 500.0}})
+	if assert.Len(t, received, 2) {
+		assert.Equal(t, KeyNetGridPower, received[1].Key)
+		assert.Equal(t, 500.0, received[1].Value)
+	}
+}
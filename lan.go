@@ -0,0 +1,398 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// Experimental local network access. The frame layout is
+//
+//	0xAA | version | length (uint16 LE) | crc8 of the first four bytes |
+//	payload (protobuf SendHeaderMsg) | crc16 of all previous bytes (LE)
+
+const (
+	lanFrameMagic      = 0xAA
+	lanFrameVersion    = 0x03
+	lanFrameHeaderSize = 5
+	lanMaxPayload      = 0xFFFF
+	// DefaultLanPort default TCP/UDP port of the local device protocol
+	DefaultLanPort = 8055
+)
+
+// ErrLanFrame invalid local network frame
+var ErrLanFrame = errors.New("invalid LAN frame")
+
+// EncodeLanFrame encode header message into a local network frame
+func EncodeLanFrame(header *Header) ([]byte, error) {
+	payload, err := proto.Marshal(&SendHeaderMsg{Msg: header})
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > lanMaxPayload {
+		return nil, fmt.Errorf("%w: payload too large (%d bytes)", ErrLanFrame, len(payload))
+	}
+	frame := make([]byte, lanFrameHeaderSize, lanFrameHeaderSize+len(payload)+2)
+	frame[0] = lanFrameMagic
+	frame[1] = lanFrameVersion
+	binary.LittleEndian.PutUint16(frame[2:4], uint16(len(payload)))
+	frame[4] = crc8(frame[:4])
+	frame = append(frame, payload...)
+	frame = binary.LittleEndian.AppendUint16(frame, crc16(frame))
+	return frame, nil
+}
+
+// ReadLanFrame read next local network frame and return the contained protobuf payload
+func ReadLanFrame(r io.Reader) ([]byte, error) {
+	head := make([]byte, lanFrameHeaderSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if head[0] != lanFrameMagic {
+		return nil, fmt.Errorf("%w: magic %02x", ErrLanFrame, head[0])
+	}
+	if crc8(head[:4]) != head[4] {
+		return nil, fmt.Errorf("%w: header checksum mismatch", ErrLanFrame)
+	}
+	length := int(binary.LittleEndian.Uint16(head[2:4]))
+	rest := make([]byte, length+2)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	frame := append(head, rest[:length]...)
+	if crc16(frame) != binary.LittleEndian.Uint16(rest[length:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrLanFrame)
+	}
+	return rest[:length], nil
+}
+
+// readLanFrame read next valid local network frame. Invalid bytes are skipped
+// until the next magic byte starting a valid frame and returned as skipped.
+func readLanFrame(r *bufio.Reader) (payload, skipped []byte, err error) {
+	skip := func() {
+		b, _ := r.ReadByte()
+		skipped = append(skipped, b)
+	}
+	for {
+		head, err := r.Peek(lanFrameHeaderSize)
+		if err != nil {
+			return nil, skipped, err
+		}
+		if head[0] != lanFrameMagic || crc8(head[:4]) != head[4] {
+			skip()
+			continue
+		}
+		length := int(binary.LittleEndian.Uint16(head[2:4]))
+		frame, err := r.Peek(lanFrameHeaderSize + length + 2)
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				skip()
+				continue
+			}
+			return nil, skipped, err
+		}
+		end := lanFrameHeaderSize + length
+		if crc16(frame[:end]) != binary.LittleEndian.Uint16(frame[end:]) {
+			skip()
+			continue
+		}
+		payload = append([]byte(nil), frame[lanFrameHeaderSize:end]...)
+		_, err = r.Discard(len(frame))
+		return payload, skipped, err
+	}
+}
+
+// DecodeLanFrame decode header message of a local network frame
+func DecodeLanFrame(r io.Reader) (*Header, error) {
+	payload, err := ReadLanFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := &SendHeaderMsg{}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	return msg.Msg, nil
+}
+
+// crc8 CRC-8 with polynomial 0x07
+func crc8(data []byte) byte {
+	crc := byte(0)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// crc16 CRC-16/ARC
+func crc16(data []byte) uint16 {
+	crc := uint16(0)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// LanTransport experimental local network connection to a device
+type LanTransport struct {
+	SerialNumber string
+	Network      string
+	Address      string
+	DialTimeout  time.Duration
+	mu           sync.Mutex
+	conn         net.Conn
+	seq          int32
+}
+
+// localTransport cloud independent transport used for commands
+type localTransport interface {
+	SendMessage(ctx context.Context, cmdFunc, cmdId int32, msg proto.Message) error
+	Close() error
+}
+
+var localLock sync.RWMutex
+var localDevices = make(map[string]localTransport)
+
+// NewLanTransport create local network transport, network is "tcp" or "udp".
+// If the address contains no port the DefaultLanPort is used.
+func NewLanTransport(serialNumber, network, address string) *LanTransport {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, fmt.Sprint(DefaultLanPort))
+	}
+	return &LanTransport{SerialNumber: serialNumber, Network: network, Address: address,
+		DialTimeout: 5 * time.Second}
+}
+
+// UseLan select local network access for the device
+func UseLan(t *LanTransport) {
+	useLocal(t.SerialNumber, t)
+}
+
+func useLocal(serialNumber string, t localTransport) {
+	localLock.Lock()
+	defer localLock.Unlock()
+	localDevices[strings.ToUpper(serialNumber)] = t
+}
+
+// DisableLan switch the device back to cloud access, closing any selected
+// LAN or BLE transport
+func DisableLan(serialNumber string) {
+	localLock.Lock()
+	defer localLock.Unlock()
+	sn := strings.ToUpper(serialNumber)
+	if t, ok := localDevices[sn]; ok {
+		t.Close()
+		delete(localDevices, sn)
+	}
+}
+
+func localTransportFor(serialNumber string) (localTransport, bool) {
+	localLock.RLock()
+	defer localLock.RUnlock()
+	t, ok := localDevices[strings.ToUpper(serialNumber)]
+	return t, ok
+}
+
+// LanTransportFor return local network transport selected for the device
+func LanTransportFor(serialNumber string) (*LanTransport, bool) {
+	t, ok := localTransportFor(serialNumber)
+	if !ok {
+		return nil, false
+	}
+	lan, ok := t.(*LanTransport)
+	return lan, ok
+}
+
+func (t *LanTransport) connect(ctx context.Context) (net.Conn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn != nil {
+		return t.conn, nil
+	}
+	dialer := &net.Dialer{Timeout: t.DialTimeout}
+	conn, err := dialer.DialContext(ctx, t.Network, t.Address)
+	if err != nil {
+		return nil, err
+	}
+	t.conn = conn
+	return conn, nil
+}
+
+// Send send header message to the device
+func (t *LanTransport) Send(ctx context.Context, header *Header) error {
+	conn, err := t.connect(ctx)
+	if err != nil {
+		return notSent(err)
+	}
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+	if header.Seq == nil {
+		header.Seq = &seq
+	}
+	if header.DeviceSn == nil {
+		header.DeviceSn = &t.SerialNumber
+	}
+	frame, err := EncodeLanFrame(header)
+	if err != nil {
+		return notSent(err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+		defer conn.SetWriteDeadline(time.Time{})
+	}
+	_, err = conn.Write(frame)
+	if err != nil {
+		t.Close()
+	}
+	return err
+}
+
+// SendMessage send protobuf message with the given command function and id
+func (t *LanTransport) SendMessage(ctx context.Context, cmdFunc, cmdId int32, msg proto.Message) error {
+	pdata, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	dataLen := int32(len(pdata))
+	return t.Send(ctx, &Header{Pdata: pdata, CmdFunc: &cmdFunc, CmdId: &cmdId, DataLen: &dataLen})
+}
+
+// Run read frames of the device until the context is done, the frames are
+// dispatched like MQTT payloads
+func (t *LanTransport) Run(ctx context.Context) error {
+	conn, err := t.connect(ctx)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { t.Close() })
+	defer stop()
+	reader := bufio.NewReaderSize(conn, lanMaxPayload+lanFrameHeaderSize+2)
+	for {
+		payload, skipped, err := readLanFrame(reader)
+		if len(skipped) > 0 {
+			log.Log.Errorf("LAN %s: %v, skipped %d bytes", t.SerialNumber, ErrLanFrame, len(skipped))
+			recordUndecoded("lan", t.SerialNumber, ErrLanFrame.Error(), skipped)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			t.Close()
+			return err
+		}
+		dispatchLocalPayload(t.SerialNumber, payload)
+	}
+}
+
+// dispatchLocalPayload dispatch payload received without cloud like MQTT payloads
+func dispatchLocalPayload(serialNumber string, payload []byte) {
+	expected := expectedInterval(serialNumber)
+	stat := GetStatEntry(serialNumber)
+	stat.mu.Lock()
+	stat.mqttCounter++
+	stat.recordHeartbeat(expected, now())
+	stat.mu.Unlock()
+	DisplayPayload(serialNumber, payload)
+}
+
+// Close close the connection, it is reopened on the next use
+func (t *LanTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// powerStreamCmdFunc command function of the PowerStream settings
+const powerStreamCmdFunc = 20
+
+// lanCommands PowerStream command codes with the protobuf equivalent used on the
+// local network
+var lanCommands = map[string]struct {
+	cmdId int32
+	param string
+	build func(v float64) proto.Message
+}{
+	cmdCodePermanentWatts: {129, "permanentWatts", func(v float64) proto.Message {
+		w := uint32(v)
+		return &PermanentWattsPack{PermanentWatts: &w}
+	}},
+	cmdCodeSupplyPriority: {130, "supplyPriority", func(v float64) proto.Message {
+		p := uint32(v)
+		return &SupplyPriorityPack{SupplyPriority: &p}
+	}},
+	cmdCodeBatLower: {132, "lowerLimit", func(v float64) proto.Message {
+		l := int32(v)
+		return &BatLowerPack{LowerLimit: &l}
+	}},
+	cmdCodeBatUpper: {133, "upperLimit", func(v float64) proto.Message {
+		u := int32(v)
+		return &BatUpperPack{UpperLimit: &u}
+	}},
+	cmdCodeBrightness: {135, "brightness", func(v float64) proto.Message {
+		b := int32(v)
+		return &BrightnessPack{Brightness: &b}
+	}},
+}
+
+// sendLan send request using the local network or BLE if selected for the device
+// and supported by the command, ok is false if the cloud need to be used
+func sendLan(ctx context.Context, req CmdSetRequest) (response *CmdSetResponse, ok bool, err error) {
+	t, selected := localTransportFor(req.Sn)
+	if !selected {
+		return nil, false, nil
+	}
+	frame, supported, err := commandFrameOf(req)
+	if !supported {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, notSent(err)
+	}
+	err = t.SendMessage(ctx, frame.CmdFunc, frame.CmdId, frame.Message)
+	if err != nil {
+		return nil, true, err
+	}
+	return &CmdSetResponse{Code: CodeSuccess, Message: "sent locally", Unconfirmed: true}, true, nil
+}
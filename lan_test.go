@@ -0,0 +1,143 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLanFrame(t *testing.T) {
+	cmdId := int32(1)
+	sn := "HW51TEST"
+	frame, err := EncodeLanFrame(&Header{CmdId: &cmdId, DeviceSn: &sn, Pdata: []byte{1, 2, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, byte(lanFrameMagic), frame[0])
+
+	header, err := DecodeLanFrame(bytes.NewReader(frame))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), header.GetCmdId())
+	assert.Equal(t, sn, header.GetDeviceSn())
+	assert.Equal(t, []byte{1, 2, 3}, header.GetPdata())
+
+	frame[len(frame)-3] ^= 0xFF
+	_, err = DecodeLanFrame(bytes.NewReader(frame))
+	assert.True(t, errors.Is(err, ErrLanFrame))
+
+	assert.Equal(t, "192.168.1.20:8055", NewLanTransport(sn, "tcp", "192.168.1.20").Address)
+}
+
+func TestLanCommand(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	received := make(chan *Header, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		header, _ := DecodeLanFrame(conn)
+		received <- header
+	}()
+
+	UseLan(NewLanTransport("HW51LANTEST", "tcp", listener.Addr().String()))
+	defer DisableLan("HW51LANTEST")
+	response, ok, err := sendLan(context.Background(), CmdSetRequest{CmdCode: cmdCodePermanentWatts,
+		Sn: "HW51LANTEST", Params: map[string]interface{}{"permanentWatts": 1500.0}})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeSuccess, response.Code)
+	header := <-received
+	assert.Equal(t, int32(129), header.GetCmdId())
+	assert.Equal(t, int32(powerStreamCmdFunc), header.GetCmdFunc())
+
+	_, ok, _ = sendLan(context.Background(), CmdSetRequest{Sn: "R331TEST"})
+	assert.False(t, ok)
+}
+
+func TestLanFrameResync(t *testing.T) {
+	frame := func(cmdId int32) []byte {
+		f, err := EncodeLanFrame(&Header{CmdId: &cmdId, Pdata: []byte{1, 2, 3}})
+		assert.NoError(t, err)
+		return f
+	}
+	corrupt := frame(2)
+	corrupt[len(corrupt)-3] ^= 0xFF
+	var stream []byte
+	stream = append(stream, frame(1)...)
+	stream = append(stream, 0x01, lanFrameMagic, 0x02)
+	stream = append(stream, corrupt...)
+	stream = append(stream, frame(3)...)
+	reader := bufio.NewReaderSize(bytes.NewReader(stream), lanMaxPayload+lanFrameHeaderSize+2)
+
+	cmdIds := make([]int32, 0)
+	skippedBytes := 0
+	for {
+		payload, skipped, err := readLanFrame(reader)
+		skippedBytes += len(skipped)
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		msg := &SendHeaderMsg{}
+		assert.NoError(t, proto.Unmarshal(payload, msg))
+		cmdIds = append(cmdIds, msg.Msg.GetCmdId())
+	}
+	assert.Equal(t, []int32{1, 3}, cmdIds)
+	assert.Equal(t, 3+len(corrupt), skippedBytes)
+}
+
+func TestLanRunCorruptFrame(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	pdata, _ := proto.Marshal(&InverterHeartbeat{PermanentWatts: proto.Uint32(1000)})
+	valid, err := EncodeLanFrame(&Header{Pdata: pdata, CmdFunc: proto.Int32(20), CmdId: proto.Int32(1)})
+	assert.NoError(t, err)
+	corrupt := append([]byte(nil), valid...)
+	corrupt[lanFrameHeaderSize] ^= 0xFF
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write(append(corrupt, valid...))
+	}()
+
+	sn := "HW51LANRESYNC"
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewLanTransport(sn, "tcp", listener.Addr().String()).Run(ctx) }()
+	assert.Eventually(t, func() bool {
+		stat := GetStatEntry(sn)
+		stat.mu.Lock()
+		defer stat.mu.Unlock()
+		return stat.mqttCounter == 1
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
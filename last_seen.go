@@ -0,0 +1,50 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type lastSeenEntry struct {
+	time   time.Time
+	source string
+}
+
+var lastSeenMap sync.Map
+
+// markSeen record data received from the device using the given source
+func markSeen(serialNumber, source string, t time.Time) {
+	if serialNumber == "" {
+		return
+	}
+	lastSeenMap.Store(strings.ToUpper(serialNumber), lastSeenEntry{time: t, source: source})
+}
+
+// LastSeen return the time and source (SourceMqtt or SourceHttp) of the last
+// data received from the device, zero time if nothing was received
+func LastSeen(serialNumber string) (time.Time, string) {
+	v, ok := lastSeenMap.Load(strings.ToUpper(serialNumber))
+	if !ok {
+		return time.Time{}, ""
+	}
+	e := v.(lastSeenEntry)
+	return e.time, e.source
+}
+
+// Fresh check if data of the device was received within maxAge
+func Fresh(serialNumber string, maxAge time.Duration) bool {
+	t, _ := LastSeen(serialNumber)
+	return !t.IsZero() && since(t) <= maxAge
+}
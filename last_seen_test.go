@@ -0,0 +1,43 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastSeen(t *testing.T) {
+	seen, source := LastSeen("HW51SEEN")
+	assert.True(t, seen.IsZero())
+	assert.Equal(t, "", source)
+	assert.False(t, Fresh("HW51SEEN", time.Minute))
+
+	before := time.Now()
+	MessageHandler(nil, &benchMessage{topic: "/app/device/property/HW51SEEN", payload: []byte(`{"params":{"soc":50}}`)})
+	seen, source = LastSeen("hw51seen")
+	assert.False(t, seen.Before(before))
+	assert.Equal(t, SourceMqtt, source)
+	assert.True(t, Fresh("HW51SEEN", time.Minute))
+
+	client := newTestClient(t, newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 100.0}))
+	_, err := client.GetDeviceAllParameters(context.Background(), "HW51SEEN")
+	assert.NoError(t, err)
+	_, source = LastSeen("HW51SEEN")
+	assert.Equal(t, SourceHttp, source)
+
+	markSeen("HW51SEEN", SourceMqtt, time.Now().Add(-10*time.Minute))
+	assert.False(t, Fresh("HW51SEEN", 5*time.Minute))
+}
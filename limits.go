@@ -0,0 +1,153 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Range allowed value range of a parameter
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// ValidationError parameter value outside of the device limits
+type ValidationError struct {
+	SerialNumber string
+	Parameter    string
+	Value        float64
+	Range        Range
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("value %v of %s for device %s out of range %v:%v", e.Value,
+		e.Parameter, e.SerialNumber, e.Range.Min, e.Range.Max)
+}
+
+// defaultRanges static limits used if the device did not report any limits
+var defaultRanges = map[string]Range{
+	"permanentWatts":       {Min: 0, Max: 800},
+	"lowerLimit":           {Min: 0, Max: 30},
+	"upperLimit":           {Min: 50, Max: 100},
+	"maxChgSoc":            {Min: 50, Max: 100},
+	"minDsgSoc":            {Min: 0, Max: 30},
+	"openOilSoc":           {Min: 10, Max: 30},
+	"closeOilSoc":          {Min: 50, Max: 100},
+	"feedGridModePowLimit": {Min: 0, Max: 800},
+}
+
+// modelRanges static limits depending on the device model, used before the defaults
+var modelRanges = map[string]map[DeviceModel]Range{
+	"acChargeWatts": {
+		ModelDelta2:    {Min: 200, Max: 1200},
+		ModelDelta2Max: {Min: 200, Max: 2400},
+		ModelDeltaMax:  {Min: 200, Max: 2000},
+		ModelDeltaPro:  {Min: 200, Max: 2900},
+		ModelRiver2:    {Min: 100, Max: 360},
+		ModelRiver2Max: {Min: 100, Max: 660},
+		ModelRiver2Pro: {Min: 100, Max: 940},
+	},
+}
+
+// Limits provider of device limits, fed by telemetry reported by the devices
+type Limits struct {
+	mu     sync.RWMutex
+	limits map[string]map[string]Range
+}
+
+// DeviceLimits device limits used by all setters
+var DeviceLimits = NewLimits()
+
+func init() {
+	RegisterTelemetryHandler(DeviceLimits.Update)
+}
+
+// NewLimits create new limits provider
+func NewLimits() *Limits {
+	return &Limits{limits: make(map[string]map[string]Range)}
+}
+
+// Set set limit of a device parameter
+func (l *Limits) Set(serialNumber, parameter string, r Range) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sn := strings.ToUpper(serialNumber)
+	if _, ok := l.limits[sn]; !ok {
+		l.limits[sn] = make(map[string]Range)
+	}
+	l.limits[sn][parameter] = r
+}
+
+// Get return limit of a device parameter, falls back to the static limits
+// of the device model and the static defaults
+func (l *Limits) Get(serialNumber, parameter string) (Range, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if r, ok := l.limits[strings.ToUpper(serialNumber)][parameter]; ok {
+		return r, true
+	}
+	if r, ok := modelRanges[parameter][DetectModel(serialNumber)]; ok {
+		return r, true
+	}
+	r, ok := defaultRanges[parameter]
+	return r, ok
+}
+
+// Validate check value against the device limit of the parameter
+func (l *Limits) Validate(serialNumber, parameter string, value float64) error {
+	r, ok := l.Get(serialNumber, parameter)
+	if !ok {
+		return nil
+	}
+	if value < r.Min || value > r.Max {
+		return &ValidationError{SerialNumber: serialNumber, Parameter: parameter, Value: value, Range: r}
+	}
+	return nil
+}
+
+// Update derive limits of PowerStream inverters from the heartbeat
+func (l *Limits) Update(points []*Telemetry) {
+	for _, p := range points {
+		v, ok := p.Value.(float64)
+		if !ok {
+			continue
+		}
+		switch p.Key {
+		case "inverterHeartbeat.ratedPower":
+			// rated power is reported in 0.1 watt
+			if v > 0 {
+				l.Set(p.SerialNumber, "permanentWatts", Range{Min: 0, Max: v / 10})
+			}
+		case "inverterHeartbeat.upperLimit":
+			// lower battery limit need to stay below the upper limit, the range
+			// is derived from the defaults so a raised limit widens it again
+			r := defaultRanges["lowerLimit"]
+			if v < r.Max {
+				r.Max = v
+			}
+			l.Set(p.SerialNumber, "lowerLimit", r)
+		case "inverterHeartbeat.lowerLimit":
+			r := defaultRanges["upperLimit"]
+			if v > r.Min {
+				r.Min = v
+			}
+			l.Set(p.SerialNumber, "upperLimit", r)
+		case "streamDisplayPropertyUpload.feedGridModePowMax":
+			if v > 0 {
+				l.Set(p.SerialNumber, "feedGridModePowLimit", Range{Min: 0, Max: v})
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitsValidate(t *testing.T) {
+	l := NewLimits()
+	sn := "HW51TEST"
+	assert.NoError(t, l.Validate(sn, "permanentWatts", 800))
+	l.Update([]*Telemetry{{SerialNumber: sn, Key: "inverterHeartbeat.ratedPower", Value: 6000.0},
+		{SerialNumber: sn, Key: "inverterHeartbeat.lowerLimit", Value: 60.0}})
+	err := l.Validate(sn, "permanentWatts", 800)
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, 600.0, validationError.Range.Max)
+	assert.Error(t, l.Validate(sn, "upperLimit", 55))
+	assert.NoError(t, l.Validate(sn, "upperLimit", 90))
+	// a lowered limit widens the range again
+	l.Update([]*Telemetry{{SerialNumber: sn, Key: "inverterHeartbeat.lowerLimit", Value: 20.0}})
+	assert.NoError(t, l.Validate(sn, "upperLimit", 55))
+	l.Update([]*Telemetry{{SerialNumber: sn, Key: "inverterHeartbeat.upperLimit", Value: 15.0}})
+	assert.Error(t, l.Validate(sn, "lowerLimit", 20))
+	l.Update([]*Telemetry{{SerialNumber: sn, Key: "inverterHeartbeat.upperLimit", Value: 90.0}})
+	assert.NoError(t, l.Validate(sn, "lowerLimit", 20))
+	assert.Error(t, l.Validate(sn, "maxChgSoc", 40))
+	assert.NoError(t, l.Validate(sn, "unknown", 4000))
+}
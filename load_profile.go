@@ -0,0 +1,192 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultProfileSlot default time slot length of the load profiles
+const DefaultProfileSlot = 15 * time.Minute
+
+// ProfileSlot averaged values of one time slot of a load profile
+type ProfileSlot struct {
+	Weekday     time.Weekday `json:"weekday"`
+	Start       string       `json:"start"`
+	Consumption float64      `json:"consumption"`
+	Output      float64      `json:"output"`
+	Samples     uint64       `json:"samples"`
+}
+
+type profileAccumulator struct {
+	consumption        float64
+	consumptionSamples uint64
+	output             float64
+	outputSamples      uint64
+}
+
+// LoadProfile record household consumption and inverter output in watts into
+// typical week and day profiles, the values are averaged per weekday and time slot
+type LoadProfile struct {
+	mu             sync.Mutex
+	location       *time.Location
+	slot           time.Duration
+	ConsumptionKey string
+	OutputKey      string
+	// ConsumptionScale and OutputScale convert the telemetry values into watts,
+	// e.g. 0.1 for the PowerStream inverter output
+	ConsumptionScale float64
+	OutputScale      float64
+	slots            [7][]profileAccumulator
+}
+
+// NewLoadProfile create load profile using the telemetry keys for consumption and
+// inverter output evaluated in the given time zone
+func NewLoadProfile(location *time.Location, slot time.Duration, consumptionKey, outputKey string) *LoadProfile {
+	if location == nil {
+		location = time.Local
+	}
+	if slot <= 0 || slot > 24*time.Hour || (24*time.Hour)%slot != 0 {
+		slot = DefaultProfileSlot
+	}
+	p := &LoadProfile{location: location, slot: slot, ConsumptionKey: consumptionKey, OutputKey: outputKey,
+		ConsumptionScale: 1, OutputScale: 1}
+	for d := range p.slots {
+		p.slots[d] = make([]profileAccumulator, 24*time.Hour/slot)
+	}
+	return p
+}
+
+func (p *LoadProfile) slotIndex(t time.Time) (time.Weekday, int) {
+	local := t.In(p.location)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	return local.Weekday(), int(sinceMidnight / p.slot)
+}
+
+// ObserveConsumption add household consumption sample in watts
+func (p *LoadProfile) ObserveConsumption(watts float64, timestamp time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, i := p.slotIndex(timestamp)
+	p.slots[d][i].consumption += watts
+	p.slots[d][i].consumptionSamples++
+}
+
+// ObserveOutput add inverter output sample in watts
+func (p *LoadProfile) ObserveOutput(watts float64, timestamp time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, i := p.slotIndex(timestamp)
+	p.slots[d][i].output += watts
+	p.slots[d][i].outputSamples++
+}
+
+// ObserveTelemetry telemetry handler recording the configured keys
+func (p *LoadProfile) ObserveTelemetry(points []*Telemetry) {
+	for _, t := range points {
+		v, ok := t.Value.(float64)
+		if !ok {
+			continue
+		}
+		switch t.Key {
+		case p.ConsumptionKey:
+			p.ObserveConsumption(v*p.ConsumptionScale, t.Timestamp)
+		case p.OutputKey:
+			p.ObserveOutput(v*p.OutputScale, t.Timestamp)
+		}
+	}
+}
+
+func (p *LoadProfile) slotStart(i int) string {
+	start := time.Duration(i) * p.slot
+	return fmt.Sprintf("%02d:%02d", int(start.Hours()), int(start.Minutes())%60)
+}
+
+func (a profileAccumulator) average() (consumption, output float64) {
+	if a.consumptionSamples > 0 {
+		consumption = a.consumption / float64(a.consumptionSamples)
+	}
+	if a.outputSamples > 0 {
+		output = a.output / float64(a.outputSamples)
+	}
+	return
+}
+
+// Week return typical week profile starting on Sunday
+func (p *LoadProfile) Week() []ProfileSlot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result := make([]ProfileSlot, 0, 7*len(p.slots[0]))
+	for d := range p.slots {
+		for i, a := range p.slots[d] {
+			consumption, output := a.average()
+			result = append(result, ProfileSlot{Weekday: time.Weekday(d), Start: p.slotStart(i),
+				Consumption: consumption, Output: output, Samples: a.consumptionSamples + a.outputSamples})
+		}
+	}
+	return result
+}
+
+// Day return typical day profile averaged over all weekdays
+func (p *LoadProfile) Day() []ProfileSlot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result := make([]ProfileSlot, len(p.slots[0]))
+	for i := range result {
+		var sum profileAccumulator
+		for d := range p.slots {
+			a := p.slots[d][i]
+			sum.consumption += a.consumption
+			sum.consumptionSamples += a.consumptionSamples
+			sum.output += a.output
+			sum.outputSamples += a.outputSamples
+		}
+		consumption, output := sum.average()
+		result[i] = ProfileSlot{Weekday: -1, Start: p.slotStart(i), Consumption: consumption, Output: output,
+			Samples: sum.consumptionSamples + sum.outputSamples}
+	}
+	return result
+}
+
+// WriteProfileJSON export profile slots as JSON
+func WriteProfileJSON(w io.Writer, slots []ProfileSlot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(slots)
+}
+
+// WriteProfileCSV export profile slots as CSV
+func WriteProfileCSV(w io.Writer, slots []ProfileSlot) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"weekday", "start", "consumption", "output", "samples"}); err != nil {
+		return err
+	}
+	for _, s := range slots {
+		weekday := "all"
+		if s.Weekday >= 0 {
+			weekday = s.Weekday.String()
+		}
+		err := cw.Write([]string{weekday, s.Start, fmt.Sprintf("%.1f", s.Consumption),
+			fmt.Sprintf("%.1f", s.Output), fmt.Sprint(s.Samples)})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,52 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfile(t *testing.T) {
+	p := NewLoadProfile(time.UTC, time.Hour, "plug.watts", "inverterHeartbeat.invOutputWatts")
+	p.OutputScale = 0.1
+	monday := time.Date(2025, 4, 7, 8, 10, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+	p.ObserveTelemetry([]*Telemetry{
+		{Key: "plug.watts", Value: 300.0, Timestamp: monday},
+		{Key: "plug.watts", Value: 500.0, Timestamp: monday.Add(20 * time.Minute)},
+		{Key: "inverterHeartbeat.invOutputWatts", Value: 2000.0, Timestamp: monday},
+		{Key: "plug.watts", Value: 100.0, Timestamp: tuesday},
+	})
+
+	week := p.Week()
+	assert.Len(t, week, 7*24)
+	slot := week[int(time.Monday)*24+8]
+	assert.Equal(t, "08:00", slot.Start)
+	assert.Equal(t, 400.0, slot.Consumption)
+	assert.Equal(t, 200.0, slot.Output)
+
+	day := p.Day()
+	assert.Len(t, day, 24)
+	assert.InDelta(t, 300.0, day[8].Consumption, 0.001)
+	assert.Equal(t, uint64(4), day[8].Samples)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteProfileCSV(&buffer, day))
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 25)
+	assert.Equal(t, "all,08:00,300.0,200.0,4", lines[9])
+}
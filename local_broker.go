@@ -0,0 +1,170 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/tknie/log"
+)
+
+// LocalBrokerConfig connection configuration of a local MQTT broker used by the
+// bridge sinks. The broker URL scheme selects the transport: tcp://, ssl:// or
+// tls:// for TLS, ws:// and wss:// for WebSocket.
+type LocalBrokerConfig struct {
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// CAFile PEM file of the broker CA, system pool if empty
+	CAFile string
+	// CertFile and KeyFile PEM files of the TLS client certificate
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	KeepAlive          time.Duration
+	ConnectTimeout     time.Duration
+	// MaxReconnectInterval maximum wait between reconnects, 1 minute if 0
+	MaxReconnectInterval time.Duration
+	OnConnect            mqtt.OnConnectHandler
+	OnConnectionLost     mqtt.ConnectionLostHandler
+}
+
+// usesTLS check if the broker URL needs TLS
+func (c *LocalBrokerConfig) usesTLS() (bool, error) {
+	u, err := url.Parse(c.Broker)
+	if err != nil {
+		return false, fmt.Errorf("invalid local broker %s: %w", c.Broker, err)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ws":
+		return false, nil
+	case "ssl", "tls", "mqtts", "wss":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported local broker scheme %q", u.Scheme)
+	}
+}
+
+// TLSConfig create TLS configuration with optional CA and client certificate
+func (c *LocalBrokerConfig) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify, MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("client certificate needs certificate and key file")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Options create MQTT client options with automatic reconnect
+func (c *LocalBrokerConfig) Options() (*mqtt.ClientOptions, error) {
+	if c.Broker == "" {
+		return nil, errors.New("local broker not configured")
+	}
+	secure, err := c.usesTLS()
+	if err != nil {
+		return nil, err
+	}
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(c.Broker)
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = "ecoflow-bridge-" + uuid.NewString()[:8]
+	}
+	opts.SetClientID(clientID)
+	opts.SetUsername(c.Username)
+	opts.SetPassword(c.Password)
+	if secure || c.CertFile != "" {
+		tlsConfig, err := c.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	if c.KeepAlive > 0 {
+		opts.SetKeepAlive(c.KeepAlive)
+	}
+	if c.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(c.ConnectTimeout)
+	}
+	maxReconnect := c.MaxReconnectInterval
+	if maxReconnect == 0 {
+		maxReconnect = time.Minute
+	}
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	opts.SetMaxReconnectInterval(maxReconnect)
+	opts.SetOnConnectHandler(c.OnConnect)
+	opts.SetConnectionLostHandler(func(client mqtt.Client, err error) {
+		log.Log.Errorf("Local broker %s connection lost: %v", c.Broker, err)
+		if c.OnConnectionLost != nil {
+			c.OnConnectionLost(client, err)
+		}
+	})
+	return opts, nil
+}
+
+// ConnectLocalBroker connect to the local broker, the connection reconnects
+// independently of the EcoFlow cloud connection
+func ConnectLocalBroker(ctx context.Context, config LocalBrokerConfig) (mqtt.Client, error) {
+	opts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		client.Disconnect(0)
+		return nil, ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewLocalRepublishSink connect to the local broker and create a republish sink
+func NewLocalRepublishSink(ctx context.Context, config LocalBrokerConfig, topicTemplate string, retained bool) (*RepublishSink, error) {
+	client, err := ConnectLocalBroker(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return NewRepublishSink(client, topicTemplate, retained), nil
+}
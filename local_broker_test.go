@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBrokerOptions(t *testing.T) {
+	config := LocalBrokerConfig{Broker: "ws://localhost:9001/mqtt", Username: "bridge", Password: "secret"}
+	opts, err := config.Options()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ws", opts.Servers[0].Scheme)
+		assert.Equal(t, "/mqtt", opts.Servers[0].Path)
+		assert.Equal(t, "bridge", opts.Username)
+		assert.True(t, opts.AutoReconnect)
+		assert.Equal(t, time.Minute, opts.MaxReconnectInterval)
+		assert.Nil(t, opts.TLSConfig)
+	}
+
+	config = LocalBrokerConfig{Broker: "wss://localhost:8884", InsecureSkipVerify: true}
+	opts, err = config.Options()
+	if assert.NoError(t, err) {
+		assert.True(t, opts.TLSConfig.InsecureSkipVerify)
+	}
+
+	config = LocalBrokerConfig{Broker: "ssl://localhost:8883", CertFile: "client.pem"}
+	_, err = config.Options()
+	assert.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, []byte("no certificate"), 0600))
+	config = LocalBrokerConfig{Broker: "mqtts://localhost:8883", CAFile: caFile}
+	_, err = config.Options()
+	assert.ErrorContains(t, err, "no certificates found")
+
+	config = LocalBrokerConfig{Broker: "http://localhost"}
+	_, err = config.Options()
+	assert.Error(t, err)
+	_, err = (&LocalBrokerConfig{}).Options()
+	assert.Error(t, err)
+}
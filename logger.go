@@ -22,8 +22,16 @@ import (
 
 var logRus = logrus.StandardLogger()
 
-// StartLog start log storage with given filename
+// StartLog start log storage with given filename, errors are reported as
+// server message
 func StartLog(fileName string) {
+	if err := InitLog(fileName); err != nil {
+		services.ServerMessage("Ecoflow: Error opening log file: %s", err)
+	}
+}
+
+// InitLog start log storage with given filename
+func InitLog(fileName string) error {
 	level := os.Getenv("ENABLE_DEBUG")
 	logLevel := logrus.WarnLevel
 	switch level {
@@ -51,11 +59,11 @@ func StartLog(fileName string) {
 	f, err := os.OpenFile(path,
 		os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
 	if err != nil {
-		services.ServerMessage("Ecoflow: Error opening log file: %s", err)
-		return
+		return err
 	}
 	logRus.SetOutput(f)
 	logRus.Infof("Init logrus")
 	tlog.Log = logRus
 	services.ServerMessage("Ecoflow: Logging initiated with '%v' level...", logRus.Level)
+	return nil
 }
@@ -0,0 +1,76 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"github.com/tknie/ecoflow/decode"
+)
+
+// Protobuf messages of the devices, defined in the decode package
+type (
+	Header                      = decode.Header
+	SendHeaderMsg               = decode.SendHeaderMsg
+	SendMsgHart                 = decode.SendMsgHart
+	PlCmdSets                   = decode.PlCmdSets
+	PlCmdId                     = decode.PlCmdId
+	EnergyItem                  = decode.EnergyItem
+	EnergyTotalReport           = decode.EnergyTotalReport
+	BatchEnergyTotalReport      = decode.BatchEnergyTotalReport
+	EnergyTotalReportAck        = decode.EnergyTotalReportAck
+	EventRecordItem             = decode.EventRecordItem
+	EventRecordReport           = decode.EventRecordReport
+	EventInfoReportAck          = decode.EventInfoReportAck
+	ProductNameSet              = decode.ProductNameSet
+	ProductNameSetAck           = decode.ProductNameSetAck
+	ProductNameGet              = decode.ProductNameGet
+	ProductNameGetAck           = decode.ProductNameGetAck
+	RTCTimeGet                  = decode.RTCTimeGet
+	RTCTimeGetAck               = decode.RTCTimeGetAck
+	RTCTimeSet                  = decode.RTCTimeSet
+	RTCTimeSetAck               = decode.RTCTimeSetAck
+	CountryTownMessage          = decode.CountryTownMessage
+	InverterHeartbeat           = decode.InverterHeartbeat
+	PermanentWattsPack          = decode.PermanentWattsPack
+	SupplyPriorityPack          = decode.SupplyPriorityPack
+	BatLowerPack                = decode.BatLowerPack
+	BatUpperPack                = decode.BatUpperPack
+	BrightnessPack              = decode.BrightnessPack
+	PowerItem                   = decode.PowerItem
+	PowerPack                   = decode.PowerPack
+	PowerAckPack                = decode.PowerAckPack
+	NodeMassage                 = decode.NodeMassage
+	MeshChildNodeInfo           = decode.MeshChildNodeInfo
+	StreamDisplayPropertyUpload = decode.StreamDisplayPropertyUpload
+	StreamRuntimePropertyUpload = decode.StreamRuntimePropertyUpload
+)
+
+// Enum values of the platform command sets and ids
+const (
+	PlCmdSets_PL_NONE_CMD_SETS  = decode.PlCmdSets_PL_NONE_CMD_SETS
+	PlCmdSets_PL_BASIC_CMD_SETS = decode.PlCmdSets_PL_BASIC_CMD_SETS
+	PlCmdSets_PL_EXT_CMD_SETS   = decode.PlCmdSets_PL_EXT_CMD_SETS
+	PlCmdId_PL_CMD_ID_NONE      = decode.PlCmdId_PL_CMD_ID_NONE
+	PlCmdId_PL_CMD_ID_XLOG      = decode.PlCmdId_PL_CMD_ID_XLOG
+	PlCmdId_PL_CMD_ID_WATTH     = decode.PlCmdId_PL_CMD_ID_WATTH
+)
+
+// Enum name maps and file descriptors of the protobuf messages
+var (
+	PlCmdSets_name         = decode.PlCmdSets_name
+	PlCmdSets_value        = decode.PlCmdSets_value
+	PlCmdId_name           = decode.PlCmdId_name
+	PlCmdId_value          = decode.PlCmdId_value
+	File_ecopacket_proto   = decode.File_ecopacket_proto
+	File_platform_proto    = decode.File_platform_proto
+	File_powerstream_proto = decode.File_powerstream_proto
+	File_stream_proto      = decode.File_stream_proto
+)
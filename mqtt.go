@@ -27,23 +27,27 @@ var devices *DeviceListResponse
 
 // InitMqtt initialize MQTT listener
 func InitMqtt(user, password string) error {
+	return InitMqttWithRetry(context.Background(), user, password, nil)
+}
+
+// InitMqttWithRetry initialize MQTT listener, login and connect are retried
+// with backoff if a retry policy is given
+func InitMqttWithRetry(ctx context.Context, user, password string, retry *RetryPolicy) error {
 	configuration := MqttClientConfiguration{
 		Email:            user,
 		Password:         password,
 		OnConnect:        OnConnect,
 		OnConnectionLost: OnConnectionLost,
 		OnReconnect:      OnReconnect,
+		ConnectRetry:     retry,
 	}
-	var err error
-	ecoclient, err = NewMqttClient(context.Background(), configuration)
-	if err != nil {
-		services.ServerMessage("Shuting down ... error creating Ecoflow MQTT client: %v", err)
-		return fmt.Errorf("Error creating newEcoflow MQTT service connection: %v", err)
-	}
-	err = ecoclient.Connect()
+	initSubscriptionState()
+	client, err := ConnectMqttClient(ctx, configuration)
 	if err != nil {
-		return fmt.Errorf("Error connecting to Ecoflow MQTT service connection: %v", err)
+		services.ServerMessage("Error connecting Ecoflow MQTT client: %v", err)
+		return fmt.Errorf("error connecting to Ecoflow MQTT service: %w", err)
 	}
+	ecoclient = client
 	services.ServerMessage("Registered to Ecoflow MQTT service")
 	return nil
 }
@@ -69,15 +73,46 @@ func displayHeader(msg *Header) {
 
 // OnConnect on connect open handler called if connetion is done
 func OnConnect(client mqtt.Client) {
-	for _, d := range devices.Devices {
-		services.ServerMessage("Subscribe for Ecoflow MQTT entries of device %s", d.SN)
-		err := ecoclient.SubscribeForParameters(d.SN, MessageHandler)
+	// the global client is not yet set during the first connect
+	m := &MqttClient{Client: client}
+	subscribed := make(map[string]bool)
+	if devices != nil {
+		for _, d := range devices.Devices {
+			services.ServerMessage("Subscribe for Ecoflow MQTT entries of device %s", d.SN)
+			err := m.SubscribeForParameters(d.SN, MessageHandler)
+			if err != nil {
+				log.Log.Errorf("Unable to subscribe for parameters %s: %v", d.SN, err)
+			} else {
+				log.Log.Infof("Subscribed to receive parameters %s", d.SN)
+			}
+			subscribed[parameterTopic(d.SN)] = true
+			if err := m.SubscribeForStatus(d.SN, StatusMessageHandler); err != nil {
+				log.Log.Errorf("Unable to subscribe for status %s: %v", d.SN, err)
+			}
+			subscribed[statusTopic(d.SN)] = true
+		}
+	}
+	if subscriptionState == nil {
+		return
+	}
+	// resubscribe topics of the previous run not part of current device list
+	for _, t := range subscriptionState.SubscribedTopics() {
+		if subscribed[t] {
+			continue
+		}
+		services.ServerMessage("Resubscribe for Ecoflow MQTT topic %s", t)
+		err := m.SubscribeToTopics([]string{t}, MessageHandler)
 		if err != nil {
-			log.Log.Errorf("Unable to subscribe for parameters %s: %v", d.SN, err)
-		} else {
-			log.Log.Infof("Subscribed to receive parameters %s", d.SN)
+			log.Log.Errorf("Unable to resubscribe for topic %s: %v", t, err)
 		}
 	}
+	if err := subscriptionState.Save(); err != nil {
+		log.Log.Errorf("Error saving subscription state: %v", err)
+	}
+}
+
+func parameterTopic(deviceSn string) string {
+	return fmt.Sprintf("/app/device/property/%s", deviceSn)
 }
 
 func GetTypeName(myvar interface{}) string {
@@ -96,12 +131,13 @@ func OnConnectionLost(_ mqtt.Client, err error) {
 
 // OnReconnect on connection reconnection
 func OnReconnect(mqtt.Client, *mqtt.ClientOptions) {
+	metricMqttReconnects.Add(1)
 	log.Log.Infof("Reconnecting...")
 	services.ServerMessage("Reconnecting to Ecoflow MQTT services ... ")
 }
 
 func (m *MqttClient) SubscribeForParameters(deviceSn string, callback mqtt.MessageHandler) error {
-	topicParams := fmt.Sprintf("/app/device/property/%s", deviceSn)
+	topicParams := parameterTopic(deviceSn)
 	return m.SubscribeToTopics([]string{topicParams}, callback)
 }
 
@@ -110,9 +146,12 @@ func (m *MqttClient) SubscribeToTopics(topics []string, callback mqtt.MessageHan
 
 	for _, t := range topics {
 		topicsMap[t] = 1
+		if subscriptionState != nil {
+			subscriptionState.Subscribed(t)
+		}
 	}
 
 	token := m.Client.SubscribeMultiple(topicsMap, callback)
 	token.Wait()
-	return nil
+	return token.Error()
 }
@@ -0,0 +1,173 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package mqtt app login and MQTT connection of the Ecoflow cloud broker
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/tknie/ecoflow/rest"
+)
+
+const (
+	ecoflowLoginUrl         = "https://api.ecoflow.com/auth/login"
+	ecoflowScene            = "IOT_APP"
+	ecoflowUserType         = "ECOFLOW"
+	ecoflowCertificationUrl = "https://api.ecoflow.com/iot-auth/app/certification"
+)
+
+// ConnectionConfig broker address and certificate credentials of the app login
+type ConnectionConfig struct {
+	CertificateAccount  string `json:"certificateAccount"`
+	CertificatePassword string `json:"certificatePassword"`
+	Url                 string `json:"url"`
+	Port                string `json:"port"`
+	Protocol            string `json:"protocol"`
+	UserId              string `json:"userId"`
+}
+
+// LoginResponse response of the app login
+type LoginResponse struct {
+	Code    rest.Code `json:"code"`
+	Message string    `json:"message"`
+	Data    struct {
+		User struct {
+			UserId        string `json:"userId"`
+			Email         string `json:"email"`
+			Name          string `json:"name"`
+			Icon          string `json:"icon"`
+			State         int    `json:"state"`
+			Regtype       string `json:"regtype"`
+			CreateTime    string `json:"createTime"`
+			Destroyed     string `json:"destroyed"`
+			RegisterLang  string `json:"registerLang"`
+			Source        string `json:"source"`
+			Administrator bool   `json:"administrator"`
+			Appid         int    `json:"appid"`
+			CountryCode   string `json:"countryCode"`
+		} `json:"user"`
+		Token string `json:"token"`
+	} `json:"data"`
+}
+
+// CredentialsResponse response of the certification request
+type CredentialsResponse struct {
+	Code    rest.Code        `json:"code"`
+	Message string           `json:"message"`
+	Data    ConnectionConfig `json:"data"`
+}
+
+// Credentials login using the app credentials and request the MQTT certificate
+// credentials of the account
+func Credentials(ctx context.Context, client *http.Client, email, password string) (*ConnectionConfig, error) {
+	mqttLoginResponse, err := Login(ctx, client, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var params = make(map[string]string)
+	params["userId"] = mqttLoginResponse.Data.User.UserId
+
+	jsonParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	certReq, err := http.NewRequestWithContext(ctx, "GET", ecoflowCertificationUrl, bytes.NewReader(jsonParams))
+	if err != nil {
+		return nil, err
+	}
+
+	certReq.Header.Set("Authorization", "Bearer "+mqttLoginResponse.Data.Token)
+	certReq.Header.Add("lang", "en_US")
+
+	resp, err := client.Do(certReq)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var mqttConn *CredentialsResponse
+	err = json.Unmarshal(responseBody, &mqttConn)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mqttConn.Data
+	c.UserId = mqttLoginResponse.Data.User.UserId
+
+	return c, nil
+}
+
+// Login login using the app credentials
+func Login(ctx context.Context, client *http.Client, email string, password string) (*LoginResponse, error) {
+	var params = make(map[string]string)
+	params["email"] = email
+	params["password"] = base64.StdEncoding.EncodeToString([]byte(password))
+	params["scene"] = ecoflowScene
+	params["userType"] = ecoflowUserType
+	jsonParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	loginReq, err := http.NewRequestWithContext(ctx, "POST", ecoflowLoginUrl, bytes.NewReader(jsonParams))
+	if err != nil {
+		return nil, err
+	}
+
+	loginReq.Header.Add("lang", "en_US")
+	loginReq.Header.Add("content-type", "application/json")
+
+	resp, err := client.Do(loginReq)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var mqttLoginResponse *LoginResponse
+	err = json.Unmarshal(responseBody, &mqttLoginResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return mqttLoginResponse, nil
+}
+
+// ClientOptions create MQTT client options connecting to the broker of the configuration
+func ClientOptions(c *ConnectionConfig) *paho.ClientOptions {
+	opts := paho.NewClientOptions()
+	opts.AddBroker(fmt.Sprintf("%s://%s:%s", c.Protocol, c.Url, c.Port))
+	opts.SetClientID(fmt.Sprintf("ANDROID_%s_%s", uuid.New(), c.UserId))
+	opts.SetUsername(c.CertificateAccount)
+	opts.SetPassword(c.CertificatePassword)
+	opts.SetConnectRetry(true)
+	return opts
+}
@@ -12,24 +12,12 @@
 package ecoflow
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
-	"github.com/google/uuid"
-)
-
-const (
-	ecoflowLoginUrl         = "https://api.ecoflow.com/auth/login"
-	ecoflowScene            = "IOT_APP"
-	ecoflowUserType         = "ECOFLOW"
-	ecoflowCertificationUrl = "https://api.ecoflow.com/iot-auth/app/certification"
+	ecomqtt "github.com/tknie/ecoflow/mqtt"
 )
 
 type MqttClientConfiguration struct {
@@ -39,65 +27,49 @@ type MqttClientConfiguration struct {
 	OnConnectionLost     mqtt.ConnectionLostHandler
 	OnReconnect          mqtt.ReconnectHandler
 	MaxReconnectInterval time.Duration
+	// ConnectRetry retry login and connect with backoff, only one attempt if nil
+	ConnectRetry *RetryPolicy
+	// HTTPClient used for login and certification requests, shared default client if nil
+	HTTPClient *http.Client
+	// Reconnect reconnect strategy after a lost connection, the automatic
+	// reconnect of paho limited by MaxReconnectInterval is used if nil
+	Reconnect *ReconnectPolicy
+	// Proxy URL of a SOCKS5 or HTTP CONNECT proxy the broker connection is
+	// tunneled through, MqttProxyFromEnv is used if empty
+	Proxy string
+	// Dialer custom dialer of the broker connection, overrides Proxy
+	Dialer ContextDialer
+	// Resolver custom name resolution of the broker, also used to reach the proxy
+	Resolver *ResolverOptions
 }
 
 type MqttClient struct {
 	Client           mqtt.Client
 	connectionConfig *MqttConnectionConfig
+	// ctx cancelled on Disconnect, stops pending reconnects
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-type MqttConnectionConfig struct {
-	CertificateAccount  string `json:"certificateAccount"`
-	CertificatePassword string `json:"certificatePassword"`
-	Url                 string `json:"url"`
-	Port                string `json:"port"`
-	Protocol            string `json:"protocol"`
-	UserId              string `json:"userId"`
-}
+// MqttConnectionConfig broker address and certificate credentials of the app login
+type MqttConnectionConfig = ecomqtt.ConnectionConfig
 
-type MqttLoginResponse struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Data    struct {
-		User struct {
-			UserId        string `json:"userId"`
-			Email         string `json:"email"`
-			Name          string `json:"name"`
-			Icon          string `json:"icon"`
-			State         int    `json:"state"`
-			Regtype       string `json:"regtype"`
-			CreateTime    string `json:"createTime"`
-			Destroyed     string `json:"destroyed"`
-			RegisterLang  string `json:"registerLang"`
-			Source        string `json:"source"`
-			Administrator bool   `json:"administrator"`
-			Appid         int    `json:"appid"`
-			CountryCode   string `json:"countryCode"`
-		} `json:"user"`
-		Token string `json:"token"`
-	} `json:"data"`
-}
+// MqttLoginResponse response of the app login
+type MqttLoginResponse = ecomqtt.LoginResponse
 
-type MqttCredentialsResponse struct {
-	Code    string               `json:"code"`
-	Message string               `json:"message"`
-	Data    MqttConnectionConfig `json:"data"`
-}
+// MqttCredentialsResponse response of the certification request
+type MqttCredentialsResponse = ecomqtt.CredentialsResponse
 
 func NewMqttClient(ctx context.Context, config MqttClientConfiguration) (*MqttClient, error) {
-	c, err := getMqttCredentials(ctx, config.Email, config.Password)
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	c, err := ecomqtt.Credentials(ctx, httpClient, config.Email, config.Password)
 	if err != nil {
 		return nil, err
 	}
-	var protocol = c.Protocol
-	var broker = c.Url
-	var port = c.Port
-	opts := mqtt.NewClientOptions()
-	opts.AddBroker(fmt.Sprintf("%s://%s:%s", protocol, broker, port))
-	opts.SetClientID(fmt.Sprintf("ANDROID_%s_%s", uuid.New(), c.UserId))
-	opts.SetUsername(c.CertificateAccount)
-	opts.SetPassword(c.CertificatePassword)
-	opts.SetConnectRetry(true)
+	opts := ecomqtt.ClientOptions(c)
 	if config.OnConnect != nil {
 		opts.OnConnect = config.OnConnect
 	}
@@ -111,93 +83,32 @@ func NewMqttClient(ctx context.Context, config MqttClientConfiguration) (*MqttCl
 	if config.MaxReconnectInterval != 0 {
 		opts.MaxReconnectInterval = config.MaxReconnectInterval
 	}
-	return &MqttClient{Client: mqtt.NewClient(opts), connectionConfig: c}, nil
-}
-
-func getMqttCredentials(ctx context.Context, email, password string) (*MqttConnectionConfig, error) {
-	mqttLoginResponse, err := getLoginResponse(ctx, email, password)
-	if err != nil {
-		return nil, err
-	}
-
-	var params = make(map[string]string)
-	params["userId"] = mqttLoginResponse.Data.User.UserId
-
-	jsonParams, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	certReq, err := http.NewRequestWithContext(ctx, "GET", ecoflowCertificationUrl, bytes.NewReader(jsonParams))
-	if err != nil {
-		return nil, err
-	}
-
-	certReq.Header.Set("Authorization", "Bearer "+mqttLoginResponse.Data.Token)
-	certReq.Header.Add("lang", "en_US")
-
-	client := http.Client{}
-	resp, err := client.Do(certReq)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var mqttConn *MqttCredentialsResponse
-	err = json.Unmarshal(responseBody, &mqttConn)
-	if err != nil {
-		return nil, err
-	}
-
-	c := &mqttConn.Data
-	c.UserId = mqttLoginResponse.Data.User.UserId
-
-	return c, nil
-}
-
-func getLoginResponse(ctx context.Context, email string, password string) (*MqttLoginResponse, error) {
-	var params = make(map[string]string)
-	params["email"] = email
-	params["password"] = base64.StdEncoding.EncodeToString([]byte(password))
-	params["scene"] = ecoflowScene
-	params["userType"] = ecoflowUserType
-	jsonParams, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	loginReq, err := http.NewRequestWithContext(ctx, "POST", ecoflowLoginUrl, bytes.NewReader(jsonParams))
-	if err != nil {
-		return nil, err
-	}
-
-	loginReq.Header.Add("lang", "en_US")
-	loginReq.Header.Add("content-type", "application/json")
-
-	client := http.Client{}
-	resp, err := client.Do(loginReq)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var mqttLoginResponse *MqttLoginResponse
-	err = json.Unmarshal(responseBody, &mqttLoginResponse)
-	if err != nil {
-		return nil, err
-	}
-
-	return mqttLoginResponse, nil
+	dialer := config.Dialer
+	proxyURL := config.Proxy
+	if proxyURL == "" {
+		proxyURL = MqttProxyFromEnv()
+	}
+	var forward ContextDialer
+	if config.Resolver != nil {
+		forward = NewDialer(*config.Resolver)
+	}
+	switch {
+	case dialer != nil:
+	case proxyURL != "":
+		if dialer, err = ProxyDialer(proxyURL, forward); err != nil {
+			return nil, err
+		}
+	default:
+		dialer = forward
+	}
+	if dialer != nil {
+		opts.SetCustomOpenConnectionFn(proxyConnection(dialer))
+	}
+	m := &MqttClient{connectionConfig: c}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.applyConnectionHandlers(opts, config)
+	m.Client = mqtt.NewClient(opts)
+	return m, nil
 }
 
 func (m *MqttClient) Connect() error {
@@ -206,3 +117,25 @@ func (m *MqttClient) Connect() error {
 	}
 	return nil
 }
+
+// ConnectMqttClient create and connect MQTT client, login and connect are retried
+// using the ConnectRetry policy of the configuration
+func ConnectMqttClient(ctx context.Context, config MqttClientConfiguration) (*MqttClient, error) {
+	policy := RetryPolicy{MaxAttempts: 1}
+	if config.ConnectRetry != nil {
+		policy = *config.ConnectRetry
+	}
+	var client *MqttClient
+	err := policy.Retry(ctx, "MQTT connect", func(ctx context.Context) error {
+		c, err := NewMqttClient(ctx, config)
+		if err != nil {
+			return err
+		}
+		if err := c.Connect(); err != nil {
+			return err
+		}
+		client = c
+		return nil
+	})
+	return client, err
+}
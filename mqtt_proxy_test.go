@@ -0,0 +1,127 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+)
+
+// connectProxy HTTP CONNECT proxy requiring basic authentication
+func connectProxy(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if user, password, ok := parseProxyAuth(r); !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			defer conn.Close()
+			defer target.Close()
+			go io.Copy(target, conn)
+			io.Copy(conn, target)
+		}()
+	}))
+}
+
+func parseProxyAuth(r *http.Request) (string, string, bool) {
+	r.Header.Set("Authorization", r.Header.Get("Proxy-Authorization"))
+	return r.BasicAuth()
+}
+
+// echoServer TCP server echoing the first line
+func echoServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				conn.Write([]byte("echo " + line))
+			}()
+		}
+	}()
+	return l
+}
+
+func TestHTTPConnectProxy(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+	server := connectProxy(t)
+	defer server.Close()
+	proxyURL, _ := url.Parse(server.URL)
+
+	proxyURL.User = url.UserPassword("user", "secret")
+	dialer, err := ProxyDialer(proxyURL.String(), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	open := proxyConnection(dialer)
+	conn, err := open(&url.URL{Scheme: "tcp", Host: echo.Addr().String()}, mqtt.ClientOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = conn.Write([]byte("ping\n"))
+	assert.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "echo ping\n", line)
+	conn.Close()
+
+	proxyURL.User = url.UserPassword("user", "wrong")
+	dialer, err = ProxyDialer(proxyURL.String(), nil)
+	if assert.NoError(t, err) {
+		_, err = dialer.DialContext(ctx, "tcp", echo.Addr().String())
+		assert.ErrorContains(t, err, "407")
+	}
+}
+
+func TestProxyDialerSchemes(t *testing.T) {
+	d, err := ProxyDialer("socks5://user:pw@127.0.0.1:1080", NewDialer(ResolverOptions{}))
+	assert.NoError(t, err)
+	assert.NotNil(t, d)
+	_, err = ProxyDialer("ftp://proxy:21", nil)
+	assert.ErrorContains(t, err, "unsupported proxy scheme")
+}
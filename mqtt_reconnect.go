@@ -0,0 +1,167 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// EventConnectionState MQTT connection state changed, Data contains ConnectionStateChange
+const EventConnectionState EventType = "mqtt.connection"
+
+// ConnectionState state of the MQTT connection
+type ConnectionState string
+
+const (
+	// ConnectionConnected connection established or re-established
+	ConnectionConnected ConnectionState = "connected"
+	// ConnectionLost connection lost, reconnect pending
+	ConnectionLost ConnectionState = "lost"
+	// ConnectionReconnecting reconnect attempt started
+	ConnectionReconnecting ConnectionState = "reconnecting"
+	// ConnectionFailed all reconnect attempts failed, the client stays disconnected
+	ConnectionFailed ConnectionState = "failed"
+	// ConnectionDisconnected client disconnected on request
+	ConnectionDisconnected ConnectionState = "disconnected"
+)
+
+// ConnectionStateChange data of EventConnectionState
+type ConnectionStateChange struct {
+	State   ConnectionState `json:"state"`
+	Attempt int             `json:"attempt,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	// Since time of the connection loss for reconnect states
+	Since time.Time `json:"since,omitempty"`
+}
+
+// ReconnectPolicy reconnect strategy after a lost MQTT connection replacing the
+// automatic reconnect of paho, which starts at one second, doubles up to
+// MaxReconnectInterval and never gives up
+type ReconnectPolicy struct {
+	// InitialDelay delay before the first attempt, one second if 0
+	InitialDelay time.Duration
+	// MaxDelay maximum delay between attempts, 10 minutes if 0
+	MaxDelay time.Duration
+	// MaxAttempts maximum number of attempts, zero retries until disconnected
+	MaxAttempts int
+	// OnReconnectFailed called after MaxAttempts failed attempts
+	OnReconnectFailed func(attempts int, err error)
+}
+
+// delay return the wait time before the given attempt starting at 1
+func (p *ReconnectPolicy) delay(attempt int) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 10 * time.Minute
+	}
+	return RetryPolicy{InitialBackoff: p.InitialDelay, MaxBackoff: maxDelay}.backoff(attempt)
+}
+
+// publishConnectionState publish state change on the event bus
+func publishConnectionState(change ConnectionStateChange) {
+	Events.Publish(Event{Type: EventConnectionState, Data: change})
+}
+
+// reconnect call connect until it succeeds, the attempts are exhausted or the
+// context is done. onAttempt is called before each attempt.
+func (p *ReconnectPolicy) reconnect(ctx context.Context, since time.Time, connect func() error, onAttempt func(attempt int)) error {
+	var err error
+	for attempt := 1; p.MaxAttempts <= 0 || attempt <= p.MaxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-CurrentClock().After(p.delay(attempt)):
+		}
+		publishConnectionState(ConnectionStateChange{State: ConnectionReconnecting, Attempt: attempt, Since: since})
+		if onAttempt != nil {
+			onAttempt(attempt)
+		}
+		if err = connect(); err == nil {
+			return nil
+		}
+		log.Log.Errorf("MQTT reconnect attempt %d failed: %v", attempt, err)
+	}
+	services.ServerMessage("Ecoflow: MQTT reconnect failed after %d attempts: %v", p.MaxAttempts, err)
+	publishConnectionState(ConnectionStateChange{State: ConnectionFailed, Attempt: p.MaxAttempts,
+		Error: err.Error(), Since: since})
+	if p.OnReconnectFailed != nil {
+		p.OnReconnectFailed(p.MaxAttempts, err)
+	}
+	return err
+}
+
+// applyConnectionHandlers install handlers publishing the connection state and
+// the reconnect policy of the configuration
+func (m *MqttClient) applyConnectionHandlers(opts *mqtt.ClientOptions, config MqttClientConfiguration) {
+	onConnect := opts.OnConnect
+	opts.SetOnConnectHandler(func(client mqtt.Client) {
+		publishConnectionState(ConnectionStateChange{State: ConnectionConnected})
+		if onConnect != nil {
+			onConnect(client)
+		}
+	})
+	onLost := opts.OnConnectionLost
+	opts.SetConnectionLostHandler(func(client mqtt.Client, err error) {
+		lost := now()
+		publishConnectionState(ConnectionStateChange{State: ConnectionLost, Error: err.Error(), Since: lost})
+		if onLost != nil {
+			onLost(client, err)
+		}
+		if config.Reconnect != nil {
+			backgroundRuntime().GoContext(m.ctx, Component{Name: "mqtt reconnect", Optional: true,
+				Run: func(ctx context.Context) error { return m.reconnectLoop(ctx, config.Reconnect, opts, lost) }})
+		}
+	})
+	if config.Reconnect == nil {
+		onReconnecting := opts.OnReconnecting
+		opts.SetReconnectingHandler(func(client mqtt.Client, o *mqtt.ClientOptions) {
+			publishConnectionState(ConnectionStateChange{State: ConnectionReconnecting})
+			if onReconnecting != nil {
+				onReconnecting(client, o)
+			}
+		})
+		return
+	}
+	// the policy controls all attempts, paho connects only once per attempt
+	opts.SetAutoReconnect(false)
+	opts.SetConnectRetry(false)
+}
+
+// reconnectLoop reconnect using the policy until connected, the policy gives up
+// or the context is done by Disconnect
+func (m *MqttClient) reconnectLoop(ctx context.Context, policy *ReconnectPolicy, opts *mqtt.ClientOptions, lost time.Time) error {
+	err := policy.reconnect(ctx, lost, m.Connect, func(int) {
+		if opts.OnReconnecting != nil {
+			opts.OnReconnecting(m.Client, opts)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	services.ServerMessage("Ecoflow: MQTT reconnected after %v", since(lost).Round(time.Second))
+	return nil
+}
+
+// Disconnect stop pending reconnects and disconnect, waiting the given
+// milliseconds for outstanding work
+func (m *MqttClient) Disconnect(quiesce uint) {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.Client.Disconnect(quiesce)
+	publishConnectionState(ConnectionStateChange{State: ConnectionDisconnected})
+}
@@ -0,0 +1,65 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectPolicy(t *testing.T) {
+	var states []ConnectionStateChange
+	unsubscribe := Events.Subscribe(func(e Event) { states = append(states, e.Data.(ConnectionStateChange)) }, EventConnectionState)
+	defer unsubscribe()
+
+	policy := &ReconnectPolicy{InitialDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond}
+	assert.Equal(t, time.Millisecond, policy.delay(1))
+	assert.Equal(t, 4*time.Millisecond, policy.delay(5))
+
+	calls := 0
+	err := policy.reconnect(context.Background(), time.Now(), func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, states, 3)
+
+	// attempts exhausted
+	states = nil
+	failedAttempts := 0
+	policy.MaxAttempts = 2
+	policy.OnReconnectFailed = func(attempts int, err error) { failedAttempts = attempts }
+	attempts := 0
+	err = policy.reconnect(context.Background(), time.Now(), func() error { return errors.New("broker unavailable") },
+		func(attempt int) { attempts = attempt })
+	assert.EqualError(t, err, "broker unavailable")
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, failedAttempts)
+	if assert.Len(t, states, 3) {
+		assert.Equal(t, ConnectionReconnecting, states[1].State)
+		assert.Equal(t, ConnectionFailed, states[2].State)
+		assert.Equal(t, "broker unavailable", states[2].Error)
+	}
+
+	// disconnect stops the reconnect
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, policy.reconnect(ctx, time.Now(), func() error { return nil }, nil), context.Canceled)
+}
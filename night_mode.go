@@ -0,0 +1,336 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// NightModeConfig configuration of the night mode. Start and End are local
+// times of the day in the format 15:04, the night may span midnight.
+type NightModeConfig struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Location time zone of Start and End, local time zone if nil
+	Location *time.Location `json:"-"`
+	// ChargeWatts AC charging power during the night, higher configured values are kept
+	ChargeWatts int `json:"chargeWatts,omitempty"`
+	// Brightness LED and display brightness in percent during the night, negative keeps it
+	Brightness int `json:"brightness"`
+	// Quiet switch the beeper off during the night
+	Quiet bool `json:"quiet"`
+	// Devices device names, aliases or groups, all registered devices if empty
+	Devices []string `json:"devices,omitempty"`
+	// SnapshotFile keep the settings to restore in the morning across restarts
+	SnapshotFile string `json:"snapshotFile,omitempty"`
+}
+
+// nightChargeSettings settings limiting the charging power, which causes the fan noise
+var nightChargeSettings = []string{"acChargeWatts", "slowChgPower"}
+
+// nightBrightnessSettings settings of the LED and display brightness
+var nightBrightnessSettings = []string{"brightness", "lcdBrightness"}
+
+// nightSnapshot settings of a device to restore in the morning
+type nightSnapshot struct {
+	Settings *SettingsDocument `json:"settings"`
+	// Quiet beeper mode before the night, nil if not changed
+	Quiet *bool `json:"quiet,omitempty"`
+}
+
+// NightMode lower charging power, dim LEDs and disable beeps of the devices at night.
+// The settings changed are exported before and restored in the morning using ApplySettings.
+type NightMode struct {
+	client    *Client
+	config    NightModeConfig
+	start     int
+	end       int
+	mu        sync.Mutex
+	snapshots map[string]*nightSnapshot
+}
+
+// NewNightMode create night mode, the snapshots of a previous night are loaded
+// from the snapshot file to be restored
+func NewNightMode(client *Client, config NightModeConfig) (*NightMode, error) {
+	start, err := parseTimeOfDay(config.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTimeOfDay(config.End)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, errors.New("night mode start and end need to differ")
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	n := &NightMode{client: client, config: config, start: start, end: end,
+		snapshots: make(map[string]*nightSnapshot)}
+	if config.SnapshotFile != "" {
+		data, err := readStoredFile(config.SnapshotFile)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &n.snapshots); err != nil {
+				return nil, fmt.Errorf("invalid night mode snapshot %s: %w", config.SnapshotFile, err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// parseTimeOfDay parse 15:04 into minutes of the day
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Night check if the time is within the night
+func (n *NightMode) Night(t time.Time) bool {
+	t = t.In(n.config.Location)
+	m := t.Hour()*60 + t.Minute()
+	if n.start < n.end {
+		return m >= n.start && m < n.end
+	}
+	return m >= n.start || m < n.end
+}
+
+// next return the next start or end of the night after the time
+func (n *NightMode) next(t time.Time) time.Time {
+	start := nextTimeOfDay(t, n.start, n.config.Location)
+	if end := nextTimeOfDay(t, n.end, n.config.Location); end.Before(start) {
+		return end
+	}
+	return start
+}
+
+// Active check if settings of the night are waiting to be restored
+func (n *NightMode) Active() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.snapshots) > 0
+}
+
+func (n *NightMode) devices() []string {
+	if len(n.config.Devices) > 0 {
+		return expandDevices(n.config.Devices)
+	}
+	list := devices
+	if list == nil {
+		return nil
+	}
+	result := make([]string, 0, len(list.Devices))
+	for _, d := range list.Devices {
+		result = append(result, d.SN)
+	}
+	return result
+}
+
+// nightSettings return the night values of the settings of the device model,
+// the current values are used to keep lower charging power. Settings without
+// current value are skipped, they could not be restored.
+func (n *NightMode) nightSettings(model DeviceModel, current map[string]float64) map[string]float64 {
+	values := make(map[string]float64)
+	supported := func(name string) bool {
+		s, ok := LookupSetting(name)
+		return ok && s.SupportedBy(model)
+	}
+	if n.config.ChargeWatts > 0 {
+		for _, name := range nightChargeSettings {
+			if !supported(name) {
+				continue
+			}
+			// without current value there is nothing to restore in the morning
+			if v, ok := current[name]; ok && v > float64(n.config.ChargeWatts) {
+				values[name] = float64(n.config.ChargeWatts)
+			}
+		}
+	}
+	if n.config.Brightness >= 0 {
+		for _, name := range nightBrightnessSettings {
+			if _, ok := current[name]; ok && supported(name) {
+				values[name] = float64(n.config.Brightness)
+			}
+		}
+	}
+	return values
+}
+
+// quietSupported check if the beeper mode of the device model can be read and switched
+func quietSupported(model DeviceModel) bool {
+	switch model {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		return true
+	}
+	return false
+}
+
+// Enter export the settings changed at night and apply the night values on all devices
+func (n *NightMode) Enter(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var errs []error
+	for _, sn := range n.devices() {
+		if _, ok := n.snapshots[sn]; ok {
+			continue
+		}
+		if err := n.enter(ctx, sn); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sn, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enter snapshot and change the settings of one device, the lock need to be held
+func (n *NightMode) enter(ctx context.Context, sn string) error {
+	model := DetectModel(sn)
+	snapshot := &nightSnapshot{Settings: &SettingsDocument{SerialNumber: sn, Model: model.String(),
+		Exported: now(), Settings: make(map[string]float64)}}
+	var night map[string]float64
+	if len(SettingsFor(model)) > 0 {
+		doc, err := n.client.ExportSettings(ctx, sn)
+		if err != nil {
+			return err
+		}
+		night = n.nightSettings(model, doc.Settings)
+		for name := range night {
+			if v, ok := doc.Settings[name]; ok {
+				snapshot.Settings.Settings[name] = v
+			}
+		}
+	}
+	quiet := n.config.Quiet && quietSupported(model)
+	if quiet {
+		mode, err := n.client.getQuotaValue(ctx, sn, quotaBeepMode)
+		if err != nil {
+			return err
+		}
+		previous := mode != 0
+		snapshot.Quiet = &previous
+	}
+	if len(night) == 0 && !quiet {
+		return nil
+	}
+	n.snapshots[sn] = snapshot
+	if err := n.save(); err != nil {
+		return err
+	}
+	services.ServerMessage("Night mode for %s: %v quiet=%v", sn, night, quiet)
+	var errs []error
+	if len(night) > 0 {
+		if _, err := n.client.ApplySettings(ctx, sn, &SettingsDocument{Model: model.String(), Settings: night}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if quiet {
+		if _, err := n.client.SetQuietMode(ctx, sn, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Leave restore the settings exported when the night mode was entered
+func (n *NightMode) Leave(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	serialNumbers := make([]string, 0, len(n.snapshots))
+	for sn := range n.snapshots {
+		serialNumbers = append(serialNumbers, sn)
+	}
+	sort.Strings(serialNumbers)
+	var errs []error
+	for _, sn := range serialNumbers {
+		services.ServerMessage("Restore settings of %s after night mode", sn)
+		snapshot := n.snapshots[sn]
+		if len(snapshot.Settings.Settings) > 0 {
+			if _, err := n.client.ApplySettings(ctx, sn, snapshot.Settings); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sn, err))
+				continue
+			}
+		}
+		if snapshot.Quiet != nil {
+			if _, err := n.client.SetQuietMode(ctx, sn, *snapshot.Quiet); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sn, err))
+				continue
+			}
+		}
+		delete(n.snapshots, sn)
+	}
+	if err := n.save(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// save write the snapshots to the snapshot file, the lock need to be held
+func (n *NightMode) save() error {
+	if n.config.SnapshotFile == "" {
+		return nil
+	}
+	if len(n.snapshots) == 0 {
+		err := os.Remove(n.config.SnapshotFile)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.Marshal(n.snapshots)
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(n.config.SnapshotFile, data)
+}
+
+// Run enter the night mode at the start and leave it at the end of the night
+// according to the package clock until the context is done. Failed restores are
+// retried at the next check.
+func (n *NightMode) Run(ctx context.Context) error {
+	clock := CurrentClock()
+	for {
+		t := clock.Now()
+		var err error
+		if n.Night(t) {
+			err = n.Enter(ctx)
+		} else if n.Active() {
+			err = n.Leave(ctx)
+		}
+		if err != nil {
+			log.Log.Errorf("Night mode: %v", err)
+		}
+		wait := n.next(t).Sub(t)
+		if err != nil && wait > time.Hour {
+			wait = time.Hour
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-clock.After(wait):
+		}
+	}
+}
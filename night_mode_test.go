@@ -0,0 +1,91 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNightModeWindow(t *testing.T) {
+	n, err := NewNightMode(nil, NightModeConfig{Start: "22:30", End: "06:00", Location: time.UTC})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, n.Night(time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, n.Night(time.Date(2025, 6, 2, 5, 59, 0, 0, time.UTC)))
+	assert.False(t, n.Night(time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC)))
+	assert.False(t, n.Night(time.Date(2025, 6, 2, 22, 29, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC), n.next(time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 6, 2, 22, 30, 0, 0, time.UTC), n.next(time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC)))
+
+	_, err = NewNightMode(nil, NightModeConfig{Start: "25:00", End: "06:00"})
+	assert.Error(t, err)
+	_, err = NewNightMode(nil, NightModeConfig{Start: "06:00", End: "06:00"})
+	assert.Error(t, err)
+}
+
+func TestNightModeEnterLeave(t *testing.T) {
+	oldInterval, oldTimeout := SettingsVerifyInterval, VerifyTimeout
+	SettingsVerifyInterval, VerifyTimeout = time.Millisecond, time.Second
+	defer func() { SettingsVerifyInterval, VerifyTimeout = oldInterval, oldTimeout }()
+
+	transport := newStationAPI(map[string]interface{}{"mppt.cfgChgWatts": 1200.0,
+		"pd.brightLevel": 3.0, "pd.beepMode": 0.0})
+	client := newTestClient(t, transport)
+	file := filepath.Join(t.TempDir(), "night.json")
+	config := NightModeConfig{Start: "22:00", End: "07:00", ChargeWatts: 400, Brightness: 0,
+		Quiet: true, Devices: []string{"R331NIGHT"}, SnapshotFile: file}
+	n, err := NewNightMode(client, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+	assert.NoError(t, n.Enter(ctx))
+	assert.True(t, n.Active())
+	assert.Equal(t, 400.0, transport.value("mppt.cfgChgWatts"))
+	assert.Equal(t, 0.0, transport.value("pd.brightLevel"))
+	assert.Equal(t, 1.0, transport.value("pd.beepMode"))
+	assert.Equal(t, 3, transport.puts())
+
+	// a restarted daemon restores the snapshot of the night
+	n, err = NewNightMode(client, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, n.Active())
+	assert.NoError(t, n.Enter(ctx))
+	assert.Equal(t, 3, transport.puts())
+	assert.NoError(t, n.Leave(ctx))
+	assert.False(t, n.Active())
+	assert.Equal(t, 1200.0, transport.value("mppt.cfgChgWatts"))
+	assert.Equal(t, 3.0, transport.value("pd.brightLevel"))
+	assert.Equal(t, 0.0, transport.value("pd.beepMode"))
+	assert.NoFileExists(t, file)
+}
+
+func TestNightSettingsUnknownCurrent(t *testing.T) {
+	n, err := NewNightMode(nil, NightModeConfig{Start: "22:00", End: "07:00", ChargeWatts: 400, Brightness: 0})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]float64{"acChargeWatts": 400, "lcdBrightness": 0},
+		n.nightSettings(ModelDelta2, map[string]float64{"acChargeWatts": 1200, "lcdBrightness": 100}))
+	// lower charging power is kept, values not reported are not changed
+	assert.Equal(t, map[string]float64{"lcdBrightness": 0},
+		n.nightSettings(ModelDelta2, map[string]float64{"acChargeWatts": 300, "lcdBrightness": 100}))
+	assert.Empty(t, n.nightSettings(ModelDelta2, map[string]float64{}))
+}
@@ -0,0 +1,48 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetUSBOutput switch the USB outputs of Delta and River stations. The Delta 2 and
+// River 2 series use the PD module like SetUSBOn, Delta Max/Pro the old command set interface.
+func (client *Client) SetUSBOutput(ctx context.Context, serialNumber string, on bool) (*CmdSetResponse, error) {
+	switch DetectModel(serialNumber) {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		return client.setUSBOn(ctx, serialNumber, on)
+	case ModelDeltaMax, ModelDeltaPro:
+		return client.SetCommand(ctx, CmdSetRequest{Sn: serialNumber,
+			Params: map[string]interface{}{"cmdSet": 32, "id": 34, "enabled": boolToInt(on)}})
+	default:
+		return nil, fmt.Errorf("USB output not supported for device %s", serialNumber)
+	}
+}
+
+// SetDCOutput switch the 12V DC (car socket) output of Delta and River stations. The
+// Delta 2 and River 2 series use the MPPT module, Delta Max/Pro the old command set interface.
+func (client *Client) SetDCOutput(ctx context.Context, serialNumber string, on bool) (*CmdSetResponse, error) {
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		cmdReq.ModuleType = ModuleTypeMppt
+		cmdReq.OperateType = "mpptCar"
+		cmdReq.Params = map[string]interface{}{"enabled": boolToInt(on)}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 81, "enabled": boolToInt(on)}
+	default:
+		return nil, fmt.Errorf("DC output not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
@@ -0,0 +1,57 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOutputs(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.SetUSBOutput(ctx, "R331OUTPUT", true)
+	assert.NoError(t, err)
+	_, err = client.SetUSBOutput(ctx, "DCABZOUTPUT", false)
+	assert.NoError(t, err)
+	_, err = client.SetDCOutput(ctx, "R621OUTPUT", false)
+	assert.NoError(t, err)
+	_, err = client.SetDCOutput(ctx, "DAEBOUTPUT", true)
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 4) {
+		assert.Equal(t, ModuleTypePd, transport.requests[0].ModuleType)
+		assert.Equal(t, "dcOutCfg", transport.requests[0].OperateType)
+		assert.Equal(t, float64(1), transport.requests[0].Params["enabled"])
+		assert.Equal(t, float64(34), transport.requests[1].Params["id"])
+		assert.Equal(t, float64(0), transport.requests[1].Params["enabled"])
+		assert.Equal(t, ModuleTypeMppt, transport.requests[2].ModuleType)
+		assert.Equal(t, "mpptCar", transport.requests[2].OperateType)
+		assert.Equal(t, float64(81), transport.requests[3].Params["id"])
+	}
+	_, err = client.SetUSBOutput(ctx, "HW51OUTPUT", true)
+	assert.Error(t, err)
+	_, err = client.SetDCOutput(ctx, "HW52OUTPUT", true)
+	assert.Error(t, err)
+
+	// SetUSBOn sends the same command as SetUSBOutput
+	_, err = client.SetUSBOn("R331OUTPUT", true)
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 5) {
+		usb := transport.requests[4]
+		usb.Id = transport.requests[0].Id
+		assert.Equal(t, transport.requests[0], usb)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// PayloadDumpConfig rotating dump files of undecoded payloads. The files use the
+// capture file format and can be read with LoadUndecodedPayloads.
+type PayloadDumpConfig struct {
+	Dir string `json:"dir"`
+	// MaxFiles number of dump files kept, the oldest files are removed
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// MaxFileSize size in bytes a file is rotated at
+	MaxFileSize int64 `json:"maxFileSize,omitempty"`
+	// MaxPerMinute payloads written per minute, further payloads are dropped
+	MaxPerMinute int `json:"maxPerMinute,omitempty"`
+}
+
+// default limits of the payload dump files
+const (
+	defaultDumpFiles     = 10
+	defaultDumpFileSize  = 1 << 20
+	defaultDumpPerMinute = 10
+	dumpFilePrefix       = "payloads-"
+	dumpFileSuffix       = ".json"
+)
+
+// payloadDumper collect the encoded payloads of the current file, the file is
+// written by a background writer so that the message handler is not blocked
+type payloadDumper struct {
+	config  PayloadDumpConfig
+	mu      sync.Mutex
+	file    string
+	entries [][]byte
+	size    int64
+	dirty   bool
+	// created a new file was started, older files may need to be removed
+	created bool
+	// closed content of rotated files not written yet
+	closed  []dumpFile
+	window  time.Time
+	count   int
+	dropped uint64
+	seq     int
+	wake    chan struct{}
+	stop    context.CancelFunc
+	done    <-chan error
+}
+
+// dumpFile content of a dump file
+type dumpFile struct {
+	name string
+	data []byte
+}
+
+var dumpLock sync.Mutex
+var dumper *payloadDumper
+
+type messageTopicKey struct{}
+
+// withMessageTopic add the topic the payload was received on to the context
+func withMessageTopic(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, messageTopicKey{}, topic)
+}
+
+// messageTopic return the topic of the payload of the context
+func messageTopic(ctx context.Context) string {
+	topic, _ := ctx.Value(messageTopicKey{}).(string)
+	return topic
+}
+
+// EnablePayloadDumps write undecoded payloads with topic and time into rotating
+// dump files, encrypted if the storage encryption is active
+func EnablePayloadDumps(config PayloadDumpConfig) error {
+	if config.Dir == "" {
+		return errors.New("payload dump directory missing")
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = defaultDumpFiles
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = defaultDumpFileSize
+	}
+	if config.MaxPerMinute <= 0 {
+		config.MaxPerMinute = defaultDumpPerMinute
+	}
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return err
+	}
+	d := &payloadDumper{config: config, wake: make(chan struct{}, 1)}
+	var ctx context.Context
+	ctx, d.stop = context.WithCancel(context.Background())
+	d.done = backgroundRuntime().GoContext(ctx, Component{Name: "payload dumps", Run: d.run,
+		Restart: RestartOnFailure, Optional: true})
+	dumpLock.Lock()
+	previous := dumper
+	dumper = d
+	dumpLock.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+	return nil
+}
+
+// DisablePayloadDumps stop writing dump files after the pending payloads are
+// written, the number of payloads dropped by the rate limit is returned
+func DisablePayloadDumps() uint64 {
+	dumpLock.Lock()
+	d := dumper
+	dumper = nil
+	dumpLock.Unlock()
+	if d == nil {
+		return 0
+	}
+	d.close()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dropped
+}
+
+// dumpPayload add the payload to the current dump file if dumps are enabled
+func dumpPayload(p UndecodedPayload) {
+	dumpLock.Lock()
+	d := dumper
+	dumpLock.Unlock()
+	if d == nil {
+		return
+	}
+	if err := d.add(p); err != nil {
+		log.Log.Errorf("Unable to dump payload of %s: %v", p.SerialNumber, err)
+	}
+}
+
+// add append the encoded payload to the current file and wake the writer, the
+// file is rotated if the encoded size exceeds MaxFileSize
+func (d *payloadDumper) add(p UndecodedPayload) error {
+	entry, err := json.MarshalIndent(p, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	if p.Received.Sub(d.window) >= time.Minute {
+		d.window = p.Received
+		d.count = 0
+	}
+	if d.count >= d.config.MaxPerMinute {
+		d.dropped++
+		d.mu.Unlock()
+		return nil
+	}
+	d.count++
+	// entries are separated by ",\n  ", the array adds "[\n  " and "\n]"
+	size := int64(len(entry) + 4)
+	if d.file == "" || (len(d.entries) > 0 && d.size+size > d.config.MaxFileSize) {
+		if d.dirty {
+			d.closed = append(d.closed, dumpFile{name: d.file, data: d.content()})
+		}
+		d.seq++
+		d.file = filepath.Join(d.config.Dir, fmt.Sprintf("%s%s-%03d%s", dumpFilePrefix,
+			p.Received.UTC().Format("20060102-150405"), d.seq%1000, dumpFileSuffix))
+		d.entries = d.entries[:0]
+		d.size = 4
+		d.created = true
+	}
+	d.entries = append(d.entries, entry)
+	d.size += size
+	d.dirty = true
+	d.mu.Unlock()
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// content return the capture file of the current entries, the caller need to
+// hold the lock
+func (d *payloadDumper) content() []byte {
+	data := make([]byte, 0, d.size)
+	data = append(data, "[\n  "...)
+	for i, entry := range d.entries {
+		if i > 0 {
+			data = append(data, ",\n  "...)
+		}
+		data = append(data, entry...)
+	}
+	return append(data, "\n]"...)
+}
+
+// run write the dump files until the context is done, pending payloads are
+// written before returning
+func (d *payloadDumper) run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return d.flush()
+		case <-d.wake:
+			if err := d.flush(); err != nil {
+				return fmt.Errorf("write payload dump: %w", err)
+			}
+		}
+	}
+}
+
+// flush write rotated and current file, the oldest files are removed after
+// a new file is written
+func (d *payloadDumper) flush() error {
+	d.mu.Lock()
+	files := d.closed
+	d.closed = nil
+	if d.dirty {
+		files = append(files, dumpFile{name: d.file, data: d.content()})
+		d.dirty = false
+	}
+	created := d.created
+	d.created = false
+	d.mu.Unlock()
+	var errs []error
+	for _, f := range files {
+		if err := writeStoredFile(f.name, f.data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if created {
+		errs = append(errs, d.rotate())
+	}
+	return errors.Join(errs...)
+}
+
+// close stop the writer after the pending payloads are written
+func (d *payloadDumper) close() {
+	d.stop()
+	if err := <-d.done; err != nil {
+		log.Log.Errorf("Unable to write payload dump: %v", err)
+	}
+}
+
+// rotate remove the oldest dump files exceeding MaxFiles
+func (d *payloadDumper) rotate() error {
+	files, err := PayloadDumpFiles(d.config.Dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for len(files) > d.config.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			errs = append(errs, err)
+		}
+		files = files[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// PayloadDumpFiles return the dump files of the directory, oldest first
+func PayloadDumpFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), dumpFilePrefix) && strings.HasSuffix(e.Name(), dumpFileSuffix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
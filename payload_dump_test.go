@@ -0,0 +1,92 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadDumps(t *testing.T) {
+	dir := t.TempDir()
+	err := EnablePayloadDumps(PayloadDumpConfig{Dir: dir, MaxFiles: 2, MaxFileSize: 400, MaxPerMinute: 5})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer DisablePayloadDumps()
+
+	MessageHandler(nil, &benchMessage{topic: "/app/device/property/HW51DUMP", payload: []byte{0x0a, 0xff, 0xff}})
+	for i := 0; i < 6; i++ {
+		recordUndecoded("lan", "HW51DUMP", "test", []byte(strings.Repeat("x", 50)))
+	}
+	// disabling waits for the background writer
+	assert.Equal(t, uint64(2), DisablePayloadDumps())
+	files, err := PayloadDumpFiles(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, files, 2)
+	payloads, err := LoadUndecodedPayloads(files[len(files)-1])
+	if assert.NoError(t, err) && assert.NotEmpty(t, payloads) {
+		assert.Equal(t, "lan", payloads[len(payloads)-1].Topic)
+	}
+	first, err := LoadUndecodedPayloads(files[0])
+	assert.NoError(t, err)
+	total := len(first) + len(payloads)
+	assert.True(t, total < 5)
+	// files with more than one payload stay below the encoded size limit
+	for _, f := range files {
+		info, err := os.Stat(f)
+		assert.NoError(t, err)
+		if p, _ := LoadUndecodedPayloads(f); len(p) > 1 {
+			assert.LessOrEqual(t, info.Size(), int64(400), f)
+		}
+	}
+	assert.Equal(t, uint64(0), DisablePayloadDumps())
+
+	recent := RecentUndecodedPayloads()
+	found := false
+	for _, p := range recent {
+		if p.Topic == "/app/device/property/HW51DUMP" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+	assert.Error(t, EnablePayloadDumps(PayloadDumpConfig{}))
+}
+
+func TestPayloadDumpFileSize(t *testing.T) {
+	dir := t.TempDir()
+	if !assert.NoError(t, EnablePayloadDumps(PayloadDumpConfig{Dir: dir, MaxFileSize: 1000, MaxPerMinute: 20})) {
+		return
+	}
+	for i := 0; i < 12; i++ {
+		recordUndecoded("lan", "HW51DUMPSIZE", "test", []byte(strings.Repeat("y", 100)))
+	}
+	DisablePayloadDumps()
+	files, err := PayloadDumpFiles(dir)
+	assert.NoError(t, err)
+	assert.True(t, len(files) > 1)
+	total := 0
+	for _, f := range files {
+		info, err := os.Stat(f)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1000), f)
+		payloads, err := LoadUndecodedPayloads(f)
+		assert.NoError(t, err)
+		total += len(payloads)
+	}
+	assert.Equal(t, 12, total)
+}
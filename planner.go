@@ -0,0 +1,197 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// TariffWindow electricity price of a time window
+type TariffWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Price price per kWh
+	Price float64 `json:"price"`
+}
+
+// tariffAt return the tariff window containing the time
+func tariffAt(windows []TariffWindow, t time.Time) (TariffWindow, bool) {
+	for _, w := range windows {
+		if !t.Before(w.Start) && t.Before(w.End) {
+			return w, true
+		}
+	}
+	return TariffWindow{}, false
+}
+
+// PlannerConfig configuration of the day-ahead planner of one PowerStream
+type PlannerConfig struct {
+	SerialNumber string
+	// BaseWatts output used if no load profile data is available
+	BaseWatts float64
+	MinWatts  float64
+	MaxWatts  float64
+	// CheapPrice output is reduced to MinWatts at or below this price, if zero
+	// 80% of the average price of the day is used
+	CheapPrice float64
+	// DefaultChargeLimit battery charge limit on normal days
+	DefaultChargeLimit float64
+	// SunnyChargeLimit battery charge limit if the forecast exceeds SunnyDayWh
+	SunnyChargeLimit float64
+	SunnyDayWh       float64
+	// Slot length of the planned time slots
+	Slot time.Duration
+}
+
+// Planner generate day-ahead schedules of charge limits and output targets from
+// the PV forecast, tariff windows and the recorded load profile
+type Planner struct {
+	Config   PlannerConfig
+	Forecast ForecastProvider
+	Profile  *LoadProfile
+	Location *time.Location
+	mu       sync.Mutex
+	tariffs  []TariffWindow
+}
+
+// NewPlanner create planner for the configuration
+func NewPlanner(config PlannerConfig, forecast ForecastProvider, profile *LoadProfile) *Planner {
+	if config.Slot <= 0 {
+		config.Slot = time.Hour
+	}
+	if config.MaxWatts == 0 {
+		config.MaxWatts = defaultRanges["permanentWatts"].Max
+	}
+	return &Planner{Config: config, Forecast: forecast, Profile: profile, Location: time.Local}
+}
+
+// SetTariffs set the tariff windows used for planning
+func (p *Planner) SetTariffs(windows []TariffWindow) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tariffs = append([]TariffWindow{}, windows...)
+}
+
+// planName plan name used in the scheduler
+func (p *Planner) planName() string {
+	return "planner/" + p.Config.SerialNumber
+}
+
+// Plan generate schedule for the day containing the given time
+func (p *Planner) Plan(ctx context.Context, day time.Time) ([]ScheduleEntry, error) {
+	if p.Config.SerialNumber == "" {
+		return nil, errors.New("planner need serial number")
+	}
+	location := p.Location
+	if location == nil {
+		location = time.Local
+	}
+	local := day.In(location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	end := start.AddDate(0, 0, 1)
+
+	var forecast []ForecastSlot
+	if p.Forecast != nil {
+		var err error
+		forecast, err = p.Forecast.Forecast(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.mu.Lock()
+	tariffs := append([]TariffWindow{}, p.tariffs...)
+	p.mu.Unlock()
+
+	cheap := p.Config.CheapPrice
+	if cheap == 0 {
+		sum, count := 0.0, 0
+		for _, w := range tariffs {
+			if w.End.After(start) && w.Start.Before(end) {
+				sum += w.Price
+				count++
+			}
+		}
+		if count > 0 {
+			cheap = 0.8 * sum / float64(count)
+		}
+	}
+
+	var profile []ProfileSlot
+	if p.Profile != nil {
+		profile = p.Profile.Day()
+	}
+
+	entries := make([]ScheduleEntry, 0)
+	if limit := p.chargeLimit(forecast, start, end); limit > 0 {
+		entries = append(entries, ScheduleEntry{At: start, SerialNumber: p.Config.SerialNumber,
+			Setting: "upperLimit", Value: limit})
+	}
+	last := math.NaN()
+	for t := start; t.Before(end); t = t.Add(p.Config.Slot) {
+		watts := p.outputTarget(t, profile, tariffs, cheap)
+		if watts == last {
+			continue
+		}
+		last = watts
+		entries = append(entries, ScheduleEntry{At: t, SerialNumber: p.Config.SerialNumber,
+			Setting: "permanentWatts", Value: watts})
+	}
+	return entries, nil
+}
+
+// chargeLimit return charge limit depending on the forecasted energy of the day
+func (p *Planner) chargeLimit(forecast []ForecastSlot, start, end time.Time) float64 {
+	if p.Config.SunnyDayWh > 0 && p.Config.SunnyChargeLimit > 0 {
+		energy := 0.0
+		for _, s := range forecast {
+			if s.End.After(start) && s.Start.Before(end) {
+				energy += s.Watts * s.End.Sub(s.Start).Hours()
+			}
+		}
+		if energy >= p.Config.SunnyDayWh {
+			return p.Config.SunnyChargeLimit
+		}
+	}
+	return p.Config.DefaultChargeLimit
+}
+
+// outputTarget output target of the slot, cheap slots use the grid and keep the
+// battery, otherwise the typical consumption is covered
+func (p *Planner) outputTarget(t time.Time, profile []ProfileSlot, tariffs []TariffWindow, cheap float64) float64 {
+	if w, ok := tariffAt(tariffs, t); ok && cheap > 0 && w.Price <= cheap {
+		return p.Config.MinWatts
+	}
+	watts := p.Config.BaseWatts
+	if len(profile) > 0 && p.Profile != nil {
+		local := t.In(p.Profile.location)
+		minutes := local.Hour()*60 + local.Minute()
+		slot := profile[minutes*len(profile)/(24*60)]
+		if slot.Samples > 0 {
+			watts = slot.Consumption
+		}
+	}
+	return math.Round(math.Min(math.Max(watts, p.Config.MinWatts), p.Config.MaxWatts))
+}
+
+// Execute plan the day and replace the previous plan in the scheduler
+func (p *Planner) Execute(ctx context.Context, day time.Time, scheduler *Scheduler) ([]ScheduleEntry, error) {
+	entries, err := p.Plan(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Replace(p.planName(), entries...)
+	return entries, nil
+}
@@ -0,0 +1,68 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testForecast []ForecastSlot
+
+func (f testForecast) Forecast(context.Context, time.Time, time.Time) ([]ForecastSlot, error) {
+	return f, nil
+}
+
+func TestParseForecastSolar(t *testing.T) {
+	data, err := os.ReadFile("testdata/forecast_solar.json")
+	assert.NoError(t, err)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	from := time.Date(2025, 4, 7, 0, 0, 0, 0, berlin)
+	slots, err := parseForecastSolar(data, from, from.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Len(t, slots, 6)
+	assert.Equal(t, time.Date(2025, 4, 7, 8, 0, 0, 0, berlin), slots[2].Start.In(berlin))
+	assert.Equal(t, 640.0, slots[2].Watts)
+	// gaps of more than two hours use a one hour slot
+	assert.Equal(t, time.Hour, slots[3].End.Sub(slots[3].Start))
+}
+
+func TestPlanner(t *testing.T) {
+	day := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	forecast := testForecast{{Start: day.Add(10 * time.Hour), End: day.Add(16 * time.Hour), Watts: 2000}}
+	planner := NewPlanner(PlannerConfig{SerialNumber: "HW51TEST", BaseWatts: 150, MinWatts: 0,
+		DefaultChargeLimit: 90, SunnyChargeLimit: 100, SunnyDayWh: 10000}, forecast, nil)
+	planner.Location = time.UTC
+	planner.SetTariffs([]TariffWindow{
+		{Start: day, End: day.Add(6 * time.Hour), Price: 0.10},
+		{Start: day.Add(6 * time.Hour), End: day.Add(24 * time.Hour), Price: 0.35},
+	})
+	entries, err := planner.Plan(context.Background(), day.Add(12*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []ScheduleEntry{
+		{At: day, SerialNumber: "HW51TEST", Setting: "upperLimit", Value: 100},
+		{At: day, SerialNumber: "HW51TEST", Setting: "permanentWatts", Value: 0},
+		{At: day.Add(6 * time.Hour), SerialNumber: "HW51TEST", Setting: "permanentWatts", Value: 150},
+	}, entries)
+
+	scheduler := NewScheduler(nil)
+	scheduler.Replace("planner/HW51TEST", entries...)
+	scheduler.Replace("planner/HW51TEST", entries[:1]...)
+	assert.Len(t, scheduler.Pending(), 1)
+	due, next := scheduler.due(day)
+	assert.Len(t, due, 1)
+	assert.Equal(t, time.Duration(-1), next)
+}
@@ -0,0 +1,85 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// DefaultPollInterval interval of the poller if not defined
+const DefaultPollInterval = time.Minute
+
+// Poller read the quota of devices using the REST API and dispatch it as
+// telemetry. The interval is stretched if the API budget of the access key
+// runs low.
+type Poller struct {
+	Client  *Client
+	Devices []string
+	// Interval poll interval, DefaultPollInterval if 0
+	Interval time.Duration
+	// MaxInterval upper limit of the stretched interval, not limited if 0
+	MaxInterval time.Duration
+}
+
+// NewPoller create poller of the devices, aliases and groups are resolved
+func NewPoller(client *Client, interval time.Duration, devices ...string) *Poller {
+	return &Poller{Client: client, Devices: expandDevices(devices), Interval: interval}
+}
+
+// Poll read the quota of all devices once
+func (p *Poller) Poll(ctx context.Context) error {
+	var lastErr error
+	for _, sn := range p.Devices {
+		data, err := p.Client.GetDeviceAllParameters(ctx, sn)
+		if err != nil {
+			log.Log.Errorf("Poll of %s failed: %v", sn, err)
+			lastErr = err
+			continue
+		}
+		DispatchTelemetryContext(ctx, NormalizeTelemetry(sn, SourceHttp, data, now()))
+	}
+	return lastErr
+}
+
+// NextInterval return the interval until the next poll
+func (p *Poller) NextInterval() time.Duration {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	next := APIBudgets.Stretch(p.Client.accessToken, interval, len(p.Devices))
+	if next > interval {
+		log.Log.Debugf("Poll interval stretched to %v by the API budget", next)
+	}
+	if p.MaxInterval > 0 && next > p.MaxInterval {
+		next = p.MaxInterval
+	}
+	return next
+}
+
+// Run poll until the context is done, usable as runtime component
+func (p *Poller) Run(ctx context.Context) error {
+	clock := CurrentClock()
+	for {
+		if err := p.Poll(ctx); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(p.NextInterval()):
+		}
+	}
+}
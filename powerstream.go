@@ -0,0 +1,129 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PowerStream command codes
+const (
+	cmdCodePermanentWatts = "WN511_SET_PERMANENT_WATTS_PACK"
+	cmdCodeBatLower       = "WN511_SET_BAT_LOWER_PACK"
+	cmdCodeBatUpper       = "WN511_SET_BAT_UPPER_PACK"
+	cmdCodeSupplyPriority = "WN511_SET_SUPPLY_PRIORITY_PACK"
+)
+
+// SupplyPriority PowerStream power supply mode
+type SupplyPriority int
+
+const (
+	// PrioritizePowerSupply feed household first
+	PrioritizePowerSupply SupplyPriority = 0
+	// PrioritizeStorage charge battery first
+	PrioritizeStorage SupplyPriority = 1
+)
+
+const (
+	quotaSupplyPriority = "20_1.supplyPriority"
+	mqttSupplyPriority  = "inverterHeartbeat.supplyPriority"
+)
+
+func (p SupplyPriority) String() string {
+	switch p {
+	case PrioritizePowerSupply:
+		return "power supply"
+	case PrioritizeStorage:
+		return "storage"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// SetPermanentWatts set PowerStream output power in watt, validated against the device limits
+func (client *Client) SetPermanentWatts(ctx context.Context, serialNumber string, watts float64) (*CmdSetResponse, error) {
+	if err := DeviceLimits.Validate(serialNumber, "permanentWatts", watts); err != nil {
+		return nil, err
+	}
+	// Ecoflow need to set a value times by 10
+	return client.SetCommand(ctx, CmdSetRequest{
+		CmdCode: cmdCodePermanentWatts,
+		Sn:      serialNumber,
+		Params:  map[string]interface{}{"permanentWatts": watts * 10},
+	})
+}
+
+// SetBatteryLowerLimit set PowerStream discharge limit of the battery in percent
+func (client *Client) SetBatteryLowerLimit(ctx context.Context, serialNumber string, percent int) (*CmdSetResponse, error) {
+	if err := DeviceLimits.Validate(serialNumber, "lowerLimit", float64(percent)); err != nil {
+		return nil, err
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		CmdCode: cmdCodeBatLower,
+		Sn:      serialNumber,
+		Params:  map[string]interface{}{"lowerLimit": percent},
+	})
+}
+
+// SetBatteryUpperLimit set PowerStream charge limit of the battery in percent
+func (client *Client) SetBatteryUpperLimit(ctx context.Context, serialNumber string, percent int) (*CmdSetResponse, error) {
+	if err := DeviceLimits.Validate(serialNumber, "upperLimit", float64(percent)); err != nil {
+		return nil, err
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		CmdCode: cmdCodeBatUpper,
+		Sn:      serialNumber,
+		Params:  map[string]interface{}{"upperLimit": percent},
+	})
+}
+
+// SetSupplyPriority set PowerStream supply priority and verify it using the MQTT echo
+func (client *Client) SetSupplyPriority(ctx context.Context, serialNumber string, priority SupplyPriority) (*CmdSetResponse, error) {
+	if priority != PrioritizePowerSupply && priority != PrioritizeStorage {
+		return nil, &ValidationError{SerialNumber: serialNumber, Parameter: "supplyPriority",
+			Value: float64(priority), Range: Range{Min: 0, Max: 1}}
+	}
+	sent := now()
+	resp, err := client.SetCommand(ctx, CmdSetRequest{
+		CmdCode: cmdCodeSupplyPriority,
+		Sn:      serialNumber,
+		Params:  map[string]interface{}{"supplyPriority": int(priority)},
+	})
+	if err != nil {
+		return resp, err
+	}
+	return resp, client.verifyValue(ctx, resp.Id, serialNumber, sent, quotaSupplyPriority,
+		[]string{mqttSupplyPriority}, float64(priority))
+}
+
+// SupplyPriorityMaxAge maximum age of a reported supply priority used by
+// GetSupplyPriority, older values are read again using the quota
+var SupplyPriorityMaxAge = 5 * time.Minute
+
+// GetSupplyPriority return current PowerStream supply priority, the latest
+// reported value is used if it is not older than SupplyPriorityMaxAge
+func (client *Client) GetSupplyPriority(ctx context.Context, serialNumber string) (SupplyPriority, error) {
+	for _, key := range []string{mqttSupplyPriority, quotaSupplyPriority} {
+		if v, ok := States.Get(serialNumber, key); ok && since(v.Timestamp) <= SupplyPriorityMaxAge {
+			if f, ok := v.Value.(float64); ok {
+				return SupplyPriority(f), nil
+			}
+		}
+	}
+	value, err := client.getQuotaValue(ctx, serialNumber, quotaSupplyPriority)
+	if err != nil {
+		return 0, err
+	}
+	return SupplyPriority(value), nil
+}
@@ -0,0 +1,330 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+const (
+	// EventPricesUpdated new tariff windows received, Data contains []TariffWindow
+	EventPricesUpdated EventType = "prices.updated"
+	// EventPriceLevel price level changed, Data contains PriceLevelChange
+	EventPriceLevel EventType = "prices.level"
+)
+
+// PriceLevel classification of the current electricity price
+type PriceLevel int
+
+const (
+	PriceNormal PriceLevel = iota
+	// PriceCheap charging from grid is recommended
+	PriceCheap
+	// PriceExpensive discharging the battery is recommended
+	PriceExpensive
+)
+
+func (l PriceLevel) String() string {
+	switch l {
+	case PriceCheap:
+		return "cheap"
+	case PriceExpensive:
+		return "expensive"
+	default:
+		return "normal"
+	}
+}
+
+// PriceLevelChange data of the EventPriceLevel event
+type PriceLevelChange struct {
+	Window TariffWindow `json:"window"`
+	Level  PriceLevel   `json:"level"`
+}
+
+// PriceProvider provider of hourly electricity prices
+type PriceProvider interface {
+	Prices(ctx context.Context, from, to time.Time) ([]TariffWindow, error)
+}
+
+// Awattar aWATTar market price provider, prices are returned in EUR per kWh
+// without taxes and fees
+type Awattar struct {
+	// Country "de" or "at"
+	Country    string
+	HTTPClient *http.Client
+}
+
+type awattarResponse struct {
+	Data []struct {
+		StartTimestamp int64   `json:"start_timestamp"`
+		EndTimestamp   int64   `json:"end_timestamp"`
+		MarketPrice    float64 `json:"marketprice"`
+		Unit           string  `json:"unit"`
+	} `json:"data"`
+}
+
+// Prices request market prices of the time range
+func (a *Awattar) Prices(ctx context.Context, from, to time.Time) ([]TariffWindow, error) {
+	country := a.Country
+	if country == "" {
+		country = "de"
+	}
+	url := fmt.Sprintf("https://api.awattar.%s/v1/marketdata?start=%d&end=%d", country,
+		from.UnixMilli(), to.UnixMilli())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := a.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("aWATTar request failed: %s", resp.Status)
+	}
+	return parseAwattar(body)
+}
+
+func parseAwattar(body []byte) ([]TariffWindow, error) {
+	response := &awattarResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, err
+	}
+	windows := make([]TariffWindow, 0, len(response.Data))
+	for _, d := range response.Data {
+		if d.Unit != "" && d.Unit != "Eur/MWh" {
+			return nil, fmt.Errorf("unsupported aWATTar unit %s", d.Unit)
+		}
+		windows = append(windows, TariffWindow{Start: time.UnixMilli(d.StartTimestamp),
+			End: time.UnixMilli(d.EndTimestamp), Price: d.MarketPrice / 1000})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows, nil
+}
+
+// tibberEndpoint GraphQL endpoint of the Tibber API
+const tibberEndpoint = "https://api.tibber.com/v1-beta/gql"
+
+// tibberQuery price information of today and tomorrow of all homes
+const tibberQuery = `{ viewer { homes { id currentSubscription { priceInfo {
+	today { total startsAt } tomorrow { total startsAt } } } } } }`
+
+// Tibber Tibber tariff price provider, prices are returned in the currency of
+// the contract per kWh including taxes and fees. Tibber publishes the prices of
+// today and tomorrow only, the requested time range is limited by them.
+type Tibber struct {
+	// Token personal access token of the Tibber developer account
+	Token string
+	// HomeID home of the contract, first home of the account if empty
+	HomeID string
+	// Endpoint GraphQL endpoint, the Tibber API if empty
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+type tibberPrice struct {
+	Total    float64   `json:"total"`
+	StartsAt time.Time `json:"startsAt"`
+}
+
+type tibberResponse struct {
+	Data struct {
+		Viewer struct {
+			Homes []struct {
+				ID                  string `json:"id"`
+				CurrentSubscription *struct {
+					PriceInfo struct {
+						Today    []tibberPrice `json:"today"`
+						Tomorrow []tibberPrice `json:"tomorrow"`
+					} `json:"priceInfo"`
+				} `json:"currentSubscription"`
+			} `json:"homes"`
+		} `json:"viewer"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Prices request tariff prices of the time range
+func (t *Tibber) Prices(ctx context.Context, from, to time.Time) ([]TariffWindow, error) {
+	query, err := json.Marshal(map[string]string{"query": tibberQuery})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := t.Endpoint
+	if endpoint == "" {
+		endpoint = tibberEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	req.Header.Set("Content-Type", "application/json")
+	client := t.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Tibber request failed: %s", resp.Status)
+	}
+	windows, err := parseTibber(body, t.HomeID)
+	if err != nil {
+		return nil, err
+	}
+	inRange := make([]TariffWindow, 0, len(windows))
+	for _, w := range windows {
+		if w.End.After(from) && w.Start.Before(to) {
+			inRange = append(inRange, w)
+		}
+	}
+	return inRange, nil
+}
+
+// parseTibber return the price windows of the home, a window ends with the
+// start of the next one, the last one after an hour
+func parseTibber(body []byte, homeID string) ([]TariffWindow, error) {
+	response := &tibberResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("Tibber request failed: %s", response.Errors[0].Message)
+	}
+	for _, home := range response.Data.Viewer.Homes {
+		if homeID != "" && home.ID != homeID {
+			continue
+		}
+		if home.CurrentSubscription == nil {
+			return nil, fmt.Errorf("Tibber home %s has no subscription", home.ID)
+		}
+		info := home.CurrentSubscription.PriceInfo
+		prices := append(append([]tibberPrice{}, info.Today...), info.Tomorrow...)
+		sort.Slice(prices, func(i, j int) bool { return prices[i].StartsAt.Before(prices[j].StartsAt) })
+		windows := make([]TariffWindow, 0, len(prices))
+		for i, p := range prices {
+			end := p.StartsAt.Add(time.Hour)
+			if i+1 < len(prices) && prices[i+1].StartsAt.Before(end) {
+				end = prices[i+1].StartsAt
+			}
+			windows = append(windows, TariffWindow{Start: p.StartsAt, End: end, Price: p.Total})
+		}
+		return windows, nil
+	}
+	return nil, fmt.Errorf("Tibber home %q not found", homeID)
+}
+
+// PriceMonitor poll a price provider and publish price events
+type PriceMonitor struct {
+	Provider PriceProvider
+	// CheapPrice and ExpensivePrice thresholds of the price levels
+	CheapPrice     float64
+	ExpensivePrice float64
+	// Interval polling interval of the provider
+	Interval time.Duration
+	// Horizon time range requested from the provider
+	Horizon time.Duration
+	Bus     *EventBus
+	windows []TariffWindow
+	current PriceLevelChange
+	started bool
+}
+
+// NewPriceMonitor create price monitor publishing on the global event bus
+func NewPriceMonitor(provider PriceProvider, cheapPrice, expensivePrice float64) *PriceMonitor {
+	return &PriceMonitor{Provider: provider, CheapPrice: cheapPrice, ExpensivePrice: expensivePrice,
+		Interval: time.Hour, Horizon: 48 * time.Hour, Bus: Events}
+}
+
+// Level classify price
+func (m *PriceMonitor) Level(price float64) PriceLevel {
+	switch {
+	case price <= m.CheapPrice:
+		return PriceCheap
+	case price >= m.ExpensivePrice:
+		return PriceExpensive
+	default:
+		return PriceNormal
+	}
+}
+
+// Run poll the provider and publish events until the context is done
+func (m *PriceMonitor) Run(ctx context.Context) error {
+	lastPoll := time.Time{}
+	for {
+		current := now()
+		if current.Sub(lastPoll) >= m.Interval {
+			windows, err := m.Provider.Prices(ctx, current.Truncate(time.Hour), current.Add(m.Horizon))
+			if err != nil {
+				services.ServerMessage("Error requesting electricity prices: %v", err)
+			} else {
+				m.windows = windows
+				m.Bus.Publish(Event{Type: EventPricesUpdated, Data: windows})
+			}
+			lastPoll = current
+		}
+		m.update(current)
+		wait := m.Interval - since(lastPoll)
+		if w, ok := tariffAt(m.windows, current); ok && w.End.Sub(current) < wait {
+			wait = w.End.Sub(current)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-CurrentClock().After(wait):
+		}
+	}
+}
+
+// update publish level event if the current window or level changed
+func (m *PriceMonitor) update(now time.Time) {
+	w, ok := tariffAt(m.windows, now)
+	if !ok {
+		return
+	}
+	change := PriceLevelChange{Window: w, Level: m.Level(w.Price)}
+	if m.started && change.Level == m.current.Level {
+		m.current = change
+		return
+	}
+	m.started = true
+	m.current = change
+	m.Bus.Publish(Event{Type: EventPriceLevel, Time: now, Data: change})
+}
@@ -0,0 +1,77 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceMonitor(t *testing.T) {
+	data, err := os.ReadFile("testdata/awattar.json")
+	assert.NoError(t, err)
+	windows, err := parseAwattar(data)
+	assert.NoError(t, err)
+	assert.Len(t, windows, 3)
+	assert.InDelta(t, 0.0641, windows[1].Price, 0.00001)
+
+	bus := NewEventBus()
+	var levels []PriceLevel
+	unsubscribe := bus.Subscribe(func(e Event) {
+		levels = append(levels, e.Data.(PriceLevelChange).Level)
+	}, EventPriceLevel)
+	monitor := NewPriceMonitor(nil, 0.07, 0.15)
+	monitor.Bus = bus
+	monitor.windows = windows
+	for _, w := range windows {
+		monitor.update(w.Start.Add(time.Minute))
+		monitor.update(w.Start.Add(2 * time.Minute))
+	}
+	bus.Publish(Event{Type: EventPricesUpdated})
+	unsubscribe()
+	monitor.update(windows[0].Start)
+	assert.Equal(t, []PriceLevel{PriceNormal, PriceCheap, PriceExpensive}, levels)
+}
+
+func TestTibberPrices(t *testing.T) {
+	data, err := os.ReadFile("testdata/tibber.json")
+	assert.NoError(t, err)
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tibber := &Tibber{Token: "token", Endpoint: server.URL}
+	from := time.Date(2025, 4, 7, 0, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	windows, err := tibber.Prices(context.Background(), from, from.Add(48*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", auth)
+	if assert.Len(t, windows, 3) {
+		assert.Equal(t, 0.2931, windows[0].Price)
+		assert.True(t, windows[0].End.Equal(windows[1].Start))
+		assert.Equal(t, time.Hour, windows[2].End.Sub(windows[2].Start))
+	}
+
+	tibber.HomeID = "unknown"
+	_, err = tibber.Prices(context.Background(), from, from.Add(time.Hour))
+	assert.Error(t, err)
+	_, err = parseTibber([]byte(`{"errors":[{"message":"invalid token"}]}`), "")
+	assert.ErrorContains(t, err, "invalid token")
+}
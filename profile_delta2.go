@@ -0,0 +1,48 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+// Delta2Quota quota of the Delta 2, the AC output is controlled by the MPPT module
+type Delta2Quota struct {
+	Soc            float64        `quota:"pd.soc"`
+	WattsInSum     float64        `quota:"pd.wattsInSum"`
+	WattsOutSum    float64        `quota:"pd.wattsOutSum"`
+	RemainTime     float64        `quota:"pd.remainTime"`
+	AcAutoOn       bool           `quota:"pd.newAcAutoOnCfg"`
+	AcEnabled      bool           `quota:"mppt.cfgAcEnabled"`
+	AcXboost       bool           `quota:"mppt.cfgAcXboost"`
+	AcChargeWatts  float64        `quota:"mppt.cfgChgWatts"`
+	SolarWatts     float64        `quota:"mppt.inWatts"`
+	AcInputWatts   float64        `quota:"inv.inputWatts"`
+	AcOutputWatts  float64        `quota:"inv.outputWatts"`
+	MaxChargeSoc   float64        `quota:"bms_emsStatus.maxChargeSoc"`
+	MinDsgSoc      float64        `quota:"bms_emsStatus.minDsgSoc"`
+	Battery        ExtraBattery   `quota:"bms_bmsStatus"`
+	ExtraBatteries []ExtraBattery `quota:"bms_slave_bmsSlaveStatus_%d"`
+}
+
+// River2Quota quota of the River 2 series without extra batteries
+type River2Quota struct {
+	Soc           float64      `quota:"pd.soc"`
+	WattsInSum    float64      `quota:"pd.wattsInSum"`
+	WattsOutSum   float64      `quota:"pd.wattsOutSum"`
+	RemainTime    float64      `quota:"pd.remainTime"`
+	AcEnabled     bool         `quota:"mppt.cfgAcEnabled"`
+	AcXboost      bool         `quota:"mppt.cfgAcXboost"`
+	AcChargeWatts float64      `quota:"mppt.cfgChgWatts"`
+	SolarWatts    float64      `quota:"mppt.inWatts"`
+	AcInputWatts  float64      `quota:"inv.inputWatts"`
+	AcOutputWatts float64      `quota:"inv.outputWatts"`
+	MaxChargeSoc  float64      `quota:"bms_emsStatus.maxChargeSoc"`
+	MinDsgSoc     float64      `quota:"bms_emsStatus.minDsgSoc"`
+	Battery       ExtraBattery `quota:"bms_bmsStatus"`
+}
@@ -0,0 +1,110 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExtraBattery quota of an extra battery pack
+type ExtraBattery struct {
+	Soc       float64 `quota:".soc"`
+	Temp      float64 `quota:".temp"`
+	Vol       float64 `quota:".vol"`
+	RemainCap float64 `quota:".remainCap"`
+	FullCap   float64 `quota:".fullCap"`
+	Cycles    float64 `quota:".cycles"`
+}
+
+// Delta2MaxQuota quota of the Delta 2 Max
+type Delta2MaxQuota struct {
+	Soc            float64        `quota:"pd.soc"`
+	WattsInSum     float64        `quota:"pd.wattsInSum"`
+	WattsOutSum    float64        `quota:"pd.wattsOutSum"`
+	RemainTime     float64        `quota:"pd.remainTime"`
+	AcAutoOn       bool           `quota:"pd.newAcAutoOnCfg"`
+	AcEnabled      bool           `quota:"inv.cfgAcEnabled"`
+	AcXboost       bool           `quota:"inv.cfgAcXboost"`
+	AcInputWatts   float64        `quota:"inv.inputWatts"`
+	AcOutputWatts  float64        `quota:"inv.outputWatts"`
+	MaxChargeSoc   float64        `quota:"bms_emsStatus.maxChargeSoc"`
+	MinDsgSoc      float64        `quota:"bms_emsStatus.minDsgSoc"`
+	Battery        ExtraBattery   `quota:"bms_bmsStatus"`
+	ExtraBatteries []ExtraBattery `quota:"bms_slave_bmsSlaveStatus_%d"`
+}
+
+// DeltaMaxQuota quota of the Delta Max
+type DeltaMaxQuota struct {
+	Soc            float64        `quota:"pd.soc"`
+	WattsInSum     float64        `quota:"pd.wattsInSum"`
+	WattsOutSum    float64        `quota:"pd.wattsOutSum"`
+	RemainTime     float64        `quota:"pd.remainTime"`
+	AcEnabled      bool           `quota:"inv.cfgAcEnabled"`
+	AcXboost       bool           `quota:"inv.cfgAcXboost"`
+	AcInputWatts   float64        `quota:"inv.inputWatts"`
+	AcOutputWatts  float64        `quota:"inv.outputWatts"`
+	MaxChargeSoc   float64        `quota:"ems.maxChargeSoc"`
+	MinDsgSoc      float64        `quota:"ems.minDsgSoc"`
+	Battery        ExtraBattery   `quota:"bmsMaster"`
+	ExtraBatteries []ExtraBattery `quota:"bmsSlave%d"`
+}
+
+// SetACOutput switch AC output and X-Boost. The Delta 2 Max uses the inverter
+// module, the Delta 2 and River 2 series the MPPT module and Delta Max/Pro the
+// old command set interface.
+func (client *Client) SetACOutput(ctx context.Context, serialNumber string, enabled, xboost bool) (*CmdSetResponse, error) {
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2Max:
+		cmdReq.ModuleType = ModuleTypeInv
+		cmdReq.OperateType = "acOutCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": boolToInt(enabled), "xboost": boolToInt(xboost),
+			"out_voltage": -1, "out_freq": 255}
+	case ModelDelta2, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		cmdReq.ModuleType = ModuleTypeMppt
+		cmdReq.OperateType = "acOutCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": boolToInt(enabled), "xboost": boolToInt(xboost),
+			"out_voltage": -1, "out_freq": 255}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 66,
+			"enabled": boolToInt(enabled), "xboost": boolToInt(xboost)}
+	default:
+		return nil, fmt.Errorf("AC output not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
+
+// SetACAlwaysOn configure AC output to be switched on automatically as long as the
+// battery level is above minSoc
+func (client *Client) SetACAlwaysOn(ctx context.Context, serialNumber string, on bool, minSoc int) (*CmdSetResponse, error) {
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch DetectModel(serialNumber) {
+	case ModelDelta2Max, ModelDelta2, ModelRiver2, ModelRiver2Max, ModelRiver2Pro:
+		cmdReq.ModuleType = ModuleTypePd
+		cmdReq.OperateType = "newAcAutoOnCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": boolToInt(on), "minAcSoc": minSoc}
+	case ModelDeltaMax, ModelDeltaPro:
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 95, "acautooutConfig": boolToInt(on),
+			"minAcoutSoc": minSoc}
+	default:
+		return nil, fmt.Errorf("AC always on not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
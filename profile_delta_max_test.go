@@ -0,0 +1,54 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadQuotaFixture(t *testing.T, name string) map[string]interface{} {
+	data, err := os.ReadFile("testdata/" + name)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+func TestDelta2MaxQuota(t *testing.T) {
+	q := &Delta2MaxQuota{}
+	unmapped, err := DecodeQuota(loadQuotaFixture(t, "delta2max_quota.json"), q)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pd.beepMode"}, unmapped)
+	assert.Equal(t, 87.0, q.Soc)
+	assert.True(t, q.AcXboost)
+	assert.True(t, q.AcAutoOn)
+	assert.Equal(t, 41.0, q.Battery.Cycles)
+	assert.Len(t, q.ExtraBatteries, 2)
+	assert.Equal(t, 78.0, q.ExtraBatteries[1].Soc)
+}
+
+func TestDeltaMaxQuota(t *testing.T) {
+	q := &DeltaMaxQuota{}
+	unmapped, err := DecodeQuota(loadQuotaFixture(t, "deltamax_quota.json"), q)
+	assert.NoError(t, err)
+	assert.Empty(t, unmapped)
+	assert.False(t, q.AcXboost)
+	assert.Equal(t, 90.0, q.MaxChargeSoc)
+	assert.Len(t, q.ExtraBatteries, 1)
+	assert.Equal(t, 70.0, q.ExtraBatteries[0].Soc)
+}
@@ -0,0 +1,43 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+// PowerStreamQuota quota of the PowerStream micro inverter, power values are
+// reported in 0.1 W
+type PowerStreamQuota struct {
+	Pv1InputWatts  float64 `quota:"20_1.pv1InputWatts"`
+	Pv1InputVolt   float64 `quota:"20_1.pv1InputVolt"`
+	Pv2InputWatts  float64 `quota:"20_1.pv2InputWatts"`
+	Pv2InputVolt   float64 `quota:"20_1.pv2InputVolt"`
+	BatSoc         float64 `quota:"20_1.batSoc"`
+	BatInputWatts  float64 `quota:"20_1.batInputWatts"`
+	BatTemp        float64 `quota:"20_1.batTemp"`
+	InvOutputWatts float64 `quota:"20_1.invOutputWatts"`
+	InvTemp        float64 `quota:"20_1.invTemp"`
+	PermanentWatts float64 `quota:"20_1.permanentWatts"`
+	SupplyPriority int     `quota:"20_1.supplyPriority"`
+	LowerLimit     float64 `quota:"20_1.lowerLimit"`
+	UpperLimit     float64 `quota:"20_1.upperLimit"`
+	InvBrightness  float64 `quota:"20_1.invBrightness"`
+	RatedPower     float64 `quota:"20_1.ratedPower"`
+}
+
+// SmartPlugQuota quota of the Smart Plug, power values are reported in 0.1 W
+type SmartPlugQuota struct {
+	Watts      float64 `quota:"2_1.watts"`
+	Volt       float64 `quota:"2_1.volt"`
+	Current    float64 `quota:"2_1.current"`
+	Temp       float64 `quota:"2_1.temp"`
+	SwitchOn   bool    `quota:"2_1.switchSta"`
+	Brightness float64 `quota:"2_1.brightness"`
+	MaxWatts   float64 `quota:"2_1.maxWatts"`
+}
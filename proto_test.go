@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/log"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func TestProtoDecode(t *testing.T) {
@@ -39,7 +40,7 @@ func TestProtoDecode(t *testing.T) {
 	assert.NoError(t, err)
 	log.Log.Debugf("%#v", ih)
 	assert.Equal(t, uint32(1743087465), uint32(*ih.Timestamp))
-	assert.Equal(t, []uint8(nil), ih.unknownFields)
+	assert.Empty(t, ih.ProtoReflect().GetUnknown())
 }
 
 func TestGenerateOnProto(t *testing.T) {
@@ -101,3 +102,14 @@ func generateInt(value int32) *int32 {
 func generateUInt(value uint32) *uint32 {
 	return &value
 }
+
+func TestProtoReexports(t *testing.T) {
+	assert.Equal(t, PlCmdSets(254), PlCmdSets_PL_EXT_CMD_SETS)
+	assert.Equal(t, "PL_CMD_ID_XLOG", PlCmdId_name[int32(PlCmdId_PL_CMD_ID_XLOG)])
+	assert.Equal(t, int32(1), PlCmdSets_value["PL_BASIC_CMD_SETS"])
+	assert.Equal(t, PlCmdId_PL_CMD_ID_WATTH.String(), "PL_CMD_ID_WATTH")
+	for _, f := range []protoreflect.FileDescriptor{File_ecopacket_proto, File_platform_proto,
+		File_powerstream_proto, File_stream_proto} {
+		assert.NotNil(t, f)
+	}
+}
@@ -13,24 +13,37 @@ package ecoflow
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	sync "sync"
+	"sync/atomic"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tknie/ecoflow/decode"
 	"github.com/tknie/log"
 	"github.com/tknie/services"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 )
 
 const layout = "2006-01-02 15:04:05.000"
 
 type statMqtt struct {
-	mu          sync.Mutex
-	mqttCounter uint64
+	mu              sync.Mutex
+	mqttCounter     uint64
+	expected        time.Duration
+	firstReceived   time.Time
+	lastReceived    time.Time
+	gaps            uint64
+	missedIntervals uint64
+	longestGap      time.Duration
+	jitter          time.Duration
 }
 
 type Entry struct {
@@ -44,89 +57,109 @@ type ProtocolHandler interface {
 
 var caller ProtocolHandler
 var mqttStatMap = sync.Map{}
+var statLock sync.Mutex
 var mapStatMqtt = make(map[string]*statMqtt)
+
+// Callback receive the decoded JSON data of the devices.
+//
+// Deprecated: use SetDataCallback or a telemetry handler instead, assigning the
+// variable is not safe while messages are received.
 var Callback func(serialNumber string, data map[string]interface{})
 
 const defaultStatLoop = 300
 
-var lastStatOutput = time.Now()
+var lastStatOutput = now()
 var StatOutput = defaultStatLoop
 
 func StatMqtt() string {
 	var buffer bytes.Buffer
-	for k, v := range mapStatMqtt {
-		buffer.WriteString(fmt.Sprintf("  %s got mqtt=%03d messages\n", k, v.mqttCounter))
+	for _, s := range Stats() {
+		buffer.WriteString(fmt.Sprintf("  %s got mqtt=%03d messages, missed=%d gaps=%d longest gap=%v jitter=%v\n",
+			s.SerialNumber, s.Messages, s.MissedIntervals, s.Gaps, s.LongestGap, s.Jitter))
 	}
 	return buffer.String()
 }
 
+// DisplayPayload decode protobuf payload and dispatch it using a new message context
 func DisplayPayload(sn string, payload []byte) bool {
-	log.Log.Debugf("Base64: %s", base64.RawStdEncoding.EncodeToString(payload))
-	log.Log.Debugf("Payload %s", FormatByteBuffer("MQTT Body", payload))
+	ctx, cancel := MessageContext()
+	defer cancel()
+	return displayPayload(ctx, sn, payload)
+}
+
+func displayPayload(ctx context.Context, sn string, payload []byte) bool {
+	if log.IsDebugLevel() {
+		log.Log.Debugf("Base64: %s", base64.RawStdEncoding.EncodeToString(payload))
+		log.Log.Debugf("Payload %s", FormatByteBuffer("MQTT Body", payload))
+	}
 
-	platform := &SendHeaderMsg{}
+	platform := headerPool.Get().(*SendHeaderMsg)
+	defer func() {
+		platform.Reset()
+		headerPool.Put(platform)
+	}()
 	err := proto.Unmarshal(payload, platform)
 	if err != nil {
 		log.Log.Errorf("Unable to parse message message %v: %v", payload, err)
+		recordUndecoded(messageTopic(ctx), sn, err.Error(), payload)
+	} else if duplicateFrame(sn, platform.Msg) {
+		log.Log.Debugf("Ignore duplicate frame of %s seq %d", sn, platform.Msg.GetSeq())
+	} else if platform.Msg.GetCmdFunc() == streamCmdFunc {
+		return displayStreamPayload(ctx, sn, platform.Msg, payload)
 	} else {
-		switch platform.Msg.GetCmdId() {
-		case 1:
-
-			ih := &InverterHeartbeat{}
-			err := proto.Unmarshal(platform.Msg.Pdata, ih)
-			if err != nil {
-				log.Log.Errorf("Unable to parse pdata message: %v", err)
-			} else {
-				log.Log.Debugf("-> InverterHearbeat %s", ih)
-
-				if caller != nil {
-					caller.CallHandler(&Entry{object: ih, serialNumber: sn})
-				}
-
-				if log.IsDebugLevel() {
-					log.Log.Debugf("DynamicWatts   %v", ih.GetDynamicWatts())
-					log.Log.Debugf("LowerLimit     %v", ih.GetLowerLimit())
-					log.Log.Debugf("PermanentWatts %v", ih.GetPermanentWatts())
-					log.Log.Debugf("UpperLimit     %v", ih.GetUpperLimit())
-					log.Log.Debugf("InstallCountry %v", ih.GetInstallCountry())
-					log.Log.Debugf("InvOnOff       %v", ih.GetInvOnOff())
-					log.Log.Debugf("Pv10pVolt      %v", ih.GetPv1OpVolt())
-					log.Log.Debugf("Pv1InputVolt   %v", ih.GetPv1InputVolt())
-					log.Log.Debugf("Pv1InputWatts  %v", ih.GetPv1InputWatts())
-					log.Log.Debugf("Pv20pVolt      %v", ih.GetPv2OpVolt())
-					log.Log.Debugf("Pv2InputVolt   %v", ih.GetPv2InputVolt())
-					log.Log.Debugf("Pv2InputWatts  %v", ih.GetPv2InputWatts())
-					log.Log.Debugf("Timestamp      %v", ih.GetTimestamp())
-					log.Log.Debugf("Time           %v", time.Unix(int64(ih.GetTimestamp()), 0))
-				}
-			}
-		case 32:
-			pp := &PowerPack{}
-			err := proto.Unmarshal(platform.Msg.Pdata, pp)
-			if err != nil {
+		msg, err := decode.Pdata(platform.Msg)
+		switch m := msg.(type) {
+		case nil:
+			if !errors.Is(err, decode.ErrUnknownMessage) {
 				log.Log.Errorf("Unable to parse pdata message: %v", err)
-			} else {
-				log.Log.Debugf("Power Pack: %#v", pp)
-				for _, p := range pp.SysPowerStream {
-					if caller != nil {
-						caller.CallHandler(&Entry{object: p, serialNumber: sn})
-					}
-				}
+				break
 			}
-		default:
 			displayHeader(platform.Msg)
+			recordUndecoded(messageTopic(ctx), sn, fmt.Sprintf("unknown cmd id %d", platform.Msg.GetCmdId()), payload)
 			log.Log.Infof("Unknown Cmd ID %d -> %s", platform.Msg.GetCmdId(), sn)
 			log.Log.Infof("Base64: %s", base64.RawStdEncoding.EncodeToString(payload))
 			return false
+		case *InverterHeartbeat:
+			log.Log.Debugf("-> InverterHearbeat %s", m)
+
+			dispatchEntry(ctx, &Entry{object: m, serialNumber: sn})
+
+			if log.IsDebugLevel() {
+				log.Log.Debugf("DynamicWatts   %v", m.GetDynamicWatts())
+				log.Log.Debugf("LowerLimit     %v", m.GetLowerLimit())
+				log.Log.Debugf("PermanentWatts %v", m.GetPermanentWatts())
+				log.Log.Debugf("UpperLimit     %v", m.GetUpperLimit())
+				log.Log.Debugf("InstallCountry %v", m.GetInstallCountry())
+				log.Log.Debugf("InvOnOff       %v", m.GetInvOnOff())
+				log.Log.Debugf("Pv10pVolt      %v", m.GetPv1OpVolt())
+				log.Log.Debugf("Pv1InputVolt   %v", m.GetPv1InputVolt())
+				log.Log.Debugf("Pv1InputWatts  %v", m.GetPv1InputWatts())
+				log.Log.Debugf("Pv20pVolt      %v", m.GetPv2OpVolt())
+				log.Log.Debugf("Pv2InputVolt   %v", m.GetPv2InputVolt())
+				log.Log.Debugf("Pv2InputWatts  %v", m.GetPv2InputWatts())
+				log.Log.Debugf("Timestamp      %v", m.GetTimestamp())
+				log.Log.Debugf("Time           %v", time.Unix(int64(m.GetTimestamp()), 0))
+			}
+		case *PowerPack:
+			log.Log.Debugf("Power Pack: %#v", m)
+			PowerPackLedger.ObservePowerPack(sn, m)
+			for _, p := range m.SysPowerStream {
+				dispatchEntry(ctx, &Entry{object: p, serialNumber: sn})
+			}
 		}
 	}
 	return true
 }
 
 func GetStatEntry(serialNumber string) *statMqtt {
+	statLock.Lock()
+	defer statLock.Unlock()
 	if s, ok := mapStatMqtt[serialNumber]; ok {
 		return s
 	} else {
+		if StatRetention.MaxDevices > 0 && len(mapStatMqtt) >= StatRetention.MaxDevices {
+			compactDevices(StatRetention.MaxDevices - 1)
+		}
 		stat := &statMqtt{}
 		mapStatMqtt[serialNumber] = stat
 		return stat
@@ -135,77 +168,160 @@ func GetStatEntry(serialNumber string) *statMqtt {
 }
 
 // MessageHandler message handle called if MQTT event entered
-func MessageHandler(_ mqtt.Client, msg mqtt.Message) {
+func MessageHandler(client mqtt.Client, msg mqtt.Message) {
+	if isStatusTopic(msg.Topic()) {
+		StatusMessageHandler(client, msg)
+		return
+	}
 	serialNumber := getSnFromTopic(msg.Topic())
+	defer recoverHandler("mqtt message", serialNumber)
+	if serialNumber == "" {
+		log.Log.Errorf("Ignore message without serial number on topic %s", msg.Topic())
+		return
+	}
+	if len(msg.Payload()) > MaxPayloadSize {
+		log.Log.Errorf("Ignore message of %s with %d bytes", serialNumber, len(msg.Payload()))
+		recordUndecoded(msg.Topic(), serialNumber, fmt.Sprintf("payload size %d exceeds maximum", len(msg.Payload())), nil)
+		return
+	}
+	ctx, cancel := MessageContext()
+	defer cancel()
+	ctx = withMessageTopic(ctx, msg.Topic())
+	expected := expectedInterval(serialNumber)
+	// the statistic lock is released before dispatching, handlers may read the
+	// heartbeat statistics
 	stat := GetStatEntry(serialNumber)
 	stat.mu.Lock()
-	defer stat.mu.Unlock()
-
 	stat.mqttCounter++
+	stat.recordHeartbeat(expected, now())
+	counter := stat.mqttCounter
+	stat.mu.Unlock()
+	markSeen(serialNumber, SourceMqtt, now())
 
-	if e, ok := mqttStatMap.Load(msg.Topic()); ok {
-		mqttStatMap.Store(msg.Topic(), e.(int)+1)
-	} else {
-		mqttStatMap.Store(msg.Topic(), 1)
-	}
+	countTopic(msg.Topic())
+	metricMqttMessages.Add(1)
 	if StatOutput > 0 &&
-		lastStatOutput.After(time.Now().Add(time.Duration(StatOutput)*time.Second)) {
-		services.ServerMessage("Received Ecoflow MQTT msgs: %04d", stat.mqttCounter)
+		lastStatOutput.After(now().Add(time.Duration(StatOutput)*time.Second)) {
+		services.ServerMessage("Received Ecoflow MQTT msgs: %04d", counter)
 		mqttStatMap.Range(func(key, value any) bool {
-			log.Log.Infof("Received message of device %s = %d at %v", key, value.(int), time.Now().Format(layout))
+			log.Log.Infof("Received message of device %s = %d at %v", key, value.(*atomic.Uint64).Load(), now().Format(layout))
 			return true
 		})
 	}
 
-	log.Log.Debugf("received message on topic %s; body (retain: %t): %s", msg.Topic(),
-		msg.Retained(), FormatByteBuffer("MQTT Body", msg.Payload()))
 	payload := msg.Payload()
+	if log.IsDebugLevel() {
+		log.Log.Debugf("received message on topic %s; body (retain: %t): %s", msg.Topic(),
+			msg.Retained(), FormatByteBuffer("MQTT Body", payload))
+	}
 
-	data := make(map[string]interface{})
-	err := json.Unmarshal(payload, &data)
-	if err == nil {
-		log.Log.Debugf("JSON: %v", string(payload))
+	var data map[string]interface{}
+	var err error
+	if isJSONPayload(payload) {
+		err = json.Unmarshal(payload, &data)
+	}
+	if subscriptionState != nil {
+		messageID := ""
+		if id, ok := data["id"].(float64); ok {
+			messageID = strconv.FormatFloat(id, 'f', -1, 64)
+		}
+		subscriptionState.Received(msg.Topic(), messageID, now())
+	}
+	if err == nil && data != nil {
 		if log.IsDebugLevel() {
-			cmdId := int(data["cmdId"].(float64))
-			log.Log.Debugf("-> CmdId   %03d", cmdId)
-			log.Log.Debugf("-> CmdFunc %0.1f", data["cmdFunc"].(float64))
-			log.Log.Debugf("-> Version %s", data["version"].(string))
-			log.Log.Debugf("ID           : %f", data["id"].(float64))
+			log.Log.Debugf("JSON: %v", string(payload))
+			log.Log.Debugf("-> CmdId   %v", data["cmdId"])
+			log.Log.Debugf("-> CmdFunc %v", data["cmdFunc"])
+			log.Log.Debugf("-> Version %v", data["version"])
+			log.Log.Debugf("ID           : %v", data["id"])
 		}
-		if _, ok := data["params"]; ok {
-			data = data["params"].(map[string]interface{})
+		Tree(serialNumber).AddMessage(data)
+		observeFirmware(serialNumber, data)
+		if params, ok := data["params"].(map[string]interface{}); ok {
+			data = params
 		}
 		if _, ok := data["serial_number"]; !ok {
 			data["serial_number"] = serialNumber
 		}
 		if _, ok := data["timestamp"]; !ok {
-			data["timestamp"] = time.Now()
+			data["timestamp"] = now()
+		}
+		DispatchTelemetryContext(ctx, NormalizeTelemetry(serialNumber, SourceMqtt, data, now()))
+		if callback := currentDataCallback(); callback != nil {
+			callCallback(callback, serialNumber, data)
 		}
-		Callback(serialNumber, data)
 
 		return
 	}
 
-	start := 0
-	end := len(payload)
-	index := bytes.Index(payload, []byte(serialNumber))
-	if index != -1 {
-		end = index + len(serialNumber)
-	}
-	log.Log.Debugf("Serial index 1: %d/%d %d:%d", index, len(payload), start, end)
-	DisplayPayload(serialNumber, payload[start:end])
-	start = end
-	if len(payload) > index+len(serialNumber) {
-		index = bytes.Index(payload[end:], []byte(serialNumber))
-		if index != -1 {
-			end = end + index + len(serialNumber)
-		} else {
-			end = len(payload)
+	for _, frame := range splitFrames(payload) {
+		displayPayload(ctx, serialNumber, frame)
+	}
+}
+
+// MaxPayloadSize maximum size of MQTT messages, larger messages are ignored
+var MaxPayloadSize = 1 << 20
+
+// splitFrames split concatenated protobuf envelopes into single frames. Each
+// envelope contains one header in field 1, the devices send several envelopes
+// in one MQTT message. A truncated rest is returned as last frame, so it is
+// reported as undecoded payload.
+func splitFrames(payload []byte) [][]byte {
+	var frames [][]byte
+	rest := payload
+	for len(rest) > 0 {
+		num, typ, n := protowire.ConsumeTag(rest)
+		if n < 0 {
+			break
+		}
+		m := protowire.ConsumeFieldValue(num, typ, rest[n:])
+		if m < 0 {
+			break
+		}
+		if num == 1 && typ == protowire.BytesType {
+			frames = append(frames, rest[:n+m])
+		}
+		rest = rest[n+m:]
+	}
+	if len(rest) > 0 || len(frames) == 0 {
+		frames = append(frames, rest)
+	}
+	return frames
+}
+
+// headerPool reused protobuf header messages, only referenced while decoding
+var headerPool = sync.Pool{New: func() any { return &SendHeaderMsg{} }}
+
+// isJSONPayload check if the payload starts with a JSON object. The protobuf tag
+// 0x0a equals '\n', so payloads failing JSON decoding still fall back to protobuf.
+func isJSONPayload(payload []byte) bool {
+	for _, b := range payload {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			return true
+		default:
+			return false
 		}
-		log.Log.Debugf("Serial index 2: %d", index)
-		DisplayPayload(serialNumber, payload[start:end])
 	}
+	return false
+}
 
+// countTopic increment the message counter of the topic
+func countTopic(topic string) {
+	if e, ok := mqttStatMap.Load(topic); ok {
+		e.(*atomic.Uint64).Add(1)
+		return
+	}
+	e, loaded := mqttStatMap.LoadOrStore(topic, &atomic.Uint64{})
+	e.(*atomic.Uint64).Add(1)
+	if loaded {
+		return
+	}
+	if topicEntries.Add(1) > int64(StatRetention.MaxTopics) && StatRetention.MaxTopics > 0 {
+		compactTopics()
+	}
 }
 
 // getSnFromTopic extract serial number from topic
@@ -0,0 +1,98 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// benchMessage MQTT message used to feed the message handler
+type benchMessage struct {
+	topic   string
+	payload []byte
+}
+
+func (m *benchMessage) Duplicate() bool   { return false }
+func (m *benchMessage) Qos() byte         { return 0 }
+func (m *benchMessage) Retained() bool    { return false }
+func (m *benchMessage) Topic() string     { return m.topic }
+func (m *benchMessage) MessageID() uint16 { return 0 }
+func (m *benchMessage) Payload() []byte   { return m.payload }
+func (m *benchMessage) Ack()              {}
+
+func TestMessageHandlerSniffing(t *testing.T) {
+	assert.True(t, isJSONPayload([]byte(" \n{\"id\":1}")))
+	assert.False(t, isJSONPayload([]byte{0x0a, 0x05}))
+	assert.False(t, isJSONPayload(nil))
+
+	var keys []string
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		for _, p := range points {
+			keys = append(keys, p.Key)
+		}
+	})
+	defer unregister()
+	topic := "/app/device/property/R331SNIFF"
+	MessageHandler(nil, &benchMessage{topic: topic, payload: []byte(`{"params":{"pd.soc":80}}`)})
+	assert.Contains(t, keys, "pd.soc")
+	keys = nil
+	MessageHandler(nil, &benchMessage{topic: "/app/device/property/HW51SNIFF", payload: benchProtoPayload(t)})
+	assert.Contains(t, keys, "inverterHeartbeat.permanentWatts")
+	e, ok := mqttStatMap.Load(topic)
+	if assert.True(t, ok) {
+		assert.Equal(t, uint64(1), e.(*atomic.Uint64).Load())
+	}
+}
+
+func benchProtoPayload(b testing.TB) []byte {
+	watts := uint32(2000)
+	volt := int32(310)
+	pdata, err := proto.Marshal(&InverterHeartbeat{PermanentWatts: &watts, Pv1InputVolt: &volt})
+	if err != nil {
+		b.Fatal(err)
+	}
+	cmdId := int32(1)
+	payload, err := proto.Marshal(&SendHeaderMsg{Msg: &Header{Pdata: pdata, CmdId: &cmdId}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return payload
+}
+
+func BenchmarkMessageHandlerProtobuf(b *testing.B) {
+	msg := &benchMessage{topic: "/app/device/property/HW51BENCH", payload: benchProtoPayload(b)}
+	b.ReportAllocs()
+	for b.Loop() {
+		MessageHandler(nil, msg)
+	}
+}
+
+func BenchmarkMessageHandlerJSON(b *testing.B) {
+	msg := &benchMessage{topic: "/app/device/property/R331BENCH",
+		payload: []byte(`{"id":1,"params":{"pd.soc":80,"inv.outputWatts":120,"bms_bmsStatus.temp":25}}`)}
+	b.ReportAllocs()
+	for b.Loop() {
+		MessageHandler(nil, msg)
+	}
+}
+
+func BenchmarkDisplayPayload(b *testing.B) {
+	payload := benchProtoPayload(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		DisplayPayload("HW51BENCH", payload)
+	}
+}
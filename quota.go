@@ -0,0 +1,203 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeQuota decode flat quota map into a struct using the field tag `quota:"key"`.
+// Nested structs and slices of structs are decoded with the tag used as format
+// string containing the index starting at 1, e.g. `quota:"bmsSlave%d"` and
+// `quota:".soc"` on the fields of the element struct. The keys not mapped to
+// any field are returned.
+func DecodeQuota(m map[string]interface{}, v interface{}) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("quota target need to be pointer to struct, got %T", v)
+	}
+	used := make(map[string]bool)
+	err := decodeQuotaStruct(m, "", rv.Elem(), used)
+	if err != nil {
+		return nil, err
+	}
+	unmapped := make([]string, 0)
+	for k := range m {
+		if !used[k] {
+			unmapped = append(unmapped, k)
+		}
+	}
+	sort.Strings(unmapped)
+	return unmapped, nil
+}
+
+// typedQuotas typed quota structs of the device models
+var typedQuotas = map[DeviceModel]func() interface{}{
+	ModelPowerStream: func() interface{} { return &PowerStreamQuota{} },
+	ModelSmartPlug:   func() interface{} { return &SmartPlugQuota{} },
+	ModelDelta2:      func() interface{} { return &Delta2Quota{} },
+	ModelDelta2Max:   func() interface{} { return &Delta2MaxQuota{} },
+	ModelDeltaMax:    func() interface{} { return &DeltaMaxQuota{} },
+	ModelRiver2:      func() interface{} { return &River2Quota{} },
+	ModelRiver2Max:   func() interface{} { return &River2Quota{} },
+	ModelRiver2Pro:   func() interface{} { return &River2Quota{} },
+}
+
+// TypedQuota return new typed quota struct of the device model, false if the
+// model has no typed quota
+func TypedQuota(model DeviceModel) (interface{}, bool) {
+	f, ok := typedQuotas[model]
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+// MissingQuotaKeys return the keys of the typed struct not contained in the
+// quota map. Slice elements are only checked for the indexes present in the map.
+func MissingQuotaKeys(m map[string]interface{}, v interface{}) ([]string, error) {
+	rt := reflect.TypeOf(v)
+	if rt == nil || rt.Kind() != reflect.Pointer || rt.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("quota target need to be pointer to struct, got %T", v)
+	}
+	missing := make([]string, 0)
+	missingQuotaKeys(m, "", rt.Elem(), &missing)
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func missingQuotaKeys(m map[string]interface{}, prefix string, rt reflect.Type, missing *[]string) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("quota")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			missingQuotaKeys(m, prefix+tag, field.Type, missing)
+		case reflect.Slice:
+			for index := 1; hasQuotaPrefix(m, prefix+fmt.Sprintf(tag, index)); index++ {
+				missingQuotaKeys(m, prefix+fmt.Sprintf(tag, index), field.Type.Elem(), missing)
+			}
+		default:
+			if _, ok := m[prefix+tag]; !ok {
+				*missing = append(*missing, prefix+tag)
+			}
+		}
+	}
+}
+
+func decodeQuotaStruct(m map[string]interface{}, prefix string, rv reflect.Value, used map[string]bool) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("quota")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		switch fv.Kind() {
+		case reflect.Struct:
+			if err := decodeQuotaStruct(m, prefix+tag, fv, used); err != nil {
+				return err
+			}
+		case reflect.Slice:
+			if fv.Type().Elem().Kind() != reflect.Struct {
+				return fmt.Errorf("quota field %s: only slices of structs supported", field.Name)
+			}
+			elements := reflect.MakeSlice(fv.Type(), 0, 0)
+			for index := 1; ; index++ {
+				elementPrefix := prefix + fmt.Sprintf(tag, index)
+				if !hasQuotaPrefix(m, elementPrefix) {
+					break
+				}
+				element := reflect.New(fv.Type().Elem()).Elem()
+				if err := decodeQuotaStruct(m, elementPrefix, element, used); err != nil {
+					return err
+				}
+				elements = reflect.Append(elements, element)
+			}
+			fv.Set(elements)
+		default:
+			key := prefix + tag
+			value, ok := m[key]
+			if !ok {
+				continue
+			}
+			used[key] = true
+			if err := setQuotaValue(fv, value); err != nil {
+				return fmt.Errorf("quota %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func hasQuotaPrefix(m map[string]interface{}, prefix string) bool {
+	for k := range m {
+		if strings.HasPrefix(k, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// setQuotaValue assign the quota value to the field, a null value leaves the
+// field unchanged like encoding/json does
+func setQuotaValue(fv reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f, ok := value.(float64); ok {
+			fv.SetFloat(f)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f, ok := value.(float64); ok {
+			fv.SetInt(int64(f))
+			return nil
+		}
+	case reflect.Bool:
+		switch b := value.(type) {
+		case bool:
+			fv.SetBool(b)
+			return nil
+		case float64:
+			fv.SetBool(b != 0)
+			return nil
+		}
+	case reflect.String:
+		fv.SetString(fmt.Sprint(value))
+		return nil
+	case reflect.Interface:
+		fv.Set(reflect.ValueOf(value))
+		return nil
+	}
+	return fmt.Errorf("can't assign %T to %s", value, fv.Type())
+}
+
+// GetQuota read all quota of the device and decode them into the typed struct,
+// the quota keys not mapped are returned
+func (c *Client) GetQuota(ctx context.Context, deviceSn string, v interface{}) ([]string, error) {
+	m, err := c.GetDeviceAllParameters(ctx, deviceSn)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeQuota(m, v)
+}
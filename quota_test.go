@@ -0,0 +1,160 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQuotaFixtures check that the typed quota structs capture every key of the
+// golden quota fixtures. Keys not mapped on purpose are listed per fixture,
+// new firmware fields show up as unexpected unmapped keys. The fixtures are
+// composed from the documented keys, TestCaptureQuotaFixture replaces them
+// with anonymized dumps of real devices.
+func TestQuotaFixtures(t *testing.T) {
+	for _, tc := range []struct {
+		model    DeviceModel
+		fixture  string
+		unmapped []string
+	}{
+		{ModelPowerStream, "powerstream_quota.json", []string{"20_1.heartbeatFrequency", "20_1.wirelessErrCode"}},
+		{ModelSmartPlug, "smartplug_quota.json", []string{"2_1.runTime"}},
+		{ModelDelta2, "delta2_quota.json", []string{"pd.beepMode"}},
+		{ModelDelta2Max, "delta2max_quota.json", []string{"pd.beepMode"}},
+		{ModelDeltaMax, "deltamax_quota.json", []string{}},
+		{ModelRiver2, "river2_quota.json", []string{"pd.beepMode"}},
+	} {
+		t.Run(tc.model.String(), func(t *testing.T) {
+			m := loadQuotaFixture(t, tc.fixture)
+			q, ok := TypedQuota(tc.model)
+			if !assert.True(t, ok) {
+				return
+			}
+			unmapped, err := DecodeQuota(m, q)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.unmapped, unmapped, "unmapped keys of %s", tc.fixture)
+			missing, err := MissingQuotaKeys(m, q)
+			assert.NoError(t, err)
+			assert.Empty(t, missing, "typed fields without fixture key")
+		})
+	}
+	_, ok := TypedQuota(ModelSmartGenerator)
+	assert.False(t, ok)
+}
+
+func TestTypedQuotaValues(t *testing.T) {
+	ps := &PowerStreamQuota{}
+	_, err := DecodeQuota(loadQuotaFixture(t, "powerstream_quota.json"), ps)
+	assert.NoError(t, err)
+	assert.Equal(t, 2400.0, ps.PermanentWatts)
+	assert.Equal(t, int(PrioritizePowerSupply), ps.SupplyPriority)
+
+	plug := &SmartPlugQuota{}
+	_, err = DecodeQuota(loadQuotaFixture(t, "smartplug_quota.json"), plug)
+	assert.NoError(t, err)
+	assert.True(t, plug.SwitchOn)
+
+	d2 := &Delta2Quota{}
+	_, err = DecodeQuota(loadQuotaFixture(t, "delta2_quota.json"), d2)
+	assert.NoError(t, err)
+	assert.Equal(t, 600.0, d2.AcChargeWatts)
+	assert.Len(t, d2.ExtraBatteries, 1)
+
+	missing, err := MissingQuotaKeys(map[string]interface{}{"pd.soc": 1.0}, &River2Quota{})
+	assert.NoError(t, err)
+	assert.Contains(t, missing, "bms_bmsStatus.soc")
+	assert.NotContains(t, missing, "pd.soc")
+	_, err = MissingQuotaKeys(nil, River2Quota{})
+	assert.Error(t, err)
+}
+
+func TestDecodeQuotaNull(t *testing.T) {
+	q := &struct {
+		Raw   interface{} `quota:"pd.raw"`
+		Watts float64     `quota:"pd.watts"`
+		Name  string      `quota:"pd.name"`
+	}{Watts: 1}
+	unmapped, err := DecodeQuota(map[string]interface{}{"pd.raw": nil, "pd.watts": nil, "pd.name": nil}, q)
+	assert.NoError(t, err)
+	assert.Empty(t, unmapped)
+	assert.Nil(t, q.Raw)
+	assert.Equal(t, 1.0, q.Watts)
+	assert.Equal(t, "", q.Name)
+}
+
+// TestCaptureQuotaFixture write anonymized /quota/all dumps of the devices
+// listed in ECOFLOW_CAPTURE_QUOTA to testdata, replacing the fixture of the
+// device model. The credentials are the same as of TestClientAllDevices.
+func TestCaptureQuotaFixture(t *testing.T) {
+	serials := os.Getenv("ECOFLOW_CAPTURE_QUOTA")
+	if serials == "" {
+		t.Skip("ECOFLOW_CAPTURE_QUOTA not set")
+	}
+	client := NewClient(os.ExpandEnv(accessToken), os.ExpandEnv(secretKey))
+	for _, sn := range strings.Split(serials, ",") {
+		sn = strings.TrimSpace(sn)
+		model := DetectModel(sn)
+		if !assert.NotEqual(t, ModelUnknown, model, "model of %s", sn) {
+			continue
+		}
+		m, err := client.GetDeviceAllParameters(context.Background(), sn)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		data, err := json.MarshalIndent(anonymizeQuota(m), "", "  ")
+		assert.NoError(t, err)
+		name := strings.ToLower(strings.ReplaceAll(model.String(), " ", "")) + "_quota.json"
+		assert.NoError(t, os.WriteFile("testdata/"+name, append(data, '\n'), 0644))
+	}
+}
+
+// anonymizeQuota replace string values like serial numbers, network names
+// and addresses and clear location values of a quota dump
+func anonymizeQuota(m map[string]interface{}) map[string]interface{} {
+	anonymized := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		field := strings.ToLower(k[strings.LastIndex(k, ".")+1:])
+		switch {
+		case strings.Contains(field, "latitude") || strings.Contains(field, "longitude"):
+			anonymized[k] = 0
+		default:
+			switch v.(type) {
+			case string:
+				anonymized[k] = "redacted"
+			case []interface{}, map[string]interface{}:
+				if data, err := json.Marshal(v); err == nil && strings.Contains(string(data), `"`) {
+					anonymized[k] = "redacted"
+					continue
+				}
+				anonymized[k] = v
+			default:
+				anonymized[k] = v
+			}
+		}
+	}
+	return anonymized
+}
+
+func TestAnonymizeQuota(t *testing.T) {
+	m := anonymizeQuota(map[string]interface{}{"20_1.pv1InputWatts": 1864.0,
+		"20_1.wifiSsid": "home", "pd.latitude": 48.1, "bms.cellVol": []interface{}{3.3, 3.2},
+		"pd.devSn": []interface{}{"R331ZEB4ZE"}})
+	assert.Equal(t, map[string]interface{}{"20_1.pv1InputWatts": 1864.0,
+		"20_1.wifiSsid": "redacted", "pd.latitude": 0, "bms.cellVol": []interface{}{3.3, 3.2},
+		"pd.devSn": "redacted"}, m)
+}
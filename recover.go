@@ -0,0 +1,63 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/tknie/log"
+)
+
+// EventHandlerPanic handler panicked and was recovered, Data contains HandlerPanic
+const EventHandlerPanic EventType = "handler.panic"
+
+// HandlerPanic recovered panic of a handler
+type HandlerPanic struct {
+	Handler      string `json:"handler"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+	Value        string `json:"value"`
+	Stack        string `json:"stack"`
+}
+
+// recoverHandler recover panic of the handler, needs to be deferred directly.
+// The panic is logged, counted and published as EventHandlerPanic so the MQTT
+// network goroutine and the subscription keep running.
+func recoverHandler(handler, serialNumber string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	metricHandlerPanics.Add(1)
+	p := HandlerPanic{Handler: handler, SerialNumber: serialNumber, Value: fmt.Sprint(r), Stack: string(debug.Stack())}
+	log.Log.Errorf("Handler %s of %s panicked: %v\n%s", handler, serialNumber, r, p.Stack)
+	Events.Publish(Event{Type: EventHandlerPanic, SerialNumber: serialNumber, Time: now(), Data: p})
+}
+
+// callTelemetryHandler call telemetry handler isolated from panics
+func callTelemetryHandler(ctx context.Context, h TelemetryContextHandler, points []*Telemetry) {
+	defer recoverHandler("telemetry", points[0].SerialNumber)
+	h(ctx, points)
+}
+
+// callCallback call the user data callback isolated from panics
+func callCallback(callback DataCallback, serialNumber string, data map[string]interface{}) {
+	defer recoverHandler("callback", serialNumber)
+	callback(serialNumber, data)
+}
+
+// callProtocolHandler call the protocol handler isolated from panics
+func callProtocolHandler(entry *Entry) {
+	defer recoverHandler("protocol", entry.serialNumber)
+	caller.CallHandler(entry)
+}
@@ -0,0 +1,44 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerPanicRecovery(t *testing.T) {
+	var panics []HandlerPanic
+	unsubscribe := Events.Subscribe(func(e Event) { panics = append(panics, e.Data.(HandlerPanic)) }, EventHandlerPanic)
+	defer unsubscribe()
+	before := Diagnostics().HandlerPanics
+
+	SetDataCallback(func(string, map[string]interface{}) { panic("callback failed") })
+	defer SetDataCallback(nil)
+	received := 0
+	unregisterPanic := RegisterTelemetryHandler(func([]*Telemetry) { panic("handler failed") })
+	defer unregisterPanic()
+	unregister := RegisterTelemetryHandler(func([]*Telemetry) { received++ })
+	defer unregister()
+
+	msg := &benchMessage{topic: "/app/device/property/R331PANIC", payload: []byte(`{"params":{"pd.soc":80}}`)}
+	assert.NotPanics(t, func() { MessageHandler(nil, msg) })
+	assert.NotPanics(t, func() { MessageHandler(nil, msg) })
+	assert.Equal(t, 2, received)
+	assert.Len(t, panics, 4)
+	assert.Equal(t, before+4, Diagnostics().HandlerPanics)
+	if assert.NotEmpty(t, panics) {
+		assert.Equal(t, "R331PANIC", panics[0].SerialNumber)
+		assert.Contains(t, panics[0].Stack, "recover")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// IPPreference address family preference of the dialer
+type IPPreference int
+
+const (
+	// PreferSystem use the addresses in the order of the resolver
+	PreferSystem IPPreference = iota
+	// PreferIPv4 try IPv4 addresses first
+	PreferIPv4
+	// PreferIPv6 try IPv6 addresses first
+	PreferIPv6
+	// IPv4Only use only IPv4 addresses
+	IPv4Only
+	// IPv6Only use only IPv6 addresses
+	IPv6Only
+)
+
+// ResolverOptions name resolution of the HTTP and MQTT connections
+type ResolverOptions struct {
+	// Nameserver host:port of the DNS server used instead of the system resolver
+	Nameserver string
+	Prefer     IPPreference
+	// CacheTTL time resolved addresses are cached, not cached if 0
+	CacheTTL time.Duration
+	// Timeout connect timeout per address, 30 seconds if 0
+	Timeout time.Duration
+}
+
+// ErrNoAddress host has no address of the allowed address family
+var ErrNoAddress = errors.New("no address of the allowed address family")
+
+// Dialer dialer resolving host names using the resolver options
+type Dialer struct {
+	options ResolverOptions
+	dialer  *net.Dialer
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+	mu      sync.Mutex
+	cache   map[string]resolvedHost
+}
+
+type resolvedHost struct {
+	addresses []net.IP
+	expires   time.Time
+}
+
+// NewDialer create dialer using the resolver options
+func NewDialer(options ResolverOptions) *Dialer {
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	d := &Dialer{options: options, dialer: &net.Dialer{Timeout: timeout}, cache: make(map[string]resolvedHost)}
+	resolver := net.DefaultResolver
+	if options.Nameserver != "" {
+		resolver = &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return d.dialer.DialContext(ctx, network, options.Nameserver)
+		}}
+	}
+	d.lookup = resolver.LookupIPAddr
+	return d
+}
+
+// LookupHost resolve host into the addresses in the order they are dialed
+func (d *Dialer) LookupHost(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return d.order([]net.IP{ip}, host)
+	}
+	if d.options.CacheTTL > 0 {
+		d.mu.Lock()
+		entry, ok := d.cache[host]
+		d.mu.Unlock()
+		if ok && now().Before(entry.expires) {
+			return entry.addresses, nil
+		}
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP)
+	}
+	ips, err = d.order(ips, host)
+	if err != nil {
+		return nil, err
+	}
+	if d.options.CacheTTL > 0 {
+		d.mu.Lock()
+		d.cache[host] = resolvedHost{addresses: ips, expires: now().Add(d.options.CacheTTL)}
+		d.mu.Unlock()
+	}
+	return ips, nil
+}
+
+// order sort and filter the addresses by the address family preference
+func (d *Dialer) order(ips []net.IP, host string) ([]net.IP, error) {
+	var v4, v6 []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	var ordered []net.IP
+	switch d.options.Prefer {
+	case PreferIPv4:
+		ordered = append(v4, v6...)
+	case PreferIPv6:
+		ordered = append(v6, v4...)
+	case IPv4Only:
+		ordered = v4
+	case IPv6Only:
+		ordered = v6
+	default:
+		ordered = ips
+	}
+	if len(ordered) == 0 {
+		return nil, fmt.Errorf("%s: %w", host, ErrNoAddress)
+	}
+	return ordered, nil
+}
+
+// DialContext connect to the address trying the resolved addresses in order
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range ips {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeLookup(calls *int, addrs ...string) func(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		*calls++
+		list := make([]net.IPAddr, 0, len(addrs))
+		for _, a := range addrs {
+			list = append(list, net.IPAddr{IP: net.ParseIP(a)})
+		}
+		return list, nil
+	}
+}
+
+func TestDialerPreference(t *testing.T) {
+	tests := []struct {
+		prefer IPPreference
+		want   []string
+	}{
+		{PreferSystem, []string{"2001:db8::1", "192.0.2.1"}},
+		{PreferIPv4, []string{"192.0.2.1", "2001:db8::1"}},
+		{PreferIPv6, []string{"2001:db8::1", "192.0.2.1"}},
+		{IPv4Only, []string{"192.0.2.1"}},
+		{IPv6Only, []string{"2001:db8::1"}},
+	}
+	for _, tt := range tests {
+		calls := 0
+		d := NewDialer(ResolverOptions{Prefer: tt.prefer})
+		d.lookup = fakeLookup(&calls, "2001:db8::1", "192.0.2.1")
+		ips, err := d.LookupHost(context.Background(), "mqtt.ecoflow.com")
+		if assert.NoError(t, err) {
+			got := make([]string, 0, len(ips))
+			for _, ip := range ips {
+				got = append(got, ip.String())
+			}
+			assert.Equal(t, tt.want, got)
+		}
+	}
+	calls := 0
+	d := NewDialer(ResolverOptions{Prefer: IPv6Only})
+	d.lookup = fakeLookup(&calls, "192.0.2.1")
+	_, err := d.LookupHost(context.Background(), "api.ecoflow.com")
+	assert.ErrorIs(t, err, ErrNoAddress)
+}
+
+func TestDialerCache(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	calls := 0
+	d := NewDialer(ResolverOptions{CacheTTL: time.Minute})
+	d.lookup = fakeLookup(&calls, "127.0.0.1")
+	for i := 0; i < 3; i++ {
+		_, err := d.LookupHost(context.Background(), "api.ecoflow.com")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+	clock.Advance(time.Minute)
+	_, err := d.LookupHost(context.Background(), "api.ecoflow.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestHTTPClientResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Host)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	calls := 0
+	d := NewDialer(ResolverOptions{Prefer: PreferIPv4})
+	d.lookup = fakeLookup(&calls, "127.0.0.1")
+	client := NewHTTPClient(TransportOptions{})
+	client.Transport.(*http.Transport).DialContext = d.DialContext
+	resp, err := client.Get("http://api.ecoflow.test:" + port)
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.True(t, strings.HasPrefix(string(body), "api.ecoflow.test"))
+	}
+	assert.Equal(t, 1, calls)
+
+	c := NewClient("access", "secret", WithResolver(ResolverOptions{Prefer: IPv4Only}))
+	assert.NotNil(t, c.httpClient.Transport.(*http.Transport).DialContext)
+}
@@ -0,0 +1,164 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// CanonicalJSON marshal the parameters using the representation of the
+// signature: keys are sorted, numbers are formatted like in the canonical query
+// string and null values of objects are omitted, as they are not signed.
+func CanonicalJSON(params map[string]interface{}) ([]byte, error) {
+	return canonicalJSON(params, nil)
+}
+
+// canonicalJSON marshal the parameters formatting numbers using the given rules
+func canonicalJSON(params map[string]interface{}, format *NumberFormat) ([]byte, error) {
+	e := &canonicalEncoder{format: format}
+	if err := e.value("", params); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type canonicalEncoder struct {
+	buf    []byte
+	format *NumberFormat
+}
+
+func (e *canonicalEncoder) value(path string, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k, nested := range v {
+			if nested != nil {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		e.buf = append(e.buf, '{')
+		for i, k := range keys {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := e.string(k); err != nil {
+				return err
+			}
+			e.buf = append(e.buf, ':')
+			if err := e.value(joinPath(path, k), v[k]); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, '}')
+	case []interface{}:
+		e.buf = append(e.buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := e.value(path+"["+strconv.Itoa(i)+"]", item); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+	case string:
+		return e.string(v)
+	case int:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case float64:
+		return e.float(path, v, 64)
+	case bool:
+		e.buf = strconv.AppendBool(e.buf, v)
+	case json.Number:
+		e.buf = append(e.buf, v.String()...)
+	case nil:
+		e.buf = append(e.buf, "null"...)
+	default:
+		return e.reflectValue(path, reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// reflectValue marshal typed slices, maps and numbers like processReflectValue,
+// other types use the standard JSON encoding
+func (e *canonicalEncoder) reflectValue(path string, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return e.value(path, items)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return e.value(path, m)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = strconv.AppendInt(e.buf, rv.Int(), 10)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.buf = strconv.AppendUint(e.buf, rv.Uint(), 10)
+		return nil
+	case reflect.Float32:
+		return e.float(path, rv.Float(), 32)
+	case reflect.String:
+		return e.string(rv.String())
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.value(path, rv.Elem().Interface())
+	}
+	data, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, data...)
+	return nil
+}
+
+func (e *canonicalEncoder) float(path string, v float64, bitSize int) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("unsupported number %v in request body", v)
+	}
+	e.buf = append(e.buf, e.format.formatFloat(path, v, bitSize)...)
+	return nil
+}
+
+func (e *canonicalEncoder) string(s string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, data...)
+	return nil
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,77 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	params := map[string]interface{}{"sn": "HW51TEST", "id": 17, "skip": nil,
+		"params": map[string]interface{}{"watts": 1e6, "ratio": 0.5, "flag": true,
+			"taskIndex": int32(1), "timeRange": []int{8, 17}, "name": "a\"b"}}
+	body, err := CanonicalJSON(params)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":17,"params":{"flag":true,"name":"a\"b","ratio":0.5,"taskIndex":1,`+
+		`"timeRange":[8,17],"watts":1000000},"sn":"HW51TEST"}`, string(body))
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, CanonicalQueryString(params), CanonicalQueryString(decoded))
+
+	_, err = CanonicalJSON(map[string]interface{}{"watts": math.NaN()})
+	assert.Error(t, err)
+}
+
+func TestRequestCanonicalBody(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{"code":"0"}`))
+	}))
+	defer server.Close()
+
+	params := map[string]interface{}{"sn": "HW51TEST", "params": map[string]interface{}{"watts": 1e21, "id": 1}}
+	request := NewRequest(server.Client(), http.MethodPut, server.URL, params, NewSigner("access", "secret"))
+	_, err := request.Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"params":{"id":1,"watts":1e+21},"sn":"HW51TEST"}`, body)
+
+	request.CanonicalBody = true
+	_, err = request.Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"params":{"id":1,"watts":1000000000000000000000},"sn":"HW51TEST"}`, body)
+}
+
+func TestNumberFormat(t *testing.T) {
+	format := &NumberFormat{Default: NumberShortest,
+		Rules: map[string]NumberRule{"permanentWatts": NumberDecimal, "watts": NumberInteger}}
+	params := map[string]interface{}{"moduleType": 5.0, "params": map[string]interface{}{
+		"permanentWatts": 2000.0, "ratio": 0.5, "tasks": []interface{}{map[string]interface{}{"watts": 99.6}}}}
+	assert.Equal(t, "moduleType=5&params.permanentWatts=2000.0&params.ratio=0.5&params.tasks[0].watts=100",
+		canonicalQueryString(params, format))
+	body, err := canonicalJSON(params, format)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"moduleType":5,"params":{"permanentWatts":2000.0,"ratio":0.5,"tasks":[{"watts":100}]}}`, string(body))
+	assert.Equal(t, "watts", parameterName("params.tasks[0].watts"))
+	assert.Equal(t, "timeRange", parameterName("params.timeRange[1]"))
+	assert.Equal(t, "sn", parameterName("sn"))
+}
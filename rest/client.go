@@ -0,0 +1,143 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// DefaultBaseURL base URL of the Ecoflow IoT Open API
+const DefaultBaseURL = "https://api.ecoflow.com"
+
+const (
+	deviceListPath = "/iot-open/sign/device/list"
+	quotaAllPath   = "/iot-open/sign/device/quota/all"
+	quotaPath      = "/iot-open/sign/device/quota"
+)
+
+// Client minimal REST-only client reading the device list and quotas and
+// setting parameters. It is the client of binaries built without MQTT and
+// protobuf, the ecoflow package adds routing, auditing and telemetry.
+type Client struct {
+	// BaseURL of the API, DefaultBaseURL if empty
+	BaseURL    string
+	HTTPClient *http.Client
+	Signer     *Signer
+}
+
+// Device device of the device list
+type Device struct {
+	SN     string `json:"sn"`
+	Online int    `json:"online"`
+}
+
+// Error API response with an error code
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("error code %s: %s", e.Code, e.Message)
+}
+
+// response common envelope of the API responses
+type response struct {
+	Code    Code            `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewClient create client using the IoT Open access and secret key
+func NewClient(accessKey, secretKey string) *Client {
+	return &Client{Signer: NewSigner(accessKey, secretKey)}
+}
+
+// ClientFromEnv create client using ECOFLOW_ACCESS_KEY and ECOFLOW_SECRET_KEY
+func ClientFromEnv() *Client {
+	return NewClient(os.Getenv("ECOFLOW_ACCESS_KEY"), os.Getenv("ECOFLOW_SECRET_KEY"))
+}
+
+// do execute the request and return the data of a successful response
+func (c *Client) do(ctx context.Context, method, path string, params map[string]interface{}) (json.RawMessage, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	data, err := NewRequest(c.HTTPClient, method, base+path, params, c.Signer).Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	ObserveCode(resp.Code)
+	if !resp.Code.OK() {
+		return nil, &Error{Code: resp.Code, Message: resp.Message}
+	}
+	return resp.Data, nil
+}
+
+// Devices return the devices linked to the account
+func (c *Client) Devices(ctx context.Context) ([]Device, error) {
+	data, err := c.do(ctx, http.MethodGet, deviceListPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	var devices []Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Quota return all quota values of the device
+func (c *Client) Quota(ctx context.Context, serialNumber string) (map[string]interface{}, error) {
+	data, err := c.do(ctx, http.MethodGet, quotaAllPath, map[string]interface{}{"sn": serialNumber})
+	if err != nil {
+		return nil, err
+	}
+	var quota map[string]interface{}
+	if err := json.Unmarshal(data, &quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// SelectedQuota return the given quota values of the device
+func (c *Client) SelectedQuota(ctx context.Context, serialNumber string, keys ...string) (map[string]interface{}, error) {
+	quotas := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		quotas = append(quotas, k)
+	}
+	data, err := c.do(ctx, http.MethodPost, quotaPath, map[string]interface{}{"sn": serialNumber,
+		"params": map[string]interface{}{"quotas": quotas}})
+	if err != nil {
+		return nil, err
+	}
+	var quota map[string]interface{}
+	if err := json.Unmarshal(data, &quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// SetQuota send the parameter request, e.g. {"sn": ..., "cmdCode": ..., "params": {...}}
+func (c *Client) SetQuota(ctx context.Context, request map[string]interface{}) error {
+	_, err := c.do(ctx, http.MethodPut, quotaPath, request)
+	return err
+}
@@ -0,0 +1,96 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	quota := map[string]interface{}{"20_1.permanentWatts": 1000.0}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "access", r.Header.Get("accessKey"))
+		assert.NotEmpty(t, r.Header.Get("sign"))
+		var body interface{}
+		switch r.URL.Path {
+		case deviceListPath:
+			body = map[string]interface{}{"code": "0", "data": []interface{}{map[string]interface{}{"sn": "HW51REST", "online": 1}}}
+		case quotaAllPath:
+			assert.Equal(t, "HW51REST", r.URL.Query().Get("sn"))
+			body = map[string]interface{}{"code": 0, "data": quota}
+		case quotaPath:
+			var req map[string]interface{}
+			data, _ := io.ReadAll(r.Body)
+			assert.NoError(t, json.Unmarshal(data, &req))
+			if r.Method == http.MethodPut {
+				body = map[string]interface{}{"code": "1006", "message": "device offline"}
+			} else {
+				body = map[string]interface{}{"code": "0", "data": quota}
+			}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := NewClient("access", "secret")
+	client.BaseURL = server.URL
+	devices, err := client.Devices(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, []Device{{SN: "HW51REST", Online: 1}}, devices)
+	q, err := client.Quota(t.Context(), "HW51REST")
+	assert.NoError(t, err)
+	assert.Equal(t, quota, q)
+	q, err = client.SelectedQuota(t.Context(), "HW51REST", "20_1.permanentWatts")
+	assert.NoError(t, err)
+	assert.Equal(t, quota, q)
+	err = client.SetQuota(t.Context(), map[string]interface{}{"sn": "HW51REST", "cmdCode": "WN511_SET_PERMANENT_WATTS_PACK",
+		"params": map[string]interface{}{"permanentWatts": 200}})
+	var apiErr *Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, Code("1006"), apiErr.Code)
+	}
+}
+
+// TestStandardLibraryOnly the package must only import the standard library so
+// REST-only binaries are built without MQTT, protobuf and uuid
+func TestStandardLibraryOnly(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			first := strings.SplitN(path, "/", 2)[0]
+			assert.False(t, strings.Contains(first, "."), "%s imports %s", name, path)
+		}
+	}
+}
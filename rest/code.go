@@ -0,0 +1,86 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// CodeSuccess response code of successful requests
+	CodeSuccess Code = "0"
+	// CodeSignatureInvalid response code of requests with wrong signature
+	CodeSignatureInvalid Code = "8521"
+)
+
+// Code response code of the EcoFlow API. Some endpoints return the code as
+// JSON string, others as JSON number. Both are accepted and stored as string.
+type Code string
+
+// UnmarshalJSON parse code given as JSON string, number or null
+func (c *Code) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*c = ""
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*c = Code(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid response code %s: %w", data, err)
+	}
+	*c = Code(n.String())
+	return nil
+}
+
+// OK check if the code reports success
+func (c Code) OK() bool {
+	return c == CodeSuccess
+}
+
+// String return code as string
+func (c Code) String() string {
+	return string(c)
+}
+
+// CodeOf return response code of a generic decoded JSON value
+func CodeOf(v interface{}) Code {
+	switch t := v.(type) {
+	case string:
+		return Code(t)
+	case float64:
+		return Code(strconv.FormatFloat(t, 'f', -1, 64))
+	case json.Number:
+		return Code(t.String())
+	case nil:
+		return ""
+	default:
+		return Code(fmt.Sprint(t))
+	}
+}
+
+// ObserveCode count API responses with signature errors
+func ObserveCode(code Code) {
+	if code == CodeSignatureInvalid {
+		SignFailures.Add(1)
+	}
+}
@@ -0,0 +1,15 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package rest signed client of the Ecoflow IoT Open REST API. The package only
+// depends on the standard library, it can be used by binaries that only poll
+// quotas and send commands without MQTT and protobuf.
+package rest
@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberRule formatting rule of floating point parameters
+type NumberRule int
+
+const (
+	// NumberShortest shortest representation, whole numbers without decimals (2000, 0.5)
+	NumberShortest NumberRule = iota
+	// NumberDecimal whole numbers keep one decimal (2000.0, 0.5)
+	NumberDecimal
+	// NumberInteger numbers are rounded to integers (2000, 1)
+	NumberInteger
+)
+
+// NumberFormat formatting rules of floating point parameters used for the
+// signature and the request body. Rules match the parameter name, the last key
+// of the parameter path, all other parameters use the default rule. Integer
+// types are always formatted as integers.
+type NumberFormat struct {
+	Default NumberRule
+	Rules   map[string]NumberRule
+}
+
+// rule return the rule of the parameter path, a nil format uses the shortest representation
+func (f *NumberFormat) rule(path string) NumberRule {
+	if f == nil {
+		return NumberShortest
+	}
+	if r, ok := f.Rules[parameterName(path)]; ok {
+		return r
+	}
+	return f.Default
+}
+
+// formatFloat format value of the parameter path with the given bit size
+func (f *NumberFormat) formatFloat(path string, v float64, bitSize int) string {
+	switch f.rule(path) {
+	case NumberInteger:
+		return strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+	case NumberDecimal:
+		s := strconv.FormatFloat(v, 'f', -1, bitSize)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	default:
+		return strconv.FormatFloat(v, 'f', -1, bitSize)
+	}
+}
+
+// parameterName return the last key of a parameter path like params.tasks[0].watts
+func parameterName(path string) string {
+	for strings.HasSuffix(path, "]") {
+		i := strings.LastIndex(path, "[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return path[strings.LastIndex(path, ".")+1:]
+}
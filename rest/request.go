@@ -0,0 +1,125 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// Counters of the REST requests, published by the ecoflow package with expvar
+var (
+	Requests      = new(expvar.Int)
+	RequestErrors = new(expvar.Int)
+	SignFailures  = new(expvar.Int)
+)
+
+// DefaultClient HTTP client used by requests without client
+var DefaultClient = http.DefaultClient
+
+// Request signed request of the Ecoflow IoT Open API
+type Request struct {
+	// CanonicalBody marshal the body using CanonicalJSON instead of the
+	// standard JSON encoding, always set if the signer has number rules
+	CanonicalBody     bool
+	httpClient        *http.Client
+	method            string
+	uri               string
+	requestParameters map[string]interface{}
+	signer            *Signer
+}
+
+// NewRequest create new request signed by the given signer
+func NewRequest(httpClient *http.Client, method string, uri string, params map[string]interface{}, signer *Signer) *Request {
+	return &Request{
+		httpClient:        httpClient,
+		method:            method,
+		uri:               uri,
+		requestParameters: params,
+		signer:            signer,
+	}
+}
+
+// Execute send the request and return the response body
+func (r *Request) Execute(ctx context.Context) ([]byte, error) {
+	signParams := r.signer.Signature(r.requestParameters)
+	requestURI := r.uri + "?" + signParams.QueryString
+
+	var reqBody bytes.Buffer
+	var err error
+
+	if r.requestParameters != nil {
+		var reqBytes []byte
+		if r.CanonicalBody || r.signer.Numbers != nil {
+			reqBytes, err = canonicalJSON(r.requestParameters, r.signer.Numbers)
+		} else {
+			reqBytes, err = json.Marshal(r.requestParameters)
+		}
+		if err != nil {
+			return nil, err
+		}
+		reqBody.Write(reqBytes)
+	}
+
+	var httpReq *http.Request
+
+	switch r.method {
+	case http.MethodGet:
+		httpReq, err = http.NewRequestWithContext(ctx, http.MethodGet, requestURI, nil)
+		if err != nil {
+			return nil, err
+		}
+	case http.MethodPost:
+		httpReq, err = http.NewRequestWithContext(ctx, http.MethodPost, r.uri, &reqBody)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Add("Content-Type", "application/json;charset=UTF-8")
+	case http.MethodPut:
+		httpReq, err = http.NewRequestWithContext(ctx, http.MethodPut, r.uri, &reqBody)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Add("Content-Type", "application/json;charset=UTF-8")
+
+	default:
+		slog.Error("Only POST and GET methods are supported so far")
+		return nil, errors.New("unsupported http method")
+	}
+
+	signParams.Apply(httpReq.Header)
+
+	client := r.httpClient
+	if client == nil {
+		client = DefaultClient
+	}
+
+	Requests.Add(1)
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		RequestErrors.Add(1)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		RequestErrors.Add(1)
+		return nil, fmt.Errorf("response status is failed|url=%s, statusCode=%s", requestURI, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,222 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	accessKeyHeader = "accessKey"
+	nonceHeader     = "nonce"
+	timestampHeader = "timestamp"
+	signHeader      = "sign"
+)
+
+// Signer signs requests of the Ecoflow IoT Open API. Clock and nonce generation
+// can be replaced, e.g. for reproducible signatures in tests.
+type Signer struct {
+	AccessKey string
+	SecretKey string
+	Now       func() time.Time
+	Nonce     func() string
+	// Numbers formatting rules of floating point parameters, nil uses the
+	// shortest representation
+	Numbers *NumberFormat
+}
+
+// Signature signature parameters of a request
+type Signature struct {
+	AccessKey   string
+	Nonce       string
+	Timestamp   string
+	Sign        string
+	QueryString string
+}
+
+// NewSigner create new signer using the access and secret key
+func NewSigner(accessKey, secretKey string) *Signer {
+	return &Signer{AccessKey: accessKey, SecretKey: secretKey, Now: time.Now, Nonce: generateNonce}
+}
+
+// Sign create signature headers of a request. The signature is calculated over the
+// parameters, if no parameters are given the JSON body is used instead.
+func (s *Signer) Sign(method string, params map[string]interface{}, body []byte) (http.Header, error) {
+	if params == nil && len(body) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			SignFailures.Add(1)
+			return nil, fmt.Errorf("%s body can't be signed: %w", method, err)
+		}
+	}
+	signature := s.Signature(params)
+	header := make(http.Header)
+	signature.Apply(header)
+	return header, nil
+}
+
+// Signature calculate signature of the request parameters
+func (s *Signer) Signature(params map[string]interface{}) *Signature {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	nonce := generateNonce
+	if s.Nonce != nil {
+		nonce = s.Nonce
+	}
+	signature := &Signature{
+		AccessKey:   s.AccessKey,
+		Nonce:       nonce(),
+		Timestamp:   fmt.Sprint(now().UnixNano()),
+		QueryString: canonicalQueryString(params, s.Numbers),
+	}
+	signature.Sign = encryptHmacSHA256(s.keyValueString(signature), s.SecretKey)
+	return signature
+}
+
+func (s *Signer) keyValueString(signature *Signature) string {
+	keyValueString := accessKeyHeader + "=" + s.AccessKey + "&" +
+		nonceHeader + "=" + signature.Nonce + "&" +
+		timestampHeader + "=" + signature.Timestamp
+
+	if signature.QueryString != "" {
+		keyValueString = signature.QueryString + "&" + keyValueString
+	}
+	return keyValueString
+}
+
+// Apply add signature headers
+func (signature *Signature) Apply(header http.Header) {
+	header.Set(accessKeyHeader, signature.AccessKey)
+	header.Set(nonceHeader, signature.Nonce)
+	header.Set(timestampHeader, signature.Timestamp)
+	header.Set(signHeader, signature.Sign)
+}
+
+func encryptHmacSHA256(message string, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(message))
+	sha := hex.EncodeToString(h.Sum(nil))
+	return sha
+}
+
+// nonce is a random int with 6 digits
+func generateNonce() string {
+	return strconv.Itoa(rand.Intn(900000) + 100000)
+}
+
+// CanonicalQueryString return the canonical parameter string the request
+// signature is calculated of. Nested maps are flattened using dots, arrays
+// using the index in brackets and the result is sorted by ASCII value.
+func CanonicalQueryString(data map[string]interface{}) string {
+	return canonicalQueryString(data, nil)
+}
+
+// canonicalQueryString return the canonical parameter string formatting
+// numbers using the given rules
+func canonicalQueryString(data map[string]interface{}, format *NumberFormat) string {
+	var result []string
+
+	// Process top-level map keys
+	for k, v := range data {
+		result = append(result, processValue(k, v, format)...)
+	}
+
+	// Sort results by ASCII value
+	sort.Strings(result)
+
+	// Concatenate results with & separator
+	return strings.Join(result, "&")
+}
+
+func processValue(prefix string, value interface{}, format *NumberFormat) []string {
+	var result []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, nestedValue := range v {
+			// Recursively process nested maps
+			nestedPrefix := prefix + "." + k
+			result = append(result, processValue(nestedPrefix, nestedValue, format)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			// Recursively process items in arrays
+			nestedPrefix := prefix + "[" + strconv.Itoa(i) + "]"
+			result = append(result, processValue(nestedPrefix, item, format)...)
+		}
+	case string:
+		result = append(result, prefix+"="+v)
+	case int:
+		result = append(result, prefix+"="+strconv.Itoa(v))
+	case float64:
+		result = append(result, prefix+"="+format.formatFloat(prefix, v, 64))
+	case bool:
+		result = append(result, prefix+"="+strconv.FormatBool(v))
+	case json.Number:
+		result = append(result, prefix+"="+v.String())
+	case nil:
+		// null values are not part of the signature
+	default:
+		result = append(result, processReflectValue(prefix, reflect.ValueOf(value), format)...)
+	}
+	return result
+}
+
+// processReflectValue flatten typed slices, arrays, maps and numbers not
+// covered by the generic JSON types
+func processReflectValue(prefix string, rv reflect.Value, format *NumberFormat) []string {
+	var result []string
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			nestedPrefix := prefix + "[" + strconv.Itoa(i) + "]"
+			result = append(result, processValue(nestedPrefix, rv.Index(i).Interface(), format)...)
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			slog.Warn("Ecoflow: unknown map key type for process value", "type", rv.Type())
+			break
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			nestedPrefix := prefix + "." + iter.Key().String()
+			result = append(result, processValue(nestedPrefix, iter.Value().Interface(), format)...)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result = append(result, prefix+"="+strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result = append(result, prefix+"="+strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32:
+		result = append(result, prefix+"="+format.formatFloat(prefix, rv.Float(), 32))
+	case reflect.String:
+		result = append(result, prefix+"="+rv.String())
+	case reflect.Pointer, reflect.Interface:
+		if !rv.IsNil() {
+			result = append(result, processValue(prefix, rv.Elem().Interface(), format)...)
+		}
+	default:
+		slog.Warn("Ecoflow: unknown type for process value", "type", rv.Type())
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignerDocumentation example of the Ecoflow IoT Open API documentation
+func TestSignerDocumentation(t *testing.T) {
+	signer := NewSigner("Fp4SvIprYSDPXtYJidEtUAd1o", "WIbFEKre0s6sLnh4ei7SPUeYnptHG6V")
+	signer.Now = func() time.Time { return time.Unix(0, 1671171709428) }
+	signer.Nonce = func() string { return "345164" }
+	body := []byte(`{"sn":"123456789","params":{"cmdSet":11,"id":24,"eps":0}}`)
+	header, err := signer.Sign("PUT", nil, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "345164", header.Get("nonce"))
+	assert.Equal(t, "1671171709428", header.Get("timestamp"))
+	assert.Equal(t, "Fp4SvIprYSDPXtYJidEtUAd1o", header.Get("accessKey"))
+	assert.Equal(t, "07c13b65e037faf3b153d51613638fa80003c4c38d2407379a7f52851af1473e", header.Get("sign"))
+}
+
+// TestCanonicalQueryStringArrays array example of the Ecoflow IoT Open API documentation
+func TestCanonicalQueryStringArrays(t *testing.T) {
+	body := `{"sn":"123456789","id":123,"version":"1.0","bean":{"beanId":2,"beanName":"beanName",
+	"beanItem":[{"itemName":"itemName","itemId":3,"itemValue":"itemValue"},
+	{"itemName":"itemName1","itemId":4,"itemValue":"itemValue1"}]}}`
+	var params map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(body), &params))
+	assert.Equal(t, "bean.beanId=2&bean.beanItem[0].itemId=3&bean.beanItem[0].itemName=itemName&"+
+		"bean.beanItem[0].itemValue=itemValue&bean.beanItem[1].itemId=4&bean.beanItem[1].itemName=itemName1&"+
+		"bean.beanItem[1].itemValue=itemValue1&bean.beanName=beanName&id=123&sn=123456789&version=1.0",
+		CanonicalQueryString(params))
+
+	timerTasks := map[string]interface{}{"sn": "HW51TEST", "params": map[string]interface{}{
+		"taskIndex": int32(1), "timeRange": []int{8, 17}, "tasks": []map[string]interface{}{{"watts": uint16(200)}}}}
+	assert.Equal(t, "params.taskIndex=1&params.tasks[0].watts=200&params.timeRange[0]=8&params.timeRange[1]=17&sn=HW51TEST",
+		CanonicalQueryString(timerTasks))
+}
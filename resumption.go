@@ -0,0 +1,167 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventResumption MQTT session resumed after a connection loss, Data contains ResumptionStats
+const EventResumption EventType = "mqtt.resumption"
+
+// SourceBackfill source of telemetry read using the REST API to patch the state
+// after a MQTT connection loss
+const SourceBackfill = "backfill"
+
+// backfillTimeout maximum time of the quota poll of one device
+var backfillTimeout = 30 * time.Second
+
+// ResumptionStats estimation of the messages missed by a device during a
+// MQTT connection loss
+type ResumptionStats struct {
+	SerialNumber     string        `json:"serialNumber"`
+	LastReceived     time.Time     `json:"lastReceived"`
+	Resumed          time.Time     `json:"resumed"`
+	Gap              time.Duration `json:"gap"`
+	ExpectedInterval time.Duration `json:"expectedInterval"`
+	MissedHeartbeats int           `json:"missedHeartbeats"`
+	// BackfillPoints number of values patched using the REST API
+	BackfillPoints int    `json:"backfillPoints,omitempty"`
+	BackfillError  string `json:"backfillError,omitempty"`
+}
+
+// ResumptionTracker estimate missed heartbeats of all devices after a MQTT
+// reconnect. With a client the current quota of the devices is polled and
+// stored as SourceBackfill values in the state store.
+type ResumptionTracker struct {
+	Client *Client
+	States *StateStore
+
+	mu     sync.Mutex
+	lost   bool
+	last   map[string]ResumptionStats
+	done   sync.WaitGroup
+	closed bool
+}
+
+// NewResumptionTracker create tracker, client may be nil to disable the backfill
+func NewResumptionTracker(client *Client) *ResumptionTracker {
+	return &ResumptionTracker{Client: client, States: States, last: make(map[string]ResumptionStats)}
+}
+
+// Start subscribe for connection state events, the returned function
+// unsubscribes and waits for running backfills
+func (r *ResumptionTracker) Start() func() {
+	unsubscribe := Events.Subscribe(r.handle, EventConnectionState)
+	return func() {
+		unsubscribe()
+		r.mu.Lock()
+		r.closed = true
+		r.mu.Unlock()
+		r.done.Wait()
+	}
+}
+
+// Last return the statistics of the last resumption of the device
+func (r *ResumptionTracker) Last(serialNumber string) (ResumptionStats, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.last[serialNumber]
+	return s, ok
+}
+
+func (r *ResumptionTracker) handle(e Event) {
+	change, ok := e.Data.(ConnectionStateChange)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch change.State {
+	case ConnectionLost:
+		r.lost = true
+	case ConnectionConnected:
+		if !r.lost || r.closed {
+			return
+		}
+		r.lost = false
+		// one resumption per reconnect, awaited by the stop function of Start
+		r.done.Add(1)
+		backgroundRuntime().GoContext(context.Background(), Component{Name: "mqtt resumption", Optional: true,
+			Run: func(ctx context.Context) error {
+				defer r.done.Done()
+				return r.resume(ctx, e.Time)
+			}})
+	}
+}
+
+// resume estimate the missed heartbeats of all devices and backfill the state,
+// the error reports the failed backfills
+func (r *ResumptionTracker) resume(ctx context.Context, resumed time.Time) error {
+	metricResumptions.Add(1)
+	var errs []error
+	for _, hb := range Stats() {
+		stats := estimateMissed(hb, resumed)
+		metricMissedHeartbeats.Add(int64(stats.MissedHeartbeats))
+		if r.Client != nil && stats.MissedHeartbeats > 0 {
+			if err := r.backfill(ctx, &stats); err != nil {
+				errs = append(errs, fmt.Errorf("backfill of %s: %w", stats.SerialNumber, err))
+			}
+		}
+		log.Log.Infof("MQTT resumption of %s after %v: %d heartbeats missed", stats.SerialNumber,
+			stats.Gap.Round(time.Second), stats.MissedHeartbeats)
+		r.mu.Lock()
+		r.last[stats.SerialNumber] = stats
+		r.mu.Unlock()
+		Events.Publish(Event{Type: EventResumption, SerialNumber: stats.SerialNumber, Time: resumed, Data: stats})
+	}
+	return errors.Join(errs...)
+}
+
+// estimateMissed number of expected heartbeats between the last received message and the resumption
+func estimateMissed(hb HeartbeatStatistics, resumed time.Time) ResumptionStats {
+	expected := hb.ExpectedInterval
+	if expected <= 0 {
+		expected = expectedInterval(hb.SerialNumber)
+	}
+	stats := ResumptionStats{SerialNumber: hb.SerialNumber, LastReceived: hb.LastReceived, Resumed: resumed,
+		ExpectedInterval: expected}
+	if hb.LastReceived.IsZero() || !resumed.After(hb.LastReceived) {
+		return stats
+	}
+	stats.Gap = resumed.Sub(hb.LastReceived)
+	stats.MissedHeartbeats = int(stats.Gap / expected)
+	return stats
+}
+
+// backfill poll the quota of the device and patch the state store
+func (r *ResumptionTracker) backfill(ctx context.Context, stats *ResumptionStats) error {
+	ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+	defer cancel()
+	data, err := r.Client.GetDeviceAllParameters(ctx, stats.SerialNumber)
+	if err != nil {
+		stats.BackfillError = err.Error()
+		log.Log.Errorf("Backfill of %s failed: %v", stats.SerialNumber, err)
+		return err
+	}
+	points := NormalizeTelemetry(stats.SerialNumber, SourceBackfill, data, now())
+	r.States.Update(points)
+	stats.BackfillPoints = len(points)
+	metricBackfills.Add(1)
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumptionBackfill(t *testing.T) {
+	now := time.Now()
+	stat := GetStatEntry("HW51RESUME")
+	stat.mu.Lock()
+	stat.recordHeartbeat(10*time.Second, now.Add(-95*time.Second))
+	stat.mu.Unlock()
+
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1500.0})
+	client := newTestClient(t, transport)
+	tracker := NewResumptionTracker(client)
+	tracker.States = NewStateStore()
+	stop := tracker.Start()
+
+	// connected without previous loss is no resumption
+	publishConnectionState(ConnectionStateChange{State: ConnectionConnected})
+	publishConnectionState(ConnectionStateChange{State: ConnectionLost, Error: "EOF"})
+	publishConnectionState(ConnectionStateChange{State: ConnectionConnected})
+	stop()
+
+	stats, ok := tracker.Last("HW51RESUME")
+	if assert.True(t, ok) {
+		assert.Equal(t, 9, stats.MissedHeartbeats)
+		assert.Equal(t, 1, stats.BackfillPoints)
+		assert.Empty(t, stats.BackfillError)
+	}
+	value, ok := tracker.States.Get("HW51RESUME", "20_1.permanentWatts")
+	if assert.True(t, ok) {
+		assert.Equal(t, SourceBackfill, value.Source)
+		assert.Equal(t, 1500.0, value.Value)
+	}
+}
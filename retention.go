@@ -0,0 +1,244 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// csvAggregateDir sub directory of the monthly aggregate files
+const csvAggregateDir = "aggregate"
+
+// csvMonthLayout file name layout of the monthly aggregate files
+const csvMonthLayout = "2006-01"
+
+var csvAggregateHeader = []string{"bucket", "serial_number", "key", "count", "min", "max", "avg"}
+
+// RetentionPolicy retention of the CSV store
+type RetentionPolicy struct {
+	// RawDays days raw files are kept before they are downsampled, kept forever if 0
+	RawDays int
+	// AggregateMonths months aggregates are kept, kept forever if 0
+	AggregateMonths int
+	// Bucket aggregation interval, one hour if 0
+	Bucket time.Duration
+}
+
+// DefaultRetentionPolicy keep raw data 14 days and hourly aggregates 12 months
+var DefaultRetentionPolicy = RetentionPolicy{RawDays: 14, AggregateMonths: 12, Bucket: time.Hour}
+
+// Compactor downsample and delete expired files of a CSV store directory
+type Compactor struct {
+	Dir    string
+	Policy RetentionPolicy
+}
+
+// NewCompactor create compactor of the CSV sink directory
+func NewCompactor(dir string, policy RetentionPolicy) *Compactor {
+	if policy.Bucket <= 0 {
+		policy.Bucket = time.Hour
+	}
+	return &Compactor{Dir: dir, Policy: policy}
+}
+
+// aggregate min, max and sum of values in one bucket
+type aggregate struct {
+	count         int
+	min, max, sum float64
+}
+
+type aggregateKey struct {
+	bucket  time.Time
+	sn, key string
+}
+
+// Compact downsample raw files older than RawDays into monthly aggregate files,
+// remove the raw files and delete aggregates older than AggregateMonths
+func (c *Compactor) Compact(now time.Time) error {
+	var errs []error
+	if c.Policy.RawDays > 0 {
+		limit := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -c.Policy.RawDays)
+		files, err := c.expired(csvRawDir, csvDayLayout, limit)
+		errs = append(errs, err)
+		for _, f := range files {
+			errs = append(errs, c.compactFile(f))
+		}
+	}
+	if c.Policy.AggregateMonths > 0 {
+		y, m, _ := now.UTC().Date()
+		limit := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).AddDate(0, -c.Policy.AggregateMonths, 0)
+		files, err := c.expired(csvAggregateDir, csvMonthLayout, limit)
+		errs = append(errs, err)
+		for _, f := range files {
+			log.Log.Infof("Remove expired aggregate %s", f)
+			errs = append(errs, os.Remove(f))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expired return files of the sub directory named before the limit
+func (c *Compactor) expired(sub, layout string, limit time.Time) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(c.Dir, sub))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".csv")
+		t, err := time.Parse(layout, name)
+		if e.IsDir() || err != nil || !t.Before(limit) {
+			continue
+		}
+		files = append(files, filepath.Join(c.Dir, sub, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// compactFile aggregate numeric values of the raw file and remove it
+func (c *Compactor) compactFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	aggregates := make(map[aggregateKey]*aggregate)
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = len(csvRawHeader)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("compact %s: %w", name, err)
+		}
+		ts, err := time.Parse(time.RFC3339Nano, record[0])
+		if err != nil {
+			continue
+		}
+		value, ok := parseCSVValue(record[4]).(float64)
+		if !ok {
+			continue
+		}
+		key := aggregateKey{ts.Truncate(c.Policy.Bucket), record[1], record[3]}
+		a, ok := aggregates[key]
+		if !ok {
+			a = &aggregate{min: math.Inf(1), max: math.Inf(-1)}
+			aggregates[key] = a
+		}
+		a.count++
+		a.sum += value
+		a.min = math.Min(a.min, value)
+		a.max = math.Max(a.max, value)
+	}
+	f.Close()
+	if err := c.writeAggregates(aggregates); err != nil {
+		return err
+	}
+	log.Log.Infof("Compacted %s into %d aggregates", name, len(aggregates))
+	return os.Remove(name)
+}
+
+// writeAggregates append aggregates to the file of their month
+func (c *Compactor) writeAggregates(aggregates map[aggregateKey]*aggregate) error {
+	keys := make([]aggregateKey, 0, len(aggregates))
+	for k := range aggregates {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].bucket.Equal(keys[j].bucket) {
+			return keys[i].bucket.Before(keys[j].bucket)
+		}
+		if keys[i].sn != keys[j].sn {
+			return keys[i].sn < keys[j].sn
+		}
+		return keys[i].key < keys[j].key
+	})
+	if err := os.MkdirAll(filepath.Join(c.Dir, csvAggregateDir), 0755); err != nil {
+		return err
+	}
+	var file *os.File
+	var writer *csv.Writer
+	month := ""
+	closeFile := func() error {
+		if file == nil {
+			return nil
+		}
+		writer.Flush()
+		err := writer.Error()
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	for _, k := range keys {
+		if m := k.bucket.Format(csvMonthLayout); m != month {
+			if err := closeFile(); err != nil {
+				return err
+			}
+			name := filepath.Join(c.Dir, csvAggregateDir, m+".csv")
+			_, statErr := os.Stat(name)
+			f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			file, writer, month = f, csv.NewWriter(f), m
+			if os.IsNotExist(statErr) {
+				if err := writer.Write(csvAggregateHeader); err != nil {
+					closeFile()
+					return err
+				}
+			}
+		}
+		a := aggregates[k]
+		err := writer.Write([]string{k.bucket.Format(time.RFC3339), k.sn, k.key, strconv.Itoa(a.count),
+			strconv.FormatFloat(a.min, 'f', -1, 64), strconv.FormatFloat(a.max, 'f', -1, 64),
+			strconv.FormatFloat(a.sum/float64(a.count), 'f', -1, 64)})
+		if err != nil {
+			closeFile()
+			return err
+		}
+	}
+	return closeFile()
+}
+
+// Run compact the store every interval until the context is cancelled
+func (c *Compactor) Run(ctx context.Context, interval time.Duration) {
+	for {
+		if err := c.Compact(now()); err != nil {
+			log.Log.Errorf("Error compacting %s: %v", c.Dir, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-CurrentClock().After(interval):
+		}
+	}
+}
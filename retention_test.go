@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactor(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewCSVSink(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	old := time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC)
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, sink.Write([]*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 80.0, Timestamp: old},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 70.0, Timestamp: old.Add(20 * time.Minute)},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.model", Value: "D2", Timestamp: old},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 60.0, Timestamp: now},
+	}))
+	assert.NoError(t, sink.Close())
+	assert.FileExists(t, filepath.Join(dir, "raw", "2025-03-01.csv"))
+
+	expiredAggregate := filepath.Join(dir, "aggregate", "2023-01.csv")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(expiredAggregate), 0755))
+	assert.NoError(t, os.WriteFile(expiredAggregate, []byte("x\n"), 0644))
+
+	compactor := NewCompactor(dir, DefaultRetentionPolicy)
+	assert.NoError(t, compactor.Compact(now))
+	assert.NoFileExists(t, filepath.Join(dir, "raw", "2025-03-01.csv"))
+	assert.FileExists(t, filepath.Join(dir, "raw", "2025-04-01.csv"))
+	assert.NoFileExists(t, expiredAggregate)
+
+	data, err := os.ReadFile(filepath.Join(dir, "aggregate", "2025-03.csv"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, []string{"bucket,serial_number,key,count,min,max,avg",
+		"2025-03-01T10:00:00Z,R331TEST,pd.soc,2,70,80,75"}, lines)
+}
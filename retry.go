@@ -0,0 +1,68 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// RetryPolicy retry with exponential backoff
+type RetryPolicy struct {
+	// MaxAttempts maximum number of attempts, zero retries until the context is done
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy default policy of the connect retry
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+
+// backoff return the wait time before the given attempt starting at 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = time.Second
+	}
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Retry call op until it succeeds, the attempts are exhausted or the context is done
+func (p RetryPolicy) Retry(ctx context.Context, name string, op func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; p.MaxAttempts <= 0 || attempt <= p.MaxAttempts; attempt++ {
+		if err = op(ctx); err == nil {
+			return nil
+		}
+		if p.MaxAttempts > 0 && attempt == p.MaxAttempts {
+			break
+		}
+		wait := p.backoff(attempt)
+		metricRetries.Add(1)
+		services.ServerMessage("Ecoflow: %s failed (attempt %d), retry in %v: %v", name, attempt, wait, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w (last error: %v)", name, ctx.Err(), err)
+		case <-CurrentClock().After(wait):
+		}
+	}
+	return fmt.Errorf("%s failed after %d attempts: %w", name, p.MaxAttempts, err)
+}
@@ -0,0 +1,54 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 3 * time.Millisecond}
+	assert.Equal(t, time.Millisecond, p.backoff(1))
+	assert.Equal(t, 2*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 3*time.Millisecond, p.backoff(5))
+
+	attempts := 0
+	err := p.Retry(context.Background(), "test", func(context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("offline")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = p.Retry(context.Background(), "test", func(context.Context) error {
+		attempts++
+		return errors.New("offline")
+	})
+	assert.ErrorContains(t, err, "after 3 attempts")
+	assert.Equal(t, 3, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = RetryPolicy{InitialBackoff: time.Hour}.Retry(ctx, "test", func(context.Context) error {
+		return errors.New("offline")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
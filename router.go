@@ -0,0 +1,383 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tknie/log"
+)
+
+// Capability access capability of a transport
+type Capability uint
+
+const (
+	// CapReadQuota transport can read the current quota of a device
+	CapReadQuota Capability = 1 << iota
+	// CapSetParam transport can send commands to a device
+	CapSetParam
+	// CapStream transport delivers telemetry of a device
+	CapStream
+)
+
+// Has check if all given capabilities are available
+func (c Capability) Has(o Capability) bool {
+	return c&o == o
+}
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range []struct {
+		c    Capability
+		name string
+	}{{CapReadQuota, "readQuota"}, {CapSetParam, "setParam"}, {CapStream, "stream"}} {
+		if c.Has(n.c) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ErrNotSupported operation not supported by the transport for the device
+var ErrNotSupported = errors.New("not supported by transport")
+
+// ErrNoData transport did not receive any data of the device yet
+var ErrNoData = errors.New("no data received")
+
+// ErrNotSent command was not sent by the transport, the next transport may send it
+var ErrNotSent = errors.New("command not sent")
+
+// notSent mark error of a command known not to be sent to the device
+func notSent(err error) error {
+	return fmt.Errorf("%w: %w", ErrNotSent, err)
+}
+
+// commandNotSent check if the error proves the command did not reach the
+// device, only then another transport is used. Timeouts, rejects and
+// cancellation are returned because the device may have applied the command.
+func commandNotSent(err error) bool {
+	if errors.Is(err, ErrNotSupported) || errors.Is(err, ErrNotSent) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// Transport access path to the devices
+type Transport interface {
+	Name() string
+	// Capabilities return the currently available capabilities for the device
+	Capabilities(serialNumber string) Capability
+	// ReadQuota read the current quota, the keys are in the namespace of the transport
+	ReadQuota(ctx context.Context, serialNumber string) (map[string]interface{}, error)
+	SetParam(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error)
+	// Stream call handler with the telemetry of the device until the returned
+	// function is called
+	Stream(ctx context.Context, serialNumber string, handler TelemetryHandler) (func(), error)
+}
+
+// HTTPTransport cloud HTTP API access
+type HTTPTransport struct {
+	Client *Client
+}
+
+// Name transport name
+func (t *HTTPTransport) Name() string { return "http" }
+
+// Capabilities HTTP API is always able to read and write
+func (t *HTTPTransport) Capabilities(string) Capability {
+	return CapReadQuota | CapSetParam
+}
+
+// ReadQuota read all quota of the device
+func (t *HTTPTransport) ReadQuota(ctx context.Context, serialNumber string) (map[string]interface{}, error) {
+	return t.Client.GetDeviceAllParameters(ctx, serialNumber)
+}
+
+// SetParam send command using the HTTP API
+func (t *HTTPTransport) SetParam(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	return t.Client.sendHTTP(ctx, req)
+}
+
+// Stream not supported by the HTTP API
+func (t *HTTPTransport) Stream(context.Context, string, TelemetryHandler) (func(), error) {
+	return nil, ErrNotSupported
+}
+
+// MQTTTransport cloud MQTT access using the global MQTT connection
+type MQTTTransport struct{}
+
+// Name transport name
+func (MQTTTransport) Name() string { return "mqtt" }
+
+func mqttConnected() bool {
+	return ecoclient != nil && ecoclient.Client != nil && ecoclient.Client.IsConnected()
+}
+
+// Capabilities MQTT delivers telemetry while connected, JSON based devices and
+// the PowerStream using protobuf frames accept commands using the set topic
+func (MQTTTransport) Capabilities(serialNumber string) Capability {
+	if !mqttConnected() {
+		return 0
+	}
+	c := CapReadQuota | CapStream
+	switch DetectModel(serialNumber) {
+	case ModelSmartPlug, ModelStream:
+	default:
+		if ecoclient.connectionConfig != nil {
+			c |= CapSetParam
+		}
+	}
+	return c
+}
+
+// ReadQuota return the latest telemetry received
+func (MQTTTransport) ReadQuota(_ context.Context, serialNumber string) (map[string]interface{}, error) {
+	return stateQuota(serialNumber)
+}
+
+func stateQuota(serialNumber string) (map[string]interface{}, error) {
+	snapshot := States.Snapshot(serialNumber)
+	if len(snapshot) == 0 {
+		return nil, ErrNoData
+	}
+	quota := make(map[string]interface{}, len(snapshot))
+	for k, v := range snapshot {
+		quota[k] = v.Value
+	}
+	return quota, nil
+}
+
+// SetParam publish command on the device set topic. JSON commands are not
+// acknowledged and the response is marked Unconfirmed, PowerStream commands
+// are sent as protobuf frames and the acknowledge of the device is awaited.
+func (t MQTTTransport) SetParam(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	if !t.Capabilities(req.Sn).Has(CapSetParam) {
+		return nil, ErrNotSupported
+	}
+	if DetectModel(req.Sn) == ModelPowerStream {
+		frame, supported, err := commandFrameOf(req)
+		if !supported {
+			return nil, ErrNotSupported
+		}
+		if err != nil {
+			return nil, notSent(err)
+		}
+		ack, err := ecoclient.SendCommandFrame(ctx, frame)
+		if err != nil {
+			return nil, err
+		}
+		return ackResponse(ack)
+	}
+	params, err := req.parameters()
+	if err != nil {
+		return nil, notSent(err)
+	}
+	params["version"] = "1.0"
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, notSent(err)
+	}
+	topic := fmt.Sprintf("/app/%s/%s/thing/property/set", ecoclient.connectionConfig.UserId, req.Sn)
+	token := ecoclient.Client.Publish(topic, 1, false, payload)
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		if errors.Is(err, mqtt.ErrNotConnected) {
+			return nil, notSent(err)
+		}
+		return nil, err
+	}
+	return &CmdSetResponse{Code: CodeSuccess, Message: "published", Unconfirmed: true}, nil
+}
+
+// Stream register telemetry handler for the device
+func (MQTTTransport) Stream(_ context.Context, serialNumber string, handler TelemetryHandler) (func(), error) {
+	return streamDevice(serialNumber, handler), nil
+}
+
+// streamDevice register telemetry handler filtered to the device
+func streamDevice(serialNumber string, handler TelemetryHandler) func() {
+	return RegisterTelemetryHandler(func(points []*Telemetry) {
+		filtered := make([]*Telemetry, 0, len(points))
+		for _, p := range points {
+			if strings.EqualFold(p.SerialNumber, serialNumber) {
+				filtered = append(filtered, p)
+			}
+		}
+		if len(filtered) > 0 {
+			handler(filtered)
+		}
+	})
+}
+
+// LocalTransport LAN or BLE access of the devices selected using UseLan or UseBle
+type LocalTransport struct{}
+
+// Name transport name
+func (LocalTransport) Name() string { return "local" }
+
+// Capabilities commands and telemetry are available if a local transport is selected
+func (LocalTransport) Capabilities(serialNumber string) Capability {
+	if _, ok := localTransportFor(serialNumber); ok {
+		return CapSetParam | CapStream
+	}
+	return 0
+}
+
+// ReadQuota not supported locally
+func (LocalTransport) ReadQuota(context.Context, string) (map[string]interface{}, error) {
+	return nil, ErrNotSupported
+}
+
+// SetParam send command locally if the command has a local equivalent
+func (LocalTransport) SetParam(ctx context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	response, ok, err := sendLan(ctx, req)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return response, err
+}
+
+// Stream register telemetry handler for the device
+func (t LocalTransport) Stream(_ context.Context, serialNumber string, handler TelemetryHandler) (func(), error) {
+	if !t.Capabilities(serialNumber).Has(CapStream) {
+		return nil, ErrNotSupported
+	}
+	return streamDevice(serialNumber, handler), nil
+}
+
+// Router select the best available transport per device, the transports are
+// tried in the given order
+type Router struct {
+	transports []Transport
+	order      map[Capability][]Transport
+}
+
+// NewRouter create router using the transports in order of preference
+func NewRouter(transports ...Transport) *Router {
+	return &Router{transports: transports}
+}
+
+// WithOrder use another order of preference for the capability
+func (r *Router) WithOrder(c Capability, transports ...Transport) *Router {
+	if r.order == nil {
+		r.order = make(map[Capability][]Transport)
+	}
+	r.order[c] = transports
+	return r
+}
+
+// DefaultRouter prefer local access, then MQTT and fall back to the HTTP API. Commands
+// prefer the acknowledged HTTP API over the MQTT set topic.
+func DefaultRouter(client *Client) *Router {
+	local, mqtt, http := LocalTransport{}, MQTTTransport{}, &HTTPTransport{Client: client}
+	return NewRouter(local, mqtt, http).WithOrder(CapSetParam, local, http, mqtt)
+}
+
+// Router return the transport router of the client
+func (c *Client) Router() *Router {
+	if c.router == nil {
+		return DefaultRouter(c)
+	}
+	return c.router
+}
+
+// SetRouter replace the transport router of the client
+func (c *Client) SetRouter(r *Router) {
+	c.router = r
+}
+
+// Select return all transports providing the capability for the device in
+// order of preference
+func (r *Router) Select(serialNumber string, c Capability) []Transport {
+	transports := r.transports
+	if ordered, ok := r.order[c]; ok {
+		transports = ordered
+	}
+	selected := make([]Transport, 0, len(transports))
+	for _, t := range transports {
+		if t.Capabilities(serialNumber).Has(c) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+// route call f with the selected transports until one succeeds. The next
+// transport is only tried if fallback accepts the error, routing stops if the
+// context is done.
+func (r *Router) route(ctx context.Context, serialNumber string, c Capability, fallback func(error) bool,
+	f func(t Transport) error) error {
+	var errs []error
+	for _, t := range r.Select(serialNumber, c) {
+		err := f(t)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			log.Log.Infof("Transport %s failed for %s: %v", t.Name(), serialNumber, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", t.Name(), err))
+		if ctx.Err() != nil || !fallback(err) {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("no transport with capability %s available for %s", c, serialNumber)
+	}
+	return errors.Join(errs...)
+}
+
+// anyError reading is repeated using the next transport on every error
+func anyError(error) bool { return true }
+
+// ReadQuota read quota using the best available transport
+func (r *Router) ReadQuota(ctx context.Context, serialNumber string) (quota map[string]interface{}, err error) {
+	err = r.route(ctx, serialNumber, CapReadQuota, anyError, func(t Transport) error {
+		quota, err = t.ReadQuota(ctx, serialNumber)
+		return err
+	})
+	return
+}
+
+// SetParam send command using the best available transport, the next transport
+// is only used if the command was not sent
+func (r *Router) SetParam(ctx context.Context, req CmdSetRequest) (response *CmdSetResponse, err error) {
+	err = r.route(ctx, req.Sn, CapSetParam, commandNotSent, func(t Transport) error {
+		response, err = t.SetParam(ctx, req)
+		return err
+	})
+	return
+}
+
+// Stream stream telemetry using the best available transport
+func (r *Router) Stream(ctx context.Context, serialNumber string, handler TelemetryHandler) (stop func(), err error) {
+	err = r.route(ctx, serialNumber, CapStream, anyError, func(t Transport) error {
+		stop, err = t.Stream(ctx, serialNumber, handler)
+		return err
+	})
+	return
+}
@@ -0,0 +1,121 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTransport struct {
+	name  string
+	caps  Capability
+	err   error
+	calls int
+}
+
+func (t *testTransport) Name() string                   { return t.name }
+func (t *testTransport) Capabilities(string) Capability { return t.caps }
+func (t *testTransport) ReadQuota(context.Context, string) (map[string]interface{}, error) {
+	t.calls++
+	return map[string]interface{}{"source": t.name}, t.err
+}
+func (t *testTransport) SetParam(context.Context, CmdSetRequest) (*CmdSetResponse, error) {
+	t.calls++
+	return &CmdSetResponse{Message: t.name}, t.err
+}
+func (t *testTransport) Stream(context.Context, string, TelemetryHandler) (func(), error) {
+	return nil, ErrNotSupported
+}
+
+func TestRouter(t *testing.T) {
+	local := &testTransport{name: "local", caps: CapSetParam, err: notSent(errors.New("offline"))}
+	mqtt := &testTransport{name: "mqtt", caps: CapStream | CapReadQuota}
+	http := &testTransport{name: "http", caps: CapReadQuota | CapSetParam}
+	router := NewRouter(local, mqtt, http)
+
+	response, err := router.SetParam(context.Background(), CmdSetRequest{Sn: "HW51TEST"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http", response.Message)
+	assert.Equal(t, 1, local.calls)
+
+	quota, err := router.ReadQuota(context.Background(), "HW51TEST")
+	assert.NoError(t, err)
+	assert.Equal(t, "mqtt", quota["source"])
+	assert.Equal(t, 1, http.calls)
+
+	_, err = router.Stream(context.Background(), "HW51TEST", func([]*Telemetry) {})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Equal(t, "readQuota|stream", mqtt.caps.String())
+}
+
+func TestRouterOrder(t *testing.T) {
+	local := &testTransport{name: "local", caps: CapSetParam, err: ErrNotSupported}
+	mqtt := &testTransport{name: "mqtt", caps: CapStream | CapReadQuota | CapSetParam}
+	http := &testTransport{name: "http", caps: CapReadQuota | CapSetParam}
+	router := NewRouter(local, mqtt, http).WithOrder(CapSetParam, local, http, mqtt)
+
+	response, err := router.SetParam(context.Background(), CmdSetRequest{Sn: "R331ORDER"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http", response.Message)
+	assert.Equal(t, 0, mqtt.calls)
+	quota, err := router.ReadQuota(context.Background(), "R331ORDER")
+	assert.NoError(t, err)
+	assert.Equal(t, "mqtt", quota["source"])
+
+	names := func(transports []Transport) []string {
+		result := make([]string, 0, len(transports))
+		for _, t := range transports {
+			result = append(result, t.Name())
+		}
+		return result
+	}
+	router = DefaultRouter(NewClient("access", "secret"))
+	assert.Equal(t, []string{"local", "http", "mqtt"}, names(router.order[CapSetParam]))
+	assert.Equal(t, []string{"local", "mqtt", "http"}, names(router.transports))
+}
+
+func TestRouterNoResend(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		fallback bool
+	}{
+		{"not supported", ErrNotSupported, true},
+		{"not sent", notSent(errors.New("offline")), true},
+		{"dial", &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{"write", &net.OpError{Op: "write", Err: errors.New("reset")}, false},
+		{"ack timeout", ErrAckTimeout, false},
+		{"rejected", ErrCommandRejected, false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+	for _, test := range tests {
+		local := &testTransport{name: "local", caps: CapSetParam, err: test.err}
+		http := &testTransport{name: "http", caps: CapSetParam}
+		_, err := NewRouter(local, http).SetParam(context.Background(), CmdSetRequest{Sn: "HW51RESEND"})
+		assert.Equal(t, test.fallback, err == nil, test.name)
+		assert.Equal(t, test.fallback, http.calls == 1, test.name)
+	}
+
+	// routing stops if the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	local := &testTransport{name: "local", caps: CapReadQuota, err: ErrNoData}
+	http := &testTransport{name: "http", caps: CapReadQuota}
+	_, err := NewRouter(local, http).ReadQuota(ctx, "HW51RESEND")
+	assert.ErrorIs(t, err, ErrNoData)
+	assert.Equal(t, 0, http.calls)
+}
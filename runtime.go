@@ -0,0 +1,228 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+	"golang.org/x/sync/errgroup"
+)
+
+// RestartMode restart policy of a runtime component
+type RestartMode int
+
+const (
+	// RestartNever component is not restarted
+	RestartNever RestartMode = iota
+	// RestartOnFailure component is restarted if it returns an error or panics
+	RestartOnFailure
+	// RestartAlways component is restarted whenever it returns before the runtime stops
+	RestartAlways
+)
+
+// DefaultRestartBackoff backoff between restarts of a component if not defined
+var DefaultRestartBackoff = RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute}
+
+// Component background task supervised by the runtime
+type Component struct {
+	Name    string
+	Run     func(ctx context.Context) error
+	Restart RestartMode
+	// Backoff wait time between restarts, MaxAttempts limits the restarts,
+	// DefaultRestartBackoff if zero
+	Backoff RetryPolicy
+	// Optional failure of the component is reported but does not stop the runtime
+	Optional bool
+}
+
+// ComponentStatus state of a runtime component
+type ComponentStatus struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"lastError,omitempty"`
+	Started   time.Time `json:"started,omitempty"`
+}
+
+// Runtime supervise the background components of the system. A component
+// failing after its restarts are exhausted stops all other components unless
+// it is optional; the errors of all components are reported by Wait.
+type Runtime struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	group  *errgroup.Group
+	mu     sync.Mutex
+	errs   []error
+	status map[string]*ComponentStatus
+}
+
+// NewRuntime create runtime stopped if the context is done
+func NewRuntime(ctx context.Context) *Runtime {
+	ctx, cancel := context.WithCancel(ctx)
+	group, ctx := errgroup.WithContext(ctx)
+	return &Runtime{ctx: ctx, cancel: cancel, group: group, status: make(map[string]*ComponentStatus)}
+}
+
+// Context context of the components, done if the runtime stops
+func (r *Runtime) Context() context.Context {
+	return r.ctx
+}
+
+// Done closed if the runtime stops
+func (r *Runtime) Done() <-chan struct{} {
+	return r.ctx.Done()
+}
+
+// Go start the component in background
+func (r *Runtime) Go(c Component) {
+	r.GoContext(context.Background(), c)
+}
+
+// GoContext start the component in background, it is stopped if the runtime or
+// ctx is done. The returned channel receives the final error of the component.
+func (r *Runtime) GoContext(ctx context.Context, c Component) <-chan error {
+	r.mu.Lock()
+	if _, ok := r.status[c.Name]; !ok {
+		r.status[c.Name] = &ComponentStatus{Name: c.Name}
+	}
+	r.mu.Unlock()
+	done := make(chan error, 1)
+	r.group.Go(func() error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(r.ctx, cancel)
+		defer stop()
+		err := r.supervise(ctx, c)
+		done <- err
+		if c.Optional {
+			return nil
+		}
+		return err
+	})
+	return done
+}
+
+// supervise run the component and restart it according to its policy
+func (r *Runtime) supervise(ctx context.Context, c Component) error {
+	backoff := c.Backoff
+	if backoff == (RetryPolicy{}) {
+		backoff = DefaultRestartBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		r.update(c.Name, func(s *ComponentStatus) {
+			s.Running = true
+			s.Started = now()
+		})
+		err := runComponent(ctx, c)
+		r.update(c.Name, func(s *ComponentStatus) {
+			s.Running = false
+			if err != nil {
+				s.LastError = err.Error()
+			}
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		restart := c.Restart == RestartAlways || (c.Restart == RestartOnFailure && err != nil)
+		if !restart || (backoff.MaxAttempts > 0 && attempt > backoff.MaxAttempts) {
+			if err == nil {
+				return nil
+			}
+			err = fmt.Errorf("component %s: %w", c.Name, err)
+			r.mu.Lock()
+			r.errs = append(r.errs, err)
+			r.mu.Unlock()
+			services.ServerMessage("Ecoflow: %v", err)
+			return err
+		}
+		wait := backoff.backoff(attempt)
+		services.ServerMessage("Ecoflow: component %s stopped, restart in %v: %v", c.Name, wait, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-CurrentClock().After(wait):
+		}
+		r.update(c.Name, func(s *ComponentStatus) { s.Restarts++ })
+	}
+}
+
+// runComponent run the component converting a panic into an error
+func runComponent(ctx context.Context, c Component) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			metricHandlerPanics.Add(1)
+			log.Log.Errorf("Component %s panicked: %v\n%s", c.Name, p, debug.Stack())
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return c.Run(ctx)
+}
+
+func (r *Runtime) update(name string, f func(s *ComponentStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f(r.status[name])
+}
+
+// Status state of all components sorted by name
+func (r *Runtime) Status() []ComponentStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]ComponentStatus, 0, len(r.status))
+	for _, s := range r.status {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Wait wait until all components are stopped and return the errors of all failed components
+func (r *Runtime) Wait() error {
+	_ = r.group.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.errs...)
+}
+
+// Stop stop all components and wait until they are finished
+func (r *Runtime) Stop() error {
+	r.cancel()
+	return r.Wait()
+}
+
+var backgroundRuntimes atomic.Pointer[Runtime]
+var processRuntime = sync.OnceValue(func() *Runtime { return NewRuntime(context.Background()) })
+
+// backgroundRuntime runtime supervising the workers of sinks, webhooks and
+// handlers, the runtime of the bootstrapped System or else a runtime of the
+// process
+func backgroundRuntime() *Runtime {
+	if r := backgroundRuntimes.Load(); r != nil {
+		return r
+	}
+	return processRuntime()
+}
+
+// setBackgroundRuntime use the runtime for background workers, nil restores
+// the runtime of the process
+func setBackgroundRuntime(r *Runtime) {
+	backgroundRuntimes.Store(r)
+}
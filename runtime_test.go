@@ -0,0 +1,116 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeRestartOnFailure(t *testing.T) {
+	r := NewRuntime(context.Background())
+	var runs atomic.Int32
+	r.Go(Component{Name: "poller", Restart: RestartOnFailure,
+		Backoff: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("poll failed")
+		}})
+	r.Go(Component{Name: "sink", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	err := r.Wait()
+	assert.ErrorContains(t, err, "component poller: poll failed")
+	assert.Equal(t, int32(3), runs.Load())
+	status := r.Status()
+	if assert.Len(t, status, 2) {
+		assert.Equal(t, "poller", status[0].Name)
+		assert.Equal(t, 2, status[0].Restarts)
+		assert.Equal(t, "poll failed", status[0].LastError)
+		assert.False(t, status[1].Running)
+	}
+	select {
+	case <-r.Done():
+	default:
+		t.Fatal("runtime not stopped by failed component")
+	}
+}
+
+func TestRuntimeOptionalAndPanic(t *testing.T) {
+	r := NewRuntime(context.Background())
+	r.Go(Component{Name: "a", Optional: true, Run: func(ctx context.Context) error {
+		return errors.New("a failed")
+	}})
+	r.Go(Component{Name: "b", Optional: true, Run: func(ctx context.Context) error {
+		panic("b broken")
+	}})
+	started := make(chan struct{})
+	r.Go(Component{Name: "c", Restart: RestartAlways, Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}})
+	<-started
+	assert.Eventually(t, func() bool {
+		return r.Status()[1].LastError != ""
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, r.Context().Err())
+	err := r.Stop()
+	assert.ErrorContains(t, err, "component a: a failed")
+	assert.ErrorContains(t, err, "component b: panic: b broken")
+}
+
+func TestRuntimeGoContext(t *testing.T) {
+	r := NewRuntime(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := r.GoContext(ctx, Component{Name: "flush", Restart: RestartAlways, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}})
+	failed := r.GoContext(context.Background(), Component{Name: "webhook", Optional: true, Run: func(ctx context.Context) error {
+		return errors.New("delivery failed")
+	}})
+	assert.ErrorContains(t, <-failed, "component webhook: delivery failed")
+	cancel()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("component not stopped by its context")
+	}
+	assert.NoError(t, r.Context().Err())
+	assert.ErrorContains(t, r.Stop(), "delivery failed")
+}
+
+func TestBackgroundRuntime(t *testing.T) {
+	r := NewRuntime(context.Background())
+	setBackgroundRuntime(r)
+	defer setBackgroundRuntime(nil)
+	b := newBatcher("test", SinkOptions{BatchSize: 2, FlushInterval: time.Hour}, func(string, []*Telemetry) error {
+		return errors.New("offline")
+	})
+	assert.Eventually(t, func() bool {
+		status := r.Status()
+		return len(status) == 1 && status[0].Name == "sink test" && status[0].Running
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, b.add([]*Telemetry{{SerialNumber: "HW51RUNTIME", Key: "a"}}))
+	assert.EqualError(t, b.close(), "offline")
+	assert.NoError(t, r.Stop())
+	setBackgroundRuntime(nil)
+	assert.Same(t, processRuntime(), backgroundRuntime())
+}
@@ -0,0 +1,192 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// Predefined scene names
+const (
+	SceneHome     = "home"
+	SceneAway     = "away"
+	SceneStorm    = "storm"
+	SceneVacation = "vacation"
+)
+
+// scenePlan schedule plan name of the entries of the active scene
+const scenePlan = "scene"
+
+// EventSceneChanged scene activated, Data contains SceneChange
+const EventSceneChanged EventType = "scene.changed"
+
+// ErrUnknownScene scene not defined
+var ErrUnknownScene = errors.New("unknown scene")
+
+// Scene mode bundling device settings and schedules, e.g. home, away, storm or vacation
+type Scene struct {
+	Name     string          `json:"name"`
+	Settings *DesiredState   `json:"settings,omitempty"`
+	Schedule []ScheduleEntry `json:"schedule,omitempty"`
+}
+
+// SceneChange activated scene
+type SceneChange struct {
+	From    string          `json:"from,omitempty"`
+	To      string          `json:"to"`
+	Plan    *Plan           `json:"plan,omitempty"`
+	Results []SettingResult `json:"results,omitempty"`
+	Time    time.Time       `json:"time"`
+}
+
+// SceneManager switch between scenes, the settings are applied using the
+// declarative Apply engine and the schedule replaces the entries of the
+// previous scene
+type SceneManager struct {
+	client    *Client
+	scheduler *Scheduler
+	mu        sync.Mutex
+	scenes    map[string]Scene
+	active    string
+}
+
+// NewSceneManager create scene manager, the scheduler may be nil if the scenes
+// have no schedules
+func NewSceneManager(client *Client, scheduler *Scheduler) *SceneManager {
+	return &SceneManager{client: client, scheduler: scheduler, scenes: make(map[string]Scene)}
+}
+
+// ReadScenes read JSON list of scenes
+func ReadScenes(r io.Reader) ([]Scene, error) {
+	var scenes []Scene
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&scenes); err != nil {
+		return nil, fmt.Errorf("invalid scenes: %w", err)
+	}
+	return scenes, nil
+}
+
+// Define add or replace scenes
+func (m *SceneManager) Define(scenes ...Scene) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range scenes {
+		if s.Name == "" {
+			return errors.New("scene need a name")
+		}
+		if len(s.Schedule) > 0 && m.scheduler == nil {
+			return fmt.Errorf("schedule of scene %s needs a scheduler", s.Name)
+		}
+		m.scenes[s.Name] = s
+	}
+	return nil
+}
+
+// Scenes return the names of the defined scenes
+func (m *SceneManager) Scenes() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.scenes))
+	for name := range m.scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Active return the name of the active scene, empty if none was activated
+func (m *SceneManager) Active() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+// Activate apply the settings of the scene and replace the scheduled entries
+// of the previous scene, entries in the past are dropped
+func (m *SceneManager) Activate(ctx context.Context, name string) (*SceneChange, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scene, ok := m.scenes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScene, name)
+	}
+	change := &SceneChange{From: m.active, To: name, Time: now()}
+	if scene.Settings != nil {
+		plan, results, err := m.client.Apply(ctx, scene.Settings)
+		change.Plan = plan
+		change.Results = results
+		if err != nil {
+			return change, fmt.Errorf("scene %s: %w", name, err)
+		}
+	}
+	if m.scheduler != nil {
+		entries := make([]ScheduleEntry, 0, len(scene.Schedule))
+		for _, e := range scene.Schedule {
+			if e.At.Before(change.Time) {
+				continue
+			}
+			e.SerialNumber = ResolveDevice(e.SerialNumber)
+			entries = append(entries, e)
+		}
+		m.scheduler.Replace(scenePlan, entries...)
+	}
+	m.active = name
+	log.Log.Infof("Scene %s activated", name)
+	Events.Publish(Event{Type: EventSceneChanged, Time: change.Time, Data: *change})
+	return change, nil
+}
+
+// ActivateOn activate the scene on events of the type accepted by match, e.g.
+// storm mode on a weather alert. The returned function unsubscribes.
+func (m *SceneManager) ActivateOn(ctx context.Context, scene string, match func(Event) bool, types ...EventType) func() {
+	return Events.Subscribe(func(e Event) {
+		if match != nil && !match(e) {
+			return
+		}
+		if m.Active() == scene {
+			return
+		}
+		if _, err := m.Activate(ctx, scene); err != nil {
+			log.Log.Errorf("Scene %s triggered by %s failed: %v", scene, e.Type, err)
+		}
+	}, types...)
+}
+
+// EnableScenes register the scene endpoints, listing needs the telemetry:read
+// and switching the device:control scope
+func (s *StatusServer) EnableScenes(m *SceneManager) {
+	s.handle("GET /scenes", ScopeTelemetryRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"scenes": m.Scenes(), "active": m.Active()})
+	}))
+	s.handle("POST /scenes/{name}", ScopeDeviceControl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		change, err := m.Activate(r.Context(), r.PathValue("name"))
+		switch {
+		case errors.Is(err, ErrUnknownScene):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case err != nil:
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, change)
+		}
+	}))
+}
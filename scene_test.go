@@ -0,0 +1,93 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSceneManager(t *testing.T) {
+	oldInterval := SettingsVerifyInterval
+	SettingsVerifyInterval = time.Millisecond
+	defer func() { SettingsVerifyInterval = oldInterval }()
+
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0})
+	client := newTestClient(t, transport)
+	scheduler := NewScheduler(client)
+	manager := NewSceneManager(client, scheduler)
+
+	scenes, err := ReadScenes(strings.NewReader(`[
+	{"name":"home","settings":{"devices":{"HW51SCENE":{"permanentWatts":200}}}},
+	{"name":"away","settings":{"devices":{"HW51SCENE":{"permanentWatts":50}}},
+	 "schedule":[{"at":"2100-01-01T08:00:00Z","serialNumber":"HW51SCENE","setting":"permanentWatts","value":100},
+	             {"at":"2000-01-01T08:00:00Z","serialNumber":"HW51SCENE","setting":"permanentWatts","value":100}]}]`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, manager.Define(scenes...))
+	assert.Error(t, NewSceneManager(client, nil).Define(scenes...))
+	assert.Equal(t, []string{"away", "home"}, manager.Scenes())
+
+	change, err := manager.Activate(context.Background(), SceneHome)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, change.Plan.Updates())
+	assert.Equal(t, 1, transport.puts())
+	assert.Empty(t, scheduler.Pending())
+
+	var changes []SceneChange
+	unsubscribe := Events.Subscribe(func(e Event) { changes = append(changes, e.Data.(SceneChange)) }, EventSceneChanged)
+	defer unsubscribe()
+	stop := manager.ActivateOn(context.Background(), SceneAway, func(e Event) bool {
+		return e.Data.(TriggerFired).Rule == "absent"
+	}, EventTrigger)
+	defer stop()
+	Events.Publish(Event{Type: EventTrigger, Data: TriggerFired{Rule: "cloud"}})
+	assert.Equal(t, SceneHome, manager.Active())
+	Events.Publish(Event{Type: EventTrigger, Data: TriggerFired{Rule: "absent"}})
+	assert.Equal(t, SceneAway, manager.Active())
+	assert.Equal(t, 2, transport.puts())
+	assert.Len(t, scheduler.Pending(), 1)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, SceneHome, changes[0].From)
+	}
+
+	_, err = manager.Activate(context.Background(), SceneStorm)
+	assert.ErrorIs(t, err, ErrUnknownScene)
+}
+
+func TestSceneEndpoints(t *testing.T) {
+	manager := NewSceneManager(NewClient("access", "secret"), nil)
+	assert.NoError(t, manager.Define(Scene{Name: SceneVacation}))
+	s := NewStatusServer("")
+	s.Auth = NewTokenAuth()
+	assert.NoError(t, s.Auth.SetTokens(map[string][]string{"automation": {"telemetry:read", "device:control"}}))
+	s.EnableScenes(manager)
+
+	call := func(method, path string) int {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Authorization", "Bearer automation")
+		recorder := httptest.NewRecorder()
+		s.Handler().ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/scenes/storm"))
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/scenes/vacation"))
+	assert.Equal(t, SceneVacation, manager.Active())
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/scenes"))
+}
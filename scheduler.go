@@ -0,0 +1,253 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// ScheduleEntry setting change applied at the given time. Entries with a Daily
+// time of day are repeated every day in the location of the scheduler.
+type ScheduleEntry struct {
+	At time.Time `json:"at"`
+	// Daily wall clock time in the format 15:04, At is the next execution
+	Daily        string  `json:"daily,omitempty"`
+	SerialNumber string  `json:"serialNumber"`
+	Setting      string  `json:"setting"`
+	Value        float64 `json:"value"`
+	// Plan name of the plan the entry belongs to, used to replace plans
+	Plan string `json:"plan,omitempty"`
+}
+
+func (e ScheduleEntry) String() string {
+	if e.Daily != "" {
+		return fmt.Sprintf("%s (daily %s) %s %s=%v", e.At.Format(time.RFC3339), e.Daily, e.SerialNumber, e.Setting, e.Value)
+	}
+	return fmt.Sprintf("%s %s %s=%v", e.At.Format(time.RFC3339), e.SerialNumber, e.Setting, e.Value)
+}
+
+// nextTimeOfDay return the next time after t the wall clock in the location shows the
+// given minutes of the day. A time skipped by a DST transition is executed at the
+// transition, a time occurring twice only at the first occurrence.
+func nextTimeOfDay(t time.Time, minutes int, loc *time.Location) time.Time {
+	local := t.In(loc)
+	for day := 0; ; day++ {
+		c := wallClock(local.Year(), local.Month(), local.Day()+day, minutes, loc)
+		if c.After(t) {
+			return c
+		}
+	}
+}
+
+// wallClock return the first instant of the day the wall clock shows the minutes
+func wallClock(year int, month time.Month, day, minutes int, loc *time.Location) time.Time {
+	hour, minute := minutes/60, minutes%60
+	c := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	start, _ := c.ZoneBounds()
+	if c.Hour() != hour || c.Minute() != minute {
+		// skipped by a forward transition, the time is normalized behind the gap
+		return start
+	}
+	if start.IsZero() {
+		return c
+	}
+	_, offset := c.Zone()
+	_, previous := start.Add(-time.Nanosecond).Zone()
+	if shift := time.Duration(previous-offset) * time.Second; shift > 0 {
+		// backward transition, the wall clock may show the time in the previous zone before
+		if e := c.Add(-shift); e.Before(start) {
+			if l := e.In(loc); l.Hour() == hour && l.Minute() == minute {
+				return e
+			}
+		}
+	}
+	return c
+}
+
+// Scheduler apply scheduled setting changes using the client
+type Scheduler struct {
+	client   *Client
+	mu       sync.Mutex
+	location *time.Location
+	entries  []ScheduleEntry
+	wakeup   chan struct{}
+	// OnError called if an entry could not be applied
+	OnError func(entry ScheduleEntry, err error)
+}
+
+// NewScheduler create scheduler applying the settings with the client
+func NewScheduler(client *Client) *Scheduler {
+	return &Scheduler{client: client, location: time.Local, wakeup: make(chan struct{}, 1)}
+}
+
+// SetLocation set the time zone the daily entries are evaluated in, the local
+// time zone is used by default. Pending daily entries are rescheduled.
+func (s *Scheduler) SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s.mu.Lock()
+	s.location = loc
+	t := now()
+	for i, e := range s.entries {
+		if e.Daily != "" {
+			s.entries[i] = s.scheduleDaily(e, t)
+		}
+	}
+	sort.SliceStable(s.entries, func(i, j int) bool { return s.entries[i].At.Before(s.entries[j].At) })
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Location return the time zone of the daily entries
+func (s *Scheduler) Location() *time.Location {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location
+}
+
+// scheduleDaily set the next execution of the daily entry after t, the lock need to be held
+func (s *Scheduler) scheduleDaily(e ScheduleEntry, t time.Time) ScheduleEntry {
+	minutes, err := parseTimeOfDay(e.Daily)
+	if err != nil {
+		log.Log.Errorf("Invalid daily schedule %s: %v", e.Daily, err)
+		e.At = time.Time{}
+		return e
+	}
+	e.At = nextTimeOfDay(t, minutes, s.location)
+	return e
+}
+
+// Add add entries to the schedule, the first execution of daily entries is
+// calculated in the location of the scheduler
+func (s *Scheduler) Add(entries ...ScheduleEntry) {
+	s.mu.Lock()
+	t := now()
+	for _, e := range entries {
+		if e.Daily != "" {
+			e = s.scheduleDaily(e, t)
+			if e.At.IsZero() {
+				continue
+			}
+		}
+		s.entries = append(s.entries, e)
+	}
+	sort.SliceStable(s.entries, func(i, j int) bool { return s.entries[i].At.Before(s.entries[j].At) })
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Replace replace all pending entries of the plan by the given entries
+func (s *Scheduler) Replace(plan string, entries ...ScheduleEntry) {
+	s.mu.Lock()
+	kept := s.entries[:0]
+	for _, e := range s.entries {
+		if e.Plan != plan {
+			kept = append(kept, e)
+		}
+	}
+	s.entries = kept
+	s.mu.Unlock()
+	planned := make([]ScheduleEntry, len(entries))
+	for i, e := range entries {
+		e.Plan = plan
+		planned[i] = e
+	}
+	s.Add(planned...)
+}
+
+// Pending return all entries not yet applied
+func (s *Scheduler) Pending() []ScheduleEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ScheduleEntry{}, s.entries...)
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// due remove and return all entries due at the given time
+func (s *Scheduler) due(now time.Time) ([]ScheduleEntry, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := 0
+	for i < len(s.entries) && !s.entries[i].At.After(now) {
+		i++
+	}
+	due := append([]ScheduleEntry{}, s.entries[:i]...)
+	s.entries = s.entries[i:]
+	rescheduled := false
+	for _, e := range due {
+		if e.Daily != "" {
+			// the next execution is calculated from the due time, a late run is not repeated
+			t := now
+			if e.At.After(t) {
+				t = e.At
+			}
+			if next := s.scheduleDaily(e, t); !next.At.IsZero() {
+				s.entries = append(s.entries, next)
+				rescheduled = true
+			}
+		}
+	}
+	if rescheduled {
+		sort.SliceStable(s.entries, func(i, j int) bool { return s.entries[i].At.Before(s.entries[j].At) })
+	}
+	next := time.Duration(-1)
+	if len(s.entries) > 0 {
+		next = s.entries[0].At.Sub(now)
+	}
+	return due, next
+}
+
+// Run apply the entries when due according to the package clock until the
+// context is done
+func (s *Scheduler) Run(ctx context.Context) error {
+	clock := CurrentClock()
+	for {
+		due, next := s.due(clock.Now())
+		for _, e := range due {
+			s.apply(ctx, e)
+		}
+		if next < 0 {
+			next = time.Hour
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wakeup:
+		case <-clock.After(next):
+		}
+	}
+}
+
+func (s *Scheduler) apply(ctx context.Context, e ScheduleEntry) {
+	log.Log.Infof("Apply scheduled %s", e)
+	_, err := s.client.ApplySetting(ctx, e.SerialNumber, e.Setting, e.Value)
+	if err != nil {
+		services.ServerMessage("Scheduled setting %s failed: %v", e, err)
+		if s.OnError != nil {
+			s.OnError(e, err)
+		}
+	}
+}
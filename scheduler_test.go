@@ -0,0 +1,76 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextTimeOfDayDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// 02:30 is skipped at the spring transition and executed at 03:00 CEST
+	next := nextTimeOfDay(time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC), 150, berlin)
+	assert.Equal(t, time.Date(2025, 3, 30, 1, 0, 0, 0, time.UTC), next.UTC())
+	next = nextTimeOfDay(next, 150, berlin)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 30, 0, 0, time.UTC), next.UTC())
+
+	// 02:30 occurs twice at the autumn transition and is executed once
+	next = nextTimeOfDay(time.Date(2025, 10, 25, 12, 0, 0, 0, time.UTC), 150, berlin)
+	assert.Equal(t, time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC), next.UTC())
+	next = nextTimeOfDay(next, 150, berlin)
+	assert.Equal(t, time.Date(2025, 10, 27, 1, 30, 0, 0, time.UTC), next.UTC())
+
+	next = nextTimeOfDay(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), 7*60, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC), next)
+}
+
+func TestSchedulerDaily(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(NewFakeClock(start))
+	defer SetClock(nil)
+
+	scheduler := NewScheduler(nil)
+	scheduler.SetLocation(newYork)
+	scheduler.Add(ScheduleEntry{Daily: "01:30", SerialNumber: "HW51DAILY", Setting: "permanentWatts", Value: 100},
+		ScheduleEntry{Daily: "25:00", SerialNumber: "HW51DAILY", Setting: "permanentWatts", Value: 200})
+	pending := scheduler.Pending()
+	if !assert.Len(t, pending, 1) {
+		return
+	}
+	// 01:30 EDT before the transition to EST
+	assert.Equal(t, time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC), pending[0].At.UTC())
+
+	var runs []time.Time
+	t0 := pending[0].At
+	for _, tick := range []time.Duration{0, time.Hour, 2 * time.Hour, 25 * time.Hour} {
+		due, _ := scheduler.due(t0.Add(tick))
+		for _, e := range due {
+			runs = append(runs, e.At.UTC())
+		}
+	}
+	assert.Equal(t, []time.Time{time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC),
+		time.Date(2025, 11, 3, 6, 30, 0, 0, time.UTC)}, runs)
+	assert.Contains(t, scheduler.Pending()[0].String(), "daily 01:30")
+
+	scheduler.SetLocation(time.UTC)
+	assert.Equal(t, 1, scheduler.Pending()[0].At.Hour())
+}
@@ -0,0 +1,198 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventSchemaDrift key not contained in the known catalog received, Data contains SchemaDrift
+const EventSchemaDrift EventType = "schema.drift"
+
+// SchemaDrift newly seen key of a device model
+type SchemaDrift struct {
+	SerialNumber string      `json:"serialNumber"`
+	Model        string      `json:"model"`
+	Source       string      `json:"source"`
+	Key          string      `json:"key"`
+	Sample       interface{} `json:"sample"`
+	Time         time.Time   `json:"time"`
+}
+
+type schemaCatalog struct {
+	keys     map[string]bool
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// SchemaDriftDetector compare received quota and MQTT keys against the catalog
+// of known keys of the typed quota structs and settings. Each new key is reported
+// once per device model, as log message and EventSchemaDrift event.
+type SchemaDriftDetector struct {
+	mu       sync.Mutex
+	catalogs map[DeviceModel]*schemaCatalog
+	drifts   map[string]SchemaDrift
+}
+
+// NewSchemaDriftDetector create detector using the typed quota structs and the
+// setting keys as known catalog
+func NewSchemaDriftDetector() *SchemaDriftDetector {
+	d := &SchemaDriftDetector{catalogs: make(map[DeviceModel]*schemaCatalog), drifts: make(map[string]SchemaDrift)}
+	for model, f := range typedQuotas {
+		d.catalog(model).addStruct("", reflect.TypeOf(f()).Elem())
+	}
+	for _, s := range settings {
+		for _, model := range s.Models {
+			d.Known(model, s.QuotaKey)
+		}
+	}
+	return d
+}
+
+func (d *SchemaDriftDetector) catalog(model DeviceModel) *schemaCatalog {
+	c, ok := d.catalogs[model]
+	if !ok {
+		c = &schemaCatalog{keys: make(map[string]bool), fields: make(map[string]bool)}
+		d.catalogs[model] = c
+	}
+	return c
+}
+
+func (c *schemaCatalog) add(key string) {
+	c.keys[key] = true
+	c.fields[key[strings.LastIndex(key, ".")+1:]] = true
+}
+
+// addStruct add the quota tags of the typed struct, slice elements are matched
+// with any index
+func (c *schemaCatalog) addStruct(prefix string, rt reflect.Type) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("quota")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			c.addStruct(prefix+tag, field.Type)
+		case reflect.Slice:
+			element := &schemaCatalog{keys: make(map[string]bool), fields: c.fields}
+			element.addStruct("", field.Type.Elem())
+			for key := range element.keys {
+				pattern := regexp.QuoteMeta(prefix) + strings.ReplaceAll(regexp.QuoteMeta(tag), "%d", `\d+`) + regexp.QuoteMeta(key)
+				c.patterns = append(c.patterns, regexp.MustCompile("^"+pattern+"$"))
+			}
+		default:
+			c.add(prefix + tag)
+		}
+	}
+}
+
+// known check the key, keys of other sources than the quota are checked by the
+// field name as the MQTT messages use different prefixes
+func (c *schemaCatalog) known(source, key string) bool {
+	if c.keys[key] || key == KeyNetBatteryPower || key == KeyNetGridPower {
+		return true
+	}
+	for _, p := range c.patterns {
+		if p.MatchString(key) {
+			return true
+		}
+	}
+	return source != SourceHttp && c.fields[key[strings.LastIndex(key, ".")+1:]]
+}
+
+// Known add keys to the catalog of the device model
+func (d *SchemaDriftDetector) Known(model DeviceModel, keys ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := d.catalog(model)
+	for _, k := range keys {
+		c.add(k)
+	}
+}
+
+// Check report the keys not contained in the catalog, each key only the first
+// time it is seen for the device model
+func (d *SchemaDriftDetector) Check(points []*Telemetry) []SchemaDrift {
+	var drifts []SchemaDrift
+	d.mu.Lock()
+	for _, p := range points {
+		model := DetectModel(p.SerialNumber)
+		if d.catalog(model).known(p.Source, p.Key) || decodedKey(p.SerialNumber, p.Key) {
+			continue
+		}
+		id := fmt.Sprintf("%d|%s", model, p.Key)
+		if _, ok := d.drifts[id]; ok {
+			continue
+		}
+		drift := SchemaDrift{SerialNumber: p.SerialNumber, Model: model.String(), Source: p.Source,
+			Key: p.Key, Sample: p.Value, Time: p.Timestamp}
+		d.drifts[id] = drift
+		drifts = append(drifts, drift)
+	}
+	d.mu.Unlock()
+	for _, drift := range drifts {
+		log.Log.Infof("Schema drift: new %s key %s of %s (%s) sample %v", drift.Source, drift.Key,
+			drift.SerialNumber, drift.Model, drift.Sample)
+		Events.Publish(Event{Type: EventSchemaDrift, SerialNumber: drift.SerialNumber, Time: drift.Time, Data: drift})
+	}
+	return drifts
+}
+
+// Drifts return all new keys seen so far sorted by model and key
+func (d *SchemaDriftDetector) Drifts() []SchemaDrift {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drifts := make([]SchemaDrift, 0, len(d.drifts))
+	for _, drift := range d.drifts {
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Model != drifts[j].Model {
+			return drifts[i].Model < drifts[j].Model
+		}
+		return drifts[i].Key < drifts[j].Key
+	})
+	return drifts
+}
+
+var (
+	schemaDriftLock     sync.RWMutex
+	schemaDriftDetector *SchemaDriftDetector
+)
+
+// SetSchemaDriftDetector enable drift detection of the HTTP quota and the
+// dispatched telemetry, nil disables the detection
+func SetSchemaDriftDetector(d *SchemaDriftDetector) {
+	schemaDriftLock.Lock()
+	defer schemaDriftLock.Unlock()
+	schemaDriftDetector = d
+}
+
+// checkSchemaDrift apply the drift detector if set
+func checkSchemaDrift(points func() []*Telemetry) {
+	schemaDriftLock.RLock()
+	d := schemaDriftDetector
+	schemaDriftLock.RUnlock()
+	if d != nil {
+		d.Check(points())
+	}
+}
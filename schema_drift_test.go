@@ -0,0 +1,65 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDriftQuota(t *testing.T) {
+	d := NewSchemaDriftDetector()
+	points := NormalizeTelemetry("R331DRIFT", SourceHttp, loadQuotaFixture(t, "delta2_quota.json"), time.Now())
+	drifts := d.Check(points)
+	if assert.Len(t, drifts, 1) {
+		assert.Equal(t, "pd.beepMode", drifts[0].Key)
+		assert.Equal(t, "Delta 2", drifts[0].Model)
+		assert.Equal(t, 1.0, drifts[0].Sample)
+	}
+	assert.Empty(t, d.Check(points))
+
+	d.Known(ModelPowerStream, "20_1.heartbeatFrequency")
+	points = NormalizeTelemetry("HW51DRIFT", SourceHttp, loadQuotaFixture(t, "powerstream_quota.json"), time.Now())
+	drifts = d.Check(points)
+	if assert.Len(t, drifts, 1) {
+		assert.Equal(t, "20_1.wirelessErrCode", drifts[0].Key)
+	}
+	assert.Len(t, d.Drifts(), 2)
+}
+
+func TestSchemaDriftDispatch(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	unsubscribe := Events.Subscribe(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}, EventSchemaDrift)
+	defer unsubscribe()
+	SetSchemaDriftDetector(NewSchemaDriftDetector())
+	defer SetSchemaDriftDetector(nil)
+
+	DispatchTelemetry([]*Telemetry{
+		{SerialNumber: "HW51DRIFT", Source: SourceMqtt, Key: "inverterHeartbeat.pv1InputWatts", Value: 100.0},
+		{SerialNumber: "HW51DRIFT", Source: SourceMqtt, Key: "inverterHeartbeat.newFirmwareField", Value: 7.0},
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, events, 1) {
+		drift := events[0].Data.(SchemaDrift)
+		assert.Equal(t, "inverterHeartbeat.newFirmwareField", drift.Key)
+		assert.Equal(t, SourceMqtt, drift.Source)
+	}
+}
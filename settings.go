@@ -0,0 +1,154 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Setting writable device setting, read using the HTTP quota and written using
+// the typed setters
+type Setting struct {
+	Name     string
+	QuotaKey string
+	// Scale factor converting the quota value into the setter value
+	Scale  float64
+	Models []DeviceModel
+	apply  func(ctx context.Context, client *Client, serialNumber string, value float64) (*CmdSetResponse, error)
+}
+
+var settings = make(map[string]*Setting)
+
+// registerSetting register writable setting
+func registerSetting(s *Setting) {
+	if s.Scale == 0 {
+		s.Scale = 1
+	}
+	settings[s.Name] = s
+}
+
+func init() {
+	registerSetting(&Setting{Name: "permanentWatts", QuotaKey: "20_1.permanentWatts", Scale: 0.1,
+		Models: []DeviceModel{ModelPowerStream},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetPermanentWatts(ctx, sn, value)
+		}})
+	registerSetting(&Setting{Name: "supplyPriority", QuotaKey: quotaSupplyPriority,
+		Models: []DeviceModel{ModelPowerStream},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetSupplyPriority(ctx, sn, SupplyPriority(value))
+		}})
+	registerSetting(&Setting{Name: "lowerLimit", QuotaKey: "20_1.lowerLimit",
+		Models: []DeviceModel{ModelPowerStream},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetBatteryLowerLimit(ctx, sn, int(value))
+		}})
+	registerSetting(&Setting{Name: "upperLimit", QuotaKey: "20_1.upperLimit",
+		Models: []DeviceModel{ModelPowerStream},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetBatteryUpperLimit(ctx, sn, int(value))
+		}})
+	registerSetting(&Setting{Name: "brightness", QuotaKey: "20_1.invBrightness", Scale: 100.0 / maxPowerStreamBrightness,
+		Models: []DeviceModel{ModelPowerStream},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetIndicatorBrightness(ctx, sn, int(math.Round(value)))
+		}})
+	registerSetting(&Setting{Name: "maxChgSoc", QuotaKey: "bms_emsStatus.maxChargeSoc",
+		Models: []DeviceModel{ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetChargeLimit(ctx, sn, int(value))
+		}})
+	registerSetting(&Setting{Name: "minDsgSoc", QuotaKey: "bms_emsStatus.minDsgSoc",
+		Models: []DeviceModel{ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetDischargeLimit(ctx, sn, int(value))
+		}})
+	registerSetting(&Setting{Name: "acChargeWatts", QuotaKey: "mppt.cfgChgWatts",
+		Models: []DeviceModel{ModelDelta2, ModelRiver2, ModelRiver2Max, ModelRiver2Pro},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetACChargeWatts(ctx, sn, int(math.Round(value)))
+		}})
+	registerSetting(&Setting{Name: "slowChgPower", QuotaKey: quotaSlowChgWatts,
+		Models: []DeviceModel{ModelDelta2Max, ModelDeltaMax, ModelDeltaPro},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetSlowChargePower(ctx, sn, int(math.Round(value)))
+		}})
+	registerSetting(&Setting{Name: "lcdBrightness", QuotaKey: "pd.brightLevel", Scale: 100.0 / maxLcdBrightLevel,
+		Models: []DeviceModel{ModelDelta2, ModelDelta2Max, ModelRiver2, ModelRiver2Max, ModelRiver2Pro},
+		apply: func(ctx context.Context, client *Client, sn string, value float64) (*CmdSetResponse, error) {
+			return client.SetIndicatorBrightness(ctx, sn, int(math.Round(value)))
+		}})
+}
+
+// LookupSetting return writable setting with the given name
+func LookupSetting(name string) (*Setting, bool) {
+	s, ok := settings[name]
+	return s, ok
+}
+
+// SupportedBy check if setting is supported by the device model
+func (s *Setting) SupportedBy(model DeviceModel) bool {
+	for _, m := range s.Models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingsFor return all writable settings of a device model sorted by name
+func SettingsFor(model DeviceModel) []*Setting {
+	result := make([]*Setting, 0)
+	for _, s := range settings {
+		if s.SupportedBy(model) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func lookupDeviceSetting(serialNumber, name string) (*Setting, error) {
+	s, ok := LookupSetting(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %s", name)
+	}
+	if !s.SupportedBy(DetectModel(serialNumber)) {
+		return nil, fmt.Errorf("setting %s not supported by device %s", name, serialNumber)
+	}
+	return s, nil
+}
+
+// ReadSetting read current value of a setting using the HTTP quota
+func (client *Client) ReadSetting(ctx context.Context, serialNumber, name string) (float64, error) {
+	s, err := lookupDeviceSetting(serialNumber, name)
+	if err != nil {
+		return 0, err
+	}
+	value, err := client.getQuotaValue(ctx, serialNumber, s.QuotaKey)
+	if err != nil {
+		return 0, err
+	}
+	return value * s.Scale, nil
+}
+
+// ApplySetting write the value of a setting
+func (client *Client) ApplySetting(ctx context.Context, serialNumber, name string, value float64) (*CmdSetResponse, error) {
+	s, err := lookupDeviceSetting(serialNumber, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, client, serialNumber, value)
+}
@@ -0,0 +1,150 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SettingsVerifyInterval polling interval of the settings verification
+var SettingsVerifyInterval = 2 * time.Second
+
+// SettingsDocument writable settings of a device
+type SettingsDocument struct {
+	SerialNumber string             `json:"serialNumber"`
+	Model        string             `json:"model"`
+	Exported     time.Time          `json:"exported"`
+	Settings     map[string]float64 `json:"settings"`
+}
+
+// SettingResult result of one setting applied by ApplySettings
+type SettingResult struct {
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`
+	Previous float64 `json:"previous"`
+	Applied  bool    `json:"applied"`
+	Verified bool    `json:"verified"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// quotaSettings extract all settings of the model from the quota
+func quotaSettings(model DeviceModel, quota map[string]interface{}) map[string]float64 {
+	values := make(map[string]float64)
+	for _, s := range SettingsFor(model) {
+		if v, ok := quota[s.QuotaKey].(float64); ok {
+			values[s.Name] = v * s.Scale
+		}
+	}
+	return values
+}
+
+// ExportSettings read all writable settings of the device
+func (client *Client) ExportSettings(ctx context.Context, serialNumber string) (*SettingsDocument, error) {
+	model := DetectModel(serialNumber)
+	if len(SettingsFor(model)) == 0 {
+		return nil, fmt.Errorf("no writable settings known for device %s", serialNumber)
+	}
+	quota, err := client.GetDeviceAllParameters(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &SettingsDocument{SerialNumber: serialNumber, Model: model.String(), Exported: now(),
+		Settings: quotaSettings(model, quota)}, nil
+}
+
+// ApplySettings replay the settings of the document on the device, which may be
+// another device of the same model. Only changed settings are sent, all sent
+// settings are verified by reading the quota until VerifyTimeout.
+func (client *Client) ApplySettings(ctx context.Context, serialNumber string, doc *SettingsDocument) ([]SettingResult, error) {
+	model := DetectModel(serialNumber)
+	if doc.Model != "" && doc.Model != model.String() {
+		return nil, fmt.Errorf("settings of %s can't be applied to %s device %s", doc.Model, model, serialNumber)
+	}
+	quota, err := client.GetDeviceAllParameters(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	current := quotaSettings(model, quota)
+	names := make([]string, 0, len(doc.Settings))
+	for name := range doc.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]SettingResult, 0, len(names))
+	pending := make(map[int]bool)
+	for _, name := range names {
+		// a setting missing in the quota is sent and verified like a changed one
+		previous, known := current[name]
+		r := SettingResult{Name: name, Value: doc.Settings[name], Previous: previous}
+		if _, err := lookupDeviceSetting(serialNumber, name); err != nil {
+			r.Error = err.Error()
+		} else if known && settingEqual(r.Previous, r.Value) {
+			r.Verified = true
+		} else if _, err := client.ApplySetting(ctx, serialNumber, name, r.Value); err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Applied = true
+			pending[len(results)] = true
+		}
+		results = append(results, r)
+	}
+	if len(pending) == 0 {
+		return results, settingsError(results)
+	}
+
+	deadline := now().Add(VerifyTimeout)
+	for len(pending) > 0 && now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		case <-CurrentClock().After(SettingsVerifyInterval):
+		}
+		quota, err := client.GetDeviceAllParameters(ctx, serialNumber)
+		if err != nil {
+			continue
+		}
+		values := quotaSettings(model, quota)
+		for i := range pending {
+			if v, ok := values[results[i].Name]; ok && settingEqual(v, results[i].Value) {
+				results[i].Verified = true
+				delete(pending, i)
+			}
+		}
+	}
+	for i := range pending {
+		results[i].Error = ErrVerificationFailed.Error()
+	}
+	return results, settingsError(results)
+}
+
+// settingEqual compare setting values, scaled values may have rounding differences
+func settingEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.5
+}
+
+func settingsError(results []SettingResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d settings failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaSettings(t *testing.T) {
+	quota := map[string]interface{}{
+		"20_1.permanentWatts": 1500.0,
+		"20_1.lowerLimit":     10.0,
+		"20_1.invBrightness":  1023.0,
+		"20_1.pv1InputWatts":  230.0,
+	}
+	values := quotaSettings(ModelPowerStream, quota)
+	assert.Equal(t, map[string]float64{"permanentWatts": 150, "lowerLimit": 10, "brightness": 100}, values)
+	assert.True(t, settingEqual(99.8, 100))
+	assert.Error(t, settingsError([]SettingResult{{Name: "lowerLimit", Error: "failed"}}))
+}
+
+func TestApplySettingsMissingKey(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		VerifyTimeout, SettingsVerifyInterval = timeout, interval
+	}(VerifyTimeout, SettingsVerifyInterval)
+	VerifyTimeout, SettingsVerifyInterval = 20*time.Millisecond, time.Millisecond
+	transport := newFakeAPI(map[string]interface{}{"20_1.permanentWatts": 1500.0})
+	client := newTestClient(t, transport)
+
+	doc := &SettingsDocument{Model: ModelPowerStream.String(),
+		Settings: map[string]float64{"permanentWatts": 150, "lowerLimit": 0}}
+	results, err := client.ApplySettings(context.Background(), "HW51BACKUP", doc)
+	assert.Error(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "lowerLimit", results[0].Name)
+		assert.True(t, results[0].Applied)
+		assert.False(t, results[0].Verified)
+		assert.Equal(t, ErrVerificationFailed.Error(), results[0].Error)
+		assert.True(t, results[1].Verified)
+		assert.False(t, results[1].Applied)
+	}
+	assert.Equal(t, 1, transport.puts())
+}
@@ -0,0 +1,61 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadApplySetting(t *testing.T) {
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0,
+		"20_1.invBrightness": 1023.0, "20_1.lowerLimit": 10.0})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		value float64
+	}{
+		{"permanentWatts", 100},
+		{"brightness", 100},
+		{"lowerLimit", 10},
+	}
+	for _, test := range tests {
+		value, err := client.ReadSetting(ctx, "HW51SETTING", test.name)
+		assert.NoError(t, err, test.name)
+		assert.InDelta(t, test.value, value, 0.001, test.name)
+	}
+
+	_, err := client.ApplySetting(ctx, "HW51SETTING", "permanentWatts", 150)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500.0, transport.value("20_1.permanentWatts"))
+	_, err = client.ApplySetting(ctx, "HW51SETTING", "brightness", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 512.0, transport.value("20_1.brightness"))
+
+	_, err = client.ReadSetting(ctx, "HW51SETTING", "unknown")
+	assert.EqualError(t, err, "unknown setting unknown")
+	_, err = client.ApplySetting(ctx, "R331SETTING", "permanentWatts", 100)
+	assert.EqualError(t, err, "setting permanentWatts not supported by device R331SETTING")
+	assert.Equal(t, 2, transport.puts())
+}
+
+func TestSettingsFor(t *testing.T) {
+	names := make([]string, 0)
+	for _, s := range SettingsFor(ModelPowerStream) {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"brightness", "lowerLimit", "permanentWatts", "supplyPriority", "upperLimit"}, names)
+}
@@ -0,0 +1,43 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"github.com/tknie/ecoflow/rest"
+)
+
+// Signer signs requests of the Ecoflow IoT Open API
+type Signer = rest.Signer
+
+// Signature signature parameters of a request
+type Signature = rest.Signature
+
+// NewSigner create new signer using the access and secret key
+func NewSigner(accessKey, secretKey string) *Signer {
+	return rest.NewSigner(accessKey, secretKey)
+}
+
+// NumberFormat formatting rules of floating point parameters used for the
+// signature and the request body
+type NumberFormat = rest.NumberFormat
+
+// NumberRule formatting rule of floating point parameters
+type NumberRule = rest.NumberRule
+
+const (
+	// NumberShortest shortest representation, whole numbers without decimals (2000)
+	NumberShortest = rest.NumberShortest
+	// NumberDecimal whole numbers keep one decimal (2000.0)
+	NumberDecimal = rest.NumberDecimal
+	// NumberInteger numbers are rounded to integers
+	NumberInteger = rest.NumberInteger
+)
@@ -0,0 +1,353 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// SourceSimulator telemetry source of the simulated devices
+const SourceSimulator = "simulator"
+
+// DefaultSimulatorInterval interval of the simulated telemetry messages
+var DefaultSimulatorInterval = 10 * time.Second
+
+// simulatorParams quota keys of the command parameters accepted by the
+// simulated devices
+var simulatorParams = map[string]string{
+	"permanentWatts": "20_1.permanentWatts",
+	"supplyPriority": quotaSupplyPriority,
+	"lowerLimit":     "20_1.lowerLimit",
+	"upperLimit":     "20_1.upperLimit",
+	"brightness":     "20_1.invBrightness",
+	"maxChgSoc":      "bms_emsStatus.maxChargeSoc",
+	"minDsgSoc":      "bms_emsStatus.minDsgSoc",
+	"chgWatts":       "mppt.cfgChgWatts",
+}
+
+// SimulatedDevice parameters of a simulated device
+type SimulatedDevice struct {
+	SerialNumber string
+	// CapacityWh battery capacity in watt hours
+	CapacityWh float64
+	// PeakSolarWatts solar input at noon
+	PeakSolarWatts float64
+	// LoadWatts constant household load of portable power stations
+	LoadWatts float64
+	// Soc initial state of charge in percent
+	Soc float64
+}
+
+type simDevice struct {
+	SimulatedDevice
+	model DeviceModel
+	quota map[string]float64
+	last  time.Time
+}
+
+// Simulator synthetic devices implementing the Transport interface, used to
+// develop UI and automation code without hardware or credentials. Solar input
+// follows a daily curve and the state of charge follows the power flow, commands
+// change the simulated settings.
+type Simulator struct {
+	// Interval of the telemetry messages of Stream and Run
+	Interval time.Duration
+	// Now time source of the simulation
+	Now     func() time.Time
+	mu      sync.Mutex
+	devices map[string]*simDevice
+}
+
+// NewSimulator create simulator with PowerStream and Delta like devices
+func NewSimulator(devices ...SimulatedDevice) *Simulator {
+	s := &Simulator{Interval: DefaultSimulatorInterval, Now: now, devices: make(map[string]*simDevice)}
+	for _, d := range devices {
+		s.Add(d)
+	}
+	return s
+}
+
+// Add add a simulated device, defaults depend on the model detected by the serial number
+func (s *Simulator) Add(device SimulatedDevice) {
+	device.SerialNumber = strings.ToUpper(device.SerialNumber)
+	model := DetectModel(device.SerialNumber)
+	if device.CapacityWh == 0 {
+		device.CapacityWh = 1024
+	}
+	if device.PeakSolarWatts == 0 {
+		device.PeakSolarWatts = 800
+	}
+	if device.LoadWatts == 0 && model != ModelPowerStream {
+		device.LoadWatts = 150
+	}
+	if device.Soc == 0 {
+		device.Soc = 50
+	}
+	d := &simDevice{SimulatedDevice: device, model: model}
+	if model == ModelPowerStream {
+		d.quota = map[string]float64{"20_1.permanentWatts": 2000, quotaSupplyPriority: float64(PrioritizePowerSupply),
+			"20_1.lowerLimit": 10, "20_1.upperLimit": 100, "20_1.invBrightness": 1023, "20_1.batSoc": device.Soc}
+	} else {
+		d.quota = map[string]float64{"bms_emsStatus.maxChargeSoc": 100, "bms_emsStatus.minDsgSoc": 0,
+			"mppt.cfgChgWatts": 500, "pd.soc": device.Soc}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[device.SerialNumber] = d
+}
+
+// Devices return serial numbers of the simulated devices
+func (s *Simulator) Devices() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sns := make([]string, 0, len(s.devices))
+	for sn := range s.devices {
+		sns = append(sns, sn)
+	}
+	return sns
+}
+
+// solarWatts solar input of a clear day, zero between 18:00 and 06:00
+func solarWatts(peak float64, t time.Time) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+	if hour <= 6 || hour >= 18 {
+		return 0
+	}
+	return math.Round(peak * math.Sin(math.Pi*(hour-6)/12))
+}
+
+// step advance the simulation of the device to the given time
+func (d *simDevice) step(now time.Time) {
+	elapsed := 0.0
+	if !d.last.IsZero() && now.After(d.last) {
+		elapsed = now.Sub(d.last).Hours()
+	}
+	d.last = now
+	solar := solarWatts(d.PeakSolarWatts, now)
+	if d.model == ModelPowerStream {
+		d.stepPowerStream(solar, elapsed)
+		return
+	}
+	d.stepPowerStation(solar, elapsed)
+}
+
+// stepPowerStream inverter feeds the permanent watts, surplus solar charges and
+// missing power discharges the battery within the limits
+func (d *simDevice) stepPowerStream(solar, hours float64) {
+	soc := d.quota["20_1.batSoc"]
+	output := d.quota["20_1.permanentWatts"] / 10
+	if SupplyPriority(d.quota[quotaSupplyPriority]) == PrioritizeStorage && soc < d.quota["20_1.upperLimit"] {
+		output = 0
+	}
+	battery := solar - output
+	switch {
+	case battery > 0 && soc >= d.quota["20_1.upperLimit"]:
+		battery = 0
+		output = solar
+	case battery < 0 && soc <= d.quota["20_1.lowerLimit"]:
+		battery = 0
+		output = solar
+	}
+	soc = nextSoc(soc, battery*hours/d.CapacityWh*100, d.quota["20_1.lowerLimit"], d.quota["20_1.upperLimit"])
+	d.quota["20_1.batSoc"] = math.Round(soc*10) / 10
+	d.quota["20_1.pv1InputWatts"] = math.Round(solar / 2 * 10)
+	d.quota["20_1.pv2InputWatts"] = math.Round(solar / 2 * 10)
+	// battery input of the inverter is positive while discharging
+	d.quota["20_1.batInputWatts"] = math.Round(-battery * 10)
+	d.quota["20_1.invOutputWatts"] = math.Round(output * 10)
+}
+
+// nextSoc change state of charge by delta percent, stopping at the limits
+func nextSoc(soc, delta, lower, upper float64) float64 {
+	next := soc + delta
+	if delta < 0 && soc >= lower {
+		next = math.Max(next, lower)
+	}
+	if delta > 0 && soc <= upper {
+		next = math.Min(next, upper)
+	}
+	return math.Max(0, math.Min(100, next))
+}
+
+// stepPowerStation load is supplied by solar and battery, surplus solar
+// charges the battery up to the charge limit
+func (d *simDevice) stepPowerStation(solar, hours float64) {
+	soc := d.quota["pd.soc"]
+	load := d.LoadWatts
+	if soc <= d.quota["bms_emsStatus.minDsgSoc"] && solar < load {
+		load = solar
+	}
+	input := solar
+	if soc >= d.quota["bms_emsStatus.maxChargeSoc"] && input > load {
+		input = load
+	}
+	soc = nextSoc(soc, (input-load)*hours/d.CapacityWh*100, d.quota["bms_emsStatus.minDsgSoc"], d.quota["bms_emsStatus.maxChargeSoc"])
+	d.quota["pd.soc"] = math.Round(soc*10) / 10
+	d.quota["pd.wattsInSum"] = input
+	d.quota["pd.wattsOutSum"] = load
+	d.quota["mppt.inWatts"] = input
+}
+
+// Step advance all simulated devices to the given time
+func (s *Simulator) Step(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.devices {
+		d.step(now)
+	}
+}
+
+func (s *Simulator) device(serialNumber string) (*simDevice, bool) {
+	d, ok := s.devices[strings.ToUpper(serialNumber)]
+	return d, ok
+}
+
+// Name transport name
+func (s *Simulator) Name() string { return "simulator" }
+
+// Capabilities simulated devices provide all capabilities
+func (s *Simulator) Capabilities(serialNumber string) Capability {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.device(serialNumber); ok {
+		return CapReadQuota | CapSetParam | CapStream
+	}
+	return 0
+}
+
+// ReadQuota advance the simulation and return the quota of the device
+func (s *Simulator) ReadQuota(_ context.Context, serialNumber string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.device(serialNumber)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	d.step(s.Now())
+	quota := make(map[string]interface{}, len(d.quota))
+	for k, v := range d.quota {
+		quota[k] = v
+	}
+	return quota, nil
+}
+
+// SetParam change the simulated settings of the device
+func (s *Simulator) SetParam(_ context.Context, req CmdSetRequest) (*CmdSetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.device(req.Sn)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	changed := 0
+	for name, v := range req.Params {
+		key, known := simulatorParams[name]
+		if _, exists := d.quota[key]; !known || !exists {
+			continue
+		}
+		value, err := simulatorValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		d.quota[key] = value
+		changed++
+	}
+	if changed == 0 {
+		return nil, ErrNotSupported
+	}
+	log.Log.Debugf("Simulator %s applied %d parameters", req.Sn, changed)
+	return &CmdSetResponse{Code: "0", Message: "simulated"}, nil
+}
+
+func simulatorValue(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case float64:
+		return value, nil
+	case float32:
+		return float64(value), nil
+	case int:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// telemetry advance the simulation and return the telemetry of the device
+func (s *Simulator) telemetry(serialNumber string) []*Telemetry {
+	quota, err := s.ReadQuota(context.Background(), serialNumber)
+	if err != nil {
+		return nil
+	}
+	return NormalizeTelemetry(strings.ToUpper(serialNumber), SourceSimulator, quota, s.Now())
+}
+
+// Stream call handler with simulated telemetry every interval until the
+// returned function is called or the context is done
+func (s *Simulator) Stream(ctx context.Context, serialNumber string, handler TelemetryHandler) (func(), error) {
+	if !s.Capabilities(serialNumber).Has(CapStream) {
+		return nil, ErrNotSupported
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go s.tick(ctx, func() {
+		if points := s.telemetry(serialNumber); len(points) > 0 {
+			handler(points)
+		}
+	})
+	return cancel, nil
+}
+
+// Run dispatch the telemetry of all simulated devices to the registered
+// telemetry handlers every interval until the context is done, used as
+// demo mode of applications
+func (s *Simulator) Run(ctx context.Context) {
+	s.tick(ctx, func() {
+		for _, sn := range s.Devices() {
+			if points := s.telemetry(sn); len(points) > 0 {
+				DispatchTelemetry(points)
+			}
+		}
+	})
+}
+
+func (s *Simulator) tick(ctx context.Context, f func()) {
+	f()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-CurrentClock().After(s.Interval):
+			f()
+		}
+	}
+}
+
+// DemoRouter router using only the simulator, set at the client to run
+// without hardware or credentials
+func DemoRouter(s *Simulator) *Router {
+	return NewRouter(s)
+}
@@ -0,0 +1,78 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatorPowerStream(t *testing.T) {
+	sim := NewSimulator(SimulatedDevice{SerialNumber: "hw51sim", CapacityWh: 1000, PeakSolarWatts: 800, Soc: 50})
+	now := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	sim.Now = func() time.Time { return now }
+	client := NewClient("access", "secret")
+	client.SetRouter(DemoRouter(sim))
+
+	_, err := client.SetPermanentWatts(context.Background(), "HW51SIM", 300)
+	assert.NoError(t, err)
+	quota, err := client.Router().ReadQuota(context.Background(), "HW51SIM")
+	assert.NoError(t, err)
+	assert.Equal(t, 3000.0, quota["20_1.permanentWatts"])
+	assert.Equal(t, 4000.0, quota["20_1.pv1InputWatts"])
+	assert.Equal(t, 3000.0, quota["20_1.invOutputWatts"])
+	assert.Equal(t, 50.0, quota["20_1.batSoc"])
+
+	// surplus of 500 W charges the battery for one hour
+	now = now.Add(time.Hour)
+	quota, _ = sim.ReadQuota(context.Background(), "HW51SIM")
+	assert.InDelta(t, 96.0, quota["20_1.batSoc"], 2)
+
+	// night: battery discharges to the lower limit
+	now = time.Date(2025, 6, 21, 19, 0, 0, 0, time.UTC)
+	sim.Step(now)
+	now = now.Add(10 * time.Hour)
+	quota, _ = sim.ReadQuota(context.Background(), "HW51SIM")
+	assert.Equal(t, 0.0, quota["20_1.pv1InputWatts"])
+	assert.Equal(t, 0.0, quota["20_1.invOutputWatts"])
+	assert.Equal(t, 10.0, quota["20_1.batSoc"])
+
+	_, err = sim.SetParam(context.Background(), CmdSetRequest{Sn: "HW51SIM", Params: map[string]interface{}{"unknown": 1}})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Equal(t, Capability(0), sim.Capabilities("R331OTHER"))
+}
+
+func TestSimulatorStream(t *testing.T) {
+	sim := NewSimulator(SimulatedDevice{SerialNumber: "R331SIM"})
+	sim.Interval = time.Millisecond
+	received := make(chan []*Telemetry, 10)
+	stop, err := sim.Stream(context.Background(), "R331SIM", func(points []*Telemetry) {
+		select {
+		case received <- points:
+		default:
+		}
+	})
+	assert.NoError(t, err)
+	defer stop()
+	select {
+	case points := <-received:
+		assert.Equal(t, SourceSimulator, points[0].Source)
+		assert.Equal(t, "R331SIM", points[0].SerialNumber)
+	case <-time.After(time.Second):
+		t.Fatal("no simulated telemetry")
+	}
+	_, err = sim.Stream(context.Background(), "HW51NONE", func([]*Telemetry) {})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
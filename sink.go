@@ -0,0 +1,218 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// Encoding encoding of telemetry published by sinks
+type Encoding int
+
+const (
+	// EncodingJSON JSON array of telemetry values
+	EncodingJSON Encoding = iota
+	// EncodingProtobuf protobuf TelemetryBatch message
+	EncodingProtobuf
+	// EncodingMsgpack msgpack array of telemetry values
+	EncodingMsgpack
+)
+
+// Sink receive normalized telemetry values
+type Sink interface {
+	Write(points []*Telemetry) error
+	Close() error
+}
+
+// SinkOptions common options of publishing sinks
+type SinkOptions struct {
+	Encoding Encoding
+	// Codec custom codec used instead of the Encoding
+	Codec     Codec
+	BatchSize int
+	// FlushInterval maximum time values wait in a batch, batches without
+	// interval use DefaultFlushInterval
+	FlushInterval time.Duration
+}
+
+// ErrSinkClosed write to a closed sink
+var ErrSinkClosed = errors.New("sink closed")
+
+// DefaultFlushInterval flush interval of batches if BatchSize is greater than
+// one and no FlushInterval is configured
+var DefaultFlushInterval = 5 * time.Second
+
+// ContextSink sink able to cancel writes using the message context
+type ContextSink interface {
+	Sink
+	WriteContext(ctx context.Context, points []*Telemetry) error
+}
+
+// AttachSink register the sink for all dispatched telemetry, the returned function
+// detaches the sink again. Sinks implementing ContextSink get the message context.
+func AttachSink(sink Sink) func() {
+	return RegisterTelemetryContextHandler(func(ctx context.Context, points []*Telemetry) {
+		var err error
+		if cs, ok := sink.(ContextSink); ok {
+			err = cs.WriteContext(ctx, points)
+		} else {
+			err = sink.Write(points)
+		}
+		if err != nil {
+			log.Log.Errorf("Error writing telemetry to sink %T: %v", sink, err)
+		}
+	})
+}
+
+// EncodeTelemetry encode telemetry values with the given encoding
+func EncodeTelemetry(points []*Telemetry, encoding Encoding) ([]byte, error) {
+	codec, err := CodecFor(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(points)
+}
+
+// DecodeTelemetry decode telemetry values encoded by EncodeTelemetry
+func DecodeTelemetry(data []byte, encoding Encoding) ([]*Telemetry, error) {
+	codec, err := CodecFor(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Decode(data)
+}
+
+// encode encode telemetry values with the configured codec or encoding
+func (o SinkOptions) encode(points []*Telemetry) ([]byte, error) {
+	if o.Codec != nil {
+		return o.Codec.Encode(points)
+	}
+	return EncodeTelemetry(points, o.Encoding)
+}
+
+// batcher collect telemetry per serial number until batch size or flush interval is reached
+type batcher struct {
+	mu      sync.Mutex
+	size    int
+	pending map[string][]*Telemetry
+	flush   func(serialNumber string, points []*Telemetry) error
+	stop    context.CancelFunc
+	done    <-chan error
+	closed  bool
+	once    sync.Once
+	err     error
+}
+
+// newBatcher create batcher of the named sink, the flush loop is a component
+// of the background runtime
+func newBatcher(name string, options SinkOptions, flush func(serialNumber string, points []*Telemetry) error) *batcher {
+	b := &batcher{size: options.BatchSize, pending: make(map[string][]*Telemetry), flush: flush}
+	interval := options.FlushInterval
+	if interval <= 0 && b.size > 1 {
+		// values of an incomplete batch must not wait until Close
+		interval = DefaultFlushInterval
+	}
+	if interval > 0 {
+		var ctx context.Context
+		ctx, b.stop = context.WithCancel(context.Background())
+		b.done = backgroundRuntime().GoContext(ctx, Component{Name: "sink " + name,
+			Run: b.loop(interval), Restart: RestartOnFailure, Optional: true})
+	}
+	return b
+}
+
+// loop flush the batches in the interval until the context is done
+func (b *batcher) loop(interval time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-CurrentClock().After(interval):
+				if err := b.flushAll(); err != nil {
+					return fmt.Errorf("flush telemetry batch: %w", err)
+				}
+			}
+		}
+	}
+}
+
+func (b *batcher) add(points []*Telemetry) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrSinkClosed
+	}
+	if b.size <= 1 && b.stop == nil {
+		b.mu.Unlock()
+		return b.flushGrouped(groupBySerialNumber(points))
+	}
+	ready := make(map[string][]*Telemetry)
+	for _, p := range points {
+		b.pending[p.SerialNumber] = append(b.pending[p.SerialNumber], p)
+		if b.size > 0 && len(b.pending[p.SerialNumber]) >= b.size {
+			ready[p.SerialNumber] = b.pending[p.SerialNumber]
+			delete(b.pending, p.SerialNumber)
+		}
+	}
+	b.mu.Unlock()
+	return b.flushGrouped(ready)
+}
+
+func (b *batcher) flushAll() error {
+	b.mu.Lock()
+	ready := b.pending
+	b.pending = make(map[string][]*Telemetry)
+	b.mu.Unlock()
+	return b.flushGrouped(ready)
+}
+
+func (b *batcher) flushGrouped(grouped map[string][]*Telemetry) error {
+	var lastErr error
+	for sn, points := range grouped {
+		if err := b.flush(sn, points); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// close stop the flush loop and flush the pending batches, further calls
+// return the result of the first one
+func (b *batcher) close() error {
+	b.once.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+		if b.stop == nil {
+			b.err = b.flushAll()
+			return
+		}
+		b.stop()
+		b.err = errors.Join(<-b.done, b.flushAll())
+	})
+	return b.err
+}
+
+func groupBySerialNumber(points []*Telemetry) map[string][]*Telemetry {
+	grouped := make(map[string][]*Telemetry)
+	for _, p := range points {
+		grouped[p.SerialNumber] = append(grouped[p.SerialNumber], p)
+	}
+	return grouped
+}
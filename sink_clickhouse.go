@@ -0,0 +1,129 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClickHouseConn connection interface, the Conn of clickhouse-go v2 implements it
+type ClickHouseConn interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	AsyncInsert(ctx context.Context, query string, wait bool, args ...any) error
+}
+
+// ClickHouseEvent wide event row, all values of one device message are stored in
+// one row with numeric and text values in map columns
+type ClickHouseEvent struct {
+	Timestamp    time.Time
+	SerialNumber string
+	Source       string
+	Numbers      map[string]float64
+	Texts        map[string]string
+}
+
+// ClickHouseSink store telemetry in ClickHouse using batched async inserts
+type ClickHouseSink struct {
+	conn  ClickHouseConn
+	Table string
+	// Wait wait for the async insert to be flushed by the server
+	Wait    bool
+	Timeout time.Duration
+	batch   *batcher
+}
+
+// NewClickHouseSink create new ClickHouse telemetry sink, the batch options
+// should be set because ClickHouse prefers few large inserts
+func NewClickHouseSink(conn ClickHouseConn, table string, options SinkOptions) *ClickHouseSink {
+	if table == "" {
+		table = "ecoflow_telemetry"
+	}
+	s := &ClickHouseSink{conn: conn, Table: table, Timeout: 30 * time.Second}
+	s.batch = newBatcher("clickhouse", options, s.insert)
+	return s
+}
+
+// CreateTable create the wide event table if not exists
+func (s *ClickHouseSink) CreateTable(ctx context.Context) error {
+	return s.conn.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	timestamp DateTime64(3, 'UTC'),
+	serial_number LowCardinality(String),
+	source LowCardinality(String),
+	numbers Map(LowCardinality(String), Float64),
+	texts Map(LowCardinality(String), String)
+) ENGINE = MergeTree
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (serial_number, timestamp)`, s.Table))
+}
+
+// ClickHouseEvents group telemetry values of the same device, source and timestamp
+// into wide events
+func ClickHouseEvents(points []*Telemetry) []*ClickHouseEvent {
+	type eventKey struct {
+		sn, source string
+		ts         int64
+	}
+	events := make(map[eventKey]*ClickHouseEvent)
+	result := make([]*ClickHouseEvent, 0)
+	for _, p := range points {
+		key := eventKey{p.SerialNumber, p.Source, p.Timestamp.UnixNano()}
+		e, ok := events[key]
+		if !ok {
+			e = &ClickHouseEvent{Timestamp: p.Timestamp.UTC(), SerialNumber: p.SerialNumber,
+				Source: p.Source, Numbers: make(map[string]float64), Texts: make(map[string]string)}
+			events[key] = e
+			result = append(result, e)
+		}
+		switch v := p.Value.(type) {
+		case float64:
+			e.Numbers[p.Key] = v
+		case bool:
+			e.Numbers[p.Key] = float64(boolToInt(v))
+		default:
+			e.Texts[p.Key] = fmt.Sprint(v)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result
+}
+
+func (s *ClickHouseSink) insert(_ string, points []*Telemetry) error {
+	events := ClickHouseEvents(points)
+	if len(events) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	rows := make([]string, 0, len(events))
+	args := make([]any, 0, len(events)*5)
+	for _, e := range events {
+		rows = append(rows, "(?, ?, ?, ?, ?)")
+		args = append(args, e.Timestamp, e.SerialNumber, e.Source, e.Numbers, e.Texts)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (timestamp, serial_number, source, numbers, texts) VALUES %s",
+		s.Table, strings.Join(rows, ", "))
+	return s.conn.AsyncInsert(ctx, query, s.Wait, args...)
+}
+
+// Write store telemetry values, batched if configured
+func (s *ClickHouseSink) Write(points []*Telemetry) error {
+	return s.batch.add(points)
+}
+
+// Close flush pending telemetry values
+func (s *ClickHouseSink) Close() error {
+	return s.batch.close()
+}
@@ -0,0 +1,60 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClickHouse struct {
+	queries []string
+	args    [][]any
+}
+
+func (c *testClickHouse) Exec(_ context.Context, query string, _ ...any) error {
+	c.queries = append(c.queries, query)
+	return nil
+}
+
+func (c *testClickHouse) AsyncInsert(_ context.Context, query string, _ bool, args ...any) error {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	return nil
+}
+
+func TestClickHouseSink(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	conn := &testClickHouse{}
+	sink := NewClickHouseSink(conn, "", SinkOptions{BatchSize: 3})
+	assert.NoError(t, sink.CreateTable(context.Background()))
+	assert.Contains(t, conn.queries[0], "CREATE TABLE IF NOT EXISTS ecoflow_telemetry")
+
+	assert.NoError(t, sink.Write([]*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 80.0, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "inv.acOut", Value: true, Timestamp: ts},
+	}))
+	assert.Len(t, conn.args, 0)
+	assert.NoError(t, sink.Write([]*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.model", Value: "D2", Timestamp: ts.Add(time.Second)},
+	}))
+	if assert.Len(t, conn.args, 1) {
+		assert.Contains(t, conn.queries[1], "VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)")
+		assert.Len(t, conn.args[0], 10)
+		assert.Equal(t, map[string]float64{"pd.soc": 80, "inv.acOut": 1}, conn.args[0][3])
+		assert.Equal(t, map[string]string{"pd.model": "D2"}, conn.args[0][9])
+	}
+	assert.NoError(t, sink.Close())
+}
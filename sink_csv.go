@@ -0,0 +1,123 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// csvRawDir sub directory of the daily raw telemetry files
+const csvRawDir = "raw"
+
+// csvDayLayout file name layout of the daily raw files
+const csvDayLayout = "2006-01-02"
+
+var csvRawHeader = []string{"timestamp", "serial_number", "source", "key", "value"}
+
+// CSVSink write telemetry into daily CSV files <dir>/raw/YYYY-MM-DD.csv, the
+// day is evaluated in UTC
+type CSVSink struct {
+	Dir string
+
+	mu     sync.Mutex
+	day    string
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewCSVSink create new CSV sink writing into the directory
+func NewCSVSink(dir string) (*CSVSink, error) {
+	if err := os.MkdirAll(filepath.Join(dir, csvRawDir), 0755); err != nil {
+		return nil, err
+	}
+	return &CSVSink{Dir: dir}, nil
+}
+
+// rotate open the file of the day, the caller holds the lock
+func (s *CSVSink) rotate(day string) error {
+	if s.day == day && s.file != nil {
+		return nil
+	}
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	name := filepath.Join(s.Dir, csvRawDir, day+".csv")
+	_, statErr := os.Stat(name)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.writer = csv.NewWriter(file)
+	s.day = day
+	if os.IsNotExist(statErr) {
+		return s.writer.Write(csvRawHeader)
+	}
+	return nil
+}
+
+// Write append telemetry values to the file of their day
+func (s *CSVSink) Write(points []*Telemetry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range points {
+		ts := p.Timestamp.UTC()
+		if err := s.rotate(ts.Format(csvDayLayout)); err != nil {
+			return err
+		}
+		err := s.writer.Write([]string{ts.Format(time.RFC3339Nano), p.SerialNumber, p.Source,
+			p.Key, string(FormatTelemetryValue(p))})
+		if err != nil {
+			return err
+		}
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+func (s *CSVSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	s.writer.Flush()
+	err := s.writer.Error()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file = nil
+	s.writer = nil
+	s.day = ""
+	return err
+}
+
+// Close flush and close the current file
+func (s *CSVSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFile()
+}
+
+// parseCSVValue convert CSV text back into telemetry value
+func parseCSVValue(v string) interface{} {
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v
+}
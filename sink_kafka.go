@@ -0,0 +1,63 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import "context"
+
+// KafkaMessage message produced to a Kafka topic
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// KafkaProducer producer interface, adapters for Kafka client libraries
+// need to implement it
+type KafkaProducer interface {
+	Produce(ctx context.Context, messages ...KafkaMessage) error
+}
+
+// KafkaSink publish telemetry to a Kafka topic, by default keyed by serial number
+type KafkaSink struct {
+	producer KafkaProducer
+	Topic    string
+	KeyFunc  func(serialNumber string) []byte
+	options  SinkOptions
+	batch    *batcher
+}
+
+// NewKafkaSink create new Kafka telemetry sink
+func NewKafkaSink(producer KafkaProducer, topic string, options SinkOptions) *KafkaSink {
+	s := &KafkaSink{producer: producer, Topic: topic, options: options,
+		KeyFunc: func(serialNumber string) []byte { return []byte(serialNumber) }}
+	s.batch = newBatcher("kafka", options, s.publish)
+	return s
+}
+
+func (s *KafkaSink) publish(serialNumber string, points []*Telemetry) error {
+	data, err := s.options.encode(points)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(context.Background(),
+		KafkaMessage{Topic: s.Topic, Key: s.KeyFunc(serialNumber), Value: data})
+}
+
+// Write publish telemetry values, batched if configured
+func (s *KafkaSink) Write(points []*Telemetry) error {
+	return s.batch.add(points)
+}
+
+// Close flush pending telemetry values
+func (s *KafkaSink) Close() error {
+	return s.batch.close()
+}
@@ -0,0 +1,53 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+// NatsPublisher publisher interface implemented by *nats.Conn
+type NatsPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NatsSink publish telemetry to NATS subjects <SubjectPrefix>.<serial number>
+type NatsSink struct {
+	conn          NatsPublisher
+	SubjectPrefix string
+	options       SinkOptions
+	batch         *batcher
+}
+
+// NewNatsSink create new NATS telemetry sink
+func NewNatsSink(conn NatsPublisher, subjectPrefix string, options SinkOptions) *NatsSink {
+	if subjectPrefix == "" {
+		subjectPrefix = "ecoflow.telemetry"
+	}
+	s := &NatsSink{conn: conn, SubjectPrefix: subjectPrefix, options: options}
+	s.batch = newBatcher("nats", options, s.publish)
+	return s
+}
+
+func (s *NatsSink) publish(serialNumber string, points []*Telemetry) error {
+	data, err := s.options.encode(points)
+	if err != nil {
+		return err
+	}
+	return s.conn.Publish(s.SubjectPrefix+"."+serialNumber, data)
+}
+
+// Write publish telemetry values, batched if configured
+func (s *NatsSink) Write(points []*Telemetry) error {
+	return s.batch.add(points)
+}
+
+// Close flush pending telemetry values
+func (s *NatsSink) Close() error {
+	return s.batch.close()
+}
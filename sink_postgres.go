@@ -0,0 +1,206 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// PostgresConn connection interface, adapters for Postgres drivers like pgx
+// need to implement it
+type PostgresConn interface {
+	Exec(ctx context.Context, sql string, args ...any) error
+	// QueryStrings return the first column of all result rows
+	QueryStrings(ctx context.Context, sql string, args ...any) ([]string, error)
+	// CopyFrom insert rows using the COPY protocol
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error)
+}
+
+// PostgresSink store telemetry in a wide Postgres table with one column per
+// quota key. New keys add columns, on TimescaleDB the table is a hypertable.
+type PostgresSink struct {
+	conn    PostgresConn
+	Table   string
+	Namer   ColumnNamer
+	Timeout time.Duration
+	batch   *batcher
+
+	mu          sync.Mutex
+	columns     map[string]bool
+	Hypertable  bool
+	initialized bool
+}
+
+// NewPostgresSink create new Postgres telemetry sink, the column names are created
+// with the PostgresColumnNamer
+func NewPostgresSink(conn PostgresConn, table string, options SinkOptions) *PostgresSink {
+	if table == "" {
+		table = "ecoflow_telemetry"
+	}
+	s := &PostgresSink{conn: conn, Table: table, Namer: PostgresColumnNamer,
+		Timeout: 30 * time.Second, columns: make(map[string]bool)}
+	s.batch = newBatcher("postgres", options, s.insert)
+	return s
+}
+
+// pgIdent quote Postgres identifier
+func pgIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// pgType return Postgres column type of the telemetry value
+func pgType(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "DOUBLE PRECISION"
+	case bool:
+		return "BOOLEAN"
+	default:
+		return "TEXT"
+	}
+}
+
+// Init create table and hypertable if not exists and load the existing columns
+func (s *PostgresSink) Init(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.init(ctx)
+}
+
+func (s *PostgresSink) init(ctx context.Context) error {
+	if s.initialized {
+		return nil
+	}
+	err := s.conn.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time TIMESTAMPTZ NOT NULL,
+	serial_number TEXT NOT NULL,
+	source TEXT
+)`, pgIdent(s.Table)))
+	if err != nil {
+		return err
+	}
+	extensions, err := s.conn.QueryStrings(ctx, "SELECT extname FROM pg_extension WHERE extname = 'timescaledb'")
+	if err != nil {
+		return err
+	}
+	if len(extensions) > 0 {
+		err = s.conn.Exec(ctx, "SELECT create_hypertable($1, 'time', if_not_exists => TRUE)", s.Table)
+		if err != nil {
+			return err
+		}
+		s.Hypertable = true
+	}
+	columns, err := s.conn.QueryStrings(ctx,
+		"SELECT column_name FROM information_schema.columns WHERE table_name = $1", s.Table)
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		s.columns[c] = true
+	}
+	s.initialized = true
+	return nil
+}
+
+// migrate add missing columns of the telemetry keys
+func (s *PostgresSink) migrate(ctx context.Context, types map[string]string) error {
+	added := make([]string, 0)
+	for column := range types {
+		if !s.columns[column] {
+			added = append(added, column)
+		}
+	}
+	sort.Strings(added)
+	for _, column := range added {
+		err := s.conn.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s",
+			pgIdent(s.Table), pgIdent(column), types[column]))
+		if err != nil {
+			return err
+		}
+		log.Log.Infof("Added column %s to table %s", column, s.Table)
+		s.columns[column] = true
+	}
+	return nil
+}
+
+func (s *PostgresSink) insert(_ string, points []*Telemetry) error {
+	if len(points) == 0 {
+		return nil
+	}
+	type rowKey struct {
+		sn, source string
+		ts         int64
+	}
+	types := make(map[string]string)
+	rows := make(map[rowKey]map[string]interface{})
+	keys := make([]rowKey, 0)
+	for _, p := range points {
+		column := s.Namer.Column(p.Key)
+		if _, ok := types[column]; !ok {
+			types[column] = pgType(p.Value)
+		}
+		key := rowKey{p.SerialNumber, p.Source, p.Timestamp.UnixNano()}
+		row, ok := rows[key]
+		if !ok {
+			row = map[string]interface{}{"time": p.Timestamp, "serial_number": p.SerialNumber, "source": p.Source}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+		row[column] = p.Value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.init(ctx); err != nil {
+		return err
+	}
+	if err := s.migrate(ctx, types); err != nil {
+		return err
+	}
+
+	columns := []string{"time", "serial_number", "source"}
+	dataColumns := make([]string, 0, len(types))
+	for c := range types {
+		dataColumns = append(dataColumns, c)
+	}
+	sort.Strings(dataColumns)
+	columns = append(columns, dataColumns...)
+	values := make([][]any, 0, len(keys))
+	for _, key := range keys {
+		row := make([]any, len(columns))
+		for i, c := range columns {
+			row[i] = rows[key][c]
+		}
+		values = append(values, row)
+	}
+	_, err := s.conn.CopyFrom(ctx, s.Table, columns, values)
+	return err
+}
+
+// Write store telemetry values, batched if configured
+func (s *PostgresSink) Write(points []*Telemetry) error {
+	return s.batch.add(points)
+}
+
+// Close flush pending telemetry values
+func (s *PostgresSink) Close() error {
+	return s.batch.close()
+}
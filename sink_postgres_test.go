@@ -0,0 +1,76 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPostgres struct {
+	timescale bool
+	exec      []string
+	columns   [][]string
+	rows      [][][]any
+}
+
+func (c *testPostgres) Exec(_ context.Context, sql string, _ ...any) error {
+	c.exec = append(c.exec, sql)
+	return nil
+}
+
+func (c *testPostgres) QueryStrings(_ context.Context, sql string, _ ...any) ([]string, error) {
+	if strings.Contains(sql, "pg_extension") {
+		if c.timescale {
+			return []string{"timescaledb"}, nil
+		}
+		return nil, nil
+	}
+	return []string{"time", "serial_number", "source", "eco_pd_soc"}, nil
+}
+
+func (c *testPostgres) CopyFrom(_ context.Context, _ string, columns []string, rows [][]any) (int64, error) {
+	c.columns = append(c.columns, columns)
+	c.rows = append(c.rows, rows)
+	return int64(len(rows)), nil
+}
+
+func TestPostgresSink(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	conn := &testPostgres{timescale: true}
+	sink := NewPostgresSink(conn, "", SinkOptions{})
+	assert.NoError(t, sink.Write([]*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 80.0, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "inv.acOutVol", Value: 230.0, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "inv.acOut", Value: true, Timestamp: ts},
+	}))
+	assert.True(t, sink.Hypertable)
+	assert.Contains(t, conn.exec[1], "create_hypertable")
+	assert.Len(t, conn.exec, 4)
+	assert.Contains(t, conn.exec[2], `ADD COLUMN IF NOT EXISTS "eco_inv_ac_out" BOOLEAN`)
+	assert.Contains(t, conn.exec[3], `ADD COLUMN IF NOT EXISTS "eco_inv_ac_out_vol" DOUBLE PRECISION`)
+	if assert.Len(t, conn.rows, 1) {
+		assert.Equal(t, []string{"time", "serial_number", "source", "eco_inv_ac_out", "eco_inv_ac_out_vol", "eco_pd_soc"}, conn.columns[0])
+		assert.Equal(t, []any{ts, "R331TEST", SourceMqtt, true, 230.0, 80.0}, conn.rows[0][0])
+	}
+
+	assert.NoError(t, sink.Write([]*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 81.0, Timestamp: ts.Add(time.Second)},
+	}))
+	assert.Len(t, conn.exec, 4)
+	assert.Len(t, conn.rows, 2)
+	assert.NoError(t, sink.Close())
+}
@@ -0,0 +1,121 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RedisClient client interface, adapters for Redis client libraries like go-redis
+// need to implement it
+type RedisClient interface {
+	HSet(ctx context.Context, key string, values map[string]string) error
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Publish(ctx context.Context, channel string, message []byte) error
+}
+
+// RedisSink keep the latest value of each device key in a Redis hash and publish
+// changed values on a pub/sub channel
+type RedisSink struct {
+	client RedisClient
+	// KeyPrefix prefix of the hash keys, the hash of a device is KeyPrefix + serial number
+	KeyPrefix string
+	// ChannelPrefix prefix of the change channels, no notifications if empty
+	ChannelPrefix string
+	// TTL expiry of the device hash refreshed on each write, no expiry if 0
+	TTL     time.Duration
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	last map[string]map[string]string
+}
+
+// NewRedisSink create new Redis latest state sink
+func NewRedisSink(client RedisClient, ttl time.Duration) *RedisSink {
+	return &RedisSink{client: client, KeyPrefix: "ecoflow:state:", ChannelPrefix: "ecoflow:changes:",
+		TTL: ttl, Timeout: 10 * time.Second, last: make(map[string]map[string]string)}
+}
+
+// Write store telemetry values and publish changes
+func (s *RedisSink) Write(points []*Telemetry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	return s.WriteContext(ctx, points)
+}
+
+// WriteContext store telemetry values and publish changes using the message context
+func (s *RedisSink) WriteContext(ctx context.Context, points []*Telemetry) error {
+	var lastErr error
+	for sn, devicePoints := range groupBySerialNumber(points) {
+		if err := s.writeDevice(ctx, sn, devicePoints); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// writeDevice store the values of a device, the cached values are updated after
+// a successful write only so that failed changes are published again
+func (s *RedisSink) writeDevice(ctx context.Context, serialNumber string, points []*Telemetry) error {
+	values := make(map[string]string, len(points))
+	changed := make([]*Telemetry, 0)
+	s.mu.Lock()
+	last := s.last[serialNumber]
+	for _, p := range points {
+		v := fmt.Sprint(p.Value)
+		values[p.Key] = v
+		if old, ok := last[p.Key]; !ok || old != v {
+			changed = append(changed, p)
+		}
+	}
+	s.mu.Unlock()
+
+	key := s.KeyPrefix + serialNumber
+	if err := s.client.HSet(ctx, key, values); err != nil {
+		return err
+	}
+	if s.TTL > 0 {
+		if err := s.client.Expire(ctx, key, s.TTL); err != nil {
+			return err
+		}
+	}
+	if s.ChannelPrefix != "" && len(changed) > 0 {
+		message, err := json.Marshal(changed)
+		if err != nil {
+			return err
+		}
+		if err := s.client.Publish(ctx, s.ChannelPrefix+serialNumber, message); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.last[serialNumber]; !ok {
+		s.last[serialNumber] = make(map[string]string)
+	}
+	for k, v := range values {
+		s.last[serialNumber][k] = v
+	}
+	return nil
+}
+
+// Close drop the cached values, the Redis client is owned by the caller
+func (s *RedisSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = make(map[string]map[string]string)
+	return nil
+}
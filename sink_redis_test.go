@@ -0,0 +1,90 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRedis struct {
+	hashes   map[string]map[string]string
+	ttl      map[string]time.Duration
+	channels []string
+	messages [][]byte
+	err      error
+}
+
+func (r *testRedis) HSet(_ context.Context, key string, values map[string]string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.hashes[key] == nil {
+		r.hashes[key] = make(map[string]string)
+	}
+	for k, v := range values {
+		r.hashes[key][k] = v
+	}
+	return nil
+}
+
+func (r *testRedis) Expire(_ context.Context, key string, ttl time.Duration) error {
+	r.ttl[key] = ttl
+	return nil
+}
+
+func (r *testRedis) Publish(_ context.Context, channel string, message []byte) error {
+	r.channels = append(r.channels, channel)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestRedisSink(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	redis := &testRedis{hashes: make(map[string]map[string]string), ttl: make(map[string]time.Duration)}
+	sink := NewRedisSink(redis, time.Hour)
+	points := []*Telemetry{
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 80.0, Timestamp: ts},
+		{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "inv.acOut", Value: true, Timestamp: ts},
+	}
+	assert.NoError(t, sink.Write(points))
+	assert.Equal(t, map[string]string{"pd.soc": "80", "inv.acOut": "true"}, redis.hashes["ecoflow:state:R331TEST"])
+	assert.Equal(t, time.Hour, redis.ttl["ecoflow:state:R331TEST"])
+	assert.Equal(t, []string{"ecoflow:changes:R331TEST"}, redis.channels)
+
+	assert.NoError(t, sink.Write(points))
+	assert.Len(t, redis.messages, 1)
+
+	assert.NoError(t, sink.Write([]*Telemetry{{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 79.0, Timestamp: ts}}))
+	if assert.Len(t, redis.messages, 2) {
+		var changed []*Telemetry
+		assert.NoError(t, json.Unmarshal(redis.messages[1], &changed))
+		assert.Len(t, changed, 1)
+		assert.Equal(t, 79.0, changed[0].Value)
+	}
+
+	// a failed write is published again
+	redis.err = errors.New("connection lost")
+	soc := []*Telemetry{{SerialNumber: "R331TEST", Source: SourceMqtt, Key: "pd.soc", Value: 78.0, Timestamp: ts}}
+	assert.Error(t, sink.Write(soc))
+	assert.Len(t, redis.messages, 2)
+	redis.err = nil
+	assert.NoError(t, sink.Write(soc))
+	assert.Len(t, redis.messages, 3)
+	assert.Equal(t, "78", redis.hashes["ecoflow:state:R331TEST"]["pd.soc"])
+	assert.NoError(t, sink.Close())
+}
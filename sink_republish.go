@@ -0,0 +1,96 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DefaultTopicTemplate default topic template of republished telemetry
+const DefaultTopicTemplate = "ecoflow/{alias}/{key}"
+
+const republishTimeout = 5 * time.Second
+
+// RepublishSink republish telemetry to a local MQTT broker using clean topics.
+// The topic template supports the placeholders {alias}, {sn}, {source}, {key},
+// {keypath} (key with dots replaced by slashes) and {tenant}/{site} of Tenants.
+type RepublishSink struct {
+	client        mqtt.Client
+	TopicTemplate string
+	QoS           byte
+	Retained      bool
+	FormatValue   func(t *Telemetry) []byte
+}
+
+// NewRepublishSink create new republish sink using a connected MQTT client
+func NewRepublishSink(client mqtt.Client, topicTemplate string, retained bool) *RepublishSink {
+	if topicTemplate == "" {
+		topicTemplate = DefaultTopicTemplate
+	}
+	return &RepublishSink{client: client, TopicTemplate: topicTemplate,
+		Retained: retained, FormatValue: FormatTelemetryValue}
+}
+
+// Topic evaluate topic template for the telemetry value
+func (s *RepublishSink) Topic(t *Telemetry) string {
+	tenant, _ := Tenants.TenantOf(t.SerialNumber)
+	replacer := strings.NewReplacer(
+		"{tenant}", tenant.ID,
+		"{site}", tenant.Site,
+		"{alias}", DeviceAlias(t.SerialNumber),
+		"{sn}", t.SerialNumber,
+		"{source}", t.Source,
+		"{keypath}", strings.ReplaceAll(t.Key, ".", "/"),
+		"{key}", t.Key)
+	return replacer.Replace(s.TopicTemplate)
+}
+
+// FormatTelemetryValue format value as plain text payload
+func FormatTelemetryValue(t *Telemetry) []byte {
+	switch v := t.Value.(type) {
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		return []byte(strconv.FormatBool(v))
+	case string:
+		return []byte(v)
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// Write publish each telemetry value to its own topic
+func (s *RepublishSink) Write(points []*Telemetry) error {
+	var lastErr error
+	for _, p := range points {
+		token := s.client.Publish(s.Topic(p), s.QoS, s.Retained, s.FormatValue(p))
+		if !token.WaitTimeout(republishTimeout) {
+			lastErr = fmt.Errorf("timeout publishing %s", p.Key)
+			continue
+		}
+		if err := token.Error(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Close disconnect from the local broker
+func (s *RepublishSink) Close() error {
+	s.client.Disconnect(250)
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepublishTopic(t *testing.T) {
+	SetDeviceAlias("HW51REPUB", "balcony")
+	defer SetDeviceAlias("HW51REPUB", "")
+	assert.NoError(t, Tenants.SetAssignments(map[string]Tenant{"HW51REPUB": {ID: "acme", Site: "roof"}}))
+	defer Tenants.SetAssignments(nil)
+	point := &Telemetry{SerialNumber: "HW51REPUB", Source: SourceMqtt, Key: "inverterHeartbeat.permanentWatts"}
+	other := &Telemetry{SerialNumber: "HW51OTHER", Source: SourceHttp, Key: "20_1.soc"}
+
+	tests := []struct {
+		template string
+		point    *Telemetry
+		topic    string
+	}{
+		{"", point, "ecoflow/balcony/inverterHeartbeat.permanentWatts"},
+		{"ecoflow/{sn}/{source}/{keypath}", point, "ecoflow/HW51REPUB/mqtt/inverterHeartbeat/permanentWatts"},
+		{"{tenant}/{site}/{alias}/{key}", point, "acme/roof/balcony/inverterHeartbeat.permanentWatts"},
+		{"{tenant}/{site}/{alias}/{key}", other, "unassigned//HW51OTHER/20_1.soc"},
+		{"home/{alias}/{keypath}/state", other, "home/HW51OTHER/20_1/soc/state"},
+		{"static/topic", point, "static/topic"},
+	}
+	for _, test := range tests {
+		sink := NewRepublishSink(nil, test.template, false)
+		assert.Equal(t, test.topic, sink.Topic(test.point), test.template)
+	}
+}
+
+func TestFormatTelemetryValue(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		payload string
+	}{
+		{12.5, "12.5"},
+		{1e6, "1000000"},
+		{true, "true"},
+		{"on", "on"},
+		{int64(7), "7"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.payload, string(FormatTelemetryValue(&Telemetry{Value: test.value})))
+	}
+}
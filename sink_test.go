@@ -0,0 +1,89 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPublisher struct {
+	subjects []string
+	data     [][]byte
+}
+
+func (p *testPublisher) Publish(subject string, data []byte) error {
+	p.subjects = append(p.subjects, subject)
+	p.data = append(p.data, data)
+	return nil
+}
+
+func TestNatsSinkBatch(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	for _, encoding := range []Encoding{EncodingJSON, EncodingProtobuf, EncodingMsgpack} {
+		publisher := &testPublisher{}
+		sink := NewNatsSink(publisher, "", SinkOptions{Encoding: encoding, BatchSize: 2})
+		p1 := &Telemetry{SerialNumber: "HW51TEST", Source: SourceMqtt, Key: "a", Value: 1.0, Timestamp: ts}
+		p2 := &Telemetry{SerialNumber: "HW51TEST", Source: SourceMqtt, Key: "b", Value: "x", Timestamp: ts}
+		assert.NoError(t, sink.Write([]*Telemetry{p1}))
+		assert.Len(t, publisher.data, 0)
+		assert.NoError(t, sink.Write([]*Telemetry{p2}))
+		assert.Len(t, publisher.data, 1)
+		assert.Equal(t, "ecoflow.telemetry.HW51TEST", publisher.subjects[0])
+		points, err := DecodeTelemetry(publisher.data[0], encoding)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Telemetry{p1, p2}, points)
+		assert.NoError(t, sink.Close())
+	}
+}
+
+func TestBatcherDefaultFlushInterval(t *testing.T) {
+	defer func(interval time.Duration) { DefaultFlushInterval = interval }(DefaultFlushInterval)
+	DefaultFlushInterval = 10 * time.Millisecond
+	flushed := make(chan []*Telemetry, 1)
+	b := newBatcher("test", SinkOptions{BatchSize: 3}, func(_ string, points []*Telemetry) error {
+		flushed <- points
+		return nil
+	})
+	defer b.close()
+	p := &Telemetry{SerialNumber: "HW51TEST", Source: SourceMqtt, Key: "a", Value: 1.0}
+	assert.NoError(t, b.add([]*Telemetry{p}))
+	select {
+	case points := <-flushed:
+		assert.Equal(t, []*Telemetry{p}, points)
+	case <-time.After(time.Second):
+		t.Fatal("incomplete batch not flushed")
+	}
+
+	// single values are written directly without flush loop
+	direct := newBatcher("test", SinkOptions{}, func(string, []*Telemetry) error { return nil })
+	assert.Nil(t, direct.done)
+	assert.NoError(t, direct.close())
+}
+
+func TestSinkClose(t *testing.T) {
+	publisher := &testPublisher{}
+	sink := NewNatsSink(publisher, "", SinkOptions{BatchSize: 2, FlushInterval: time.Hour})
+	p := &Telemetry{SerialNumber: "HW51TEST", Source: SourceMqtt, Key: "a", Value: 1.0}
+	assert.NoError(t, sink.Write([]*Telemetry{p}))
+	assert.NoError(t, sink.Close())
+	assert.Len(t, publisher.data, 1)
+	assert.NoError(t, sink.Close())
+	assert.ErrorIs(t, sink.Write([]*Telemetry{p}), ErrSinkClosed)
+	assert.Len(t, publisher.data, 1)
+
+	direct := NewNatsSink(publisher, "", SinkOptions{})
+	assert.NoError(t, direct.Close())
+	assert.ErrorIs(t, direct.Write([]*Telemetry{p}), ErrSinkClosed)
+}
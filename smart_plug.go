@@ -0,0 +1,31 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+const cmdCodePlugSwitch = "WN511_SOCKET_SET_PLUG_SWITCH_MESSAGE"
+
+// SetPlugSwitch switch the Smart Plug on or off
+func (client *Client) SetPlugSwitch(ctx context.Context, serialNumber string, on bool) (*CmdSetResponse, error) {
+	if DetectModel(serialNumber) != ModelSmartPlug {
+		return nil, fmt.Errorf("plug switch not supported for device %s", serialNumber)
+	}
+	return client.SetCommand(ctx, CmdSetRequest{
+		CmdCode: cmdCodePlugSwitch,
+		Sn:      serialNumber,
+		Params:  map[string]interface{}{"plugSwitch": boolToInt(on)},
+	})
+}
@@ -0,0 +1,269 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/log"
+)
+
+// soakStallTimeout time without dispatched telemetry reported as deadlock
+const soakStallTimeout = 10 * time.Second
+
+// chaos random fault injection, the seed is logged to reproduce a run
+type chaos struct {
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	rate float64
+}
+
+func newChaos(t *testing.T, rate float64) *chaos {
+	seed := uint64(time.Now().UnixNano())
+	if v := os.Getenv("ECOFLOW_SOAK_SEED"); v != "" {
+		seed, _ = strconv.ParseUint(v, 10, 64)
+	}
+	t.Logf("Soak chaos seed %d", seed)
+	return &chaos{rnd: rand.New(rand.NewPCG(seed, seed)), rate: rate}
+}
+
+// fail decide if the next operation fails
+func (c *chaos) fail() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rnd.Float64() < c.rate
+}
+
+// delay random delay up to max
+func (c *chaos) delay(max time.Duration) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rnd.Int64N(int64(max)))
+}
+
+// chaosAPI fake REST API backed by the simulator answering with HTTP 500 errors
+type chaosAPI struct {
+	chaos    *chaos
+	sim      *Simulator
+	requests atomic.Int64
+	failures atomic.Int64
+	puts     atomic.Int64
+}
+
+func (a *chaosAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	a.requests.Add(1)
+	if r.Method == http.MethodPut {
+		a.puts.Add(1)
+	}
+	if a.chaos.fail() {
+		a.failures.Add(1)
+		return &http.Response{StatusCode: http.StatusInternalServerError, Status: "500 Internal Server Error",
+			Body: io.NopCloser(bytes.NewReader(nil)), Header: make(http.Header)}, nil
+	}
+	var body interface{} = map[string]interface{}{"code": "0", "message": "Success"}
+	switch r.Method {
+	case http.MethodGet:
+		quota, err := a.sim.ReadQuota(r.Context(), r.URL.Query().Get("sn"))
+		if err != nil {
+			return nil, err
+		}
+		body = map[string]interface{}{"code": "0", "data": quota}
+	case http.MethodPut:
+		var req CmdSetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		if _, err := a.sim.SetParam(r.Context(), req); err != nil {
+			body = map[string]interface{}{"code": "1006", "message": err.Error()}
+		}
+	}
+	data, _ := json.Marshal(body)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data)), Header: make(http.Header)}, nil
+}
+
+// slowSink sink with random write latency
+type slowSink struct {
+	chaos  *chaos
+	points atomic.Int64
+}
+
+func (s *slowSink) Write(points []*Telemetry) error {
+	if s.chaos.fail() {
+		time.Sleep(s.chaos.delay(5 * time.Millisecond))
+	}
+	s.points.Add(int64(len(points)))
+	return nil
+}
+
+func (s *slowSink) Close() error { return nil }
+
+// soakDuration duration of the soak run, ECOFLOW_SOAK=2h runs a long soak,
+// otherwise a short smoke run is done
+func soakDuration(t *testing.T) time.Duration {
+	v := os.Getenv("ECOFLOW_SOAK")
+	if v == "" {
+		return time.Second
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		t.Fatalf("invalid ECOFLOW_SOAK: %v", err)
+	}
+	return d
+}
+
+// TestSoak run the pipeline against the simulator with broker drops, API
+// errors and slow sinks and check that no goroutines leak, the pipeline does
+// not stall and the counters are consistent
+func TestSoak(t *testing.T) {
+	duration := soakDuration(t)
+	// the default logger of the log package is not safe for concurrent use
+	oldLog := log.Log
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	log.Log = logger
+	defer func() { log.Log = oldLog }()
+	baseline := runtime.NumGoroutine()
+	chaos := newChaos(t, 0.1)
+
+	oldBudgets := APIBudgets
+	APIBudgets = NewAPIBudgetTracker(APIQuota{})
+	defer func() { APIBudgets = oldBudgets }()
+
+	devices := []string{"HW51SOAK0001", "HW51SOAK0002", "R331SOAK0003"}
+	sim := NewSimulator()
+	for _, sn := range devices {
+		sim.Add(SimulatedDevice{SerialNumber: sn})
+	}
+	sim.Interval = 5 * time.Millisecond
+	api := &chaosAPI{chaos: chaos, sim: sim}
+	client := newTestClient(t, api)
+
+	var dispatched atomic.Int64
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		dispatched.Add(int64(len(points)))
+	})
+	defer unregister()
+	sink := &slowSink{chaos: chaos}
+	detach := AttachSink(sink)
+	defer detach()
+	var lost, connected atomic.Int64
+	unsubscribe := Events.Subscribe(func(e Event) {
+		switch e.Data.(ConnectionStateChange).State {
+		case ConnectionLost:
+			lost.Add(1)
+		case ConnectionConnected:
+			connected.Add(1)
+		}
+	}, EventConnectionState)
+	defer unsubscribe()
+	stopResumption := NewResumptionTracker(client).Start()
+
+	var attempts atomic.Int64
+	rt := NewRuntime(context.Background())
+	rt.Go(Component{Name: "simulator", Run: func(ctx context.Context) error {
+		sim.Run(ctx)
+		return nil
+	}})
+	poller := NewPoller(client, 10*time.Millisecond, devices...)
+	rt.Go(Component{Name: "poller", Run: poller.Run})
+	rt.Go(Component{Name: "broker", Run: func(ctx context.Context) error {
+		policy := &ReconnectPolicy{InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(20 * time.Millisecond):
+			}
+			if !chaos.fail() {
+				continue
+			}
+			since := time.Now()
+			publishConnectionState(ConnectionStateChange{State: ConnectionLost, Error: "broker dropped", Since: since})
+			err := policy.reconnect(ctx, since, func() error {
+				if chaos.fail() {
+					return errors.New("broker unavailable")
+				}
+				return nil
+			}, nil)
+			if err != nil {
+				return nil
+			}
+			publishConnectionState(ConnectionStateChange{State: ConnectionConnected})
+		}
+	}})
+	rt.Go(Component{Name: "commands", Run: func(ctx context.Context) error {
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(15 * time.Millisecond):
+			}
+			attempts.Add(1)
+			_, _ = client.SetPermanentWatts(ctx, devices[i%2], float64(100+i%300))
+		}
+	}})
+
+	start := time.Now()
+	deadline := start.Add(duration)
+	last, lastProgress := int64(0), time.Now()
+	for time.Now().Before(deadline) {
+		time.Sleep(min(time.Second, time.Until(deadline)+time.Millisecond))
+		if n := dispatched.Load(); n != last {
+			last, lastProgress = n, time.Now()
+		} else if time.Since(lastProgress) > soakStallTimeout {
+			pprof.Lookup("goroutine").WriteTo(os.Stderr, 2)
+			t.Fatalf("telemetry pipeline stalled for %v", soakStallTimeout)
+		}
+	}
+
+	assert.NoError(t, rt.Stop())
+	stopResumption()
+	t.Logf("Soak %v: %d points, %d API requests (%d failed), %d commands, %d broker drops",
+		duration, dispatched.Load(), api.requests.Load(), api.failures.Load(), attempts.Load(), lost.Load())
+
+	assert.Positive(t, dispatched.Load())
+	assert.Equal(t, dispatched.Load(), sink.points.Load(), "sink missed telemetry")
+	assert.Equal(t, lost.Load(), connected.Load(), "broker drops not reconnected")
+	assert.LessOrEqual(t, api.puts.Load(), attempts.Load(), "commands sent more than once")
+	if startOfDay(time.Now()).Equal(startOfDay(start)) {
+		assert.Equal(t, int(api.requests.Load()), APIBudgets.Usage("access").Day, "API budget out of sync")
+	}
+	for _, sn := range devices {
+		_, ok := States.Get(sn, "20_1.invOutputWatts")
+		if DetectModel(sn) != ModelPowerStream {
+			ok = len(States.Snapshot(sn)) > 0
+		}
+		assert.True(t, ok, "no state of %s", sn)
+	}
+	unregister()
+	detach()
+	unsubscribe()
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= baseline+2
+	}, 5*time.Second, 10*time.Millisecond, "goroutines leaked")
+}
@@ -0,0 +1,202 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatRetentionConfig limits of the per topic and per device MQTT statistics.
+// Entries exceeding the limits or being idle longer than IdleTTL are removed,
+// their message counters are kept in the totals.
+type StatRetentionConfig struct {
+	MaxTopics  int
+	MaxDevices int
+	IdleTTL    time.Duration
+	// Interval of the periodic compaction started with RunStatCompaction
+	Interval time.Duration
+}
+
+// StatRetention retention limits used for the MQTT statistics, zero values disable the limit
+var StatRetention = StatRetentionConfig{MaxTopics: 1000, MaxDevices: 1000, IdleTTL: 24 * time.Hour, Interval: 10 * time.Minute}
+
+// StatTotals totals of the MQTT statistics including removed entries
+type StatTotals struct {
+	Topics          int    `json:"topics"`
+	Devices         int    `json:"devices"`
+	Messages        uint64 `json:"messages"`
+	EvictedTopics   uint64 `json:"evictedTopics"`
+	EvictedDevices  uint64 `json:"evictedDevices"`
+	EvictedMessages uint64 `json:"evictedMessages"`
+	// EvictedDeviceMessages messages counted for removed devices
+	EvictedDeviceMessages uint64 `json:"evictedDeviceMessages"`
+}
+
+// topicActivity last counter value of a topic seen by the compaction
+type topicActivity struct {
+	count   uint64
+	changed time.Time
+}
+
+var topicLock sync.Mutex
+var topicSeen = make(map[string]*topicActivity)
+var topicEntries atomic.Int64
+
+var evictedTopics atomic.Uint64
+var evictedTopicMessages atomic.Uint64
+var evictedDevices atomic.Uint64
+var evictedDeviceMessages atomic.Uint64
+
+// CompactStats remove idle and least recently active topic and device statistics
+// exceeding the StatRetention limits
+func CompactStats() {
+	compactTopics()
+	limit := StatRetention.MaxDevices
+	if limit <= 0 {
+		limit = -1
+	}
+	statLock.Lock()
+	compactDevices(limit)
+	statLock.Unlock()
+}
+
+// RunStatCompaction compact the statistics periodically until the context is done
+func RunStatCompaction(ctx context.Context) error {
+	interval := StatRetention.Interval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-CurrentClock().After(interval):
+			CompactStats()
+		}
+	}
+}
+
+// Totals return the totals of the MQTT statistics, messages of removed
+// topics are still part of the message count
+func Totals() StatTotals {
+	t := StatTotals{EvictedTopics: evictedTopics.Load(), EvictedDevices: evictedDevices.Load(),
+		EvictedMessages: evictedTopicMessages.Load(), EvictedDeviceMessages: evictedDeviceMessages.Load()}
+	mqttStatMap.Range(func(_, value any) bool {
+		t.Topics++
+		t.Messages += value.(*atomic.Uint64).Load()
+		return true
+	})
+	t.Messages += t.EvictedMessages
+	statLock.Lock()
+	t.Devices = len(mapStatMqtt)
+	statLock.Unlock()
+	return t
+}
+
+// compactTopics evict topics idle longer than IdleTTL and the least recently
+// active topics exceeding MaxTopics
+func compactTopics() {
+	topicLock.Lock()
+	defer topicLock.Unlock()
+	t := now()
+	type activity struct {
+		topic   string
+		changed time.Time
+	}
+	var active []activity
+	mqttStatMap.Range(func(key, value any) bool {
+		topic := key.(string)
+		count := value.(*atomic.Uint64).Load()
+		seen, ok := topicSeen[topic]
+		if !ok || seen.count != count {
+			seen = &topicActivity{count: count, changed: t}
+			topicSeen[topic] = seen
+		}
+		if StatRetention.IdleTTL > 0 && t.Sub(seen.changed) > StatRetention.IdleTTL {
+			evictTopic(topic)
+			return true
+		}
+		active = append(active, activity{topic: topic, changed: seen.changed})
+		return true
+	})
+	for topic := range topicSeen {
+		if _, ok := mqttStatMap.Load(topic); !ok {
+			delete(topicSeen, topic)
+		}
+	}
+	if StatRetention.MaxTopics <= 0 || len(active) <= StatRetention.MaxTopics {
+		return
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].changed.Before(active[j].changed) })
+	for _, a := range active[:len(active)-StatRetention.MaxTopics] {
+		evictTopic(a.topic)
+	}
+}
+
+// evictTopic remove a topic counter and keep its messages in the totals
+func evictTopic(topic string) {
+	if e, ok := mqttStatMap.LoadAndDelete(topic); ok {
+		evictedTopics.Add(1)
+		evictedTopicMessages.Add(e.(*atomic.Uint64).Load())
+		topicEntries.Add(-1)
+	}
+	delete(topicSeen, topic)
+}
+
+// compactDevices evict devices idle longer than IdleTTL and the devices with the
+// oldest message exceeding the limit, a negative limit keeps all active devices.
+// statLock need to be held by the caller.
+// Devices currently updated are skipped.
+func compactDevices(limit int) {
+	t := now()
+	type activity struct {
+		serialNumber string
+		last         time.Time
+	}
+	var active []activity
+	for sn, stat := range mapStatMqtt {
+		if !stat.mu.TryLock() {
+			continue
+		}
+		last := stat.lastReceived
+		if StatRetention.IdleTTL > 0 && !last.IsZero() && t.Sub(last) > StatRetention.IdleTTL {
+			evictDevice(sn, stat)
+			stat.mu.Unlock()
+			continue
+		}
+		stat.mu.Unlock()
+		active = append(active, activity{serialNumber: sn, last: last})
+	}
+	if limit < 0 || len(active) <= limit {
+		return
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].last.Before(active[j].last) })
+	for _, a := range active[:len(active)-limit] {
+		stat := mapStatMqtt[a.serialNumber]
+		if stat.mu.TryLock() {
+			evictDevice(a.serialNumber, stat)
+			stat.mu.Unlock()
+		}
+	}
+}
+
+// evictDevice remove the statistic of a device and keep its messages in the totals,
+// the statistic need to be locked by the caller
+func evictDevice(serialNumber string, stat *statMqtt) {
+	delete(mapStatMqtt, serialNumber)
+	evictedDevices.Add(1)
+	evictedDeviceMessages.Add(stat.mqttCounter)
+}
@@ -0,0 +1,99 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetStatRetention(t *testing.T, cfg StatRetentionConfig) *FakeClock {
+	old := StatRetention
+	StatRetention = cfg
+	clock := NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	t.Cleanup(func() {
+		StatRetention = old
+		SetClock(nil)
+	})
+	return clock
+}
+
+func TestStatRetentionTopics(t *testing.T) {
+	clock := resetStatRetention(t, StatRetentionConfig{MaxTopics: 3, IdleTTL: time.Hour})
+	before := Totals()
+	for i := 0; i < 5; i++ {
+		countTopic(fmt.Sprintf("/retention/topic/T%d", i))
+		clock.Advance(time.Minute)
+	}
+	countTopic("/retention/topic/T4")
+	_, ok := mqttStatMap.Load("/retention/topic/T4")
+	assert.True(t, ok)
+	after := Totals()
+	assert.Equal(t, before.Messages+6, after.Messages)
+	assert.True(t, after.EvictedTopics > before.EvictedTopics)
+	assert.True(t, after.Topics <= 3)
+
+	clock.Advance(2 * time.Hour)
+	CompactStats()
+	clock.Advance(2 * time.Hour)
+	CompactStats()
+	_, ok = mqttStatMap.Load("/retention/topic/T4")
+	assert.False(t, ok)
+	assert.Equal(t, before.Messages+6, Totals().Messages)
+}
+
+func TestStatRetentionDevices(t *testing.T) {
+	clock := resetStatRetention(t, StatRetentionConfig{MaxDevices: 1000, IdleTTL: time.Hour})
+	for i := 0; i < 3; i++ {
+		sn := fmt.Sprintf("RETENTION%d", i)
+		stat := GetStatEntry(sn)
+		stat.mu.Lock()
+		stat.mqttCounter += 2
+		stat.recordHeartbeat(time.Minute, now())
+		stat.mu.Unlock()
+	}
+	before := Totals()
+	clock.Advance(30 * time.Minute)
+	stat := GetStatEntry("RETENTION0")
+	stat.mu.Lock()
+	stat.recordHeartbeat(time.Minute, now())
+	stat.mu.Unlock()
+	clock.Advance(45 * time.Minute)
+	CompactStats()
+	_, ok := HeartbeatStats("RETENTION0")
+	assert.True(t, ok)
+	_, ok = HeartbeatStats("RETENTION1")
+	assert.False(t, ok)
+	after := Totals()
+	assert.Equal(t, before.EvictedDevices+2, after.EvictedDevices)
+	assert.Equal(t, before.EvictedDeviceMessages+4, after.EvictedDeviceMessages)
+}
+
+func TestStatRetentionMaxDevices(t *testing.T) {
+	clock := resetStatRetention(t, StatRetentionConfig{})
+	CompactStats()
+	StatRetention.MaxDevices = len(mapStatMqtt) + 2
+	for i := 0; i < 4; i++ {
+		stat := GetStatEntry(fmt.Sprintf("RETMAX%d", i))
+		stat.mu.Lock()
+		stat.recordHeartbeat(time.Minute, now())
+		stat.mu.Unlock()
+		clock.Advance(time.Minute)
+	}
+	_, ok := HeartbeatStats("RETMAX3")
+	assert.True(t, ok)
+	assert.True(t, Totals().Devices <= StatRetention.MaxDevices)
+}
@@ -0,0 +1,121 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StateValue latest value of a device key
+type StateValue struct {
+	Value     interface{} `json:"value"`
+	Source    string      `json:"source"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// StateStore latest state of all keys of all devices
+type StateStore struct {
+	mu      sync.RWMutex
+	devices map[string]map[string]StateValue
+	changed chan struct{}
+}
+
+// States state store fed by all dispatched telemetry
+var States = NewStateStore()
+
+func init() {
+	RegisterTelemetryHandler(States.Update)
+}
+
+// NewStateStore create new empty state store
+func NewStateStore() *StateStore {
+	return &StateStore{devices: make(map[string]map[string]StateValue), changed: make(chan struct{})}
+}
+
+// Update store telemetry values as latest state, older values are ignored
+func (s *StateStore) Update(points []*Telemetry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range points {
+		sn := strings.ToUpper(p.SerialNumber)
+		values, ok := s.devices[sn]
+		if !ok {
+			values = make(map[string]StateValue)
+			s.devices[sn] = values
+		}
+		if old, ok := values[p.Key]; ok && old.Timestamp.After(p.Timestamp) {
+			continue
+		}
+		values[p.Key] = StateValue{Value: p.Value, Source: p.Source, Timestamp: p.Timestamp}
+	}
+	// wake up all waiting routines
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// WaitFor wait until one of the keys of the device matches, e.g. for command
+// echo verification
+func (s *StateStore) WaitFor(ctx context.Context, serialNumber string, keys []string, match func(StateValue) bool) (StateValue, error) {
+	sn := strings.ToUpper(serialNumber)
+	for {
+		s.mu.RLock()
+		changed := s.changed
+		for _, k := range keys {
+			if v, ok := s.devices[sn][k]; ok && match(v) {
+				s.mu.RUnlock()
+				return v, nil
+			}
+		}
+		s.mu.RUnlock()
+		select {
+		case <-ctx.Done():
+			return StateValue{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Get return latest value of a device key
+func (s *StateStore) Get(serialNumber, key string) (StateValue, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.devices[strings.ToUpper(serialNumber)][key]
+	return v, ok
+}
+
+// Snapshot return copy of the latest state of a device
+func (s *StateStore) Snapshot(serialNumber string) map[string]StateValue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := s.devices[strings.ToUpper(serialNumber)]
+	snapshot := make(map[string]StateValue, len(values))
+	for k, v := range values {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// Devices return serial numbers of all devices with state
+func (s *StateStore) Devices() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	serialNumbers := make([]string, 0, len(s.devices))
+	for sn := range s.devices {
+		serialNumbers = append(serialNumbers, sn)
+	}
+	sort.Strings(serialNumbers)
+	return serialNumbers
+}
@@ -0,0 +1,180 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// statusShutdownTimeout time given to running requests if the context of Serve is done
+const statusShutdownTimeout = 5 * time.Second
+
+// StatusServer embedded HTTP server providing health, statistics, state and debug information
+type StatusServer struct {
+	server *http.Server
+	States *StateStore
+	// Auth token authorization, health is always accessible
+	Auth *TokenAuth
+	mux  *http.ServeMux
+	sse  *TelemetrySSE
+	done <-chan error
+}
+
+// NewStatusServer create new status server listening on the given address
+func NewStatusServer(address string) *StatusServer {
+	s := &StatusServer{States: States, Auth: GatewayTokens, mux: http.NewServeMux(),
+		sse: NewTelemetrySSE(defaultSSEHistory)}
+	s.mux.HandleFunc("GET /healthz", s.healthz)
+	s.handle("GET /stats", ScopeTelemetryRead, http.HandlerFunc(s.stats))
+	s.handle("GET /state/{sn}", ScopeTelemetryRead, http.HandlerFunc(s.state))
+	s.handle("GET /debug/payloads", ScopeTelemetryRead, http.HandlerFunc(s.payloads))
+	s.handle("GET /diagnostics", ScopeTelemetryRead, http.HandlerFunc(s.diagnostics))
+	s.handle("GET /debug/vars", ScopeTelemetryRead, expvar.Handler())
+	s.handle("GET /events", ScopeTelemetryRead, s.sse)
+	// streaming requests are cancelled on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	s.server = &http.Server{Addr: address, Handler: s.mux, ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context { return ctx }}
+	s.server.RegisterOnShutdown(cancel)
+	return s
+}
+
+// handle register handler requiring the scope of the current Auth
+func (s *StatusServer) handle(pattern string, scope Scope, handler http.Handler) {
+	s.mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := s.Auth
+		if auth == nil {
+			auth = GatewayTokens
+		}
+		auth.Require(scope, handler).ServeHTTP(w, r)
+	}))
+}
+
+// EnableControl register the device control endpoint using the client, it
+// needs a token with the device:control scope
+func (s *StatusServer) EnableControl(client *Client) {
+	s.handle("POST /devices/{sn}/settings/{name}", ScopeDeviceControl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Value *float64 `json:"value"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Value == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body needs numeric value"})
+			return
+		}
+		sn := ResolveDevice(r.PathValue("sn"))
+		resp, err := client.ApplySetting(r.Context(), sn, r.PathValue("name"), *req.Value)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+}
+
+// Handler return HTTP handler of all status endpoints
+func (s *StatusServer) Handler() http.Handler {
+	return s.mux
+}
+
+// Start start listening as component of the background runtime, the server is
+// stopped using Shutdown which also returns the error of the server. Use Serve
+// to run the server as component of an own runtime.
+func (s *StatusServer) Start() {
+	s.done = backgroundRuntime().GoContext(context.Background(), Component{Name: "status server",
+		Run: s.Serve, Optional: true})
+}
+
+// Serve listen until the server is shut down or the context is done, used as
+// runtime component
+func (s *StatusServer) Serve(ctx context.Context) error {
+	services.ServerMessage("Ecoflow: status server listening on %s", s.server.Addr)
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), statusShutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			log.Log.Errorf("Status server shutdown error: %v", err)
+		}
+	})
+	defer stop()
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stop status server, if started using Start the error of the server
+// is returned too
+func (s *StatusServer) Shutdown(ctx context.Context) error {
+	s.sse.Close()
+	err := s.server.Shutdown(ctx)
+	if s.done == nil {
+		return err
+	}
+	defer func() { s.done = nil }()
+	select {
+	case serveErr := <-s.done:
+		return errors.Join(err, serveErr)
+	case <-ctx.Done():
+		return errors.Join(err, ctx.Err())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Log.Errorf("Error writing status response: %v", err)
+	}
+}
+
+func (s *StatusServer) healthz(w http.ResponseWriter, _ *http.Request) {
+	connected := ecoclient != nil && ecoclient.Client.IsConnected()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "mqttConnected": connected})
+}
+
+func (s *StatusServer) diagnostics(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, Diagnostics())
+}
+
+func (s *StatusServer) stats(w http.ResponseWriter, _ *http.Request) {
+	topics := make(map[string]uint64)
+	mqttStatMap.Range(func(key, value any) bool {
+		topics[key.(string)] = value.(*atomic.Uint64).Load()
+		return true
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"devices": Stats(), "topics": topics, "totals": Totals()})
+}
+
+func (s *StatusServer) state(w http.ResponseWriter, r *http.Request) {
+	sn := ResolveDevice(r.PathValue("sn"))
+	snapshot := s.States.Snapshot(sn)
+	if len(snapshot) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no state for device " + sn})
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+func (s *StatusServer) payloads(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, RecentUndecodedPayloads())
+}
@@ -0,0 +1,85 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusServerServeContext(t *testing.T) {
+	s := NewStatusServer("127.0.0.1:0")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("status server not stopped by the context")
+	}
+}
+
+func TestStatusServerEndpoints(t *testing.T) {
+	s := NewStatusServer("")
+	s.Auth = NewTokenAuth()
+	s.States = NewStateStore()
+	s.States.Update([]*Telemetry{{SerialNumber: "HW51STATUS", Source: SourceMqtt, Key: "soc", Value: 80.0, Timestamp: time.Now()}})
+	SetDeviceAlias("HW51STATUS", "status")
+	defer SetDeviceAlias("HW51STATUS", "")
+	recordUndecoded("lan", "HW51STATUS", "test", []byte{1, 2, 3})
+
+	get := func(path string, v interface{}) int {
+		recorder := httptest.NewRecorder()
+		s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), path)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), v), path)
+		return recorder.Code
+	}
+
+	health := map[string]interface{}{}
+	assert.Equal(t, http.StatusOK, get("/healthz", &health))
+	assert.Equal(t, "ok", health["status"])
+	assert.Contains(t, health, "mqttConnected")
+
+	stats := map[string]json.RawMessage{}
+	assert.Equal(t, http.StatusOK, get("/stats", &stats))
+	assert.Contains(t, stats, "devices")
+	assert.Contains(t, stats, "topics")
+	assert.Contains(t, stats, "totals")
+
+	for _, sn := range []string{"HW51STATUS", "hw51status", "status"} {
+		state := map[string]StateValue{}
+		assert.Equal(t, http.StatusOK, get("/state/"+sn, &state), sn)
+		assert.Equal(t, 80.0, state["soc"].Value, sn)
+		assert.Equal(t, SourceMqtt, state["soc"].Source, sn)
+	}
+	missing := map[string]string{}
+	assert.Equal(t, http.StatusNotFound, get("/state/HW51UNKNOWN", &missing))
+	assert.Equal(t, "no state for device HW51UNKNOWN", missing["error"])
+
+	var payloads []UndecodedPayload
+	assert.Equal(t, http.StatusOK, get("/debug/payloads", &payloads))
+	if assert.NotEmpty(t, payloads) {
+		last := payloads[len(payloads)-1]
+		assert.Equal(t, "HW51STATUS", last.SerialNumber)
+		assert.Equal(t, "AQID", last.Payload)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// storageMagic prefix of files encrypted by the storage encryption
+var storageMagic = []byte("EFENC1\n")
+
+// ErrEncryptedStorage file is encrypted but no storage encryption is configured
+var ErrEncryptedStorage = errors.New("file is encrypted, storage key not configured")
+
+// ErrStorageKey configured storage key is invalid, files are not written
+// unencrypted instead
+var ErrStorageKey = errors.New("invalid storage key")
+
+// Encryptor encryption of stored files, AES-GCM is built in, other
+// implementations like age can be set with SetStorageEncryption
+type Encryptor interface {
+	Encrypt(plain []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// AESGCM AES-GCM encryption with a random nonce stored in front of the cipher text
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM create AES-GCM encryption, the key needs 16, 24 or 32 bytes
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+// Encrypt encrypt data
+func (a *AESGCM) Encrypt(plain []byte) ([]byte, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return a.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Decrypt decrypt and verify data
+func (a *AESGCM) Decrypt(data []byte) ([]byte, error) {
+	size := a.aead.NonceSize()
+	if len(data) < size {
+		return nil, errors.New("encrypted data too short")
+	}
+	return a.aead.Open(nil, data[:size], data[size:], nil)
+}
+
+// ParseStorageKey parse hexadecimal or base64 encoded key of 16, 24 or 32 bytes.
+// The encoding can be given explicitly using the prefix hex: or base64:, keys
+// valid in both encodings need the prefix.
+func ParseStorageKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "hex:"):
+		return validStorageKey(hex.DecodeString(strings.TrimPrefix(s, "hex:")))
+	case strings.HasPrefix(s, "base64:"):
+		return validStorageKey(base64.StdEncoding.DecodeString(strings.TrimPrefix(s, "base64:")))
+	}
+	hexKey, hexErr := validStorageKey(hex.DecodeString(s))
+	base64Key, base64Err := validStorageKey(base64.StdEncoding.DecodeString(s))
+	switch {
+	case hexErr == nil && base64Err == nil:
+		return nil, fmt.Errorf("%w: key is hexadecimal and base64, use prefix hex: or base64:", ErrStorageKey)
+	case hexErr == nil:
+		return hexKey, nil
+	case base64Err == nil:
+		return base64Key, nil
+	}
+	return nil, fmt.Errorf("%w: neither hexadecimal nor base64 encoded key of 16, 24 or 32 bytes", ErrStorageKey)
+}
+
+// validStorageKey check the length of the decoded key
+func validStorageKey(key []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageKey, err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: key length %d, need 16, 24 or 32 bytes", ErrStorageKey, len(key))
+}
+
+// StorageKeyFromEnv read the storage key out of ECOFLOW_STORAGE_KEY or the file
+// ECOFLOW_STORAGE_KEY_FILE, e.g. a systemd credential or a file provided by a
+// keyring helper. Nil is returned if none is set.
+func StorageKeyFromEnv() ([]byte, error) {
+	if s := os.Getenv("ECOFLOW_STORAGE_KEY"); s != "" {
+		return ParseStorageKey(s)
+	}
+	if name := os.Getenv("ECOFLOW_STORAGE_KEY_FILE"); name != "" {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return ParseStorageKey(string(data))
+	}
+	return nil, nil
+}
+
+var storageLock sync.RWMutex
+var storageEncryption Encryptor
+
+// storageKeyErr error of the storage key of the environment, writes are
+// refused until a valid encryption is set
+var storageKeyErr error
+
+func init() {
+	key, err := StorageKeyFromEnv()
+	if err == nil && key != nil {
+		storageEncryption, err = NewAESGCM(key)
+	}
+	if err != nil {
+		storageKeyErr = fmt.Errorf("storage encryption of ECOFLOW_STORAGE_KEY: %w", err)
+	}
+}
+
+// SetStorageEncryption encrypt credential cache, captures and state files,
+// nil stores them unencrypted. If the storage key of the environment is
+// invalid, the error is returned and only a valid encryption is accepted.
+func SetStorageEncryption(e Encryptor) error {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+	if e == nil && storageKeyErr != nil {
+		return storageKeyErr
+	}
+	storageEncryption = e
+	storageKeyErr = nil
+	return nil
+}
+
+// StorageEncryption return the active storage encryption, nil if disabled
+func StorageEncryption() Encryptor {
+	storageLock.RLock()
+	defer storageLock.RUnlock()
+	return storageEncryption
+}
+
+// StorageKeyError return the error of an invalid storage key of the environment
+func StorageKeyError() error {
+	storageLock.RLock()
+	defer storageLock.RUnlock()
+	return storageKeyErr
+}
+
+// writeStoredFile write file atomically, encrypted if storage encryption is active
+func writeStoredFile(fileName string, data []byte) error {
+	if err := StorageKeyError(); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
+	}
+	if e := StorageEncryption(); e != nil {
+		encrypted, err := e.Encrypt(data)
+		if err != nil {
+			return fmt.Errorf("encrypt %s: %w", fileName, err)
+		}
+		data = append(append([]byte(nil), storageMagic...), encrypted...)
+	}
+	tmpName := fileName + ".tmp"
+	if err := os.WriteFile(tmpName, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, fileName)
+}
+
+// readStoredFile read file written by writeStoredFile, unencrypted files are
+// returned unchanged so existing files can be migrated
+func readStoredFile(fileName string) ([]byte, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil || !bytes.HasPrefix(data, storageMagic) {
+		return data, err
+	}
+	e := StorageEncryption()
+	if e == nil {
+		return nil, fmt.Errorf("%s: %w", fileName, ErrEncryptedStorage)
+	}
+	plain, err := e.Decrypt(data[len(storageMagic):])
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", fileName, err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,145 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStorageKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestStorageEncryption(t *testing.T) {
+	defer SetStorageEncryption(StorageEncryption())
+	dir := t.TempDir()
+	account := &Account{AccessKey: "access", SecretKey: "secret", Email: "user@example.com", Password: "pw"}
+
+	SetStorageEncryption(nil)
+	assert.ErrorIs(t, account.SaveCredentials(filepath.Join(dir, "account")), ErrUnencryptedCredentials)
+	// unencrypted files are read unchanged
+	plainName := filepath.Join(dir, "plain.json")
+	assert.NoError(t, writeStoredFile(plainName, []byte("{}")))
+	data, err := readStoredFile(plainName)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+
+	key, err := ParseStorageKey(testStorageKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	encryption, err := NewAESGCM(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	SetStorageEncryption(encryption)
+	name := filepath.Join(dir, "account")
+	if !assert.NoError(t, account.SaveCredentials(name)) {
+		return
+	}
+	raw, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(raw, storageMagic))
+	assert.False(t, bytes.Contains(raw, []byte("secret")))
+	loaded, err := LoadAccount(name)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "secret", loaded.SecretKey)
+		assert.Equal(t, "user@example.com", loaded.Email)
+	}
+	data, err = readStoredFile(plainName)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+
+	// tampered file
+	raw[len(raw)-1] ^= 0xff
+	assert.NoError(t, os.WriteFile(name, raw, 0600))
+	_, err = LoadAccount(name)
+	assert.ErrorContains(t, err, "decrypt")
+
+	SetStorageEncryption(nil)
+	_, err = LoadAccount(filepath.Join(dir, "account"))
+	assert.ErrorIs(t, err, ErrEncryptedStorage)
+}
+
+func TestEncryptedCapture(t *testing.T) {
+	defer SetStorageEncryption(StorageEncryption())
+	key, _ := ParseStorageKey("AAECAwQFBgcICQoLDA0ODw==")
+	encryption, err := NewAESGCM(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	SetStorageEncryption(encryption)
+	recordUndecoded("", "HW51CAPTURE", "test", []byte{1, 2, 3})
+	name := filepath.Join(t.TempDir(), "capture.json")
+	assert.NoError(t, SaveUndecodedPayloads(name))
+	payloads, err := LoadUndecodedPayloads(name)
+	if assert.NoError(t, err) && assert.NotEmpty(t, payloads) {
+		assert.Equal(t, "HW51CAPTURE", payloads[len(payloads)-1].SerialNumber)
+	}
+	_, err = ParseStorageKey("not a key!")
+	assert.Error(t, err)
+}
+
+func TestParseStorageKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		length int
+	}{
+		{testStorageKey, 32},
+		{"hex:" + testStorageKey, 32},
+		{"AAECAwQFBgcICQoLDA0ODw==", 16},
+		{"base64:AAECAwQFBgcICQoLDA0ODw==", 16},
+		// 32 characters are valid hexadecimal and base64 keys
+		{"000102030405060708090a0b0c0d0e0f", 0},
+		{"hex:000102030405060708090a0b0c0d0e0f", 16},
+		{"base64:000102030405060708090a0b0c0d0e0f", 24},
+		{"0001020304", 0},
+		{"hex:AAECAwQFBgcICQoLDA0ODw==", 0},
+		{"not a key!", 0},
+	}
+	for _, test := range tests {
+		key, err := ParseStorageKey(test.key)
+		if test.length == 0 {
+			assert.ErrorIs(t, err, ErrStorageKey, test.key)
+			continue
+		}
+		if assert.NoError(t, err, test.key) {
+			assert.Len(t, key, test.length, test.key)
+		}
+	}
+}
+
+func TestStorageKeyErrorRefusesWrites(t *testing.T) {
+	defer func(e Encryptor, err error) {
+		storageEncryption, storageKeyErr = e, err
+	}(StorageEncryption(), StorageKeyError())
+	storageEncryption, storageKeyErr = nil, ErrStorageKey
+	dir := t.TempDir()
+
+	name := filepath.Join(dir, "state.json")
+	assert.ErrorIs(t, writeStoredFile(name, []byte("{}")), ErrStorageKey)
+	assert.NoFileExists(t, name)
+	account := &Account{AccessKey: "access", SecretKey: "secret"}
+	assert.ErrorIs(t, account.SaveCredentials(filepath.Join(dir, "account")), ErrStorageKey)
+	assert.ErrorIs(t, SetStorageEncryption(nil), ErrStorageKey)
+
+	key, _ := ParseStorageKey(testStorageKey)
+	encryption, err := NewAESGCM(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, SetStorageEncryption(encryption))
+	assert.NoError(t, writeStoredFile(name, []byte("{}")))
+}
@@ -0,0 +1,207 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// Grid events published by the StormGuard, Data contains GridEvent
+const (
+	EventGridDown     EventType = "grid.down"
+	EventGridRestored EventType = "grid.restored"
+)
+
+// emergencyTimeout maximum time of the emergency profile commands
+const emergencyTimeout = time.Minute
+
+// GridIndicator telemetry keys matching the case insensitive glob pattern
+// signal a grid loss if the value is below the threshold
+type GridIndicator struct {
+	Pattern string
+	Below   float64
+}
+
+// DefaultGridIndicators AC input voltage of Delta stations (reported in mV) and
+// grid state of the Smart Home Panel
+var DefaultGridIndicators = []GridIndicator{
+	{Pattern: "inv.acinvol", Below: 80000},
+	{Pattern: "*gridsta", Below: 0.5},
+}
+
+// GridEvent grid loss or restoration of a device
+type GridEvent struct {
+	SerialNumber string  `json:"serialNumber"`
+	Key          string  `json:"key"`
+	Value        float64 `json:"value"`
+	// Outage duration of the outage, set on restoration
+	Outage time.Duration `json:"outage,omitempty"`
+	Time   time.Time     `json:"time"`
+}
+
+// EmergencyProfile actions executed while the grid is down: non-critical
+// plugs are switched off and the charge limit of the stations is raised to
+// keep the battery reserve. Restoring the grid switches the plugs on again.
+type EmergencyProfile struct {
+	// ShedPlugs Smart Plugs switched off, serial numbers, aliases or groups
+	ShedPlugs []string `json:"shedPlugs,omitempty"`
+	// Stations Delta/River stations the charge limit is set for
+	Stations []string `json:"stations,omitempty"`
+	// ChargeLimit charge limit in percent while the grid is down, unchanged if 0
+	ChargeLimit int `json:"chargeLimit,omitempty"`
+}
+
+// StormGuard detect grid loss using the grid indicators of the telemetry and
+// publish EventGridDown and EventGridRestored. Scenes can be bound to these
+// events using SceneManager.ActivateOn.
+type StormGuard struct {
+	Indicators []GridIndicator
+	// Client and Profile optional emergency profile executed on grid events
+	Client  *Client
+	Profile *EmergencyProfile
+	mu      sync.Mutex
+	down    map[string]time.Time
+	running sync.WaitGroup
+}
+
+// NewStormGuard create storm guard using the default indicators
+func NewStormGuard() *StormGuard {
+	return &StormGuard{Indicators: DefaultGridIndicators, down: make(map[string]time.Time)}
+}
+
+// GridDown check if the grid of the device is down
+func (g *StormGuard) GridDown(serialNumber string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.down[strings.ToUpper(serialNumber)]
+	return ok
+}
+
+// Start register the telemetry handler, the returned function unregisters the
+// handler and waits for running emergency profiles
+func (g *StormGuard) Start() func() {
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) { g.Handle(points) })
+	return func() {
+		unregister()
+		g.running.Wait()
+	}
+}
+
+func (g *StormGuard) indicator(key string) (GridIndicator, bool) {
+	key = strings.ToLower(key)
+	for _, i := range g.Indicators {
+		if ok, _ := path.Match(strings.ToLower(i.Pattern), key); ok {
+			return i, true
+		}
+	}
+	return GridIndicator{}, false
+}
+
+// Handle check the grid indicators and publish the grid events of changed devices
+func (g *StormGuard) Handle(points []*Telemetry) []GridEvent {
+	var events []GridEvent
+	var types []EventType
+	g.mu.Lock()
+	for _, p := range points {
+		i, ok := g.indicator(p.Key)
+		if !ok {
+			continue
+		}
+		value, ok := p.Value.(float64)
+		if !ok {
+			continue
+		}
+		sn := strings.ToUpper(p.SerialNumber)
+		timestamp := p.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now()
+		}
+		since, down := g.down[sn]
+		e := GridEvent{SerialNumber: sn, Key: p.Key, Value: value, Time: timestamp}
+		switch {
+		case value < i.Below && !down:
+			g.down[sn] = timestamp
+			events = append(events, e)
+			types = append(types, EventGridDown)
+		case value >= i.Below && down:
+			delete(g.down, sn)
+			e.Outage = timestamp.Sub(since)
+			events = append(events, e)
+			types = append(types, EventGridRestored)
+		}
+	}
+	g.mu.Unlock()
+	for n, e := range events {
+		if types[n] == EventGridDown {
+			services.ServerMessage("Grid down at %s (%s=%v)", DeviceAlias(e.SerialNumber), e.Key, e.Value)
+		} else {
+			services.ServerMessage("Grid restored at %s after %v", DeviceAlias(e.SerialNumber), e.Outage.Round(time.Second))
+		}
+		Events.Publish(Event{Type: types[n], SerialNumber: e.SerialNumber, Time: e.Time, Data: e})
+		g.emergency(types[n] == EventGridDown)
+	}
+	return events
+}
+
+// emergency execute the emergency profile in background
+func (g *StormGuard) emergency(down bool) {
+	if g.Client == nil || g.Profile == nil {
+		return
+	}
+	g.running.Add(1)
+	backgroundRuntime().GoContext(context.Background(), Component{Name: "emergency profile", Optional: true,
+		Run: func(ctx context.Context) error {
+			defer g.running.Done()
+			ctx, cancel := context.WithTimeout(ctx, emergencyTimeout)
+			defer cancel()
+			return g.Profile.Execute(ctx, g.Client, down)
+		}})
+}
+
+// Execute apply the profile for grid down or switch the plugs on again on restoration
+func (p *EmergencyProfile) Execute(ctx context.Context, client *Client, down bool) error {
+	var errs []error
+	for _, plug := range expandDevices(p.ShedPlugs) {
+		if _, err := client.SetPlugSwitch(ctx, plug, !down); err != nil {
+			errs = append(errs, fmt.Errorf("plug %s: %w", DeviceAlias(plug), err))
+		}
+	}
+	if down && p.ChargeLimit > 0 {
+		for _, station := range expandDevices(p.Stations) {
+			if _, err := client.SetChargeLimit(ctx, station, p.ChargeLimit); err != nil {
+				errs = append(errs, fmt.Errorf("station %s: %w", DeviceAlias(station), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expandDevices resolve groups and aliases into serial numbers
+func expandDevices(names []string) []string {
+	devices := make([]string, 0, len(names))
+	for _, name := range names {
+		if members, ok := DeviceGroup(name); ok {
+			devices = append(devices, members...)
+			continue
+		}
+		devices = append(devices, ResolveDevice(name))
+	}
+	return devices
+}
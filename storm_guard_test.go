@@ -0,0 +1,62 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStormGuard(t *testing.T) {
+	record := &recordTransport{}
+	client := NewClient("access", "secret")
+	client.SetRouter(NewRouter(record))
+	guard := NewStormGuard()
+	guard.Client = client
+	guard.Profile = &EmergencyProfile{ShedPlugs: []string{"HW52STORM"}, Stations: []string{"R331STORM"}, ChargeLimit: 100}
+
+	var events []Event
+	unsubscribe := Events.Subscribe(func(e Event) { events = append(events, e) }, EventGridDown, EventGridRestored)
+	defer unsubscribe()
+
+	start := time.Date(2025, 1, 10, 20, 0, 0, 0, time.UTC)
+	assert.Empty(t, guard.Handle([]*Telemetry{{SerialNumber: "R331STORM", Key: "inv.acInVol", Value: 231000.0, Timestamp: start}}))
+	down := guard.Handle([]*Telemetry{{SerialNumber: "R331STORM", Key: "inv.acInVol", Value: 0.0, Timestamp: start.Add(time.Second)}})
+	assert.Len(t, down, 1)
+	assert.True(t, guard.GridDown("r331storm"))
+	assert.Empty(t, guard.Handle([]*Telemetry{{SerialNumber: "R331STORM", Key: "inv.acInVol", Value: 0.0, Timestamp: start.Add(2 * time.Second)}}))
+	guard.running.Wait()
+	if assert.Len(t, record.requests, 2) {
+		assert.Equal(t, 0, record.requests[0].Params["plugSwitch"])
+		assert.Equal(t, 100, record.requests[1].Params["maxChgSoc"])
+	}
+
+	restored := guard.Handle([]*Telemetry{{SerialNumber: "R331STORM", Key: "inv.acInVol", Value: 229000.0, Timestamp: start.Add(time.Hour + time.Second)}})
+	if assert.Len(t, restored, 1) {
+		assert.Equal(t, time.Hour, restored[0].Outage)
+	}
+	guard.running.Wait()
+	if assert.Len(t, record.requests, 3) {
+		assert.Equal(t, 1, record.requests[2].Params["plugSwitch"])
+	}
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, EventGridDown, events[0].Type)
+		assert.Equal(t, EventGridRestored, events[1].Type)
+	}
+
+	// Smart Home Panel grid state
+	assert.Len(t, guard.Handle([]*Telemetry{{SerialNumber: "SP10STORM", Key: "heartbeat.gridSta", Value: 0.0}}), 1)
+	assert.True(t, guard.GridDown("SP10STORM"))
+	guard.running.Wait()
+}
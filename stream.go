@@ -0,0 +1,121 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tknie/ecoflow/decode"
+	"github.com/tknie/log"
+)
+
+const (
+	streamCmdFunc        = decode.CmdFuncStream
+	streamCmdIdDisplay   = decode.CmdIdStreamDisplay
+	streamCmdIdRuntime   = decode.CmdIdStreamRuntime
+	streamCmdIdSetConfig = 17
+)
+
+// FeedGridMode feed-in mode of the Stream series
+type FeedGridMode int
+
+const (
+	// FeedGridOff no energy is fed into the grid
+	FeedGridOff FeedGridMode = 1
+	// FeedGridOn surplus energy is fed into the grid up to the feed-in limit
+	FeedGridOn FeedGridMode = 2
+)
+
+// StreamQuota quota of the Stream series
+type StreamQuota struct {
+	Soc                float64      `quota:"cmsBattSoc"`
+	MaxChargeSoc       float64      `quota:"cmsMaxChgSoc"`
+	MinDsgSoc          float64      `quota:"cmsMinDsgSoc"`
+	PvWatts            float64      `quota:"powGetPvSum"`
+	GridWatts          float64      `quota:"powGetSysGrid"`
+	LoadWatts          float64      `quota:"powGetSysLoad"`
+	BatteryWatts       float64      `quota:"powGetBpCms"`
+	FeedGridMode       FeedGridMode `quota:"feedGridMode"`
+	FeedGridPowLimit   float64      `quota:"feedGridModePowLimit"`
+	FeedGridPowMax     float64      `quota:"feedGridModePowMax"`
+	BypassMode         bool         `quota:"cfgBypassMode"`
+	AC1Enabled         bool         `quota:"relay2Onoff"`
+	AC2Enabled         bool         `quota:"relay3Onoff"`
+	EnergyStrategyMode float64      `quota:"energyStrategyOperateMode"`
+}
+
+// displayStreamPayload decode the Stream series protobuf messages
+func displayStreamPayload(ctx context.Context, sn string, header *Header, payload []byte) bool {
+	msg, err := decode.Pdata(header)
+	if errors.Is(err, decode.ErrUnknownMessage) {
+		displayHeader(header)
+		recordUndecoded(messageTopic(ctx), sn, fmt.Sprintf("unknown stream cmd id %d", header.GetCmdId()), payload)
+		log.Log.Infof("Unknown Stream Cmd ID %d -> %s", header.GetCmdId(), sn)
+		return false
+	}
+	if err != nil {
+		log.Log.Errorf("Unable to parse stream pdata message: %v", err)
+		recordUndecoded(messageTopic(ctx), sn, err.Error(), payload)
+		return false
+	}
+	log.Log.Debugf("-> Stream %s", msg)
+	dispatchEntry(ctx, &Entry{object: msg, serialNumber: sn})
+	return true
+}
+
+// streamCommand create configuration request of the Stream series
+func streamCommand(serialNumber string, params map[string]interface{}) CmdSetRequest {
+	return CmdSetRequest{
+		Sn:      serialNumber,
+		CmdId:   streamCmdIdSetConfig,
+		CmdFunc: streamCmdFunc,
+		Dest:    2,
+		DirDest: 1,
+		DirSrc:  1,
+		NeedAck: true,
+		Params:  params,
+	}
+}
+
+func checkStream(serialNumber string) error {
+	if DetectModel(serialNumber) != ModelStream {
+		return fmt.Errorf("device %s is no Stream device", serialNumber)
+	}
+	return nil
+}
+
+// SetStreamBypassMode switch AC bypass of the Stream series, in bypass mode the
+// AC outlets are fed directly by the grid
+func (client *Client) SetStreamBypassMode(ctx context.Context, serialNumber string, enabled bool) (*CmdSetResponse, error) {
+	if err := checkStream(serialNumber); err != nil {
+		return nil, err
+	}
+	return client.SetCommand(ctx, streamCommand(serialNumber, map[string]interface{}{"cfgBypassMode": enabled}))
+}
+
+// SetStreamFeedInLimit set the grid feed-in limit in watts of the Stream series,
+// a limit of zero disables grid feed-in
+func (client *Client) SetStreamFeedInLimit(ctx context.Context, serialNumber string, watts int) (*CmdSetResponse, error) {
+	if err := checkStream(serialNumber); err != nil {
+		return nil, err
+	}
+	if err := DeviceLimits.Validate(serialNumber, "feedGridModePowLimit", float64(watts)); err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"feedGridMode": FeedGridOn, "feedGridModePowLimit": watts}
+	if watts == 0 {
+		params = map[string]interface{}{"feedGridMode": FeedGridOff}
+	}
+	return client.SetCommand(ctx, streamCommand(serialNumber, params))
+}
@@ -0,0 +1,48 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStreamPayload(t *testing.T) {
+	soc := float32(55.5)
+	limit := uint32(600)
+	pdata, err := proto.Marshal(&StreamDisplayPropertyUpload{CmsBattSoc: &soc, FeedGridModePowLimit: &limit})
+	assert.NoError(t, err)
+	cmdFunc := int32(streamCmdFunc)
+	cmdId := int32(streamCmdIdDisplay)
+	payload, err := proto.Marshal(&SendHeaderMsg{Msg: &Header{Pdata: pdata, CmdFunc: &cmdFunc, CmdId: &cmdId}})
+	assert.NoError(t, err)
+
+	var received []*Telemetry
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) { received = append(received, points...) })
+	defer unregister()
+	assert.True(t, DisplayPayload("BK11TEST", payload))
+	assert.Len(t, received, 2)
+	assert.Equal(t, "streamDisplayPropertyUpload.cmsBattSoc", received[0].Key)
+	assert.Equal(t, 55.5, received[0].Value)
+	assert.Equal(t, ModelStream, DetectModel("BK11TEST"))
+}
+
+func TestStreamCommand(t *testing.T) {
+	params, err := streamCommand("BK11TEST", map[string]interface{}{"feedGridModePowLimit": 600}).parameters()
+	assert.NoError(t, err)
+	assert.Equal(t, 17.0, params["cmdId"])
+	assert.Equal(t, 254.0, params["cmdFunc"])
+	assert.Equal(t, true, params["needAck"])
+	assert.NotContains(t, params, "moduleType")
+}
@@ -0,0 +1,224 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+)
+
+// SubscriptionStateFile file the subscription state is persisted in,
+// persistence is disabled if empty
+var SubscriptionStateFile = os.Getenv("ECOFLOW_SUBSCRIPTION_STATE")
+
+// Backfill called for each device the collector was blind for after restart,
+// can be used to request the missing data using the historical data API
+var Backfill func(period BlindPeriod)
+
+// subscriptionSaveInterval minimum interval between two state file updates
+var subscriptionSaveInterval = 30 * time.Second
+
+var subscriptionState *SubscriptionState
+
+// TopicState last received message of a subscribed topic
+type TopicState struct {
+	Topic         string    `json:"topic"`
+	SerialNumber  string    `json:"serialNumber"`
+	LastMessageID string    `json:"lastMessageId,omitempty"`
+	LastReceived  time.Time `json:"lastReceived"`
+}
+
+// SubscriptionState persistent state of all subscribed topics
+type SubscriptionState struct {
+	mu        sync.Mutex
+	writeMu   sync.Mutex
+	fileName  string
+	lastSave  time.Time
+	saveTimer *clockTimer
+	SavedAt   time.Time              `json:"savedAt"`
+	Topics    map[string]*TopicState `json:"topics"`
+}
+
+// BlindPeriod period no messages were received for a device
+type BlindPeriod struct {
+	SerialNumber string
+	Topic        string
+	From         time.Time
+	To           time.Time
+}
+
+// Duration duration of the blind period
+func (b BlindPeriod) Duration() time.Duration {
+	return b.To.Sub(b.From)
+}
+
+// LoadSubscriptionState load subscription state from file, a missing file
+// returns an empty state
+func LoadSubscriptionState(fileName string) (*SubscriptionState, error) {
+	state := &SubscriptionState{fileName: fileName, Topics: make(map[string]*TopicState)}
+	data, err := readStoredFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, err
+	}
+	if state.Topics == nil {
+		state.Topics = make(map[string]*TopicState)
+	}
+	return state, nil
+}
+
+// Save write subscription state to the state file, a scheduled save is
+// no longer needed
+func (s *SubscriptionState) Save() error {
+	s.mu.Lock()
+	if s.saveTimer != nil {
+		s.saveTimer.Stop()
+		s.saveTimer = nil
+	}
+	s.mu.Unlock()
+	return s.save()
+}
+
+// save write the state, only encoding holds the state lock so receiving
+// messages is not blocked by the file system
+func (s *SubscriptionState) save() error {
+	if s.fileName == "" {
+		return nil
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	s.SavedAt = now()
+	s.lastSave = s.SavedAt
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeStoredFile(s.fileName, data)
+}
+
+// scheduleSave save the state in background, at most once per
+// subscriptionSaveInterval. The caller holds the state lock.
+func (s *SubscriptionState) scheduleSave() {
+	if s.fileName == "" || s.saveTimer != nil {
+		return
+	}
+	delay := subscriptionSaveInterval - since(s.lastSave)
+	if delay < 0 {
+		delay = 0
+	}
+	s.saveTimer = afterFunc(delay, func() {
+		s.mu.Lock()
+		s.saveTimer = nil
+		s.mu.Unlock()
+		if err := s.save(); err != nil {
+			log.Log.Errorf("Error saving subscription state: %v", err)
+		}
+	})
+}
+
+// SubscribedTopics return all topics subscribed in sorted order
+func (s *SubscriptionState) SubscribedTopics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	topics := make([]string, 0, len(s.Topics))
+	for t := range s.Topics {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Subscribed mark topic to be subscribed
+func (s *SubscriptionState) Subscribed(topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Topics[topic]; !ok {
+		s.Topics[topic] = &TopicState{Topic: topic, SerialNumber: getSnFromTopic(topic)}
+	}
+}
+
+// Received record message received on a topic, the state file is updated in
+// background
+func (s *SubscriptionState) Received(topic, messageID string, received time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ts, ok := s.Topics[topic]
+	if !ok {
+		ts = &TopicState{Topic: topic, SerialNumber: getSnFromTopic(topic)}
+		s.Topics[topic] = ts
+	}
+	if messageID != "" {
+		ts.LastMessageID = messageID
+	}
+	ts.LastReceived = received
+	s.scheduleSave()
+}
+
+// BlindPeriods return the periods between the last received message of each
+// topic and the given time
+func (s *SubscriptionState) BlindPeriods(now time.Time) []BlindPeriod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	periods := make([]BlindPeriod, 0)
+	for _, ts := range s.Topics {
+		if ts.LastReceived.IsZero() {
+			continue
+		}
+		periods = append(periods, BlindPeriod{SerialNumber: ts.SerialNumber,
+			Topic: ts.Topic, From: ts.LastReceived, To: now})
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Topic < periods[j].Topic })
+	return periods
+}
+
+// initSubscriptionState load persisted subscription state and report blind periods
+func initSubscriptionState() {
+	if SubscriptionStateFile == "" {
+		return
+	}
+	state, err := LoadSubscriptionState(SubscriptionStateFile)
+	if err != nil {
+		services.ServerMessage("Ecoflow: Error loading subscription state: %v", err)
+		return
+	}
+	for _, p := range state.BlindPeriods(now()) {
+		services.ServerMessage("Ecoflow: device %s was blind for %v since %v", p.SerialNumber,
+			p.Duration().Round(time.Second), p.From.Format(layout))
+		if Backfill != nil {
+			Backfill(p)
+		}
+	}
+	subscriptionState = state
+}
+
+// SaveSubscriptionState write current subscription state, e.g. on shutdown
+func SaveSubscriptionState() error {
+	if subscriptionState == nil {
+		return nil
+	}
+	return subscriptionState.Save()
+}
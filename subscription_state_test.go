@@ -0,0 +1,82 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionStateDebounce(t *testing.T) {
+	oldInterval := subscriptionSaveInterval
+	subscriptionSaveInterval = 50 * time.Millisecond
+	defer func() { subscriptionSaveInterval = oldInterval }()
+	fileName := filepath.Join(t.TempDir(), "subscriptions.json")
+	state, err := LoadSubscriptionState(fileName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	topic := "/app/device/property/HW51SUB"
+	state.Subscribed(topic)
+	assert.NoError(t, state.Save())
+	saved, err := LoadSubscriptionState(fileName)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// messages do not write the file synchronously
+	received := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		state.Received(topic, "", received.Add(time.Duration(i)*time.Second))
+	}
+	current, err := LoadSubscriptionState(fileName)
+	if assert.NoError(t, err) {
+		assert.Equal(t, saved.SavedAt, current.SavedAt)
+		assert.True(t, current.Topics[topic].LastReceived.IsZero())
+	}
+	assert.Eventually(t, func() bool {
+		current, err := LoadSubscriptionState(fileName)
+		return err == nil && current.Topics[topic].LastReceived.Equal(received.Add(9*time.Second))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSubscriptionStateSaveOnShutdown(t *testing.T) {
+	oldInterval := subscriptionSaveInterval
+	subscriptionSaveInterval = time.Hour
+	defer func() { subscriptionSaveInterval = oldInterval }()
+	fileName := filepath.Join(t.TempDir(), "subscriptions.json")
+	state, err := LoadSubscriptionState(fileName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, state.Save())
+	topic := "/app/device/property/HW51SUB"
+	received := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	state.Received(topic, "42", received)
+	assert.NotNil(t, state.saveTimer)
+	assert.NoError(t, state.Save())
+	assert.Nil(t, state.saveTimer)
+
+	loaded, err := LoadSubscriptionState(fileName)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{topic}, loaded.SubscribedTopics())
+		assert.Equal(t, "42", loaded.Topics[topic].LastMessageID)
+		periods := loaded.BlindPeriods(received.Add(time.Hour))
+		if assert.Len(t, periods, 1) {
+			assert.Equal(t, "HW51SUB", periods[0].SerialNumber)
+			assert.Equal(t, time.Hour, periods[0].Duration())
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tknie/services"
+)
+
+// BootstrapConfig configuration of Bootstrap
+type BootstrapConfig struct {
+	// Account credentials, IoT Open keys enable the REST client and device list,
+	// the app login enables MQTT telemetry
+	Account *Account
+	// OnData called with the decoded JSON data of the devices
+	OnData func(serialNumber string, data map[string]interface{})
+	// Handlers telemetry handlers registered for the lifetime of the system
+	Handlers []TelemetryHandler
+	// ContextHandlers context aware telemetry handlers, the context is cancelled on Stop
+	ContextHandlers []TelemetryContextHandler
+	// HandlerTimeout maximum handler time per message, HandlerTimeout default if 0
+	HandlerTimeout time.Duration
+	// Sinks telemetry sinks attached for the lifetime of the system and closed on Stop
+	Sinks []Sink
+	// StatusAddress address of the status HTTP server, not started if empty
+	StatusAddress        string
+	MaxReconnectInterval time.Duration
+	// ConnectRetry retry MQTT login and connect with backoff
+	ConnectRetry *RetryPolicy
+	// Reconnect reconnect strategy after a lost MQTT connection
+	Reconnect *ReconnectPolicy
+	// Backfill poll the quota of the devices using the REST API after a MQTT
+	// reconnect to patch the state store, needs IoT Open keys
+	Backfill bool
+	// SchemaDrift report quota and MQTT keys not known by the typed quota structs
+	SchemaDrift bool
+}
+
+// System running Ecoflow integration created by Bootstrap
+type System struct {
+	Account *Account
+	Client  *Client
+	Mqtt    *MqttClient
+	Devices *DeviceListResponse
+	// Runtime supervising the background components of the system
+	Runtime *Runtime
+	status  *StatusServer
+	sinks   []Sink
+	cleanup []func()
+	cancel  context.CancelFunc
+}
+
+// Bootstrap create the HTTP client, fetch the device list, connect MQTT and
+// register the handlers in the required order
+func Bootstrap(ctx context.Context, cfg BootstrapConfig) (*System, error) {
+	account := cfg.Account
+	if account == nil {
+		account = AccountFromEnv()
+	}
+	if !account.HasCredential(CredentialIoT) && !account.HasCredential(CredentialApp) {
+		return nil, errors.New("bootstrap needs IoT Open keys or app login credentials")
+	}
+	s := &System{Account: account, sinks: cfg.Sinks}
+	if account.HasCredential(CredentialIoT) {
+		client, err := account.Client()
+		if err != nil {
+			return nil, err
+		}
+		s.Client = client
+		list, err := client.CachedDeviceList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.Devices = list
+		devices = list
+	}
+
+	if cfg.OnData != nil {
+		SetDataCallback(cfg.OnData)
+	}
+	if cfg.HandlerTimeout > 0 {
+		HandlerTimeout = cfg.HandlerTimeout
+	}
+	var handlerCtx context.Context
+	handlerCtx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	s.Runtime = NewRuntime(handlerCtx)
+	// workers of sinks, webhooks and handlers are supervised by the system
+	setBackgroundRuntime(s.Runtime)
+	SetHandlerContext(handlerCtx)
+	for _, h := range cfg.Handlers {
+		s.cleanup = append(s.cleanup, RegisterTelemetryHandler(h))
+	}
+	for _, h := range cfg.ContextHandlers {
+		s.cleanup = append(s.cleanup, RegisterTelemetryContextHandler(h))
+	}
+	for _, sink := range cfg.Sinks {
+		s.cleanup = append(s.cleanup, AttachSink(sink))
+	}
+	if cfg.SchemaDrift {
+		SetSchemaDriftDetector(NewSchemaDriftDetector())
+		s.cleanup = append(s.cleanup, func() { SetSchemaDriftDetector(nil) })
+	}
+
+	if account.HasCredential(CredentialApp) {
+		initSubscriptionState()
+		if err := account.Require(CredentialApp, "MQTT app client"); err != nil {
+			return nil, err
+		}
+		m, err := ConnectMqttClient(ctx, MqttClientConfiguration{
+			Email:                account.Email,
+			Password:             account.Password,
+			OnConnect:            OnConnect,
+			OnConnectionLost:     OnConnectionLost,
+			OnReconnect:          OnReconnect,
+			MaxReconnectInterval: cfg.MaxReconnectInterval,
+			ConnectRetry:         cfg.ConnectRetry,
+			Reconnect:            cfg.Reconnect,
+		})
+		if err != nil {
+			s.Stop(ctx)
+			return nil, err
+		}
+		ecoclient = m
+		s.Mqtt = m
+		services.ServerMessage("Registered to Ecoflow MQTT service")
+		tracker := NewResumptionTracker(nil)
+		if cfg.Backfill {
+			tracker.Client = s.Client
+		}
+		s.cleanup = append(s.cleanup, tracker.Start())
+	}
+
+	s.Runtime.Go(Component{Name: "stats", Run: RunStatCompaction, Restart: RestartOnFailure, Optional: true})
+	if cfg.StatusAddress != "" {
+		s.status = NewStatusServer(cfg.StatusAddress)
+		if s.Client != nil {
+			s.status.EnableControl(s.Client)
+		}
+		s.Runtime.Go(Component{Name: "status", Run: s.status.Serve, Restart: RestartOnFailure})
+	}
+	return s, nil
+}
+
+// Go start the component supervised by the runtime of the system
+func (s *System) Go(c Component) {
+	s.Runtime.Go(c)
+}
+
+// Stop disconnect MQTT, stop the runtime components and cancel running handlers, unregister the
+// handlers, close the sinks and stop the status server. The errors of failed components are
+// part of the returned error.
+func (s *System) Stop(ctx context.Context) error {
+	var errs []error
+	if s.Mqtt != nil {
+		s.Mqtt.Disconnect(250)
+		if ecoclient == s.Mqtt {
+			ecoclient = nil
+		}
+		s.Mqtt = nil
+		errs = append(errs, SaveSubscriptionState())
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+		SetHandlerContext(context.Background())
+	}
+	for i := len(s.cleanup) - 1; i >= 0; i-- {
+		s.cleanup[i]()
+	}
+	s.cleanup = nil
+	for _, sink := range s.sinks {
+		errs = append(errs, sink.Close())
+	}
+	s.sinks = nil
+	if s.status != nil {
+		errs = append(errs, s.status.Shutdown(ctx))
+		s.status = nil
+	}
+	if s.Runtime != nil {
+		errs = append(errs, s.Runtime.Wait())
+		backgroundRuntimes.CompareAndSwap(s.Runtime, nil)
+	}
+	return errors.Join(errs...)
+}
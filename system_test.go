@@ -0,0 +1,36 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeCountSink struct{ closed int }
+
+func (s *closeCountSink) Write([]*Telemetry) error { return nil }
+func (s *closeCountSink) Close() error             { s.closed++; return nil }
+
+func TestBootstrapStop(t *testing.T) {
+	_, err := Bootstrap(context.Background(), BootstrapConfig{Account: &Account{}})
+	assert.Error(t, err)
+
+	sink := &closeCountSink{}
+	s := &System{sinks: []Sink{sink}}
+	s.cleanup = append(s.cleanup, AttachSink(sink))
+	assert.NoError(t, s.Stop(context.Background()))
+	assert.NoError(t, s.Stop(context.Background()))
+	assert.Equal(t, 1, sink.closed)
+}
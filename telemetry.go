@@ -0,0 +1,274 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Telemetry sources
+const (
+	SourceMqtt = "mqtt"
+	SourceHttp = "http"
+)
+
+// Telemetry normalized telemetry value of a device
+type Telemetry struct {
+	SerialNumber string      `json:"serialNumber"`
+	Source       string      `json:"source"`
+	Key          string      `json:"key"`
+	Value        interface{} `json:"value"`
+	Timestamp    time.Time   `json:"timestamp"`
+	// Anomaly name of the anomaly rule if tagged by the anomaly detector
+	Anomaly string `json:"anomaly,omitempty"`
+}
+
+// TelemetryHandler handler receiving all normalized telemetry values of one message
+type TelemetryHandler func(points []*Telemetry)
+
+// TelemetryContextHandler handler receiving all normalized telemetry values of one
+// message together with the message context. The context is cancelled if the
+// HandlerTimeout elapses or the handler context is cancelled at shutdown.
+type TelemetryContextHandler func(ctx context.Context, points []*Telemetry)
+
+// HandlerTimeout maximum time all handlers may use for one message, no timeout if 0
+var HandlerTimeout = 30 * time.Second
+
+var telemetryLock sync.RWMutex
+var telemetryHandlers = make(map[int]TelemetryContextHandler)
+var telemetryHandlerID = 0
+var handlerContext = context.Background()
+
+// SetHandlerContext set the root context the message contexts are derived from,
+// cancelling it cancels all running handlers
+func SetHandlerContext(ctx context.Context) {
+	telemetryLock.Lock()
+	defer telemetryLock.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	handlerContext = ctx
+}
+
+// MessageContext create the context of one received message derived from the
+// handler context and limited by HandlerTimeout
+func MessageContext() (context.Context, context.CancelFunc) {
+	telemetryLock.RLock()
+	ctx := handlerContext
+	telemetryLock.RUnlock()
+	if HandlerTimeout > 0 {
+		return context.WithTimeout(ctx, HandlerTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// RegisterTelemetryHandler register handler for normalized telemetry, the returned
+// function unregisters the handler
+func RegisterTelemetryHandler(handler TelemetryHandler) func() {
+	return RegisterTelemetryContextHandler(func(_ context.Context, points []*Telemetry) {
+		handler(points)
+	})
+}
+
+// RegisterTelemetryContextHandler register context aware handler for normalized
+// telemetry, the returned function unregisters the handler
+func RegisterTelemetryContextHandler(handler TelemetryContextHandler) func() {
+	telemetryLock.Lock()
+	defer telemetryLock.Unlock()
+	telemetryHandlerID++
+	id := telemetryHandlerID
+	telemetryHandlers[id] = handler
+	return func() {
+		telemetryLock.Lock()
+		defer telemetryLock.Unlock()
+		delete(telemetryHandlers, id)
+	}
+}
+
+// DispatchTelemetry send telemetry values passing the device filters to all
+// registered handlers using a new message context
+func DispatchTelemetry(points []*Telemetry) {
+	ctx, cancel := MessageContext()
+	defer cancel()
+	DispatchTelemetryContext(ctx, points)
+}
+
+// DispatchTelemetryContext send telemetry values decoded by the custom decoders and
+// the derived net power values passing the device filters and the anomaly detector
+// to the trigger engine, the schema drift detector and all registered handlers.
+// Every handler is called even if the context is already done, so handlers
+// keeping in-memory state stay consistent and decide themselves how to react.
+func DispatchTelemetryContext(ctx context.Context, points []*Telemetry) {
+	points = applyDecoders(points)
+	if derived := DerivePowerFlow(points); len(derived) > 0 {
+		points = append(points[:len(points):len(points)], derived...)
+	}
+	points = detectAnomalies(FilterTelemetry(points))
+	if len(points) == 0 {
+		return
+	}
+	checkTriggers(points)
+	checkSchemaDrift(func() []*Telemetry { return points })
+	telemetryLock.RLock()
+	handlers := make([]TelemetryContextHandler, 0, len(telemetryHandlers))
+	for _, h := range telemetryHandlers {
+		handlers = append(handlers, h)
+	}
+	telemetryLock.RUnlock()
+	for _, h := range handlers {
+		callTelemetryHandler(ctx, h, points)
+	}
+	if err := ctx.Err(); err != nil {
+		log.Log.Errorf("Telemetry dispatch of %s exceeded the message context: %v", points[0].SerialNumber, err)
+	}
+}
+
+// NormalizeTelemetry flatten device data into telemetry values, nested keys are
+// joined using dots
+func NormalizeTelemetry(serialNumber, source string, data map[string]interface{}, timestamp time.Time) []*Telemetry {
+	points := make([]*Telemetry, 0, len(data))
+	points = appendTelemetry(points, serialNumber, source, "", data, timestamp)
+	sort.Slice(points, func(i, j int) bool { return points[i].Key < points[j].Key })
+	return points
+}
+
+func appendTelemetry(points []*Telemetry, serialNumber, source, prefix string, data map[string]interface{}, timestamp time.Time) []*Telemetry {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case map[string]interface{}:
+			points = appendTelemetry(points, serialNumber, source, key, value, timestamp)
+		case time.Time:
+			// timestamp added by the message handler
+		default:
+			points = append(points, &Telemetry{SerialNumber: serialNumber, Source: source,
+				Key: key, Value: signedValue(serialNumber, key, normalizeValue(value)), Timestamp: timestamp})
+		}
+	}
+	return points
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case float64, string, bool:
+		return value
+	case float32:
+		return float64(value)
+	case int:
+		return float64(value)
+	case int32:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case uint32:
+		return float64(value)
+	case uint64:
+		return float64(value)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// NormalizeProtoTelemetry flatten all set fields of a protobuf message into telemetry
+// values, the key is prefixed with the message name
+func NormalizeProtoTelemetry(serialNumber string, msg proto.Message, timestamp time.Time) []*Telemetry {
+	points := make([]*Telemetry, 0)
+	m := msg.ProtoReflect()
+	name := string(m.Descriptor().Name())
+	prefix := strings.ToLower(name[:1]) + name[1:]
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			return true
+		}
+		var value interface{}
+		switch fd.Kind() {
+		case protoreflect.StringKind:
+			value = v.String()
+		case protoreflect.BoolKind:
+			value = v.Bool()
+		case protoreflect.BytesKind:
+			value = fmt.Sprintf("%x", v.Bytes())
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			value = v.Float()
+		case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+			value = float64(v.Uint())
+		case protoreflect.EnumKind:
+			value = float64(v.Enum())
+		default:
+			value = float64(v.Int())
+		}
+		key := prefix + "." + fd.JSONName()
+		points = append(points, &Telemetry{SerialNumber: serialNumber, Source: SourceMqtt,
+			Key: key, Value: signedValue(serialNumber, key, value), Timestamp: timestamp})
+		return true
+	})
+	return points
+}
+
+// Proto convert telemetry value into the protobuf export message
+func (t *Telemetry) Proto() *TelemetryPoint {
+	p := &TelemetryPoint{SerialNumber: t.SerialNumber, Source: t.Source,
+		Key: t.Key, Timestamp: timestamppb.New(t.Timestamp)}
+	switch v := t.Value.(type) {
+	case float64:
+		p.Value = &TelemetryPoint_Number{Number: v}
+	case bool:
+		p.Value = &TelemetryPoint_Flag{Flag: v}
+	case string:
+		p.Value = &TelemetryPoint_Text{Text: v}
+	default:
+		p.Value = &TelemetryPoint_Text{Text: fmt.Sprint(v)}
+	}
+	return p
+}
+
+// TelemetryFromProto convert protobuf export message into telemetry value
+func TelemetryFromProto(p *TelemetryPoint) *Telemetry {
+	t := &Telemetry{SerialNumber: p.GetSerialNumber(), Source: p.GetSource(),
+		Key: p.GetKey(), Timestamp: p.GetTimestamp().AsTime()}
+	switch v := p.GetValue().(type) {
+	case *TelemetryPoint_Number:
+		t.Value = v.Number
+	case *TelemetryPoint_Flag:
+		t.Value = v.Flag
+	case *TelemetryPoint_Text:
+		t.Value = v.Text
+	}
+	return t
+}
+
+// dispatchEntry dispatch protobuf decoded entry to the protocol handler and telemetry handlers
+func dispatchEntry(ctx context.Context, entry *Entry) {
+	if caller != nil {
+		callProtocolHandler(entry)
+	}
+	if msg, ok := entry.object.(proto.Message); ok {
+		DispatchTelemetryContext(ctx, NormalizeProtoTelemetry(entry.serialNumber, msg, now()))
+	} else {
+		log.Log.Debugf("Entry %T is no protobuf message", entry.object)
+	}
+}
@@ -0,0 +1,333 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: telemetry.proto
+
+package ecoflow
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TelemetryPoint normalized telemetry value of a device
+type TelemetryPoint struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumber string                 `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Source       string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Key          string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*TelemetryPoint_Number
+	//	*TelemetryPoint_Text
+	//	*TelemetryPoint_Flag
+	Value         isTelemetryPoint_Value `protobuf_oneof:"value"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TelemetryPoint) Reset() {
+	*x = TelemetryPoint{}
+	mi := &file_telemetry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TelemetryPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TelemetryPoint) ProtoMessage() {}
+
+func (x *TelemetryPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TelemetryPoint.ProtoReflect.Descriptor instead.
+func (*TelemetryPoint) Descriptor() ([]byte, []int) {
+	return file_telemetry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TelemetryPoint) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *TelemetryPoint) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TelemetryPoint) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TelemetryPoint) GetValue() isTelemetryPoint_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TelemetryPoint) GetNumber() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*TelemetryPoint_Number); ok {
+			return x.Number
+		}
+	}
+	return 0
+}
+
+func (x *TelemetryPoint) GetText() string {
+	if x != nil {
+		if x, ok := x.Value.(*TelemetryPoint_Text); ok {
+			return x.Text
+		}
+	}
+	return ""
+}
+
+func (x *TelemetryPoint) GetFlag() bool {
+	if x != nil {
+		if x, ok := x.Value.(*TelemetryPoint_Flag); ok {
+			return x.Flag
+		}
+	}
+	return false
+}
+
+func (x *TelemetryPoint) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type isTelemetryPoint_Value interface {
+	isTelemetryPoint_Value()
+}
+
+type TelemetryPoint_Number struct {
+	Number float64 `protobuf:"fixed64,4,opt,name=number,proto3,oneof"`
+}
+
+type TelemetryPoint_Text struct {
+	Text string `protobuf:"bytes,5,opt,name=text,proto3,oneof"`
+}
+
+type TelemetryPoint_Flag struct {
+	Flag bool `protobuf:"varint,6,opt,name=flag,proto3,oneof"`
+}
+
+func (*TelemetryPoint_Number) isTelemetryPoint_Value() {}
+
+func (*TelemetryPoint_Text) isTelemetryPoint_Value() {}
+
+func (*TelemetryPoint_Flag) isTelemetryPoint_Value() {}
+
+type SubscribeTelemetryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumbers []string               `protobuf:"bytes,1,rep,name=serial_numbers,json=serialNumbers,proto3" json:"serial_numbers,omitempty"`
+	Keys          []string               `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeTelemetryRequest) Reset() {
+	*x = SubscribeTelemetryRequest{}
+	mi := &file_telemetry_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeTelemetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeTelemetryRequest) ProtoMessage() {}
+
+func (x *SubscribeTelemetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeTelemetryRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeTelemetryRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeTelemetryRequest) GetSerialNumbers() []string {
+	if x != nil {
+		return x.SerialNumbers
+	}
+	return nil
+}
+
+func (x *SubscribeTelemetryRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// TelemetryBatch batch of telemetry values published by sinks
+type TelemetryBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Points        []*TelemetryPoint      `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TelemetryBatch) Reset() {
+	*x = TelemetryBatch{}
+	mi := &file_telemetry_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TelemetryBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TelemetryBatch) ProtoMessage() {}
+
+func (x *TelemetryBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TelemetryBatch.ProtoReflect.Descriptor instead.
+func (*TelemetryBatch) Descriptor() ([]byte, []int) {
+	return file_telemetry_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TelemetryBatch) GetPoints() []*TelemetryPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+var File_telemetry_proto protoreflect.FileDescriptor
+
+const file_telemetry_proto_rawDesc = "" +
+	"\n" +
+	"\x0ftelemetry.proto\x12\aecoflow\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe8\x01\n" +
+	"\x0eTelemetryPoint\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x18\n" +
+	"\x06number\x18\x04 \x01(\x01H\x00R\x06number\x12\x14\n" +
+	"\x04text\x18\x05 \x01(\tH\x00R\x04text\x12\x14\n" +
+	"\x04flag\x18\x06 \x01(\bH\x00R\x04flag\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestampB\a\n" +
+	"\x05value\"V\n" +
+	"\x19SubscribeTelemetryRequest\x12%\n" +
+	"\x0eserial_numbers\x18\x01 \x03(\tR\rserialNumbers\x12\x12\n" +
+	"\x04keys\x18\x02 \x03(\tR\x04keys\"A\n" +
+	"\x0eTelemetryBatch\x12/\n" +
+	"\x06points\x18\x01 \x03(\v2\x17.ecoflow.TelemetryPointR\x06points2]\n" +
+	"\x0fTelemetryExport\x12J\n" +
+	"\tSubscribe\x12\".ecoflow.SubscribeTelemetryRequest\x1a\x17.ecoflow.TelemetryPoint0\x01b\x06proto3"
+
+var (
+	file_telemetry_proto_rawDescOnce sync.Once
+	file_telemetry_proto_rawDescData []byte
+)
+
+func file_telemetry_proto_rawDescGZIP() []byte {
+	file_telemetry_proto_rawDescOnce.Do(func() {
+		file_telemetry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_telemetry_proto_rawDesc), len(file_telemetry_proto_rawDesc)))
+	})
+	return file_telemetry_proto_rawDescData
+}
+
+var file_telemetry_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_telemetry_proto_goTypes = []any{
+	(*TelemetryPoint)(nil),            // 0: ecoflow.TelemetryPoint
+	(*SubscribeTelemetryRequest)(nil), // 1: ecoflow.SubscribeTelemetryRequest
+	(*TelemetryBatch)(nil),            // 2: ecoflow.TelemetryBatch
+	(*timestamppb.Timestamp)(nil),     // 3: google.protobuf.Timestamp
+}
+var file_telemetry_proto_depIdxs = []int32{
+	3, // 0: ecoflow.TelemetryPoint.timestamp:type_name -> google.protobuf.Timestamp
+	0, // 1: ecoflow.TelemetryBatch.points:type_name -> ecoflow.TelemetryPoint
+	1, // 2: ecoflow.TelemetryExport.Subscribe:input_type -> ecoflow.SubscribeTelemetryRequest
+	0, // 3: ecoflow.TelemetryExport.Subscribe:output_type -> ecoflow.TelemetryPoint
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_telemetry_proto_init() }
+func file_telemetry_proto_init() {
+	if File_telemetry_proto != nil {
+		return
+	}
+	file_telemetry_proto_msgTypes[0].OneofWrappers = []any{
+		(*TelemetryPoint_Number)(nil),
+		(*TelemetryPoint_Text)(nil),
+		(*TelemetryPoint_Flag)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_telemetry_proto_rawDesc), len(file_telemetry_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_telemetry_proto_goTypes,
+		DependencyIndexes: file_telemetry_proto_depIdxs,
+		MessageInfos:      file_telemetry_proto_msgTypes,
+	}.Build()
+	File_telemetry_proto = out.File
+	file_telemetry_proto_goTypes = nil
+	file_telemetry_proto_depIdxs = nil
+}
@@ -0,0 +1,72 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package telemetry stable v1 API of the normalized device telemetry
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/tknie/ecoflow"
+)
+
+const (
+	// SourceMqtt telemetry received using MQTT
+	SourceMqtt = ecoflow.SourceMqtt
+	// SourceHttp telemetry read using the REST API
+	SourceHttp = ecoflow.SourceHttp
+	// SourceBackfill state patched using the REST API after a MQTT reconnect
+	SourceBackfill = ecoflow.SourceBackfill
+)
+
+// Point normalized telemetry value
+type Point = ecoflow.Telemetry
+
+// Handler receive dispatched telemetry
+type Handler = ecoflow.TelemetryHandler
+
+// ContextHandler receive dispatched telemetry with the message context
+type ContextHandler = ecoflow.TelemetryContextHandler
+
+// DataCallback receive the decoded JSON data of the devices
+type DataCallback = ecoflow.DataCallback
+
+// Register register telemetry handler, the returned function unregisters it
+func Register(handler Handler) func() {
+	return ecoflow.RegisterTelemetryHandler(handler)
+}
+
+// RegisterContext register context aware telemetry handler, the returned
+// function unregisters it
+func RegisterContext(handler ContextHandler) func() {
+	return ecoflow.RegisterTelemetryContextHandler(handler)
+}
+
+// OnData set the callback receiving the decoded JSON data, nil removes it
+func OnData(callback DataCallback) {
+	ecoflow.SetDataCallback(callback)
+}
+
+// Dispatch dispatch telemetry to all handlers
+func Dispatch(ctx context.Context, points []*Point) {
+	ecoflow.DispatchTelemetryContext(ctx, points)
+}
+
+// Normalize flatten device data into telemetry points
+func Normalize(serialNumber, source string, data map[string]interface{}, timestamp time.Time) []*Point {
+	return ecoflow.NormalizeTelemetry(serialNumber, source, data, timestamp)
+}
+
+// LastSeen return time and source of the last data received from the device
+func LastSeen(serialNumber string) (time.Time, string) {
+	return ecoflow.LastSeen(serialNumber)
+}
@@ -0,0 +1,128 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: telemetry.proto
+
+package ecoflow
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TelemetryExport_Subscribe_FullMethodName = "/ecoflow.TelemetryExport/Subscribe"
+)
+
+// TelemetryExportClient is the client API for TelemetryExport service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TelemetryExport stream normalized telemetry to downstream collectors
+type TelemetryExportClient interface {
+	Subscribe(ctx context.Context, in *SubscribeTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TelemetryPoint], error)
+}
+
+type telemetryExportClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTelemetryExportClient(cc grpc.ClientConnInterface) TelemetryExportClient {
+	return &telemetryExportClient{cc}
+}
+
+func (c *telemetryExportClient) Subscribe(ctx context.Context, in *SubscribeTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TelemetryPoint], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryExport_ServiceDesc.Streams[0], TelemetryExport_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeTelemetryRequest, TelemetryPoint]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryExport_SubscribeClient = grpc.ServerStreamingClient[TelemetryPoint]
+
+// TelemetryExportServer is the server API for TelemetryExport service.
+// All implementations must embed UnimplementedTelemetryExportServer
+// for forward compatibility.
+//
+// TelemetryExport stream normalized telemetry to downstream collectors
+type TelemetryExportServer interface {
+	Subscribe(*SubscribeTelemetryRequest, grpc.ServerStreamingServer[TelemetryPoint]) error
+	mustEmbedUnimplementedTelemetryExportServer()
+}
+
+// UnimplementedTelemetryExportServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTelemetryExportServer struct{}
+
+func (UnimplementedTelemetryExportServer) Subscribe(*SubscribeTelemetryRequest, grpc.ServerStreamingServer[TelemetryPoint]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedTelemetryExportServer) mustEmbedUnimplementedTelemetryExportServer() {}
+func (UnimplementedTelemetryExportServer) testEmbeddedByValue()                         {}
+
+// UnsafeTelemetryExportServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TelemetryExportServer will
+// result in compilation errors.
+type UnsafeTelemetryExportServer interface {
+	mustEmbedUnimplementedTelemetryExportServer()
+}
+
+func RegisterTelemetryExportServer(s grpc.ServiceRegistrar, srv TelemetryExportServer) {
+	// If the following call pancis, it indicates UnimplementedTelemetryExportServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TelemetryExport_ServiceDesc, srv)
+}
+
+func _TelemetryExport_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTelemetryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TelemetryExportServer).Subscribe(m, &grpc.GenericServerStream[SubscribeTelemetryRequest, TelemetryPoint]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryExport_SubscribeServer = grpc.ServerStreamingServer[TelemetryPoint]
+
+// TelemetryExport_ServiceDesc is the grpc.ServiceDesc for TelemetryExport service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TelemetryExport_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ecoflow.TelemetryExport",
+	HandlerType: (*TelemetryExportServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _TelemetryExport_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "telemetry.proto",
+}
@@ -0,0 +1,154 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tknie/log"
+	"github.com/tknie/services"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// defaultTelemetryBuffer number of messages buffered per subscriber
+const defaultTelemetryBuffer = 100
+
+// telemetryStopTimeout time given to the running calls on shutdown before
+// the connections are closed
+const telemetryStopTimeout = 5 * time.Second
+
+// TelemetryServer gRPC server streaming normalized telemetry to subscribers
+type TelemetryServer struct {
+	UnimplementedTelemetryExportServer
+	BufferSize int
+	dropped    atomic.Uint64
+	stopping   chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewTelemetryServer create new gRPC telemetry export service
+func NewTelemetryServer() *TelemetryServer {
+	return &TelemetryServer{BufferSize: defaultTelemetryBuffer, stopping: make(chan struct{})}
+}
+
+// Stop end all running and future subscriptions
+func (s *TelemetryServer) Stop() {
+	s.stopOnce.Do(func() { close(s.stopping) })
+}
+
+// Dropped number of telemetry messages dropped for slow subscribers
+func (s *TelemetryServer) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Subscribe stream telemetry filtered by serial numbers and keys to the subscriber
+func (s *TelemetryServer) Subscribe(req *SubscribeTelemetryRequest, stream grpc.ServerStreamingServer[TelemetryPoint]) error {
+	serialNumbers := make(map[string]bool)
+	for _, sn := range req.GetSerialNumbers() {
+		serialNumbers[strings.ToUpper(sn)] = true
+	}
+	keys := make(map[string]bool)
+	for _, k := range req.GetKeys() {
+		keys[k] = true
+	}
+	bufferSize := s.BufferSize
+	if bufferSize < 1 {
+		bufferSize = defaultTelemetryBuffer
+	}
+	ch := make(chan []*Telemetry, bufferSize)
+	unregister := RegisterTelemetryHandler(func(points []*Telemetry) {
+		select {
+		case ch <- points:
+		default:
+			s.dropped.Add(1)
+		}
+	})
+	defer unregister()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopping:
+			return nil
+		case points := <-ch:
+			for _, p := range points {
+				if len(serialNumbers) > 0 && !serialNumbers[strings.ToUpper(p.SerialNumber)] {
+					continue
+				}
+				if len(keys) > 0 && !keys[p.Key] {
+					continue
+				}
+				if err := stream.Send(p.Proto()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// ServeTelemetry start gRPC telemetry export on the given address until the
+// context is cancelled, calls need the telemetry:read scope of GatewayTokens
+func ServeTelemetry(ctx context.Context, address string, opts ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return serveTelemetry(ctx, listener, opts...)
+}
+
+// serveTelemetry serve telemetry export on the listener. If the context is done
+// the subscriptions are ended and the server is stopped gracefully, calls
+// still running after telemetryStopTimeout are cancelled.
+func serveTelemetry(ctx context.Context, listener net.Listener, opts ...grpc.ServerOption) error {
+	server := grpc.NewServer(append(GatewayTokens.ServerOptions(ScopeTelemetryRead), opts...)...)
+	telemetry := NewTelemetryServer()
+	RegisterTelemetryExportServer(server, telemetry)
+	services.ServerMessage("Ecoflow: telemetry export listening on %s", listener.Addr())
+	group, groupCtx := errgroup.WithContext(ctx)
+	serving := make(chan struct{})
+	group.Go(func() error {
+		defer close(serving)
+		return server.Serve(listener)
+	})
+	group.Go(func() error {
+		select {
+		case <-serving:
+			return nil
+		case <-groupCtx.Done():
+		}
+		telemetry.Stop()
+		stopped := make(chan struct{})
+		group.Go(func() error {
+			defer close(stopped)
+			server.GracefulStop()
+			return nil
+		})
+		select {
+		case <-stopped:
+		case <-CurrentClock().After(telemetryStopTimeout):
+			server.Stop()
+		}
+		return nil
+	})
+	err := group.Wait()
+	if err != nil {
+		log.Log.Errorf("Telemetry export server error: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestServeTelemetryShutdownWithSubscriber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- serveTelemetry(ctx, listener) }()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	stream, err := NewTelemetryExportClient(conn).Subscribe(context.Background(),
+		&SubscribeTelemetryRequest{SerialNumbers: []string{"HW51EXPORT"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	received := make(chan *TelemetryPoint, 1)
+	go func() {
+		for {
+			point, err := stream.Recv()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- point
+		}
+	}()
+	// dispatch until the subscription is registered
+	point := &Telemetry{SerialNumber: "HW51EXPORT", Source: SourceMqtt, Key: "soc", Value: 80.0, Timestamp: time.Now()}
+	assert.Eventually(t, func() bool {
+		DispatchTelemetry([]*Telemetry{point})
+		select {
+		case p := <-received:
+			return p != nil
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("telemetry export not stopped with connected subscriber")
+	}
+}
+
+func TestServeTelemetryListenerClosed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	listener.Close()
+	assert.Error(t, serveTelemetry(context.Background(), listener))
+}
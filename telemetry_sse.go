@@ -0,0 +1,209 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSSEHistory number of telemetry events kept for Last-Event-ID resume
+const defaultSSEHistory = 1000
+
+// sseKeepAlive interval of comment lines keeping idle connections open
+var sseKeepAlive = 15 * time.Second
+
+// sseEvent telemetry point with its event id
+type sseEvent struct {
+	id    uint64
+	point *Telemetry
+}
+
+// TelemetrySSE Server-Sent Events stream of normalized telemetry. Each point is
+// sent as "telemetry" event with an increasing id, reconnecting clients get the
+// missed points of the history using the Last-Event-ID header.
+type TelemetrySSE struct {
+	BufferSize  int
+	lock        sync.Mutex
+	history     []sseEvent
+	maxHistory  int
+	lastID      uint64
+	subscribers map[chan sseEvent]bool
+	unregister  func()
+	dropped     atomic.Uint64
+}
+
+// NewTelemetrySSE create SSE stream keeping the given number of points for resume
+func NewTelemetrySSE(history int) *TelemetrySSE {
+	if history < 1 {
+		history = defaultSSEHistory
+	}
+	return &TelemetrySSE{BufferSize: defaultTelemetryBuffer, maxHistory: history,
+		subscribers: make(map[chan sseEvent]bool)}
+}
+
+// Dropped number of telemetry points dropped for slow subscribers
+func (s *TelemetrySSE) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// start register the telemetry handler on first use
+func (s *TelemetrySSE) start() {
+	if s.unregister == nil {
+		s.unregister = RegisterTelemetryHandler(s.publish)
+	}
+}
+
+// Close unregister the telemetry handler
+func (s *TelemetrySSE) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.unregister != nil {
+		s.unregister()
+		s.unregister = nil
+	}
+}
+
+func (s *TelemetrySSE) publish(points []*Telemetry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, p := range points {
+		s.lastID++
+		e := sseEvent{id: s.lastID, point: p}
+		// ring buffer, the event of id n is stored at (n-1) % maxHistory
+		if len(s.history) < s.maxHistory {
+			s.history = append(s.history, e)
+		} else {
+			s.history[(e.id-1)%uint64(s.maxHistory)] = e
+		}
+		for ch := range s.subscribers {
+			select {
+			case ch <- e:
+			default:
+				s.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// subscribe register subscriber and return the history after the given id
+func (s *TelemetrySSE) subscribe(after uint64, resume bool) (chan sseEvent, []sseEvent) {
+	bufferSize := s.BufferSize
+	if bufferSize < 1 {
+		bufferSize = defaultTelemetryBuffer
+	}
+	ch := make(chan sseEvent, bufferSize)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.start()
+	s.subscribers[ch] = true
+	var missed []sseEvent
+	if resume {
+		first := after + 1
+		if oldest := s.lastID - uint64(len(s.history)) + 1; first < oldest {
+			first = oldest
+		}
+		for id := first; id <= s.lastID; id++ {
+			missed = append(missed, s.history[(id-1)%uint64(s.maxHistory)])
+		}
+	}
+	return ch, missed
+}
+
+func (s *TelemetrySSE) unsubscribe(ch chan sseEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.subscribers, ch)
+}
+
+// ServeHTTP stream telemetry, optionally filtered by the sn and key query
+// parameters, until the client disconnects
+func (s *TelemetrySSE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	serialNumbers := make(map[string]bool)
+	for _, sn := range r.URL.Query()["sn"] {
+		serialNumbers[strings.ToUpper(ResolveDevice(sn))] = true
+	}
+	keys := make(map[string]bool)
+	for _, k := range r.URL.Query()["key"] {
+		keys[k] = true
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	after, err := strconv.ParseUint(lastEventID, 10, 64)
+	ch, missed := s.subscribe(after, err == nil)
+	defer s.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: 3000\n\n")
+
+	write := func(e sseEvent) error {
+		if len(serialNumbers) > 0 && !serialNumbers[strings.ToUpper(e.point.SerialNumber)] {
+			return nil
+		}
+		if len(keys) > 0 && !keys[e.point.Key] {
+			return nil
+		}
+		data, err := json.Marshal(e.point)
+		if err != nil {
+			return nil
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: telemetry\ndata: %s\n\n", e.id, data)
+		return err
+	}
+	var last uint64
+	for _, e := range missed {
+		if err := write(e); err != nil {
+			return
+		}
+		last = e.id
+	}
+	flusher.Flush()
+
+	keepAlive := CurrentClock().After(sseKeepAlive)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-ch:
+			// skip points already sent from the history
+			if e.id <= last {
+				continue
+			}
+			if err := write(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive:
+			if _, err := fmt.Fprintf(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+			keepAlive = CurrentClock().After(sseKeepAlive)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readSSE read events of the stream until count data lines are received
+func readSSE(t *testing.T, reader *bufio.Reader, count int) (ids []string, data []string) {
+	for len(data) < count {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	return
+}
+
+func TestTelemetrySSEResume(t *testing.T) {
+	s := NewStatusServer("")
+	s.Auth = NewTokenAuth()
+	defer s.sse.Close()
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	connect := func(lastEventID string) (*http.Response, context.CancelFunc) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?sn=HW51SSE", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			cancel()
+			t.FailNow()
+		}
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		return resp, cancel
+	}
+	resp, cancel := connect("")
+	reader := bufio.NewReader(resp.Body)
+	// wait for the stream to be established
+	_, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	DispatchTelemetry([]*Telemetry{{SerialNumber: "HW51SSE", Key: "20_1.pv1InputWatts", Value: 120.0},
+		{SerialNumber: "HW51OTHER", Key: "20_1.pv1InputWatts", Value: 1.0},
+		{SerialNumber: "HW51SSE", Key: "20_1.batSoc", Value: 80.0}})
+	ids, data := readSSE(t, reader, 2)
+	assert.Contains(t, data[0], `"key":"20_1.pv1InputWatts"`)
+	assert.Contains(t, data[1], `"value":80`)
+	resp.Body.Close()
+	cancel()
+
+	// points sent while disconnected are replayed after the last event id
+	DispatchTelemetry([]*Telemetry{{SerialNumber: "HW51SSE", Key: "20_1.batSoc", Value: 81.0}})
+	resp, cancel = connect(ids[0])
+	defer cancel()
+	defer resp.Body.Close()
+	_, data = readSSE(t, bufio.NewReader(resp.Body), 2)
+	assert.Contains(t, data[0], `"value":80`)
+	assert.Contains(t, data[1], `"value":81`)
+}
+
+func TestTelemetrySSEHistoryRing(t *testing.T) {
+	s := NewTelemetrySSE(3)
+	defer s.Close()
+	for i := 0; i < 5; i++ {
+		s.publish([]*Telemetry{{SerialNumber: "HW51RING", Key: "a", Value: float64(i)}})
+	}
+	ids := func(after uint64) []uint64 {
+		ch, missed := s.subscribe(after, true)
+		defer s.unsubscribe(ch)
+		result := make([]uint64, 0, len(missed))
+		for _, e := range missed {
+			result = append(result, e.id)
+		}
+		return result
+	}
+	assert.Equal(t, []uint64{3, 4, 5}, ids(0))
+	assert.Equal(t, []uint64{5}, ids(4))
+	assert.Empty(t, ids(5))
+	assert.Empty(t, ids(10))
+}
@@ -0,0 +1,97 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTelemetry(t *testing.T) {
+	ts := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	data := map[string]interface{}{"pd": map[string]interface{}{"soc": 80.0, "model": "D2"},
+		"inv.acOut": true, "timestamp": ts}
+	points := NormalizeTelemetry("R331TEST", SourceMqtt, data, ts)
+	assert.Len(t, points, 3)
+	assert.Equal(t, "inv.acOut", points[0].Key)
+	assert.Equal(t, "pd.model", points[1].Key)
+	assert.Equal(t, "pd.soc", points[2].Key)
+	assert.Equal(t, 80.0, points[2].Value)
+
+	for _, p := range points {
+		assert.Equal(t, p, TelemetryFromProto(p.Proto()))
+	}
+
+	permanentWatts := uint32(2000)
+	ih := &InverterHeartbeat{PermanentWatts: &permanentWatts}
+	points = NormalizeProtoTelemetry("HW51TEST", ih, ts)
+	assert.Len(t, points, 1)
+	assert.Equal(t, "inverterHeartbeat.permanentWatts", points[0].Key)
+	assert.Equal(t, 2000.0, points[0].Value)
+}
+
+func TestDispatchTelemetryContext(t *testing.T) {
+	root, cancel := context.WithCancel(context.Background())
+	SetHandlerContext(root)
+	defer SetHandlerContext(context.Background())
+	oldTimeout := HandlerTimeout
+	HandlerTimeout = 50 * time.Millisecond
+	defer func() { HandlerTimeout = oldTimeout }()
+
+	var deadline time.Time
+	var hasDeadline bool
+	var canceled error
+	started := make(chan struct{}, 1)
+	unregister := RegisterTelemetryContextHandler(func(ctx context.Context, points []*Telemetry) {
+		deadline, hasDeadline = ctx.Deadline()
+		started <- struct{}{}
+		<-ctx.Done()
+		canceled = ctx.Err()
+	})
+	defer unregister()
+
+	points := []*Telemetry{{SerialNumber: "CTXTEST", Key: "soc", Value: 50.0}}
+	DispatchTelemetry(points)
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now(), deadline, time.Second)
+	assert.Equal(t, context.DeadlineExceeded, canceled)
+	<-started
+
+	HandlerTimeout = 0
+	go func() {
+		<-started
+		cancel()
+	}()
+	DispatchTelemetry(points)
+	assert.Equal(t, context.Canceled, canceled)
+}
+
+func TestDispatchTelemetryAfterTimeout(t *testing.T) {
+	oldTimeout := HandlerTimeout
+	HandlerTimeout = 20 * time.Millisecond
+	defer func() { HandlerTimeout = oldTimeout }()
+
+	// both handlers use up the timeout, the second one is called nevertheless
+	var observed []error
+	slow := func(ctx context.Context, points []*Telemetry) {
+		<-ctx.Done()
+		observed = append(observed, ctx.Err())
+	}
+	defer RegisterTelemetryContextHandler(slow)()
+	defer RegisterTelemetryContextHandler(slow)()
+
+	DispatchTelemetry([]*Telemetry{{SerialNumber: "CTXLATE", Key: "soc", Value: 50.0}})
+	assert.Equal(t, []error{context.DeadlineExceeded, context.DeadlineExceeded}, observed)
+}
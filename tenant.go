@@ -0,0 +1,248 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tknie/log"
+)
+
+// UnassignedTenant tenant of devices without assignment
+const UnassignedTenant = "unassigned"
+
+// Tenant customer and site a device belongs to
+type Tenant struct {
+	ID   string `json:"tenant"`
+	Site string `json:"site,omitempty"`
+}
+
+type tenantRule struct {
+	pattern string
+	tenant  Tenant
+}
+
+type tenantPartition struct {
+	nextID   uint64
+	handlers map[uint64]TelemetryContextHandler
+}
+
+// TenantRouter map serial numbers to tenants and partition telemetry streams,
+// sinks and metrics by tenant, for collectors serving several customers
+type TenantRouter struct {
+	mu         sync.RWMutex
+	devices    map[string]Tenant
+	rules      []tenantRule
+	partitions map[string]*tenantPartition
+	counters   map[string]*atomic.Uint64
+	unregister func()
+}
+
+// Tenants global tenant router, also used for the {tenant} and {site} topic placeholders
+var Tenants = NewTenantRouter()
+
+func init() {
+	expvar.Publish("ecoflowTenants", expvar.Func(func() any { return Tenants.Counters() }))
+}
+
+// NewTenantRouter create tenant router without assignments
+func NewTenantRouter() *TenantRouter {
+	return &TenantRouter{devices: make(map[string]Tenant), partitions: make(map[string]*tenantPartition),
+		counters: make(map[string]*atomic.Uint64)}
+}
+
+// Assign assign a serial number, alias or glob pattern like HW51* to the
+// tenant. Serial numbers take precedence over patterns, which are checked in
+// the order of assignment.
+func (r *TenantRouter) Assign(serialNumber string, tenant Tenant) error {
+	if tenant.ID == "" {
+		return fmt.Errorf("tenant of %s needs an id", serialNumber)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if strings.ContainsAny(serialNumber, "*?[") {
+		pattern := strings.ToUpper(serialNumber)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("tenant pattern %s: %w", serialNumber, err)
+		}
+		r.rules = append(r.rules, tenantRule{pattern: pattern, tenant: tenant})
+		return nil
+	}
+	r.devices[strings.ToUpper(ResolveDevice(serialNumber))] = tenant
+	return nil
+}
+
+// SetAssignments replace all assignments
+func (r *TenantRouter) SetAssignments(assignments map[string]Tenant) error {
+	next, err := newTenantAssignments(assignments)
+	if err != nil {
+		return err
+	}
+	r.replace(next)
+	return nil
+}
+
+// newTenantAssignments create router of the tenant assignments
+func newTenantAssignments(assignments map[string]Tenant) (*TenantRouter, error) {
+	next := NewTenantRouter()
+	keys := make([]string, 0, len(assignments))
+	for k := range assignments {
+		keys = append(keys, k)
+	}
+	// patterns of a map have no order, the longest pattern is the most specific
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		if err := next.Assign(k, assignments[k]); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// replace take over the assignments of the router
+func (r *TenantRouter) replace(next *TenantRouter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.devices = next.devices
+	r.rules = next.rules
+}
+
+// TenantOf return the tenant of the device, false if not assigned
+func (r *TenantRouter) TenantOf(serialNumber string) (Tenant, bool) {
+	sn := strings.ToUpper(serialNumber)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if t, ok := r.devices[sn]; ok {
+		return t, true
+	}
+	for _, rule := range r.rules {
+		if ok, _ := path.Match(rule.pattern, sn); ok {
+			return rule.tenant, true
+		}
+	}
+	return Tenant{ID: UnassignedTenant}, false
+}
+
+// Labels return tenant and site labels of the device for metrics
+func (r *TenantRouter) Labels(serialNumber string) map[string]string {
+	t, _ := r.TenantOf(serialNumber)
+	return map[string]string{"tenant": t.ID, "site": t.Site}
+}
+
+// Subscribe register handler receiving only telemetry of the tenant, the
+// returned function unregisters the handler
+func (r *TenantRouter) Subscribe(tenantID string, handler TelemetryContextHandler) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.partitions[tenantID]
+	if !ok {
+		p = &tenantPartition{handlers: make(map[uint64]TelemetryContextHandler)}
+		r.partitions[tenantID] = p
+	}
+	p.nextID++
+	id := p.nextID
+	p.handlers[id] = handler
+	if r.unregister == nil {
+		r.unregister = RegisterTelemetryContextHandler(r.Handle)
+	}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(p.handlers, id)
+	}
+}
+
+// AttachSink write telemetry of the tenant to the sink
+func (r *TenantRouter) AttachSink(tenantID string, sink Sink) func() {
+	return r.Subscribe(tenantID, func(ctx context.Context, points []*Telemetry) {
+		var err error
+		if cs, ok := sink.(ContextSink); ok {
+			err = cs.WriteContext(ctx, points)
+		} else {
+			err = sink.Write(points)
+		}
+		if err != nil {
+			log.Log.Errorf("Error writing telemetry of tenant %s to sink %T: %v", tenantID, sink, err)
+		}
+	})
+}
+
+// Handle partition the telemetry by tenant and call the handlers of each tenant
+func (r *TenantRouter) Handle(ctx context.Context, points []*Telemetry) {
+	partitioned := make(map[string][]*Telemetry)
+	for _, p := range points {
+		t, _ := r.TenantOf(p.SerialNumber)
+		partitioned[t.ID] = append(partitioned[t.ID], p)
+	}
+	for tenantID, tenantPoints := range partitioned {
+		r.count(tenantID, len(tenantPoints))
+		r.mu.RLock()
+		var handlers []TelemetryContextHandler
+		if p, ok := r.partitions[tenantID]; ok {
+			for _, h := range p.handlers {
+				handlers = append(handlers, h)
+			}
+		}
+		r.mu.RUnlock()
+		for _, h := range handlers {
+			callTelemetryHandler(ctx, h, tenantPoints)
+		}
+	}
+}
+
+func (r *TenantRouter) count(tenantID string, n int) {
+	r.mu.RLock()
+	c, ok := r.counters[tenantID]
+	r.mu.RUnlock()
+	if !ok {
+		r.mu.Lock()
+		if c, ok = r.counters[tenantID]; !ok {
+			c = &atomic.Uint64{}
+			r.counters[tenantID] = c
+		}
+		r.mu.Unlock()
+	}
+	c.Add(uint64(n))
+}
+
+// Counters return number of telemetry values per tenant
+func (r *TenantRouter) Counters() map[string]uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counters := make(map[string]uint64, len(r.counters))
+	for id, c := range r.counters {
+		counters[id] = c.Load()
+	}
+	return counters
+}
+
+// Close unregister the telemetry handler of the router
+func (r *TenantRouter) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unregister != nil {
+		r.unregister()
+		r.unregister = nil
+	}
+}
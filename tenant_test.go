@@ -0,0 +1,60 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantRouterPartition(t *testing.T) {
+	r := NewTenantRouter()
+	defer r.Close()
+	assert.NoError(t, r.Assign("hw51abc", Tenant{ID: "acme", Site: "roof"}))
+	assert.NoError(t, r.Assign("HW51*", Tenant{ID: "globex"}))
+	assert.Error(t, r.Assign("HW52*", Tenant{}))
+	assert.Error(t, r.Assign("HW[", Tenant{ID: "bad"}))
+
+	tenant, ok := r.TenantOf("HW51ABC")
+	assert.True(t, ok)
+	assert.Equal(t, Tenant{ID: "acme", Site: "roof"}, tenant)
+	tenant, ok = r.TenantOf("hw51xyz")
+	assert.True(t, ok)
+	assert.Equal(t, "globex", tenant.ID)
+	_, ok = r.TenantOf("R331")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]string{"tenant": UnassignedTenant, "site": ""}, r.Labels("R331"))
+
+	var acme, globex []*Telemetry
+	unsubscribe := r.Subscribe("acme", func(ctx context.Context, points []*Telemetry) { acme = append(acme, points...) })
+	r.Subscribe("globex", func(ctx context.Context, points []*Telemetry) { globex = append(globex, points...) })
+	points := []*Telemetry{{SerialNumber: "HW51ABC", Key: "a"}, {SerialNumber: "HW51XYZ", Key: "b"},
+		{SerialNumber: "HW51ABC", Key: "c"}, {SerialNumber: "R331", Key: "d"}}
+	r.Handle(context.Background(), points)
+	assert.Len(t, acme, 2)
+	assert.Len(t, globex, 1)
+	assert.Equal(t, map[string]uint64{"acme": 2, "globex": 1, UnassignedTenant: 1}, r.Counters())
+
+	unsubscribe()
+	r.Handle(context.Background(), points)
+	assert.Len(t, acme, 2)
+	assert.Len(t, globex, 2)
+}
+
+func TestTenantTopicPlaceholders(t *testing.T) {
+	assert.NoError(t, Tenants.SetAssignments(map[string]Tenant{"HW51*": {ID: "acme", Site: "roof"}}))
+	defer Tenants.SetAssignments(nil)
+	sink := NewRepublishSink(nil, "{tenant}/{site}/{sn}/{key}", false)
+	assert.Equal(t, "acme/roof/HW51ABC/watts", sink.Topic(&Telemetry{SerialNumber: "HW51ABC", Key: "watts"}))
+}
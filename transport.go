@@ -0,0 +1,134 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// TransportOptions keep-alive and connection pool tuning of the HTTP transport
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableHTTP2        bool
+	Timeout             time.Duration
+	// Resolver custom name resolution, the system resolver is used if nil
+	Resolver *ResolverOptions
+}
+
+// ClientOption option of the Ecoflow HTTP client
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	transport     TransportOptions
+	httpClient    *http.Client
+	canonicalBody bool
+	numbers       *NumberFormat
+}
+
+// defaultHTTPClient shared HTTP client used for all requests without customized client
+var defaultHTTPClient = NewHTTPClient(TransportOptions{})
+
+// WithMaxIdleConns set maximum number of idle (keep-alive) connections
+func WithMaxIdleConns(maxIdleConns, maxIdleConnsPerHost int) ClientOption {
+	return func(o *clientOptions) {
+		o.transport.MaxIdleConns = maxIdleConns
+		o.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+}
+
+// WithIdleConnTimeout set time an idle connection is kept open
+func WithIdleConnTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.transport.IdleConnTimeout = timeout
+	}
+}
+
+// WithDisableHTTP2 do not try to use HTTP/2
+func WithDisableHTTP2() ClientOption {
+	return func(o *clientOptions) {
+		o.transport.DisableHTTP2 = true
+	}
+}
+
+// WithTimeout set overall request timeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.transport.Timeout = timeout
+	}
+}
+
+// WithHTTPClient use a customized HTTP client, all transport options are ignored
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithCanonicalBody marshal request bodies with sorted keys and the number
+// format used for the signature, so the body matches the signed parameters
+func WithCanonicalBody() ClientOption {
+	return func(o *clientOptions) {
+		o.canonicalBody = true
+	}
+}
+
+// WithNumberFormat format floating point parameters of signature and body
+// using the given rules, e.g. for firmware expecting 2000.0 instead of 2000
+func WithNumberFormat(format *NumberFormat) ClientOption {
+	return func(o *clientOptions) {
+		o.numbers = format
+	}
+}
+
+// WithResolver resolve the API host using a custom DNS server, address family
+// preference and DNS cache
+func WithResolver(options ResolverOptions) ClientOption {
+	return func(o *clientOptions) {
+		o.transport.Resolver = &options
+	}
+}
+
+// NewHTTPClient create HTTP client with a tuned transport
+func NewHTTPClient(options TransportOptions) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.MaxIdleConns > 0 {
+		transport.MaxIdleConns = options.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	if options.Resolver != nil {
+		transport.DialContext = NewDialer(*options.Resolver).DialContext
+	}
+	if options.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return &http.Client{Transport: transport, Timeout: options.Timeout}
+}
+
+func (o *clientOptions) client() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+	if o.transport == (TransportOptions{}) {
+		return defaultHTTPClient
+	}
+	return NewHTTPClient(o.transport)
+}
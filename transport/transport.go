@@ -0,0 +1,45 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// Package transport stable v1 API selecting how commands reach the devices
+package transport
+
+import (
+	"github.com/tknie/ecoflow"
+)
+
+// Transport way of sending commands to a device
+type Transport = ecoflow.Transport
+
+// Router select the transport of each command
+type Router = ecoflow.Router
+
+// Options options of the HTTP transport
+type Options = ecoflow.TransportOptions
+
+// HTTP send commands using the signed REST API
+type HTTP = ecoflow.HTTPTransport
+
+// MQTT send commands using the app login MQTT connection
+type MQTT = ecoflow.MQTTTransport
+
+// Local send commands using the local network connection
+type Local = ecoflow.LocalTransport
+
+// NewRouter create router trying the transports in the given order
+func NewRouter(transports ...Transport) *Router {
+	return ecoflow.NewRouter(transports...)
+}
+
+// Default create default router of the client
+func Default(client *ecoflow.Client) *Router {
+	return ecoflow.DefaultRouter(client)
+}
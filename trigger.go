@@ -0,0 +1,226 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventTrigger rate of change trigger fired, Data contains TriggerFired
+const EventTrigger EventType = "telemetry.trigger"
+
+// TriggerDirection direction of the change a trigger rule reacts on
+type TriggerDirection int
+
+const (
+	// TriggerDrop value decreased by at least Delta
+	TriggerDrop TriggerDirection = iota
+	// TriggerRise value increased by at least Delta
+	TriggerRise
+	// TriggerAny value changed by at least Delta in any direction
+	TriggerAny
+)
+
+// TriggerRule rule applied to numeric telemetry values with keys matching the
+// case insensitive glob pattern. The rule fires if the value changed by Delta
+// within Window, afterwards it is quiet for Cooldown.
+type TriggerRule struct {
+	Name      string
+	Pattern   string
+	Direction TriggerDirection
+	Delta     float64
+	Window    time.Duration
+	Cooldown  time.Duration
+}
+
+// DefaultTriggerRules PV power dropping 300 W within 10 seconds (cloud) and
+// household load rising 500 W within 10 seconds (appliance start)
+var DefaultTriggerRules = []TriggerRule{
+	{Name: "cloud", Pattern: "*pv*watts*", Direction: TriggerDrop, Delta: 300, Window: 10 * time.Second, Cooldown: time.Minute},
+	{Name: "appliance-start", Pattern: "*powgetsysload*", Direction: TriggerRise, Delta: 500, Window: 10 * time.Second, Cooldown: time.Minute},
+}
+
+// TriggerFired fired trigger
+type TriggerFired struct {
+	Rule         string        `json:"rule"`
+	SerialNumber string        `json:"serialNumber"`
+	Key          string        `json:"key"`
+	From         float64       `json:"from"`
+	To           float64       `json:"to"`
+	Duration     time.Duration `json:"duration"`
+	// Rate change per second
+	Rate float64   `json:"rate"`
+	Time time.Time `json:"time"`
+}
+
+type triggerSample struct {
+	value float64
+	time  time.Time
+}
+
+type triggerState struct {
+	samples []triggerSample
+	quiet   map[string]time.Time
+}
+
+// TriggerEngine alerting stage publishing EventTrigger for fast changes of telemetry values
+type TriggerEngine struct {
+	Rules []TriggerRule
+
+	mu     sync.Mutex
+	states map[string]*triggerState
+}
+
+// NewTriggerEngine create trigger engine, invalid patterns return an error
+func NewTriggerEngine(rules ...TriggerRule) (*TriggerEngine, error) {
+	checked := make([]TriggerRule, 0, len(rules))
+	for _, r := range rules {
+		r.Pattern = strings.ToLower(r.Pattern)
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("trigger rule %s: %w", r.Name, err)
+		}
+		if r.Delta <= 0 || r.Window <= 0 {
+			return nil, fmt.Errorf("trigger rule %s needs positive delta and window", r.Name)
+		}
+		checked = append(checked, r)
+	}
+	return &TriggerEngine{Rules: checked, states: make(map[string]*triggerState)}, nil
+}
+
+// Handle check the values against the rules, usable as TelemetryHandler
+func (e *TriggerEngine) Handle(points []*Telemetry) {
+	for _, p := range points {
+		for _, fired := range e.check(p) {
+			log.Log.Infof("Trigger %s of %s %s: %v -> %v", fired.Rule, fired.SerialNumber, fired.Key, fired.From, fired.To)
+			Events.Publish(Event{Type: EventTrigger, SerialNumber: fired.SerialNumber, Time: fired.Time, Data: fired})
+		}
+	}
+}
+
+// matching return the rules matching the key and their longest window
+func (e *TriggerEngine) matching(key string) ([]TriggerRule, time.Duration) {
+	var rules []TriggerRule
+	var window time.Duration
+	for _, r := range e.Rules {
+		if ok, _ := path.Match(r.Pattern, key); ok {
+			rules = append(rules, r)
+			if r.Window > window {
+				window = r.Window
+			}
+		}
+	}
+	return rules, window
+}
+
+// check add the value to the samples and return the fired triggers
+func (e *TriggerEngine) check(p *Telemetry) []TriggerFired {
+	value, ok := p.Value.(float64)
+	if !ok {
+		return nil
+	}
+	rules, window := e.matching(strings.ToLower(p.Key))
+	if len(rules) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id := p.SerialNumber + "/" + p.Key
+	state := e.states[id]
+	if state == nil {
+		state = &triggerState{quiet: make(map[string]time.Time)}
+		e.states[id] = state
+	}
+	// drop samples outside of the longest window
+	keep := state.samples[:0]
+	for _, s := range state.samples {
+		if p.Timestamp.Sub(s.time) <= window {
+			keep = append(keep, s)
+		}
+	}
+	state.samples = keep
+
+	var fired []TriggerFired
+	for _, r := range rules {
+		if p.Timestamp.Before(state.quiet[r.Name]) {
+			continue
+		}
+		// reference is the extreme sample within the window of the rule
+		var ref *triggerSample
+		for i := range state.samples {
+			s := &state.samples[i]
+			if p.Timestamp.Sub(s.time) > r.Window {
+				continue
+			}
+			if ref == nil || changeOf(r.Direction, s.value, value) > changeOf(r.Direction, ref.value, value) {
+				ref = s
+			}
+		}
+		if ref == nil || changeOf(r.Direction, ref.value, value) < r.Delta {
+			continue
+		}
+		duration := p.Timestamp.Sub(ref.time)
+		f := TriggerFired{Rule: r.Name, SerialNumber: p.SerialNumber, Key: p.Key, From: ref.value, To: value,
+			Duration: duration, Time: p.Timestamp}
+		if duration > 0 {
+			f.Rate = (value - ref.value) / duration.Seconds()
+		}
+		fired = append(fired, f)
+		state.quiet[r.Name] = p.Timestamp.Add(r.Cooldown)
+	}
+	if len(fired) > 0 {
+		// the next trigger needs a new change starting at the current value
+		state.samples = state.samples[:0]
+	}
+	state.samples = append(state.samples, triggerSample{value: value, time: p.Timestamp})
+	return fired
+}
+
+// changeOf change from the reference to the value in the direction of the rule
+func changeOf(direction TriggerDirection, from, to float64) float64 {
+	switch direction {
+	case TriggerDrop:
+		return from - to
+	case TriggerRise:
+		return to - from
+	}
+	if to > from {
+		return to - from
+	}
+	return from - to
+}
+
+var triggerLock sync.RWMutex
+var triggerEngine *TriggerEngine
+
+// SetTriggerEngine set alerting stage applied to dispatched telemetry after the
+// anomaly detector, nil disables the triggers
+func SetTriggerEngine(e *TriggerEngine) {
+	triggerLock.Lock()
+	defer triggerLock.Unlock()
+	triggerEngine = e
+}
+
+// checkTriggers apply the trigger engine if set
+func checkTriggers(points []*Telemetry) {
+	triggerLock.RLock()
+	e := triggerEngine
+	triggerLock.RUnlock()
+	if e != nil {
+		e.Handle(points)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerEngine(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var fired []TriggerFired
+	unsubscribe := Events.Subscribe(func(e Event) { fired = append(fired, e.Data.(TriggerFired)) }, EventTrigger)
+	defer unsubscribe()
+
+	e, err := NewTriggerEngine(DefaultTriggerRules...)
+	if !assert.NoError(t, err) {
+		return
+	}
+	SetTriggerEngine(e)
+	defer SetTriggerEngine(nil)
+	point := func(key string, value float64, at time.Duration) *Telemetry {
+		return &Telemetry{SerialNumber: "HW51TRIGGER", Source: SourceMqtt, Key: key, Value: value, Timestamp: ts.Add(at)}
+	}
+	pv := "20_1.pv1InputWatts"
+	// slow decline is no cloud
+	DispatchTelemetry([]*Telemetry{point(pv, 600, 0)})
+	DispatchTelemetry([]*Telemetry{point(pv, 450, 8*time.Second)})
+	DispatchTelemetry([]*Telemetry{point(pv, 300, 16*time.Second)})
+	assert.Empty(t, fired)
+
+	DispatchTelemetry([]*Telemetry{point(pv, 620, 20*time.Second)})
+	DispatchTelemetry([]*Telemetry{point(pv, 280, 26*time.Second)})
+	if assert.Len(t, fired, 1) {
+		assert.Equal(t, "cloud", fired[0].Rule)
+		assert.Equal(t, 620.0, fired[0].From)
+		assert.Equal(t, 280.0, fired[0].To)
+		assert.InDelta(t, -340.0/6, fired[0].Rate, 0.01)
+	}
+	// cooldown suppresses the following drop
+	DispatchTelemetry([]*Telemetry{point(pv, 700, 30*time.Second), point(pv, 100, 32*time.Second)})
+	assert.Len(t, fired, 1)
+
+	load := "streamDisplayPropertyUpload.powGetSysLoad"
+	DispatchTelemetry([]*Telemetry{point(load, 150, 0), point(load, 900, 3*time.Second)})
+	if assert.Len(t, fired, 2) {
+		assert.Equal(t, "appliance-start", fired[1].Rule)
+	}
+
+	_, err = NewTriggerEngine(TriggerRule{Name: "invalid", Pattern: "*", Window: time.Second})
+	assert.Error(t, err)
+}
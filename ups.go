@@ -0,0 +1,189 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// EventUPSSwitchover AC input loss and inverter takeover of a UPS device, Data contains UPSSwitchover
+const EventUPSSwitchover EventType = "ups.switchover"
+
+// UPSSwitchover switchover from AC input to the inverter. The latency is
+// derived from the telemetry timestamps, so the resolution is limited by the
+// message interval of the device.
+type UPSSwitchover struct {
+	SerialNumber string        `json:"serialNumber"`
+	LostAt       time.Time     `json:"lostAt"`
+	TakeoverAt   time.Time     `json:"takeoverAt,omitempty"`
+	Latency      time.Duration `json:"latency"`
+	OutputWatts  float64       `json:"outputWatts"`
+	// Failed inverter did not take over within MaxLatency
+	Failed bool `json:"failed,omitempty"`
+}
+
+// UPSStatistics switchover statistics of a device
+type UPSStatistics struct {
+	SerialNumber string         `json:"serialNumber"`
+	Switchovers  int            `json:"switchovers"`
+	Failures     int            `json:"failures"`
+	MinLatency   time.Duration  `json:"minLatency"`
+	MaxLatency   time.Duration  `json:"maxLatency"`
+	MeanLatency  time.Duration  `json:"meanLatency"`
+	Last         *UPSSwitchover `json:"last,omitempty"`
+	total        time.Duration
+}
+
+type upsState struct {
+	inputLost  bool
+	lostAt     time.Time
+	outputOn   bool
+	statistics UPSStatistics
+}
+
+// UPSMonitor measure switchovers of devices used as UPS. The AC input is lost
+// if InputKey drops below InputBelow, the inverter took over as soon as
+// OutputKey is above OutputAbove while the input is lost.
+type UPSMonitor struct {
+	InputKey    string
+	InputBelow  float64
+	OutputKey   string
+	OutputAbove float64
+	// MaxLatency switchovers without takeover within this time are failures
+	MaxLatency time.Duration
+	devices    map[string]bool
+	mu         sync.Mutex
+	states     map[string]*upsState
+}
+
+// NewUPSMonitor create monitor of the Delta AC input voltage (mV) and inverter
+// output, all devices are monitored if none is given
+func NewUPSMonitor(devices ...string) *UPSMonitor {
+	m := &UPSMonitor{InputKey: "inv.acInVol", InputBelow: 80000, OutputKey: "inv.outputWatts",
+		MaxLatency: 30 * time.Second, devices: make(map[string]bool), states: make(map[string]*upsState)}
+	for _, d := range devices {
+		m.devices[strings.ToUpper(ResolveDevice(d))] = true
+	}
+	return m
+}
+
+// Start register the telemetry handler, the returned function unregisters it
+func (m *UPSMonitor) Start() func() {
+	return RegisterTelemetryHandler(func(points []*Telemetry) { m.Handle(points) })
+}
+
+func (m *UPSMonitor) state(sn string) *upsState {
+	s, ok := m.states[sn]
+	if !ok {
+		s = &upsState{statistics: UPSStatistics{SerialNumber: sn}}
+		m.states[sn] = s
+	}
+	return s
+}
+
+// Handle evaluate the telemetry and return the completed switchovers
+func (m *UPSMonitor) Handle(points []*Telemetry) []UPSSwitchover {
+	var switchovers []UPSSwitchover
+	m.mu.Lock()
+	for _, p := range points {
+		sn := strings.ToUpper(p.SerialNumber)
+		if len(m.devices) > 0 && !m.devices[sn] {
+			continue
+		}
+		value, ok := p.Value.(float64)
+		if !ok || (p.Key != m.InputKey && p.Key != m.OutputKey) {
+			continue
+		}
+		timestamp := p.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now()
+		}
+		s := m.state(sn)
+		if s.inputLost && !s.lostAt.IsZero() && timestamp.Sub(s.lostAt) > m.MaxLatency {
+			switchovers = append(switchovers, s.complete(UPSSwitchover{SerialNumber: sn, LostAt: s.lostAt,
+				Latency: timestamp.Sub(s.lostAt), Failed: true}))
+			s.lostAt = time.Time{}
+		}
+		switch p.Key {
+		case m.InputKey:
+			lost := value < m.InputBelow
+			if lost && !s.inputLost {
+				s.lostAt = timestamp
+			}
+			s.inputLost = lost
+		case m.OutputKey:
+			s.outputOn = value > m.OutputAbove
+			if s.outputOn && s.inputLost && !s.lostAt.IsZero() {
+				switchovers = append(switchovers, s.complete(UPSSwitchover{SerialNumber: sn, LostAt: s.lostAt,
+					TakeoverAt: timestamp, Latency: timestamp.Sub(s.lostAt), OutputWatts: value}))
+				s.lostAt = time.Time{}
+			}
+		}
+	}
+	m.mu.Unlock()
+	for _, sw := range switchovers {
+		if sw.Failed {
+			log.Log.Errorf("UPS switchover of %s failed, no inverter output within %v", sw.SerialNumber, sw.Latency)
+		} else {
+			log.Log.Infof("UPS switchover of %s after %v with %.0f W", sw.SerialNumber, sw.Latency, sw.OutputWatts)
+		}
+		Events.Publish(Event{Type: EventUPSSwitchover, SerialNumber: sw.SerialNumber, Time: sw.LostAt, Data: sw})
+	}
+	return switchovers
+}
+
+// complete add the switchover to the statistics
+func (s *upsState) complete(sw UPSSwitchover) UPSSwitchover {
+	st := &s.statistics
+	st.Last = &sw
+	if sw.Failed {
+		st.Failures++
+		return sw
+	}
+	if st.Switchovers == 0 || sw.Latency < st.MinLatency {
+		st.MinLatency = sw.Latency
+	}
+	if sw.Latency > st.MaxLatency {
+		st.MaxLatency = sw.Latency
+	}
+	st.Switchovers++
+	st.total += sw.Latency
+	st.MeanLatency = st.total / time.Duration(st.Switchovers)
+	return sw
+}
+
+// Statistics return the switchover statistics of all devices sorted by serial number
+func (m *UPSMonitor) Statistics() []UPSStatistics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]UPSStatistics, 0, len(m.states))
+	for _, s := range m.states {
+		if s.statistics.Switchovers > 0 || s.statistics.Failures > 0 {
+			stats = append(stats, s.statistics)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].SerialNumber < stats[j].SerialNumber })
+	return stats
+}
+
+// EnableUPS register the UPS switchover statistics endpoint
+func (s *StatusServer) EnableUPS(m *UPSMonitor) {
+	s.handle("GET /ups", ScopeTelemetryRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.Statistics())
+	}))
+}
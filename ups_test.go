@@ -0,0 +1,73 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUPSMonitor(t *testing.T) {
+	m := NewUPSMonitor("R351UPS")
+	start := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	point := func(key string, value float64, offset time.Duration) *Telemetry {
+		return &Telemetry{SerialNumber: "R351UPS", Key: key, Value: value, Timestamp: start.Add(offset)}
+	}
+
+	assert.Empty(t, m.Handle([]*Telemetry{point("inv.acInVol", 230000, 0), point("inv.outputWatts", 120, 0)}))
+	assert.Empty(t, m.Handle([]*Telemetry{point("inv.acInVol", 0, time.Second)}))
+	switchovers := m.Handle([]*Telemetry{point("inv.outputWatts", 118, 3*time.Second)})
+	if assert.Len(t, switchovers, 1) {
+		assert.Equal(t, 2*time.Second, switchovers[0].Latency)
+		assert.Equal(t, 118.0, switchovers[0].OutputWatts)
+	}
+	// further output values during the outage are no new switchover
+	assert.Empty(t, m.Handle([]*Telemetry{point("inv.outputWatts", 118, 5*time.Second)}))
+
+	// grid back, second switchover reported in the same message
+	m.Handle([]*Telemetry{point("inv.acInVol", 231000, time.Minute)})
+	assert.Len(t, m.Handle([]*Telemetry{point("inv.acInVol", 0, 2*time.Minute), point("inv.outputWatts", 90, 2*time.Minute)}), 1)
+
+	// no takeover within the maximum latency
+	m.Handle([]*Telemetry{point("inv.acInVol", 231000, 3*time.Minute)})
+	m.Handle([]*Telemetry{point("inv.acInVol", 0, 4*time.Minute), point("inv.outputWatts", 0, 4*time.Minute)})
+	failed := m.Handle([]*Telemetry{point("inv.outputWatts", 0, 5*time.Minute)})
+	if assert.Len(t, failed, 1) {
+		assert.True(t, failed[0].Failed)
+	}
+
+	// other devices are ignored
+	assert.Empty(t, m.Handle([]*Telemetry{{SerialNumber: "R351OTHER", Key: "inv.acInVol", Value: 0.0}}))
+
+	stats := m.Statistics()
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, 2, stats[0].Switchovers)
+		assert.Equal(t, 1, stats[0].Failures)
+		assert.Equal(t, time.Duration(0), stats[0].MinLatency)
+		assert.Equal(t, 2*time.Second, stats[0].MaxLatency)
+		assert.Equal(t, time.Second, stats[0].MeanLatency)
+		assert.True(t, stats[0].Last.Failed)
+	}
+
+	s := NewStatusServer("")
+	s.Auth = NewTokenAuth()
+	s.EnableUPS(m)
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ups", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), `"switchovers":2`))
+}
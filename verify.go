@@ -0,0 +1,75 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// VerifyTimeout time waiting for the MQTT echo of a command
+var VerifyTimeout = 30 * time.Second
+
+// ErrVerificationFailed device did not report the requested value
+var ErrVerificationFailed = errors.New("command verification failed")
+
+// verifyValue wait for the MQTT echo of the requested value reported after the
+// command was sent. If no echo is received the HTTP quota is checked. The result
+// is added to the audit log entry.
+func (client *Client) verifyValue(ctx context.Context, id, serialNumber string, sent time.Time, quotaKey string, mqttKeys []string, expected float64) error {
+	match := func(v StateValue) bool {
+		f, ok := v.Value.(float64)
+		return ok && f == expected && !v.Timestamp.Before(sent)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, VerifyTimeout)
+	defer cancel()
+	_, err := States.WaitFor(waitCtx, serialNumber, append(mqttKeys, quotaKey), match)
+	if err == nil {
+		return verified(id, serialNumber, "verified by mqtt echo", nil)
+	}
+	log.Log.Debugf("No MQTT echo of %s for %s: %v", quotaKey, serialNumber, err)
+	value, err := client.getQuotaValue(ctx, serialNumber, quotaKey)
+	if err != nil {
+		return verified(id, serialNumber, "verification error: "+err.Error(), err)
+	}
+	if value != expected {
+		return verified(id, serialNumber, fmt.Sprintf("verification failed: %s=%v", quotaKey, value),
+			fmt.Errorf("%w: %s is %v, expected %v", ErrVerificationFailed, quotaKey, value, expected))
+	}
+	return verified(id, serialNumber, "verified by http quota", nil)
+}
+
+// verified record the verification result and publish its lifecycle event
+func verified(id, serialNumber, result string, err error) error {
+	Audit.Verified(id, result)
+	publishCommandVerification(id, serialNumber, result, err)
+	return err
+}
+
+// getQuotaValue read numeric quota value using HTTP request
+func (client *Client) getQuotaValue(ctx context.Context, serialNumber, quotaKey string) (float64, error) {
+	m, err := client.GetDeviceAllParameters(ctx, serialNumber)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := m[quotaKey].(float64)
+	if !ok {
+		return 0, fmt.Errorf("quota %s not available for device %s", quotaKey, serialNumber)
+	}
+	States.Update([]*Telemetry{{SerialNumber: serialNumber, Source: SourceHttp,
+		Key: quotaKey, Value: value, Timestamp: now()}})
+	return value, nil
+}
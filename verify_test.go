@@ -0,0 +1,122 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateStoreWaitFor(t *testing.T) {
+	s := NewStateStore()
+	ts := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	match := func(v StateValue) bool { return v.Value == 1.0 }
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.Update([]*Telemetry{{SerialNumber: "hw51wait", Key: "other", Value: 1.0, Timestamp: ts}})
+		s.Update([]*Telemetry{{SerialNumber: "hw51wait", Key: "a", Value: 1.0, Timestamp: ts}})
+	}()
+	v, err := s.WaitFor(context.Background(), "HW51WAIT", []string{"a", "b"}, match)
+	assert.NoError(t, err)
+	assert.Equal(t, ts, v.Timestamp)
+
+	// older values do not replace the state
+	s.Update([]*Telemetry{{SerialNumber: "HW51WAIT", Key: "a", Value: 2.0, Timestamp: ts.Add(-time.Second)}})
+	v, ok := s.Get("HW51WAIT", "a")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, v.Value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.WaitFor(ctx, "HW51WAIT", []string{"b"}, match)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestVerifyValue(t *testing.T) {
+	oldTimeout := VerifyTimeout
+	VerifyTimeout = 20 * time.Millisecond
+	defer func() { VerifyTimeout = oldTimeout }()
+	transport := newPowerStreamAPI(map[string]interface{}{quotaSupplyPriority: 0.0})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+	sn := "HW51VERIFY"
+	Audit.Record(AuditEntry{ID: "verify-1", SerialNumber: sn})
+	Audit.Record(AuditEntry{ID: "verify-2", SerialNumber: sn})
+	Audit.Record(AuditEntry{ID: "verify-3", SerialNumber: sn})
+
+	// echo received after the command was sent
+	sent := time.Now()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		States.Update([]*Telemetry{{SerialNumber: sn, Key: mqttSupplyPriority, Value: 1.0, Timestamp: time.Now()}})
+	}()
+	assert.NoError(t, client.verifyValue(ctx, "verify-1", sn, sent, quotaSupplyPriority, []string{mqttSupplyPriority}, 1))
+
+	// the echo of the previous command is not taken for the next one
+	sent = time.Now().Add(time.Millisecond)
+	err := client.verifyValue(ctx, "verify-2", sn, sent, quotaSupplyPriority, []string{mqttSupplyPriority}, 1)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+
+	transport.set(quotaSupplyPriority, 1.0)
+	sent = time.Now().Add(time.Millisecond)
+	assert.NoError(t, client.verifyValue(ctx, "verify-3", sn, sent, quotaSupplyPriority, []string{mqttSupplyPriority}, 1))
+
+	results := make(map[string]string)
+	for _, e := range Audit.Query(AuditFilter{SerialNumber: sn}) {
+		results[e.ID] = e.Verification
+	}
+	assert.Equal(t, map[string]string{"verify-1": "verified by mqtt echo",
+		"verify-2": "verification failed: 20_1.supplyPriority=0", "verify-3": "verified by http quota"}, results)
+}
+
+func TestSupplyPriority(t *testing.T) {
+	oldTimeout := VerifyTimeout
+	VerifyTimeout = 10 * time.Millisecond
+	defer func() { VerifyTimeout = oldTimeout }()
+	states := States
+	States = NewStateStore()
+	t.Cleanup(func() { States = states })
+	transport := newPowerStreamAPI(map[string]interface{}{quotaSupplyPriority: 0.0})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	priority, err := client.GetSupplyPriority(ctx, "HW51PRIORITY")
+	assert.NoError(t, err)
+	assert.Equal(t, PrioritizePowerSupply, priority)
+
+	resp, err := client.SetSupplyPriority(ctx, "HW51PRIORITY", PrioritizeStorage)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, transport.puts())
+	if assert.NotNil(t, resp) && assert.NotEmpty(t, resp.Id) {
+		entries := Audit.Query(AuditFilter{SerialNumber: "HW51PRIORITY", Limit: 1})
+		assert.Equal(t, resp.Id, entries[0].ID)
+		assert.Equal(t, "verified by http quota", entries[0].Verification)
+	}
+	priority, err = client.GetSupplyPriority(ctx, "HW51PRIORITY")
+	assert.NoError(t, err)
+	assert.Equal(t, PrioritizeStorage, priority)
+
+	_, err = client.SetSupplyPriority(ctx, "HW51PRIORITY", SupplyPriority(5))
+	assert.Error(t, err)
+	assert.Equal(t, 1, transport.puts())
+
+	// outdated reported values are read again
+	States.Update([]*Telemetry{{SerialNumber: "HW51PRIOSTALE", Key: mqttSupplyPriority, Value: 1.0,
+		Timestamp: now().Add(-2 * SupplyPriorityMaxAge)}})
+	transport.set(quotaSupplyPriority, 0.0)
+	priority, err = client.GetSupplyPriority(ctx, "HW51PRIOSTALE")
+	assert.NoError(t, err)
+	assert.Equal(t, PrioritizePowerSupply, priority)
+}
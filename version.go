@@ -0,0 +1,45 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"sync/atomic"
+)
+
+// Version semantic version of the library API. The packages client, transport,
+// telemetry and commands are the stable v1 surface, exported names of this
+// package marked deprecated are removed in the next major version.
+const Version = "1.0.0"
+
+// DataCallback callback receiving the decoded JSON data of the devices
+type DataCallback func(serialNumber string, data map[string]interface{})
+
+var dataCallback atomic.Pointer[DataCallback]
+
+// SetDataCallback set the callback receiving the decoded JSON data of the
+// devices, nil removes the callback
+func SetDataCallback(callback DataCallback) {
+	if callback == nil {
+		dataCallback.Store(nil)
+		return
+	}
+	dataCallback.Store(&callback)
+}
+
+// currentDataCallback return callback set using SetDataCallback, the deprecated
+// Callback variable is used if none is set
+func currentDataCallback() DataCallback {
+	if c := dataCallback.Load(); c != nil {
+		return *c
+	}
+	return Callback
+}
@@ -0,0 +1,160 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tknie/log"
+)
+
+// WebhookSignatureHeader header containing the hex HMAC-SHA256 of the body
+const WebhookSignatureHeader = "X-Ecoflow-Signature"
+
+// webhookQueueSize number of events waiting for delivery, newer events are
+// dropped if the queue is full
+const webhookQueueSize = 100
+
+// WebhookConfig configuration of a webhook receiving events as JSON POST
+type WebhookConfig struct {
+	URL string
+	// Events event types delivered, the command lifecycle events if empty
+	Events []EventType
+	// Secret signs the body in the WebhookSignatureHeader if set
+	Secret  string
+	Timeout time.Duration
+	// Retry delivery retry policy, one attempt if nil
+	Retry *RetryPolicy
+}
+
+// Webhook deliver events to an HTTP endpoint, the delivery runs in the
+// background so commands are not delayed by slow receivers
+type Webhook struct {
+	config      WebhookConfig
+	client      *http.Client
+	queue       chan Event
+	unsubscribe func()
+	done        <-chan error
+	mu          sync.Mutex
+	closed      bool
+}
+
+// StartWebhook subscribe the webhook to the event bus and start the delivery
+func StartWebhook(config WebhookConfig, client *http.Client) *Webhook {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	types := config.Events
+	if len(types) == 0 {
+		types = CommandEvents
+	}
+	w := &Webhook{config: config, client: client, queue: make(chan Event, webhookQueueSize)}
+	w.unsubscribe = Events.Subscribe(w.enqueue, types...)
+	w.done = backgroundRuntime().GoContext(context.Background(), Component{Name: "webhook " + config.URL,
+		Run: w.run, Optional: true})
+	return w
+}
+
+func (w *Webhook) enqueue(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- event:
+	default:
+		log.Log.Errorf("Webhook %s queue full, drop %s event", w.config.URL, event.Type)
+	}
+}
+
+// run deliver the queued events until the queue is closed, the error reports
+// the failed deliveries
+func (w *Webhook) run(ctx context.Context) error {
+	var failed int
+	var lastErr error
+	for event := range w.queue {
+		policy := RetryPolicy{MaxAttempts: 1}
+		if w.config.Retry != nil {
+			policy = *w.config.Retry
+		}
+		err := policy.Retry(ctx, "webhook "+w.config.URL, func(ctx context.Context) error {
+			return w.deliver(ctx, event)
+		})
+		if err != nil {
+			log.Log.Errorf("Webhook %s delivery of %s failed: %v", w.config.URL, event.Type, err)
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d deliveries failed, last: %w", failed, lastErr)
+	}
+	return nil
+}
+
+// deliver post one event
+func (w *Webhook) deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Ecoflow-Event", string(event.Type))
+	if w.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.config.Secret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook status %s", resp.Status)
+	}
+	return nil
+}
+
+// Close unsubscribe the webhook and wait until queued events are delivered, the
+// error reports failed deliveries
+func (w *Webhook) Close() error {
+	w.unsubscribe()
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	return <-w.done
+}
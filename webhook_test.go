@@ -0,0 +1,68 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	var signatures []string
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e Event
+		_ = json.Unmarshal(body, &e)
+		mu.Lock()
+		events = append(events, e)
+		signatures = append(signatures, r.Header.Get(WebhookSignatureHeader))
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	webhook := StartWebhook(WebhookConfig{URL: server.URL, Secret: "secret"}, server.Client())
+
+	transport := newPowerStreamAPI(map[string]interface{}{"20_1.permanentWatts": 1000.0})
+	client := newTestClient(t, transport)
+	_, err := client.SetPermanentWatts(context.Background(), "HW51HOOK", 200)
+	assert.NoError(t, err)
+	observeFirmware("HW51HOOKFW", map[string]interface{}{"otaStatus": 2.0, "otaProgress": 50.0})
+	_, err = client.SetPermanentWatts(context.Background(), "HW51HOOKFW", 200)
+	assert.ErrorIs(t, err, ErrFirmwareUpdating)
+	publishCommandVerification("42", "HW51HOOK", "verified by mqtt echo", nil)
+	Events.Publish(Event{Type: EventFirmwareUpdate})
+	assert.NoError(t, webhook.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, EventCommandIssued, events[0].Type)
+		assert.Equal(t, EventCommandFailed, events[1].Type)
+		assert.Equal(t, EventCommandVerified, events[2].Type)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(bodies[0])
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signatures[0])
+	}
+}
@@ -0,0 +1,64 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// unchangedAcOut acOutCfg value leaving the setting unchanged
+const unchangedAcOut = 255
+
+// xboostModels device models supporting X-Boost of the AC output
+var xboostModels = map[DeviceModel]bool{
+	ModelDelta2:    true,
+	ModelDelta2Max: true,
+	ModelDeltaMax:  true,
+	ModelDeltaPro:  true,
+	ModelRiver2:    true,
+	ModelRiver2Max: true,
+	ModelRiver2Pro: true,
+}
+
+// EnableXBoost switch X-Boost of the AC output. The station limits the load
+// on its own, the command has no parameter for it.
+func (client *Client) EnableXBoost(ctx context.Context, serialNumber string, on bool) (*CmdSetResponse, error) {
+	model := DetectModel(serialNumber)
+	if !xboostModels[model] {
+		return nil, fmt.Errorf("X-Boost not supported for device %s", serialNumber)
+	}
+	cmdReq := CmdSetRequest{Sn: serialNumber}
+	switch model {
+	case ModelDelta2Max:
+		cmdReq.ModuleType = ModuleTypeInv
+		cmdReq.OperateType = "acOutCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": unchangedAcOut, "xboost": boolToInt(on),
+			"out_voltage": -1, "out_freq": unchangedAcOut}
+	case ModelDeltaMax, ModelDeltaPro:
+		// the command set interface need the AC output state together with X-Boost
+		enabled := 1
+		if v, ok := States.Get(serialNumber, "inv.cfgAcEnabled"); ok {
+			if f, ok := v.Value.(float64); ok {
+				enabled = int(f)
+			}
+		}
+		cmdReq.Params = map[string]interface{}{"cmdSet": 32, "id": 66,
+			"enabled": enabled, "xboost": boolToInt(on)}
+	default:
+		cmdReq.ModuleType = ModuleTypeMppt
+		cmdReq.OperateType = "acOutCfg"
+		cmdReq.Params = map[string]interface{}{"enabled": unchangedAcOut, "xboost": boolToInt(on),
+			"out_voltage": -1, "out_freq": unchangedAcOut}
+	}
+	return client.SetCommand(ctx, cmdReq)
+}
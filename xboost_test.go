@@ -0,0 +1,48 @@
+/*
+* Copyright 2025 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package ecoflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableXBoost(t *testing.T) {
+	transport := newFakeAPI(map[string]interface{}{})
+	client := newTestClient(t, transport)
+	ctx := context.Background()
+
+	States.Update([]*Telemetry{{SerialNumber: "DAEBXBOOST", Source: SourceHttp, Key: "inv.cfgAcEnabled",
+		Value: 0.0, Timestamp: time.Now()}})
+	_, err := client.EnableXBoost(ctx, "DAEBXBOOST", true)
+	assert.NoError(t, err)
+	_, err = client.EnableXBoost(ctx, "R351XBOOST", false)
+	assert.NoError(t, err)
+	_, err = client.EnableXBoost(ctx, "R621XBOOST", true)
+	assert.NoError(t, err)
+	if assert.Len(t, transport.requests, 3) {
+		assert.Equal(t, float64(66), transport.requests[0].Params["id"])
+		assert.Equal(t, float64(0), transport.requests[0].Params["enabled"])
+		assert.Equal(t, float64(1), transport.requests[0].Params["xboost"])
+		assert.Equal(t, ModuleTypeInv, transport.requests[1].ModuleType)
+		assert.Equal(t, float64(0), transport.requests[1].Params["xboost"])
+		assert.Equal(t, float64(unchangedAcOut), transport.requests[1].Params["enabled"])
+		assert.Equal(t, ModuleTypeMppt, transport.requests[2].ModuleType)
+	}
+
+	_, err = client.EnableXBoost(ctx, "HW51XBOOST", true)
+	assert.Error(t, err)
+	assert.Len(t, transport.requests, 3)
+}